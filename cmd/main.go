@@ -1,15 +1,28 @@
 package main
 
 import (
+	"context"
 	"log"
 	"log/slog"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/nsaltun/packman/config"
 	"github.com/nsaltun/packman/internal/app"
+	"github.com/nsaltun/packman/internal/auth"
 	"github.com/nsaltun/packman/internal/handler"
+	"github.com/nsaltun/packman/internal/idempotency"
+	"github.com/nsaltun/packman/internal/logging"
+	"github.com/nsaltun/packman/internal/metrics"
+	"github.com/nsaltun/packman/internal/middleware"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/outbox"
+	"github.com/nsaltun/packman/internal/ratelimit"
 	"github.com/nsaltun/packman/internal/repository"
 	"github.com/nsaltun/packman/internal/service"
+	"github.com/nsaltun/packman/internal/stream"
+	"github.com/nsaltun/packman/internal/tracing"
 	"github.com/nsaltun/packman/migrations"
 	"github.com/nsaltun/packman/pkg/postgres"
 )
@@ -21,38 +34,260 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	//Initialize logger
-	//TODO: implement log LEVEL from config
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	// Initialize logger, its level can be changed at runtime through the admin API
+	logger, logLevel := logging.NewLogger(cfg.Log, os.Stdout)
 	slog.SetDefault(logger)
 
 	// Initialize app with lifecycle management (for graceful shutdown)
 	application := app.New()
 
-	// Postgress DB client with connection pool
-	pgClient, err := postgres.NewClient(cfg.Database)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+	// Trace requests through the handlers, services and queries, closed last so the spans of the final
+	// requests are exported
+	if cfg.Tracing.Enabled {
+		tracingProvider, err := tracing.NewProvider(cfg.Tracing, os.Stdout)
+		if err != nil {
+			log.Fatalf("Failed to set up tracing: %v", err)
+		}
+		application.Register(tracingProvider)
 	}
-	application.Register(pgClient)
 
-	// DB migrations
-	if err := migrations.RunMigrations(pgClient.Pool, cfg.Database.URL); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	// Create repositories based on the configured storage
+	var packRepo repository.PackRepository
+	var pgClient *postgres.Client
+	var webhookRepo repository.WebhookRepository
+	// API keys are kept in memory without a database, only the bootstrap key survives a restart
+	apiKeyRepo := repository.NewMemoryAPIKeyRepo()
+	// Signals open change streams, fed by database notifications with PostgreSQL and by local writes otherwise
+	hub := stream.NewHub()
+	switch cfg.Storage.Type {
+	case config.StorageMemory:
+		slog.Warn("using in-memory storage, configuration changes are lost on restart")
+		packRepo = repository.NewNotifyingRepo(repository.NewMemoryRepo(), hub.Notify)
+	case config.StorageFile:
+		packRepo, err = repository.NewFileRepo(cfg.Storage.FilePath)
+		if err != nil {
+			log.Fatalf("Failed to open file storage: %v", err)
+		}
+		// changes by other processes sharing the file reach the streams with the next heartbeat
+		packRepo = repository.NewNotifyingRepo(packRepo, hub.Notify)
+	default:
+		// Postgress DB client with connection pool
+		pgClient, err = postgres.NewClient(cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		application.Register(pgClient)
+
+		// DB migrations
+		if err := migrations.RunMigrations(pgClient.Pool, cfg.Database.URL); err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
+
+		packRepo = repository.NewPostgresRepo(pgClient)
+		apiKeyRepo = repository.NewPostgresAPIKeyRepo(pgClient.Pool)
+
+		// Configuration change notifications for the cache, the change streams and the outbox relay
+		listener := postgres.NewListener(pgClient.Pool)
+
+		// Cache the active pack sizes, dropped whenever any instance changes them
+		if cfg.Cache.Enabled {
+			cachedRepo := repository.NewCachedRepo(packRepo, cfg.Cache.TTL)
+			listener.Subscribe(repository.PackConfigurationChannel, func(ctx context.Context, payload string) {
+				slog.DebugContext(ctx, "pack configuration changed", slog.String("version", payload))
+				// the replica may not have replayed the change yet, reload it from the primary
+				pgClient.MarkWrite()
+				cachedRepo.Invalidate()
+			})
+			// notifications may have been missed while disconnected
+			listener.OnConnect(func(ctx context.Context) {
+				cachedRepo.Invalidate()
+			})
+			packRepo = cachedRepo
+		}
+
+		// Push changes of any instance to the open streams, after the cache dropped the old configuration
+		listener.Subscribe(repository.PackConfigurationChannel, func(ctx context.Context, payload string) {
+			pgClient.MarkWrite()
+			hub.Notify()
+		})
+		// changes may have been missed while disconnected
+		listener.OnConnect(func(ctx context.Context) {
+			hub.Notify()
+		})
+
+		// Deliver configuration change events to the webhook subscriptions
+		webhookRepo = repository.NewPostgresWebhookRepo(pgClient.Pool)
+		dispatcher := outbox.NewWebhookDispatcher(webhookRepo, cfg.Webhooks)
+
+		// Relay configuration change events from the outbox to the subscriptions and the configured sinks
+		sinks, err := outbox.NewSinks(cfg.Outbox)
+		if err != nil {
+			log.Fatalf("Failed to create outbox sinks: %v", err)
+		}
+		sinks = append([]outbox.Sink{outbox.NewSubscriptionsSink(webhookRepo, dispatcher.Wake)}, sinks...)
+		relay := outbox.NewRelay(repository.NewPostgresOutboxRepo(pgClient.Pool), sinks, cfg.Outbox)
+		// events are committed together with the change, so its notification means one is waiting
+		listener.Subscribe(repository.PackConfigurationChannel, func(ctx context.Context, payload string) {
+			relay.Wake()
+		})
+
+		// closed in reverse order: the relay stops before the dispatcher, the listener and the pool
+		application.Register(listener)
+		application.Register(dispatcher)
+		application.Register(relay)
 	}
 
-	// Create repositories and services
-	packRepo := repository.NewPostgresRepo(pgClient.Pool)
-	packService := service.NewPackService(packRepo)
+	if cfg.Storage.Type != config.StoragePostgres && len(cfg.Outbox.Sinks) > 0 {
+		slog.Warn("the outbox relay requires PostgreSQL storage, configuration change events are not published")
+	}
+
+	// Create services
+	packService := service.NewPackService(packRepo, cfg.PackAnalysis, cfg.PackApproval)
+	if cfg.Tracing.Enabled {
+		packService = tracing.NewPackService(packService)
+	}
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, cfg.Auth.BootstrapKey)
+
+	// Expose Prometheus metrics of the requests, the calculations, the configuration and the connection pools
+	var appMetrics *metrics.Metrics
+	var httpMetrics middleware.HTTPMetrics
+	if cfg.Metrics.Enabled {
+		appMetrics = metrics.New()
+		httpMetrics = appMetrics
+		// calculations are recorded for both the HTTP and the gRPC API
+		packService = metrics.NewPackService(packService, appMetrics)
+		appMetrics.RegisterPackConfiguration(packService)
+		if pgClient != nil {
+			appMetrics.RegisterPool("primary", pgClient.Pool)
+			if pgClient.Replica != nil {
+				appMetrics.RegisterPool("replica", pgClient.Replica)
+			}
+		}
+	}
+
+	// Requests are checked against the API keys, and the tokens of the identity provider when configured,
+	// unless authentication is disabled
+	var authenticator middleware.Authenticator
+	if cfg.Auth.Enabled {
+		authenticator = apiKeyService
+		if keySet := newKeySet(cfg.Auth.JWT); keySet != nil {
+			authenticator = auth.NewDispatcher(auth.NewTokenVerifier(keySet, cfg.Auth.JWT), apiKeyService)
+		} else if cfg.Auth.BootstrapKey == "" {
+			requireAdminKey(apiKeyService)
+		}
+	} else {
+		slog.Warn("authentication is disabled, every request is allowed to read and change the configuration")
+	}
+
+	// Limit the requests of each client, per instance in memory or across instances in PostgreSQL
+	var limiter middleware.RateLimiter
+	if cfg.RateLimit.Enabled {
+		rateLimitRepo := repository.NewMemoryRateLimitRepo()
+		if cfg.RateLimit.Backend == config.RateLimitBackendPostgres {
+			rateLimitRepo = repository.NewPostgresRateLimitRepo(pgClient.Pool)
+		}
+		rateLimiter := ratelimit.NewLimiter(rateLimitRepo, cfg.RateLimit)
+		application.Register(rateLimiter)
+		limiter = rateLimiter
+	}
+
+	// Replay the responses to retried changes, across instances in PostgreSQL
+	var idempotencyKeys middleware.IdempotencyStore
+	if cfg.Idempotency.Enabled {
+		idempotencyRepo := repository.NewMemoryIdempotencyRepo()
+		if pgClient != nil {
+			idempotencyRepo = repository.NewPostgresIdempotencyRepo(pgClient.Pool)
+		}
+		idempotencyStore := idempotency.NewStore(idempotencyRepo, cfg.Idempotency)
+		application.Register(idempotencyStore)
+		idempotencyKeys = idempotencyStore
+	}
 
 	// Create handlers
-	packHandler := handler.NewPackHTTPHandler(packService)
-	healthHandler := handler.NewHealthHandler(pgClient)
+	serverOptions := handler.ServerOptions{
+		PackHandler:             handler.NewPackHTTPHandler(packService),
+		StreamHandler:           handler.NewPackStreamHTTPHandler(packService, hub, cfg.Stream),
+		APIKeyHandler:           handler.NewAPIKeyHTTPHandler(apiKeyService),
+		OpenAPIHandler:          handler.NewOpenAPIHTTPHandler(),
+		LogLevelHandler:         handler.NewLogLevelHTTPHandler(logLevel),
+		HealthHandler:           handler.NewHealthHandler(pgClient),
+		HTTPMetrics:             httpMetrics,
+		Auth:                    authenticator,
+		Limiter:                 limiter,
+		IdempotencyKeys:         idempotencyKeys,
+		IdempotencyMaxBodyBytes: cfg.Idempotency.MaxBodyBytes,
+	}
+	// metrics are served by the HTTP server unless they have their own port
+	if appMetrics != nil && cfg.Metrics.Port == "" {
+		serverOptions.MetricsHandler = handler.NewMetricsHTTPHandler(appMetrics.Handler())
+	}
+	// webhook subscriptions are stored in PostgreSQL only
+	if webhookRepo != nil {
+		serverOptions.WebhookHandler = handler.NewWebhookHTTPHandler(service.NewWebhookService(webhookRepo))
+	}
 
 	// Create server
-	server := handler.NewServer(packHandler, healthHandler, cfg.HTTP)
+	server := handler.NewServer(serverOptions, cfg.HTTP)
 	application.Register(server)
+	// closed before the server, which waits for open streams on shutdown
+	application.Register(hub)
+
+	// Serve the pack sizes to internal services over gRPC, with the same keys and limits as the HTTP API
+	if cfg.GRPC.Enabled {
+		grpcServer := handler.NewGRPCServer(handler.NewPackGRPCHandler(packService), pgClient, authenticator, limiter, cfg.GRPC)
+		application.Register(grpcServer)
+	}
+
+	// Serve the metrics on their own port, so they can be scraped without an API key
+	if appMetrics != nil && cfg.Metrics.Port != "" {
+		application.Register(handler.NewMetricsServer(appMetrics.Handler(), cfg.Metrics))
+	}
 
 	// Start all components and wait for shutdown signal
 	application.Run()
 }
+
+// requireAdminKey stops startup when no stored key can issue further keys, a first deploy without
+// AUTH_BOOTSTRAP_KEY or JWT_JWKS_URL would otherwise start without any way to manage the API keys
+func requireAdminKey(apiKeyService service.APIKeyService) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keys, err := apiKeyService.ListAPIKeys(ctx)
+	if err != nil {
+		log.Fatalf("Failed to check for an admin API key: %v", err)
+	}
+	for _, key := range keys {
+		if key.RevokedAt == nil && slices.Contains(key.Scopes, model.ScopeAdmin) {
+			slog.Warn("no AUTH_BOOTSTRAP_KEY is set, only previously issued API keys can access the API")
+			return
+		}
+	}
+	log.Fatalf("AUTH_BOOTSTRAP_KEY or JWT_JWKS_URL is required when AUTH_ENABLED is true and no admin API key is stored")
+}
+
+// newKeySet loads the signing keys of bearer tokens, it returns nil when tokens are not configured
+// A JWKS file must be readable at startup, a JWKS URL is retried on demand so the provider can start later
+func newKeySet(cfg config.JWTConfig) *auth.KeySet {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.FetchTimeout)
+	defer cancel()
+
+	switch {
+	case cfg.JWKSFile != "":
+		keySet := auth.NewFileKeySet(cfg.JWKSFile, cfg.RefreshInterval)
+		if err := keySet.Load(ctx); err != nil {
+			log.Fatalf("Failed to load JWT_JWKS_FILE: %v", err)
+		}
+		return keySet
+	case cfg.JWKSURL != "":
+		keySet := auth.NewURLKeySet(cfg.JWKSURL, cfg.RefreshInterval, cfg.FetchTimeout)
+		if err := keySet.Load(ctx); err != nil {
+			slog.Warn("failed to load token signing keys, tokens cannot be verified until they load",
+				slog.String("url", cfg.JWKSURL),
+				slog.String("error", err.Error()),
+			)
+		}
+		return keySet
+	}
+	return nil
+}
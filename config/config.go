@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -9,8 +12,22 @@ import (
 
 // Config holds the application configuration
 type Config struct {
-	HTTP     HttpConfig
-	Database DatabaseConfig
+	HTTP         HttpConfig
+	GRPC         GRPCConfig
+	Metrics      MetricsConfig
+	Tracing      TracingConfig
+	Log          LogConfig
+	Storage      StorageConfig
+	Database     DatabaseConfig
+	Cache        CacheConfig
+	Outbox       OutboxConfig
+	Webhooks     WebhookConfig
+	Stream       StreamConfig
+	Auth         AuthConfig
+	RateLimit    RateLimitConfig
+	Idempotency  IdempotencyConfig
+	PackAnalysis PackAnalysisConfig
+	PackApproval PackApprovalConfig
 }
 
 // HttpConfig holds the HTTP server settings
@@ -22,16 +39,84 @@ type HttpConfig struct {
 	CORS         CORSConfig
 }
 
+// GRPCConfig holds the gRPC server settings
+// The server listens on its own port next to the HTTP server. HealthCheckInterval is how often the
+// database is checked for the health service
+type GRPCConfig struct {
+	Enabled             bool          `env:"GRPC_ENABLED" envDefault:"false"`
+	Port                string        `env:"GRPC_PORT" envDefault:"9090"`
+	Reflection          bool          `env:"GRPC_REFLECTION_ENABLED" envDefault:"true"`
+	HealthCheckInterval time.Duration `env:"GRPC_HEALTH_CHECK_INTERVAL" envDefault:"10s"`
+}
+
+// MetricsConfig holds the Prometheus metrics settings
+// Without a Port, /metrics is served by the HTTP server and requires the read scope. With a Port, it is
+// served on that port without authentication, which should only be reachable by the scraper
+type MetricsConfig struct {
+	Enabled bool   `env:"METRICS_ENABLED" envDefault:"true"`
+	Port    string `env:"METRICS_PORT" envDefault:""`
+}
+
+// Tracing exporters
+const (
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+)
+
+// TracingConfig holds the OpenTelemetry tracing settings
+// Spans are exported with OTLP over gRPC to OTLPEndpoint, or written to stdout for local use. SampleRatio is
+// the share of new traces that are recorded, requests continuing a trace follow the decision of their caller
+type TracingConfig struct {
+	Enabled      bool    `env:"TRACING_ENABLED" envDefault:"false"`
+	Exporter     string  `env:"TRACING_EXPORTER" envDefault:"otlp"`
+	OTLPEndpoint string  `env:"TRACING_OTLP_ENDPOINT" envDefault:"localhost:4317"`
+	OTLPInsecure bool    `env:"TRACING_OTLP_INSECURE" envDefault:"true"`
+	SampleRatio  float64 `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
+	ServiceName  string  `env:"TRACING_SERVICE_NAME" envDefault:"packman"`
+}
+
+// Log formats
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// LogConfig holds the logger settings, the level can be changed at runtime through the admin API
+// With sampling, the first SamplingInitial records with the same level and message in each SamplingInterval
+// are logged and after that every SamplingThereafter-th. Warnings and errors are never sampled
+type LogConfig struct {
+	Level              slog.Level    `env:"LOG_LEVEL" envDefault:"info"`
+	Format             string        `env:"LOG_FORMAT" envDefault:"json"`
+	SamplingEnabled    bool          `env:"LOG_SAMPLING_ENABLED" envDefault:"false"`
+	SamplingInitial    int           `env:"LOG_SAMPLING_INITIAL" envDefault:"100"`
+	SamplingThereafter int           `env:"LOG_SAMPLING_THEREAFTER" envDefault:"100"`
+	SamplingInterval   time.Duration `env:"LOG_SAMPLING_INTERVAL" envDefault:"1s"`
+}
+
 // CORSConfig holds CORS settings
 type CORSConfig struct {
 	AllowOrigins     []string      `env:"CORS_ALLOW_ORIGINS" envDefault:"*"`
-	AllowMethods     []string      `env:"CORS_ALLOW_METHODS" envDefault:"GET,POST,PUT,DELETE,OPTIONS"`
-	AllowHeaders     []string      `env:"CORS_ALLOW_HEADERS" envDefault:"Origin,Content-Type,Accept,Authorization"`
-	ExposeHeaders    []string      `env:"CORS_EXPOSE_HEADERS" envDefault:"Content-Length"`
+	AllowMethods     []string      `env:"CORS_ALLOW_METHODS" envDefault:"GET,POST,PUT,PATCH,DELETE,OPTIONS"`
+	AllowHeaders     []string      `env:"CORS_ALLOW_HEADERS" envDefault:"Origin,Content-Type,Accept,Authorization,X-API-Key,Idempotency-Key,X-Request-ID,traceparent,tracestate"`
+	ExposeHeaders    []string      `env:"CORS_EXPOSE_HEADERS" envDefault:"Content-Length,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,RateLimit-Policy,Retry-After,Idempotent-Replayed"`
 	AllowCredentials bool          `env:"CORS_ALLOW_CREDENTIALS" envDefault:"false"`
 	MaxAge           time.Duration `env:"CORS_MAX_AGE" envDefault:"12h"`
 }
 
+// Storage backends
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+	StorageFile     = "file"
+)
+
+// StorageConfig selects where the pack configuration is stored
+// FilePath is the state file of the file storage, JSON or YAML by extension
+type StorageConfig struct {
+	Type     string `env:"STORAGE" envDefault:"postgres"`
+	FilePath string `env:"STORAGE_FILE_PATH" envDefault:"data/pack-configuration.json"`
+}
+
 // DatabaseConfig holds the database connection settings
 type DatabaseConfig struct {
 	URL               string        `env:"DATABASE_URL"`
@@ -40,6 +125,158 @@ type DatabaseConfig struct {
 	ConnMaxLifetime   time.Duration `env:"DB_CONN_MAX_LIFETIME" envDefault:"5m"`
 	MaxConnIdleTime   time.Duration `env:"DB_CONN_MAX_IDLE_TIME" envDefault:"5m"`
 	HealthCheckPeriod time.Duration `env:"DB_HEALTH_CHECK_PERIOD" envDefault:"1m"`
+	// Optional read-only replica for pack size and history reads
+	// Reads stay on the primary for ReadYourWritesWindow after a write and while the replica is unhealthy
+	ReplicaURL               string        `env:"DATABASE_REPLICA_URL"`
+	ReadYourWritesWindow     time.Duration `env:"DB_READ_YOUR_WRITES_WINDOW" envDefault:"5s"`
+	ReplicaHealthCheckPeriod time.Duration `env:"DB_REPLICA_HEALTH_CHECK_PERIOD" envDefault:"10s"`
+}
+
+// CacheConfig holds the settings of the in-memory cache of the active pack sizes used with PostgreSQL
+// Changes are picked up via LISTEN/NOTIFY, TTL bounds staleness when a notification is missed
+type CacheConfig struct {
+	Enabled bool          `env:"PACK_CACHE_ENABLED" envDefault:"true"`
+	TTL     time.Duration `env:"PACK_CACHE_TTL" envDefault:"30s"`
+}
+
+// Outbox sinks
+const (
+	OutboxSinkWebhook = "webhook"
+	OutboxSinkFile    = "file"
+	OutboxSinkStdout  = "stdout"
+)
+
+// OutboxConfig holds the settings of the relay delivering configuration change events, PostgreSQL only
+// Sinks is a comma separated list of webhook, file and stdout, the webhook subscriptions are always delivered to
+// Events are retried with exponential backoff starting at RetryBackoff and marked failed after MaxAttempts
+type OutboxConfig struct {
+	Sinks           []string      `env:"OUTBOX_SINKS"`
+	WebhookURL      string        `env:"OUTBOX_WEBHOOK_URL"`
+	FilePath        string        `env:"OUTBOX_FILE_PATH" envDefault:"data/pack-configuration-events.jsonl"`
+	PollInterval    time.Duration `env:"OUTBOX_POLL_INTERVAL" envDefault:"5s"`
+	BatchSize       int           `env:"OUTBOX_BATCH_SIZE" envDefault:"10"`
+	MaxAttempts     int           `env:"OUTBOX_MAX_ATTEMPTS" envDefault:"10"`
+	RetryBackoff    time.Duration `env:"OUTBOX_RETRY_BACKOFF" envDefault:"5s"`
+	DeliveryTimeout time.Duration `env:"OUTBOX_DELIVERY_TIMEOUT" envDefault:"10s"`
+	// WebhookAllowPrivateNetworks allows OUTBOX_WEBHOOK_URL to resolve to a loopback, link-local or private address
+	WebhookAllowPrivateNetworks bool `env:"OUTBOX_WEBHOOK_ALLOW_PRIVATE_NETWORKS" envDefault:"false"`
+}
+
+// WebhookConfig holds the settings of the dispatcher delivering events to webhook subscriptions, PostgreSQL only
+// Deliveries are retried with exponential backoff starting at RetryBackoff and dead-lettered after MaxAttempts
+type WebhookConfig struct {
+	PollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" envDefault:"5s"`
+	BatchSize    int           `env:"WEBHOOK_BATCH_SIZE" envDefault:"10"`
+	MaxAttempts  int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+	RetryBackoff time.Duration `env:"WEBHOOK_RETRY_BACKOFF" envDefault:"10s"`
+	Timeout      time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+	// AllowPrivateNetworks allows subscription URLs to resolve to a loopback, link-local or private address
+	AllowPrivateNetworks bool `env:"WEBHOOK_ALLOW_PRIVATE_NETWORKS" envDefault:"false"`
+}
+
+// StreamConfig holds the settings of the server-sent events stream of configuration changes
+// A comment is sent every HeartbeatInterval so proxies keep idle streams open, the current version is checked again then
+type StreamConfig struct {
+	HeartbeatInterval time.Duration `env:"STREAM_HEARTBEAT_INTERVAL" envDefault:"15s"`
+}
+
+// AuthConfig holds the API key authentication settings
+// BootstrapKey is an admin key that is not stored, it allows issuing the first stored keys and is the only
+// key that survives a restart with memory or file storage
+type AuthConfig struct {
+	Enabled      bool   `env:"AUTH_ENABLED" envDefault:"true"`
+	BootstrapKey string `env:"AUTH_BOOTSTRAP_KEY"`
+	JWT          JWTConfig
+}
+
+// JWTConfig holds the settings for bearer tokens issued by an OpenID Connect provider
+// Tokens are accepted next to API keys when JWKSURL or JWKSFile is set. The roles in RolesClaim, a dot separated
+// path such as realm_access.roles, are mapped to scopes with RoleMapping, or used as scope names when it is empty
+type JWTConfig struct {
+	JWKSURL         string              `env:"JWT_JWKS_URL"`
+	JWKSFile        string              `env:"JWT_JWKS_FILE"`
+	Issuer          string              `env:"JWT_ISSUER"`
+	Audience        string              `env:"JWT_AUDIENCE"`
+	NameClaim       string              `env:"JWT_NAME_CLAIM" envDefault:"sub"`
+	RolesClaim      string              `env:"JWT_ROLES_CLAIM" envDefault:"roles"`
+	RoleMapping     map[string][]string `env:"JWT_ROLE_MAPPING"`
+	ClockSkew       time.Duration       `env:"JWT_CLOCK_SKEW" envDefault:"30s"`
+	RefreshInterval time.Duration       `env:"JWT_JWKS_REFRESH_INTERVAL" envDefault:"1h"`
+	FetchTimeout    time.Duration       `env:"JWT_JWKS_FETCH_TIMEOUT" envDefault:"5s"`
+}
+
+// minBootstrapKeyLength keeps the bootstrap key from being guessable
+const minBootstrapKeyLength = 16
+
+// Rate limit backends
+const (
+	RateLimitBackendMemory   = "memory"
+	RateLimitBackendPostgres = "postgres"
+)
+
+// RateLimit allows Requests per Period, bursts of up to Requests are allowed after a quiet period
+// It is written as requests/period, e.g. 100/1m
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// String formats the limit the way it is configured
+func (l RateLimit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// RateLimitConfig holds the per-client request limits
+// Clients are identified by their API key or token, or by their IP address when anonymous.
+// Routes maps "METHOD /path" with the path as registered, e.g. "POST /api/v1/pack-sizes/drafts/:id/approve",
+// or a gRPC method by its full name, e.g. "/packman.v1.PackService/CalculatePacks",
+// to its own limit, the other routes share Default. The memory backend limits each instance on its own,
+// the postgres backend shares the limits between instances.
+// Address limits all requests of an IP address before their credentials are checked, so guessing API keys or
+// tokens is limited as well
+type RateLimitConfig struct {
+	Enabled         bool                 `env:"RATE_LIMIT_ENABLED" envDefault:"true"`
+	Backend         string               `env:"RATE_LIMIT_BACKEND" envDefault:"memory"`
+	Default         RateLimit            `env:"RATE_LIMIT_DEFAULT" envDefault:"300/1m"`
+	Address         RateLimit            `env:"RATE_LIMIT_ADDRESS" envDefault:"600/1m"`
+	Routes          map[string]RateLimit `env:"RATE_LIMIT_ROUTES"`
+	CleanupInterval time.Duration        `env:"RATE_LIMIT_CLEANUP_INTERVAL" envDefault:"10m"`
+}
+
+// IdempotencyConfig holds the settings of the Idempotency-Key header on mutating routes
+// The first response to a key is replayed for KeyTTL. A request that has not finished after LockTimeout,
+// e.g. because its instance crashed, may be retried with the same key. Bodies of requests with a key are
+// read to fingerprint them, larger ones than MaxBodyBytes are rejected
+type IdempotencyConfig struct {
+	Enabled         bool          `env:"IDEMPOTENCY_ENABLED" envDefault:"true"`
+	KeyTTL          time.Duration `env:"IDEMPOTENCY_KEY_TTL" envDefault:"24h"`
+	LockTimeout     time.Duration `env:"IDEMPOTENCY_LOCK_TIMEOUT" envDefault:"1m"`
+	CleanupInterval time.Duration `env:"IDEMPOTENCY_CLEANUP_INTERVAL" envDefault:"1h"`
+	MaxBodyBytes    int64         `env:"IDEMPOTENCY_MAX_BODY_BYTES" envDefault:"1048576"`
+}
+
+// Pack analysis modes
+const (
+	PackAnalysisModeWarn   = "warn"
+	PackAnalysisModeReject = "reject"
+	PackAnalysisModeOff    = "off"
+)
+
+// PackAnalysisConfig holds the policy for semantic analysis of pack size sets
+// Mode is one of "warn" (report problems), "reject" (fail updates with problems) or "off"
+type PackAnalysisConfig struct {
+	Mode             string `env:"PACK_ANALYSIS_MODE" envDefault:"warn"`
+	MaxPackSizes     int    `env:"PACK_ANALYSIS_MAX_PACK_SIZES" envDefault:"10"`
+	MaxCommonDivisor int    `env:"PACK_ANALYSIS_MAX_COMMON_DIVISOR" envDefault:"250"`
+	MaxOvershoot     int    `env:"PACK_ANALYSIS_MAX_OVERSHOOT" envDefault:"1000"`
+}
+
+// PackApprovalConfig holds the policy for changing the active pack sizes
+// Changes may go through drafts approved by a second user. DirectWrites also allows the PUT, PATCH and
+// import routes and the UpdatePackSizes RPC to change the active sizes right away, disabling it makes
+// drafts the only way to change them
+type PackApprovalConfig struct {
+	DirectWrites bool `env:"PACK_DIRECT_WRITES_ENABLED" envDefault:"true"`
 }
 
 // NewConfig returns a new instance of Config
@@ -74,34 +311,244 @@ func NewConfig() (*Config, error) {
 	vi.SetDefault("HTTP_WRITE_TIMEOUT", "10s")
 	vi.SetDefault("HTTP_IDLE_TIMEOUT", "60s")
 
+	// Set defaults for gRPC server
+	vi.SetDefault("GRPC_ENABLED", false)
+	vi.SetDefault("GRPC_PORT", "9090")
+	vi.SetDefault("GRPC_REFLECTION_ENABLED", true)
+	vi.SetDefault("GRPC_HEALTH_CHECK_INTERVAL", "10s")
+
+	// Set defaults for metrics
+	vi.SetDefault("METRICS_ENABLED", true)
+	vi.SetDefault("METRICS_PORT", "")
+
+	// Set defaults for tracing
+	vi.SetDefault("TRACING_ENABLED", false)
+	vi.SetDefault("TRACING_EXPORTER", TracingExporterOTLP)
+	vi.SetDefault("TRACING_OTLP_ENDPOINT", "localhost:4317")
+	vi.SetDefault("TRACING_OTLP_INSECURE", true)
+	vi.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	vi.SetDefault("TRACING_SERVICE_NAME", "packman")
+
+	// Set defaults for logging
+	vi.SetDefault("LOG_LEVEL", "info")
+	vi.SetDefault("LOG_FORMAT", LogFormatJSON)
+	vi.SetDefault("LOG_SAMPLING_ENABLED", false)
+	vi.SetDefault("LOG_SAMPLING_INITIAL", 100)
+	vi.SetDefault("LOG_SAMPLING_THEREAFTER", 100)
+	vi.SetDefault("LOG_SAMPLING_INTERVAL", "1s")
+
 	// Set defaults for CORS
 	vi.SetDefault("CORS_ALLOW_ORIGINS", "*")
-	vi.SetDefault("CORS_ALLOW_METHODS", "GET,POST,PUT,DELETE,OPTIONS")
-	vi.SetDefault("CORS_ALLOW_HEADERS", "Origin,Content-Type,Accept,Authorization")
-	vi.SetDefault("CORS_EXPOSE_HEADERS", "Content-Length")
+	vi.SetDefault("CORS_ALLOW_METHODS", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
+	vi.SetDefault("CORS_ALLOW_HEADERS", "Origin,Content-Type,Accept,Authorization,X-API-Key,Idempotency-Key,X-Request-ID,traceparent,tracestate")
+	vi.SetDefault("CORS_EXPOSE_HEADERS", "Content-Length,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,RateLimit-Policy,Retry-After,Idempotent-Replayed")
 	vi.SetDefault("CORS_ALLOW_CREDENTIALS", false)
 	vi.SetDefault("CORS_MAX_AGE", "12h")
 
+	// Set defaults for storage
+	vi.SetDefault("STORAGE", StoragePostgres)
+	vi.SetDefault("STORAGE_FILE_PATH", "data/pack-configuration.json")
+
 	// Set defaults for database connection pool
 	vi.SetDefault("DB_MAX_OPEN_CONNS", 20)
 	vi.SetDefault("DB_MAX_IDLE_CONNS", 5)
 	vi.SetDefault("DB_CONN_MAX_LIFETIME", "5m")
 	vi.SetDefault("DB_CONN_MAX_IDLE_TIME", "5m")
 	vi.SetDefault("DB_HEALTH_CHECK_PERIOD", "1m")
+	vi.SetDefault("DB_READ_YOUR_WRITES_WINDOW", "5s")
+	vi.SetDefault("DB_REPLICA_HEALTH_CHECK_PERIOD", "10s")
 
-	// Load database configuration from DATABASE_URL
+	// Set defaults for pack size cache
+	vi.SetDefault("PACK_CACHE_ENABLED", true)
+	vi.SetDefault("PACK_CACHE_TTL", "30s")
+
+	// Set defaults for outbox relay
+	vi.SetDefault("OUTBOX_SINKS", "")
+	vi.SetDefault("OUTBOX_FILE_PATH", "data/pack-configuration-events.jsonl")
+	vi.SetDefault("OUTBOX_POLL_INTERVAL", "5s")
+	vi.SetDefault("OUTBOX_BATCH_SIZE", 10)
+	vi.SetDefault("OUTBOX_MAX_ATTEMPTS", 10)
+	vi.SetDefault("OUTBOX_RETRY_BACKOFF", "5s")
+	vi.SetDefault("OUTBOX_DELIVERY_TIMEOUT", "10s")
+
+	// Set defaults for webhook subscriptions
+	vi.SetDefault("WEBHOOK_POLL_INTERVAL", "5s")
+	vi.SetDefault("WEBHOOK_BATCH_SIZE", 10)
+	vi.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	vi.SetDefault("WEBHOOK_RETRY_BACKOFF", "10s")
+	vi.SetDefault("WEBHOOK_TIMEOUT", "10s")
+	vi.SetDefault("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false)
+
+	// Set defaults for change stream
+	vi.SetDefault("STREAM_HEARTBEAT_INTERVAL", "15s")
+
+	// Set defaults for authentication
+	vi.SetDefault("AUTH_ENABLED", true)
+	vi.SetDefault("AUTH_BOOTSTRAP_KEY", "")
+	vi.SetDefault("JWT_NAME_CLAIM", "sub")
+	vi.SetDefault("JWT_ROLES_CLAIM", "roles")
+	vi.SetDefault("JWT_CLOCK_SKEW", "30s")
+	vi.SetDefault("JWT_JWKS_REFRESH_INTERVAL", "1h")
+	vi.SetDefault("JWT_JWKS_FETCH_TIMEOUT", "5s")
+
+	// Set defaults for rate limiting
+	vi.SetDefault("RATE_LIMIT_ENABLED", true)
+	vi.SetDefault("RATE_LIMIT_BACKEND", RateLimitBackendMemory)
+	vi.SetDefault("RATE_LIMIT_DEFAULT", "300/1m")
+	vi.SetDefault("RATE_LIMIT_ADDRESS", "600/1m")
+	vi.SetDefault("RATE_LIMIT_ROUTES", "")
+	vi.SetDefault("RATE_LIMIT_CLEANUP_INTERVAL", "10m")
+
+	// Set defaults for idempotency keys
+	vi.SetDefault("IDEMPOTENCY_ENABLED", true)
+	vi.SetDefault("IDEMPOTENCY_KEY_TTL", "24h")
+	vi.SetDefault("IDEMPOTENCY_LOCK_TIMEOUT", "1m")
+	vi.SetDefault("IDEMPOTENCY_CLEANUP_INTERVAL", "1h")
+	vi.SetDefault("IDEMPOTENCY_MAX_BODY_BYTES", 1<<20)
+
+	// Set defaults for pack size analysis
+	vi.SetDefault("PACK_ANALYSIS_MODE", PackAnalysisModeWarn)
+	vi.SetDefault("PACK_ANALYSIS_MAX_PACK_SIZES", 10)
+	vi.SetDefault("PACK_ANALYSIS_MAX_COMMON_DIVISOR", 250)
+	vi.SetDefault("PACK_ANALYSIS_MAX_OVERSHOOT", 1000)
+
+	// Set defaults for pack size approval
+	vi.SetDefault("PACK_DIRECT_WRITES_ENABLED", true)
+
+	storageConfig := StorageConfig{
+		Type:     vi.GetString("STORAGE"),
+		FilePath: vi.GetString("STORAGE_FILE_PATH"),
+	}
+	switch storageConfig.Type {
+	case StoragePostgres, StorageMemory, StorageFile:
+	default:
+		return nil, fmt.Errorf("STORAGE must be one of %s, %s, %s", StoragePostgres, StorageMemory, StorageFile)
+	}
+
+	// Load database configuration from DATABASE_URL, only required when storing in PostgreSQL
 	databaseURL := vi.GetString("DATABASE_URL")
-	if databaseURL == "" {
+	if databaseURL == "" && storageConfig.Type == StoragePostgres {
 		return nil, fmt.Errorf("DATABASE_URL environment variable is required")
 	}
 
+	outboxConfig, err := newOutboxConfig(vi)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookConfig := WebhookConfig{
+		PollInterval: vi.GetDuration("WEBHOOK_POLL_INTERVAL"),
+		BatchSize:    vi.GetInt("WEBHOOK_BATCH_SIZE"),
+		MaxAttempts:  vi.GetInt("WEBHOOK_MAX_ATTEMPTS"),
+		RetryBackoff: vi.GetDuration("WEBHOOK_RETRY_BACKOFF"),
+		Timeout:      vi.GetDuration("WEBHOOK_TIMEOUT"),
+
+		AllowPrivateNetworks: vi.GetBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS"),
+	}
+	if webhookConfig.PollInterval <= 0 || webhookConfig.BatchSize <= 0 || webhookConfig.MaxAttempts <= 0 || webhookConfig.Timeout <= 0 {
+		return nil, fmt.Errorf("WEBHOOK_POLL_INTERVAL, WEBHOOK_BATCH_SIZE, WEBHOOK_MAX_ATTEMPTS and WEBHOOK_TIMEOUT must be positive")
+	}
+
+	streamConfig := StreamConfig{
+		HeartbeatInterval: vi.GetDuration("STREAM_HEARTBEAT_INTERVAL"),
+	}
+	if streamConfig.HeartbeatInterval <= 0 {
+		return nil, fmt.Errorf("STREAM_HEARTBEAT_INTERVAL must be positive")
+	}
+
+	jwtConfig, err := newJWTConfig(vi)
+	if err != nil {
+		return nil, err
+	}
+
+	authConfig := AuthConfig{
+		Enabled:      vi.GetBool("AUTH_ENABLED"),
+		BootstrapKey: vi.GetString("AUTH_BOOTSTRAP_KEY"),
+		JWT:          *jwtConfig,
+	}
+	if authConfig.BootstrapKey != "" && len(authConfig.BootstrapKey) < minBootstrapKeyLength {
+		return nil, fmt.Errorf("AUTH_BOOTSTRAP_KEY must be at least %d characters", minBootstrapKeyLength)
+	}
+	// stored keys can only be issued with an admin key, without a database the bootstrap key or a token is the only way in
+	// With PostgreSQL, startup checks for a stored admin key instead
+	tokensEnabled := authConfig.JWT.JWKSURL != "" || authConfig.JWT.JWKSFile != ""
+	if authConfig.Enabled && authConfig.BootstrapKey == "" && !tokensEnabled && storageConfig.Type != StoragePostgres {
+		return nil, fmt.Errorf("AUTH_BOOTSTRAP_KEY or JWT_JWKS_URL is required when AUTH_ENABLED is true and STORAGE is %s", storageConfig.Type)
+	}
+
+	rateLimitConfig, err := newRateLimitConfig(vi, storageConfig.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	idempotencyConfig := IdempotencyConfig{
+		Enabled:         vi.GetBool("IDEMPOTENCY_ENABLED"),
+		KeyTTL:          vi.GetDuration("IDEMPOTENCY_KEY_TTL"),
+		LockTimeout:     vi.GetDuration("IDEMPOTENCY_LOCK_TIMEOUT"),
+		CleanupInterval: vi.GetDuration("IDEMPOTENCY_CLEANUP_INTERVAL"),
+		MaxBodyBytes:    vi.GetInt64("IDEMPOTENCY_MAX_BODY_BYTES"),
+	}
+	if idempotencyConfig.KeyTTL <= 0 || idempotencyConfig.LockTimeout <= 0 || idempotencyConfig.CleanupInterval <= 0 {
+		return nil, fmt.Errorf("IDEMPOTENCY_KEY_TTL, IDEMPOTENCY_LOCK_TIMEOUT and IDEMPOTENCY_CLEANUP_INTERVAL must be positive")
+	}
+	if idempotencyConfig.LockTimeout >= idempotencyConfig.KeyTTL {
+		return nil, fmt.Errorf("IDEMPOTENCY_LOCK_TIMEOUT must be shorter than IDEMPOTENCY_KEY_TTL")
+	}
+	if idempotencyConfig.MaxBodyBytes <= 0 {
+		return nil, fmt.Errorf("IDEMPOTENCY_MAX_BODY_BYTES must be positive")
+	}
+
+	logConfig, err := newLogConfig(vi)
+	if err != nil {
+		return nil, err
+	}
+
+	tracingConfig, err := newTracingConfig(vi)
+	if err != nil {
+		return nil, err
+	}
+
+	packAnalysisConfig, err := newPackAnalysisConfig(vi)
+	if err != nil {
+		return nil, err
+	}
+
+	grpcConfig := GRPCConfig{
+		Enabled:             vi.GetBool("GRPC_ENABLED"),
+		Port:                vi.GetString("GRPC_PORT"),
+		Reflection:          vi.GetBool("GRPC_REFLECTION_ENABLED"),
+		HealthCheckInterval: vi.GetDuration("GRPC_HEALTH_CHECK_INTERVAL"),
+	}
+	if grpcConfig.Enabled && grpcConfig.Port == vi.GetString("PORT") {
+		return nil, fmt.Errorf("GRPC_PORT must differ from PORT")
+	}
+	if grpcConfig.HealthCheckInterval <= 0 {
+		return nil, fmt.Errorf("GRPC_HEALTH_CHECK_INTERVAL must be positive")
+	}
+
+	metricsConfig := MetricsConfig{
+		Enabled: vi.GetBool("METRICS_ENABLED"),
+		Port:    vi.GetString("METRICS_PORT"),
+	}
+	if metricsConfig.Enabled && metricsConfig.Port != "" {
+		if metricsConfig.Port == vi.GetString("PORT") {
+			return nil, fmt.Errorf("METRICS_PORT must differ from PORT, leave it empty to serve metrics on PORT")
+		}
+		if grpcConfig.Enabled && metricsConfig.Port == grpcConfig.Port {
+			return nil, fmt.Errorf("METRICS_PORT must differ from GRPC_PORT")
+		}
+	}
+
 	dbConfig := DatabaseConfig{
-		URL:               databaseURL,
-		MaxOpenConns:      vi.GetInt("DB_MAX_OPEN_CONNS"),
-		MaxIdleConns:      vi.GetInt("DB_MAX_IDLE_CONNS"),
-		ConnMaxLifetime:   vi.GetDuration("DB_CONN_MAX_LIFETIME"),
-		MaxConnIdleTime:   vi.GetDuration("DB_CONN_MAX_IDLE_TIME"),
-		HealthCheckPeriod: vi.GetDuration("DB_HEALTH_CHECK_PERIOD"),
+		URL:                      databaseURL,
+		MaxOpenConns:             vi.GetInt("DB_MAX_OPEN_CONNS"),
+		MaxIdleConns:             vi.GetInt("DB_MAX_IDLE_CONNS"),
+		ConnMaxLifetime:          vi.GetDuration("DB_CONN_MAX_LIFETIME"),
+		MaxConnIdleTime:          vi.GetDuration("DB_CONN_MAX_IDLE_TIME"),
+		HealthCheckPeriod:        vi.GetDuration("DB_HEALTH_CHECK_PERIOD"),
+		ReplicaURL:               vi.GetString("DATABASE_REPLICA_URL"),
+		ReadYourWritesWindow:     vi.GetDuration("DB_READ_YOUR_WRITES_WINDOW"),
+		ReplicaHealthCheckPeriod: vi.GetDuration("DB_REPLICA_HEALTH_CHECK_PERIOD"),
 	}
 
 	return &Config{
@@ -119,6 +566,262 @@ func NewConfig() (*Config, error) {
 				MaxAge:           vi.GetDuration("CORS_MAX_AGE"),
 			},
 		},
+		GRPC:     grpcConfig,
+		Metrics:  metricsConfig,
+		Tracing:  *tracingConfig,
+		Log:      *logConfig,
+		Storage:  storageConfig,
 		Database: dbConfig,
+		Cache: CacheConfig{
+			Enabled: vi.GetBool("PACK_CACHE_ENABLED"),
+			TTL:     vi.GetDuration("PACK_CACHE_TTL"),
+		},
+		Outbox:       *outboxConfig,
+		Webhooks:     webhookConfig,
+		Stream:       streamConfig,
+		Auth:         authConfig,
+		RateLimit:    *rateLimitConfig,
+		Idempotency:  idempotencyConfig,
+		PackAnalysis: *packAnalysisConfig,
+		PackApproval: PackApprovalConfig{
+			DirectWrites: vi.GetBool("PACK_DIRECT_WRITES_ENABLED"),
+		},
 	}, nil
 }
+
+// newPackAnalysisConfig reads and validates the pack size analysis policy
+func newPackAnalysisConfig(vi *viper.Viper) (*PackAnalysisConfig, error) {
+	cfg := &PackAnalysisConfig{
+		Mode:             strings.ToLower(vi.GetString("PACK_ANALYSIS_MODE")),
+		MaxPackSizes:     vi.GetInt("PACK_ANALYSIS_MAX_PACK_SIZES"),
+		MaxCommonDivisor: vi.GetInt("PACK_ANALYSIS_MAX_COMMON_DIVISOR"),
+		MaxOvershoot:     vi.GetInt("PACK_ANALYSIS_MAX_OVERSHOOT"),
+	}
+	switch cfg.Mode {
+	case PackAnalysisModeWarn, PackAnalysisModeReject, PackAnalysisModeOff:
+	default:
+		return nil, fmt.Errorf("PACK_ANALYSIS_MODE must be one of %s, %s, %s", PackAnalysisModeWarn, PackAnalysisModeReject, PackAnalysisModeOff)
+	}
+	return cfg, nil
+}
+
+// newTracingConfig reads and validates the tracing settings
+func newTracingConfig(vi *viper.Viper) (*TracingConfig, error) {
+	tracingConfig := &TracingConfig{
+		Enabled:      vi.GetBool("TRACING_ENABLED"),
+		Exporter:     vi.GetString("TRACING_EXPORTER"),
+		OTLPEndpoint: vi.GetString("TRACING_OTLP_ENDPOINT"),
+		OTLPInsecure: vi.GetBool("TRACING_OTLP_INSECURE"),
+		SampleRatio:  vi.GetFloat64("TRACING_SAMPLE_RATIO"),
+		ServiceName:  vi.GetString("TRACING_SERVICE_NAME"),
+	}
+	if !tracingConfig.Enabled {
+		return tracingConfig, nil
+	}
+
+	switch tracingConfig.Exporter {
+	case TracingExporterOTLP:
+		if tracingConfig.OTLPEndpoint == "" {
+			return nil, fmt.Errorf("TRACING_OTLP_ENDPOINT is required for the %s exporter", TracingExporterOTLP)
+		}
+	case TracingExporterStdout:
+	default:
+		return nil, fmt.Errorf("TRACING_EXPORTER must be one of %s, %s", TracingExporterOTLP, TracingExporterStdout)
+	}
+	if tracingConfig.SampleRatio < 0 || tracingConfig.SampleRatio > 1 {
+		return nil, fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
+	if tracingConfig.ServiceName == "" {
+		return nil, fmt.Errorf("TRACING_SERVICE_NAME cannot be empty")
+	}
+	return tracingConfig, nil
+}
+
+// newLogConfig reads and validates the logger settings
+func newLogConfig(vi *viper.Viper) (*LogConfig, error) {
+	cfg := &LogConfig{
+		Format:             strings.ToLower(vi.GetString("LOG_FORMAT")),
+		SamplingEnabled:    vi.GetBool("LOG_SAMPLING_ENABLED"),
+		SamplingInitial:    vi.GetInt("LOG_SAMPLING_INITIAL"),
+		SamplingThereafter: vi.GetInt("LOG_SAMPLING_THEREAFTER"),
+		SamplingInterval:   vi.GetDuration("LOG_SAMPLING_INTERVAL"),
+	}
+	if err := cfg.Level.UnmarshalText([]byte(vi.GetString("LOG_LEVEL"))); err != nil {
+		return nil, fmt.Errorf("LOG_LEVEL must be one of debug, info, warn, error: %w", err)
+	}
+	switch cfg.Format {
+	case LogFormatJSON, LogFormatText:
+	default:
+		return nil, fmt.Errorf("LOG_FORMAT must be one of %s, %s", LogFormatJSON, LogFormatText)
+	}
+	if cfg.SamplingEnabled && (cfg.SamplingInitial <= 0 || cfg.SamplingThereafter <= 0 || cfg.SamplingInterval <= 0) {
+		return nil, fmt.Errorf("LOG_SAMPLING_INITIAL, LOG_SAMPLING_THEREAFTER and LOG_SAMPLING_INTERVAL must be positive")
+	}
+	return cfg, nil
+}
+
+// newOutboxConfig reads and validates the outbox relay settings
+func newOutboxConfig(vi *viper.Viper) (*OutboxConfig, error) {
+	cfg := &OutboxConfig{
+		WebhookURL:      vi.GetString("OUTBOX_WEBHOOK_URL"),
+		FilePath:        vi.GetString("OUTBOX_FILE_PATH"),
+		PollInterval:    vi.GetDuration("OUTBOX_POLL_INTERVAL"),
+		BatchSize:       vi.GetInt("OUTBOX_BATCH_SIZE"),
+		MaxAttempts:     vi.GetInt("OUTBOX_MAX_ATTEMPTS"),
+		RetryBackoff:    vi.GetDuration("OUTBOX_RETRY_BACKOFF"),
+		DeliveryTimeout: vi.GetDuration("OUTBOX_DELIVERY_TIMEOUT"),
+
+		WebhookAllowPrivateNetworks: vi.GetBool("OUTBOX_WEBHOOK_ALLOW_PRIVATE_NETWORKS"),
+	}
+
+	for _, sink := range strings.Split(vi.GetString("OUTBOX_SINKS"), ",") {
+		sink = strings.TrimSpace(sink)
+		switch sink {
+		case "":
+			continue
+		case OutboxSinkWebhook:
+			if cfg.WebhookURL == "" {
+				return nil, fmt.Errorf("OUTBOX_WEBHOOK_URL is required for the %s outbox sink", OutboxSinkWebhook)
+			}
+		case OutboxSinkFile, OutboxSinkStdout:
+		default:
+			return nil, fmt.Errorf("OUTBOX_SINKS entries must be one of %s, %s, %s", OutboxSinkWebhook, OutboxSinkFile, OutboxSinkStdout)
+		}
+		cfg.Sinks = append(cfg.Sinks, sink)
+	}
+
+	if cfg.PollInterval <= 0 || cfg.BatchSize <= 0 || cfg.MaxAttempts <= 0 || cfg.DeliveryTimeout <= 0 {
+		return nil, fmt.Errorf("OUTBOX_POLL_INTERVAL, OUTBOX_BATCH_SIZE, OUTBOX_MAX_ATTEMPTS and OUTBOX_DELIVERY_TIMEOUT must be positive")
+	}
+
+	return cfg, nil
+}
+
+// newJWTConfig reads and validates the bearer token settings
+func newJWTConfig(vi *viper.Viper) (*JWTConfig, error) {
+	cfg := &JWTConfig{
+		JWKSURL:         vi.GetString("JWT_JWKS_URL"),
+		JWKSFile:        vi.GetString("JWT_JWKS_FILE"),
+		Issuer:          vi.GetString("JWT_ISSUER"),
+		Audience:        vi.GetString("JWT_AUDIENCE"),
+		NameClaim:       vi.GetString("JWT_NAME_CLAIM"),
+		RolesClaim:      vi.GetString("JWT_ROLES_CLAIM"),
+		ClockSkew:       vi.GetDuration("JWT_CLOCK_SKEW"),
+		RefreshInterval: vi.GetDuration("JWT_JWKS_REFRESH_INTERVAL"),
+		FetchTimeout:    vi.GetDuration("JWT_JWKS_FETCH_TIMEOUT"),
+	}
+
+	if cfg.JWKSURL == "" && cfg.JWKSFile == "" {
+		return cfg, nil
+	}
+	if cfg.JWKSURL != "" && cfg.JWKSFile != "" {
+		return nil, fmt.Errorf("only one of JWT_JWKS_URL and JWT_JWKS_FILE can be set")
+	}
+	// tokens of other applications of the same provider must not be accepted
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return nil, fmt.Errorf("JWT_ISSUER and JWT_AUDIENCE are required with JWT_JWKS_URL or JWT_JWKS_FILE")
+	}
+	if cfg.NameClaim == "" || cfg.RolesClaim == "" {
+		return nil, fmt.Errorf("JWT_NAME_CLAIM and JWT_ROLES_CLAIM cannot be empty")
+	}
+	if cfg.ClockSkew < 0 || cfg.RefreshInterval <= 0 || cfg.FetchTimeout <= 0 {
+		return nil, fmt.Errorf("JWT_JWKS_REFRESH_INTERVAL and JWT_JWKS_FETCH_TIMEOUT must be positive and JWT_CLOCK_SKEW cannot be negative")
+	}
+
+	// JWT_ROLE_MAPPING is a comma separated list of role=scope pairs, e.g. packman-admins=admin,packman-viewers=read,
+	// a role listed more than once grants all its scopes
+	for _, pair := range strings.Split(vi.GetString("JWT_ROLE_MAPPING"), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		role, scope, found := strings.Cut(pair, "=")
+		role, scope = strings.TrimSpace(role), strings.TrimSpace(scope)
+		if !found || role == "" {
+			return nil, fmt.Errorf("JWT_ROLE_MAPPING entries must be role=scope pairs")
+		}
+		switch scope {
+		case "calculate", "read", "admin":
+		default:
+			return nil, fmt.Errorf("JWT_ROLE_MAPPING scopes must be one of calculate, read, admin")
+		}
+		if cfg.RoleMapping == nil {
+			cfg.RoleMapping = make(map[string][]string)
+		}
+		cfg.RoleMapping[role] = append(cfg.RoleMapping[role], scope)
+	}
+
+	return cfg, nil
+}
+
+// newRateLimitConfig reads and validates the rate limit settings
+func newRateLimitConfig(vi *viper.Viper, storage string) (*RateLimitConfig, error) {
+	cfg := &RateLimitConfig{
+		Enabled:         vi.GetBool("RATE_LIMIT_ENABLED"),
+		Backend:         vi.GetString("RATE_LIMIT_BACKEND"),
+		CleanupInterval: vi.GetDuration("RATE_LIMIT_CLEANUP_INTERVAL"),
+	}
+
+	switch cfg.Backend {
+	case RateLimitBackendMemory:
+	case RateLimitBackendPostgres:
+		if storage != StoragePostgres {
+			return nil, fmt.Errorf("RATE_LIMIT_BACKEND %s requires STORAGE %s", RateLimitBackendPostgres, StoragePostgres)
+		}
+	default:
+		return nil, fmt.Errorf("RATE_LIMIT_BACKEND must be one of %s, %s", RateLimitBackendMemory, RateLimitBackendPostgres)
+	}
+	if cfg.CleanupInterval <= 0 {
+		return nil, fmt.Errorf("RATE_LIMIT_CLEANUP_INTERVAL must be positive")
+	}
+
+	var err error
+	if cfg.Default, err = parseRateLimit(vi.GetString("RATE_LIMIT_DEFAULT")); err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT_DEFAULT %w", err)
+	}
+	if cfg.Address, err = parseRateLimit(vi.GetString("RATE_LIMIT_ADDRESS")); err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT_ADDRESS %w", err)
+	}
+
+	// RATE_LIMIT_ROUTES is a comma separated list of route=limit pairs, e.g. POST /api/v1/calculate=600/1m
+	for _, pair := range strings.Split(vi.GetString("RATE_LIMIT_ROUTES"), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		route, raw, found := strings.Cut(pair, "=")
+		method, path, hasPath := strings.Cut(strings.TrimSpace(route), " ")
+		path = strings.TrimSpace(path)
+		if !found || !hasPath || method == "" || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("RATE_LIMIT_ROUTES entries must look like METHOD /path=requests/period")
+		}
+		limit, err := parseRateLimit(raw)
+		if err != nil {
+			return nil, fmt.Errorf("RATE_LIMIT_ROUTES limit of %s %w", route, err)
+		}
+		if cfg.Routes == nil {
+			cfg.Routes = make(map[string]RateLimit)
+		}
+		cfg.Routes[strings.ToUpper(method)+" "+path] = limit
+	}
+
+	return cfg, nil
+}
+
+// parseRateLimit parses a requests/period limit such as 100/1m
+func parseRateLimit(raw string) (RateLimit, error) {
+	requests, period, found := strings.Cut(strings.TrimSpace(raw), "/")
+	if !found {
+		return RateLimit{}, fmt.Errorf("must look like requests/period, e.g. 100/1m")
+	}
+
+	var limit RateLimit
+	var err error
+	if limit.Requests, err = strconv.Atoi(requests); err != nil || limit.Requests <= 0 {
+		return RateLimit{}, fmt.Errorf("requests must be a positive integer")
+	}
+	if limit.Period, err = time.ParseDuration(period); err != nil || limit.Period <= 0 {
+		return RateLimit{}, fmt.Errorf("period must be a positive duration, e.g. 1m")
+	}
+	return limit, nil
+}
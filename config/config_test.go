@@ -0,0 +1,28 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfig_DirectWritesEnabledByDefault(t *testing.T) {
+	// NewConfig looks for .env and .env.example up to two directories up, none of them exist here
+	dir := filepath.Join(t.TempDir(), "a", "b", "c")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	t.Chdir(dir)
+	t.Setenv("STORAGE", StorageMemory)
+	t.Setenv("AUTH_ENABLED", "false")
+
+	cfg, err := NewConfig()
+	require.NoError(t, err)
+	assert.True(t, cfg.PackApproval.DirectWrites)
+
+	t.Setenv("PACK_DIRECT_WRITES_ENABLED", "false")
+	cfg, err = NewConfig()
+	require.NoError(t, err)
+	assert.False(t, cfg.PackApproval.DirectWrites)
+}
@@ -10,18 +10,36 @@ type ErrorCode string
 
 const (
 	// Client errors (4xx)
-	ErrCodeValidation   ErrorCode = "VALIDATION_ERROR"
-	ErrCodeNotFound     ErrorCode = "NOT_FOUND"
-	ErrCodeConflict     ErrorCode = "CONFLICT"
-	ErrCodeBadRequest   ErrorCode = "BAD_REQUEST"
-	ErrCodeUnauthorized ErrorCode = "UNAUTHORIZED"
-	ErrCodeForbidden    ErrorCode = "FORBIDDEN"
+	ErrCodeValidation    ErrorCode = "VALIDATION_ERROR"
+	ErrCodeNotFound      ErrorCode = "NOT_FOUND"
+	ErrCodeConflict      ErrorCode = "CONFLICT"
+	ErrCodeBadRequest    ErrorCode = "BAD_REQUEST"
+	ErrCodeUnauthorized  ErrorCode = "UNAUTHORIZED"
+	ErrCodeForbidden     ErrorCode = "FORBIDDEN"
+	ErrCodeTooLarge      ErrorCode = "PAYLOAD_TOO_LARGE"
+	ErrCodeUnprocessable ErrorCode = "UNPROCESSABLE_ENTITY"
+	ErrCodeRateLimited   ErrorCode = "RATE_LIMITED"
 
 	// Server errors (5xx)
 	ErrCodeInternal       ErrorCode = "INTERNAL_ERROR"
 	ErrCodeServiceUnavail ErrorCode = "SERVICE_UNAVAILABLE"
 )
 
+// Codes lists every error code, e.g. for the API specification
+var Codes = []ErrorCode{
+	ErrCodeValidation,
+	ErrCodeNotFound,
+	ErrCodeConflict,
+	ErrCodeBadRequest,
+	ErrCodeUnauthorized,
+	ErrCodeForbidden,
+	ErrCodeTooLarge,
+	ErrCodeUnprocessable,
+	ErrCodeRateLimited,
+	ErrCodeInternal,
+	ErrCodeServiceUnavail,
+}
+
 // AppError is the base error type that includes metadata
 type AppError struct {
 	Code       ErrorCode              // Machine-readable error code
@@ -93,6 +111,46 @@ func ConflictError(message string, internal error) *AppError {
 	return NewAppError(ErrCodeConflict, message, http.StatusConflict, internal)
 }
 
+// UnauthorizedError creates a 401 error for requests without valid credentials
+func UnauthorizedError(message string, internal error) *AppError {
+	if message == "" {
+		message = "Authentication required"
+	}
+	return NewAppError(ErrCodeUnauthorized, message, http.StatusUnauthorized, internal)
+}
+
+// ForbiddenError creates a 403 forbidden error
+func ForbiddenError(message string, internal error) *AppError {
+	if message == "" {
+		message = "Forbidden"
+	}
+	return NewAppError(ErrCodeForbidden, message, http.StatusForbidden, internal)
+}
+
+// PayloadTooLargeError creates a 413 error for request bodies over the accepted size
+func PayloadTooLargeError(message string, internal error) *AppError {
+	if message == "" {
+		message = "Request body is too large"
+	}
+	return NewAppError(ErrCodeTooLarge, message, http.StatusRequestEntityTooLarge, internal)
+}
+
+// UnprocessableError creates a 422 error for well-formed requests that cannot be processed
+func UnprocessableError(message string, internal error) *AppError {
+	if message == "" {
+		message = "Request cannot be processed"
+	}
+	return NewAppError(ErrCodeUnprocessable, message, http.StatusUnprocessableEntity, internal)
+}
+
+// RateLimitedError creates a 429 error for callers that exceeded their request limit
+func RateLimitedError(message string, internal error) *AppError {
+	if message == "" {
+		message = "Too many requests"
+	}
+	return NewAppError(ErrCodeRateLimited, message, http.StatusTooManyRequests, internal)
+}
+
 // InternalError creates a 500 internal server error
 func InternalError(message string, internal error) *AppError {
 	if message == "" {
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// Authenticator resolves the caller from a credential
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*model.Principal, error)
+}
+
+// Dispatcher authenticates JWTs with the token verifier and every other credential with the API keys
+type Dispatcher struct {
+	tokens Authenticator
+	keys   Authenticator
+}
+
+// NewDispatcher creates an authenticator accepting both tokens and API keys
+func NewDispatcher(tokens, keys Authenticator) *Dispatcher {
+	return &Dispatcher{tokens: tokens, keys: keys}
+}
+
+// Authenticate resolves the caller with the authenticator matching the credential
+func (d *Dispatcher) Authenticate(ctx context.Context, credential string) (*model.Principal, error) {
+	if isJWT(credential) {
+		return d.tokens.Authenticate(ctx, credential)
+	}
+	return d.keys.Authenticate(ctx, credential)
+}
+
+// isJWT reports whether a credential looks like a compact JWS, three segments starting with an encoded JSON header
+func isJWT(credential string) bool {
+	return strings.HasPrefix(credential, "eyJ") && strings.Count(credential, ".") == 2
+}
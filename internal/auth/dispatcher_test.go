@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// namedAuthenticator authenticates every credential as a caller with its name
+type namedAuthenticator string
+
+func (a namedAuthenticator) Authenticate(ctx context.Context, credential string) (*model.Principal, error) {
+	return &model.Principal{Name: string(a)}, nil
+}
+
+func TestDispatcher(t *testing.T) {
+	dispatcher := NewDispatcher(namedAuthenticator("tokens"), namedAuthenticator("keys"))
+
+	tests := map[string]string{
+		"eyJhbGciOiJSUzI1NiJ9.eyJzdWIiOiJhbGljZSJ9.c2ln": "tokens",
+		"pmk_0123456789abcdef":                           "keys",
+		"bootstrap.key.with.dots":                        "keys",
+	}
+	for credential, expected := range tests {
+		principal, err := dispatcher.Authenticate(context.Background(), credential)
+		require.NoError(t, err)
+		assert.Equal(t, expected, principal.Name, credential)
+	}
+}
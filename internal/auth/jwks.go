@@ -0,0 +1,299 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// minReloadInterval limits how often tokens naming an unknown key can make the key set reload
+const minReloadInterval = 10 * time.Second
+
+// maxJWKSBytes caps the size of a JWKS document
+const maxJWKSBytes = 1 << 20
+
+// reloadKey is the singleflight key of a reload, a key set has a single document
+const reloadKey = "jwks"
+
+var (
+	// ErrUnknownKey indicates a token was signed with a key that is not in the key set
+	ErrUnknownKey = errors.New("unknown signing key")
+	// ErrKeysUnavailable indicates the key set could not be loaded
+	ErrKeysUnavailable = errors.New("signing keys unavailable")
+)
+
+// KeySet holds the public keys tokens are verified with, loaded from a JWKS document
+// The keys are reloaded once they are older than the refresh interval, and when a token names an unknown key,
+// so a key rotation at the provider is picked up without a restart
+type KeySet struct {
+	load            func(ctx context.Context) ([]byte, error)
+	refreshInterval time.Duration
+	// reloads shares one load between concurrent callers, mu is not held while loading
+	reloads singleflight.Group
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	loadedAt  time.Time
+	attemptAt time.Time
+	loadErr   error
+}
+
+// NewFileKeySet creates a key set read from a JWKS file
+func NewFileKeySet(path string, refreshInterval time.Duration) *KeySet {
+	return &KeySet{
+		load: func(ctx context.Context) ([]byte, error) {
+			return os.ReadFile(path)
+		},
+		refreshInterval: refreshInterval,
+	}
+}
+
+// NewURLKeySet creates a key set fetched from a JWKS URL, usually the jwks_uri of an OpenID Connect provider
+func NewURLKeySet(url string, refreshInterval, timeout time.Duration) *KeySet {
+	client := &http.Client{Timeout: timeout}
+	return &KeySet{
+		load: func(ctx context.Context) ([]byte, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Accept", "application/json")
+
+			resp, err := client.Do(req)
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("jwks endpoint responded with status %d", resp.StatusCode)
+			}
+			return io.ReadAll(io.LimitReader(resp.Body, maxJWKSBytes))
+		},
+		refreshInterval: refreshInterval,
+	}
+}
+
+// Load reads the key set, it is called at startup to report a misconfiguration early
+func (s *KeySet) Load(ctx context.Context) error {
+	_, err, _ := s.reloads.Do(reloadKey, func() (any, error) {
+		return nil, s.reload(ctx)
+	})
+	return err
+}
+
+// Key returns the key with the given ID, an empty kid matches the only key of a set with a single key
+// Stale keys are still used when reloading them fails, the provider being down does not lock every client out.
+// A stale key is returned right away while the set reloads in the background, only a token naming an unknown
+// key waits for the reload
+func (s *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	key, found := s.lookup(kid)
+	refresh := time.Since(s.loadedAt) >= s.refreshInterval && time.Since(s.attemptAt) >= minReloadInterval
+	s.mu.Unlock()
+
+	// an unknown key always joins a reload in flight, reloadIfDue keeps it from starting one too often
+	if !found || refresh {
+		// the load outlives the request that started it, other requests may be waiting for it
+		reloadCtx := context.WithoutCancel(ctx)
+		done := s.reloads.DoChan(reloadKey, func() (any, error) {
+			err := s.reloadIfDue(reloadCtx)
+			if err != nil {
+				slog.WarnContext(reloadCtx, "failed to reload signing keys", slog.String("error", err.Error()))
+			}
+			return nil, err
+		})
+		if found {
+			return key, nil
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, found = s.lookup(kid)
+	if !found {
+		if s.keys == nil {
+			return nil, fmt.Errorf("%w: %w", ErrKeysUnavailable, s.loadErr)
+		}
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// lookup finds a key in the loaded set, the caller holds mu
+func (s *KeySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// reloadIfDue reloads the keys unless a reload was attempted within minReloadInterval,
+// callers that decided to reload just before another reload finished do not load the set again
+func (s *KeySet) reloadIfDue(ctx context.Context) error {
+	s.mu.Lock()
+	due := time.Since(s.attemptAt) >= minReloadInterval
+	s.mu.Unlock()
+
+	if !due {
+		return nil
+	}
+	return s.reload(ctx)
+}
+
+// reload replaces the keys with a freshly loaded set, it is only called through reloads
+// A failed reload keeps the previous keys
+func (s *KeySet) reload(ctx context.Context) error {
+	s.mu.Lock()
+	s.attemptAt = time.Now()
+	attemptAt := s.attemptAt
+	s.mu.Unlock()
+
+	data, err := s.load(ctx)
+	var keys map[string]crypto.PublicKey
+	if err == nil {
+		keys, err = parseJWKS(data)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		s.keys = keys
+		s.loadedAt = attemptAt
+	}
+	s.loadErr = err
+	return err
+}
+
+// jsonWebKey is the subset of RFC 7517 fields needed for RSA and EC signature keys
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS returns the RSA and EC signature keys of a JWKS document by key ID
+// Encryption keys and other key types are skipped, a document without any usable key is an error
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var doc struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		var key crypto.PublicKey
+		var err error
+		switch jwk.Kty {
+		case "RSA":
+			key, err = parseRSAKey(jwk)
+		case "EC":
+			key, err = parseECKey(jwk)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwks key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwks has no RSA or EC signature keys")
+	}
+	return keys, nil
+}
+
+func parseRSAKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %w", err)
+	}
+	e, err := decodeBigInt(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("exponent: %w", err)
+	}
+	if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("exponent out of range")
+	}
+
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func parseECKey(jwk jsonWebKey) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch jwk.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+	}
+
+	x, err := decodeBigInt(jwk.X)
+	if err != nil {
+		return nil, fmt.Errorf("x: %w", err)
+	}
+	y, err := decodeBigInt(jwk.Y)
+	if err != nil {
+		return nil, fmt.Errorf("y: %w", err)
+	}
+
+	key := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	// reject points that are not on the curve
+	if _, err := key.ECDH(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// decodeBigInt decodes a base64url encoded unsigned big-endian integer
+func decodeBigInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, fmt.Errorf("missing")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
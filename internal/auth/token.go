@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// signingMethods are the accepted token algorithms, symmetric algorithms are never accepted
+// so a public key cannot be used as an HMAC secret
+var signingMethods = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}
+
+// TokenVerifier authenticates callers by a JWT issued by an OpenID Connect provider
+type TokenVerifier struct {
+	keys        *KeySet
+	parser      *jwt.Parser
+	nameClaim   string
+	rolesClaim  []string
+	roleMapping map[string][]string
+}
+
+// NewTokenVerifier creates a verifier accepting tokens of the configured issuer and audience signed with keys
+func NewTokenVerifier(keys *KeySet, cfg config.JWTConfig) *TokenVerifier {
+	return &TokenVerifier{
+		keys: keys,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods(signingMethods),
+			jwt.WithIssuer(cfg.Issuer),
+			jwt.WithAudience(cfg.Audience),
+			jwt.WithLeeway(cfg.ClockSkew),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+		),
+		nameClaim:   cfg.NameClaim,
+		rolesClaim:  strings.Split(cfg.RolesClaim, "."),
+		roleMapping: cfg.RoleMapping,
+	}
+}
+
+// Authenticate verifies a token and returns the caller it was issued to
+// The caller is named jwt:<name claim>, so a subject never matches an API key or the bootstrap key, and granted the scopes its roles map to,
+// a valid token without any mapped role authenticates but is forbidden on every route
+func (v *TokenVerifier) Authenticate(ctx context.Context, rawToken string) (*model.Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		if errors.Is(err, ErrKeysUnavailable) {
+			return nil, apperror.ServiceUnavailableError("Failed to load token signing keys", err)
+		}
+		return nil, apperror.UnauthorizedError("Invalid token", err)
+	}
+
+	name, _ := claims[v.nameClaim].(string)
+	if name == "" {
+		return nil, apperror.UnauthorizedError("Token has no "+v.nameClaim+" claim", nil)
+	}
+
+	return &model.Principal{Name: model.TokenPrincipalPrefix + name, Scopes: v.scopes(claims)}, nil
+}
+
+// scopes maps the roles of a token to scopes, unknown roles are ignored
+func (v *TokenVerifier) scopes(claims jwt.MapClaims) []model.Scope {
+	var scopes []model.Scope
+	for _, role := range roleValues(claims, v.rolesClaim) {
+		granted := []string{role}
+		if v.roleMapping != nil {
+			granted = v.roleMapping[role]
+		}
+		for _, scope := range granted {
+			switch s := model.Scope(scope); s {
+			case model.ScopeCalculate, model.ScopeRead, model.ScopeAdmin:
+				if !slices.Contains(scopes, s) {
+					scopes = append(scopes, s)
+				}
+			}
+		}
+	}
+	return scopes
+}
+
+// roleValues returns the roles at path in the claims
+// Roles are either an array of strings or a space separated string, like the scope claim of OAuth 2.0
+func roleValues(claims map[string]any, path []string) []string {
+	var value any = claims
+	for _, key := range path {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+
+	switch roles := value.(type) {
+	case string:
+		return strings.Fields(roles)
+	case []any:
+		values := make([]string, 0, len(roles))
+		for _, role := range roles {
+			if s, ok := role.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
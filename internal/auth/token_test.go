@@ -0,0 +1,374 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://id.example.com/realms/warehouse"
+	testAudience = "packman"
+)
+
+// testKeys are the signing keys of a fake identity provider
+type testKeys struct {
+	id  string
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return testKeys{id: "1", rsa: rsaKey, ec: ecKey}
+}
+
+// jwks returns the public keys as a JWKS document with the key IDs "rsa-<id>" and "ec-<id>"
+func (k testKeys) jwks(t *testing.T) []byte {
+	t.Helper()
+
+	encode := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+	doc := map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-" + k.id, "use": "sig", "n": encode(k.rsa.N), "e": encode(big.NewInt(int64(k.rsa.E)))},
+		{"kty": "EC", "kid": "ec-" + k.id, "crv": "P-256", "x": encode(k.ec.X), "y": encode(k.ec.Y)},
+		// keys of other types and encryption keys are skipped
+		{"kty": "oct", "kid": "hmac-1", "k": "c2VjcmV0"},
+		{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": encode(k.rsa.N), "e": "AQAB"},
+	}}
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	return data
+}
+
+// validClaims returns claims accepted by the test verifier
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"sub":   "alice",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"read"},
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func newTestVerifier(t *testing.T, keys testKeys, mutate func(*config.JWTConfig)) *TokenVerifier {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, keys.jwks(t), 0o600))
+
+	cfg := config.JWTConfig{
+		JWKSFile:        path,
+		Issuer:          testIssuer,
+		Audience:        testAudience,
+		NameClaim:       "sub",
+		RolesClaim:      "roles",
+		ClockSkew:       30 * time.Second,
+		RefreshInterval: time.Hour,
+	}
+	if mutate != nil {
+		mutate(&cfg)
+	}
+	return NewTokenVerifier(NewFileKeySet(path, cfg.RefreshInterval), cfg)
+}
+
+func assertAppErrorCode(t *testing.T, err error, code apperror.ErrorCode) {
+	t.Helper()
+
+	appErr, ok := apperror.AsAppError(err)
+	require.True(t, ok, "expected an AppError, got %v", err)
+	assert.Equal(t, code, appErr.Code)
+}
+
+func TestTokenVerifier(t *testing.T) {
+	keys := newTestKeys(t)
+	verifier := newTestVerifier(t, keys, nil)
+	ctx := context.Background()
+
+	t.Run("RS256", func(t *testing.T) {
+		principal, err := verifier.Authenticate(ctx, sign(t, jwt.SigningMethodRS256, keys.rsa, "rsa-1", validClaims()))
+		require.NoError(t, err)
+		assert.Equal(t, &model.Principal{Name: "jwt:alice", Scopes: []model.Scope{model.ScopeRead}}, principal)
+	})
+	t.Run("ES256", func(t *testing.T) {
+		principal, err := verifier.Authenticate(ctx, sign(t, jwt.SigningMethodES256, keys.ec, "ec-1", validClaims()))
+		require.NoError(t, err)
+		assert.Equal(t, "jwt:alice", principal.Name)
+	})
+
+	rejected := map[string]func() string{
+		"wrong issuer": func() string {
+			claims := validClaims()
+			claims["iss"] = "https://evil.example.com"
+			return sign(t, jwt.SigningMethodRS256, keys.rsa, "rsa-1", claims)
+		},
+		"wrong audience": func() string {
+			claims := validClaims()
+			claims["aud"] = []string{"billing"}
+			return sign(t, jwt.SigningMethodRS256, keys.rsa, "rsa-1", claims)
+		},
+		"expired": func() string {
+			claims := validClaims()
+			claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			return sign(t, jwt.SigningMethodRS256, keys.rsa, "rsa-1", claims)
+		},
+		"without expiry": func() string {
+			claims := validClaims()
+			delete(claims, "exp")
+			return sign(t, jwt.SigningMethodRS256, keys.rsa, "rsa-1", claims)
+		},
+		"signed by another key": func() string {
+			other, err := rsa.GenerateKey(rand.Reader, 2048)
+			require.NoError(t, err)
+			return sign(t, jwt.SigningMethodRS256, other, "rsa-1", validClaims())
+		},
+		"HS256 with the public key as secret": func() string {
+			return sign(t, jwt.SigningMethodHS256, keys.rsa.N.Bytes(), "rsa-1", validClaims())
+		},
+		"key of the wrong type": func() string {
+			return sign(t, jwt.SigningMethodES256, keys.ec, "rsa-1", validClaims())
+		},
+		"encryption key": func() string {
+			return sign(t, jwt.SigningMethodRS256, keys.rsa, "enc-1", validClaims())
+		},
+		"without subject": func() string {
+			claims := validClaims()
+			delete(claims, "sub")
+			return sign(t, jwt.SigningMethodRS256, keys.rsa, "rsa-1", claims)
+		},
+		"malformed": func() string {
+			return "eyJhbGciOiJSUzI1NiJ9.e30.c2lnbmF0dXJl"
+		},
+	}
+	for name, token := range rejected {
+		t.Run(name+" is rejected", func(t *testing.T) {
+			principal, err := verifier.Authenticate(ctx, token())
+			assert.Nil(t, principal)
+			assertAppErrorCode(t, err, apperror.ErrCodeUnauthorized)
+		})
+	}
+}
+
+func TestTokenVerifier_Roles(t *testing.T) {
+	keys := newTestKeys(t)
+	ctx := context.Background()
+
+	t.Run("mapped roles from a nested claim", func(t *testing.T) {
+		verifier := newTestVerifier(t, keys, func(cfg *config.JWTConfig) {
+			cfg.NameClaim = "preferred_username"
+			cfg.RolesClaim = "realm_access.roles"
+			cfg.RoleMapping = map[string][]string{
+				"packman-operators": {"calculate", "read"},
+				"packman-admins":    {"admin"},
+			}
+		})
+
+		claims := validClaims()
+		claims["preferred_username"] = "alice@example.com"
+		claims["realm_access"] = map[string]any{"roles": []string{"offline_access", "packman-operators", "read"}}
+
+		principal, err := verifier.Authenticate(ctx, sign(t, jwt.SigningMethodRS256, keys.rsa, "rsa-1", claims))
+		require.NoError(t, err)
+		assert.Equal(t, "jwt:alice@example.com", principal.Name)
+		// roles that are not mapped are ignored, even if they are named like a scope
+		assert.Equal(t, []model.Scope{model.ScopeCalculate, model.ScopeRead}, principal.Scopes)
+	})
+	t.Run("space separated scope claim", func(t *testing.T) {
+		verifier := newTestVerifier(t, keys, func(cfg *config.JWTConfig) {
+			cfg.RolesClaim = "scope"
+		})
+
+		claims := validClaims()
+		claims["scope"] = "openid calculate admin"
+
+		principal, err := verifier.Authenticate(ctx, sign(t, jwt.SigningMethodRS256, keys.rsa, "rsa-1", claims))
+		require.NoError(t, err)
+		assert.True(t, principal.HasScope(model.ScopeAdmin))
+	})
+	t.Run("token without roles authenticates without scopes", func(t *testing.T) {
+		verifier := newTestVerifier(t, keys, nil)
+
+		claims := validClaims()
+		delete(claims, "roles")
+
+		principal, err := verifier.Authenticate(ctx, sign(t, jwt.SigningMethodRS256, keys.rsa, "rsa-1", claims))
+		require.NoError(t, err)
+		assert.False(t, principal.HasScope(model.ScopeRead))
+	})
+}
+
+func TestURLKeySet(t *testing.T) {
+	ctx := context.Background()
+	current := newTestKeys(t)
+
+	var jwks atomic.Value
+	jwks.Store(current.jwks(t))
+	var fetches atomic.Int32
+	var failing atomic.Bool
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write(jwks.Load().([]byte))
+	}))
+	defer provider.Close()
+
+	keySet := NewURLKeySet(provider.URL, time.Hour, time.Second)
+	verifier := NewTokenVerifier(keySet, config.JWTConfig{
+		Issuer:     testIssuer,
+		Audience:   testAudience,
+		NameClaim:  "sub",
+		RolesClaim: "roles",
+	})
+
+	t.Run("keys are cached", func(t *testing.T) {
+		for range 3 {
+			_, err := verifier.Authenticate(ctx, sign(t, jwt.SigningMethodRS256, current.rsa, "rsa-1", validClaims()))
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(1), fetches.Load())
+	})
+	t.Run("an unknown key reloads the set after a rotation", func(t *testing.T) {
+		rotated := newTestKeys(t)
+		rotated.id = "2"
+		jwks.Store(rotated.jwks(t))
+		// allow the reload without waiting for minReloadInterval
+		keySet.mu.Lock()
+		keySet.attemptAt = time.Time{}
+		keySet.mu.Unlock()
+
+		_, err := verifier.Authenticate(ctx, sign(t, jwt.SigningMethodRS256, rotated.rsa, "rsa-2", validClaims()))
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), fetches.Load())
+
+		// unknown keys do not make every request fetch the set
+		unknown := sign(t, jwt.SigningMethodRS256, rotated.rsa, "rsa-3", validClaims())
+		_, err = verifier.Authenticate(ctx, unknown)
+		assertAppErrorCode(t, err, apperror.ErrCodeUnauthorized)
+		assert.Equal(t, int32(2), fetches.Load())
+	})
+	t.Run("provider unavailable before the first load", func(t *testing.T) {
+		failing.Store(true)
+		defer failing.Store(false)
+
+		unloaded := NewTokenVerifier(NewURLKeySet(provider.URL, time.Hour, time.Second), config.JWTConfig{
+			Issuer:     testIssuer,
+			Audience:   testAudience,
+			NameClaim:  "sub",
+			RolesClaim: "roles",
+		})
+		_, err := unloaded.Authenticate(ctx, sign(t, jwt.SigningMethodRS256, current.rsa, "rsa-1", validClaims()))
+		assertAppErrorCode(t, err, apperror.ErrCodeServiceUnavail)
+	})
+}
+
+func TestKeySet_ReloadsWithoutBlocking(t *testing.T) {
+	ctx := context.Background()
+	current, rotated := newTestKeys(t), newTestKeys(t)
+	rotated.id = "2"
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	keySet := &KeySet{
+		load: func(ctx context.Context) ([]byte, error) {
+			if loads.Add(1) == 1 {
+				return current.jwks(t), nil
+			}
+			<-release
+			return rotated.jwks(t), nil
+		},
+		refreshInterval: time.Hour,
+	}
+	require.NoError(t, keySet.Load(ctx))
+
+	// the keys are stale and may be reloaded
+	keySet.mu.Lock()
+	keySet.loadedAt = time.Now().Add(-2 * time.Hour)
+	keySet.attemptAt = time.Time{}
+	keySet.mu.Unlock()
+
+	t.Run("stale keys are served while the set reloads", func(t *testing.T) {
+		key, err := keySet.Key(ctx, "rsa-1")
+		require.NoError(t, err)
+		assert.Equal(t, &current.rsa.PublicKey, key)
+	})
+	t.Run("concurrent lookups of an unknown key share the reload", func(t *testing.T) {
+		results := make(chan error, 5)
+		for range cap(results) {
+			go func() {
+				_, err := keySet.Key(ctx, "rsa-2")
+				results <- err
+			}()
+		}
+		// known keys are not blocked by the reload in flight
+		_, err := keySet.Key(ctx, "ec-1")
+		require.NoError(t, err)
+
+		close(release)
+		for range cap(results) {
+			require.NoError(t, <-results)
+		}
+		assert.Equal(t, int32(2), loads.Load())
+	})
+	t.Run("a waiting lookup gives up with its request", func(t *testing.T) {
+		keySet.mu.Lock()
+		keySet.attemptAt = time.Time{}
+		keySet.mu.Unlock()
+		keySet.load = func(ctx context.Context) ([]byte, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+
+		canceled, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err := keySet.Key(canceled, "rsa-3")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestParseJWKS_NoUsableKeys(t *testing.T) {
+	_, err := parseJWKS([]byte(`{"keys":[{"kty":"oct","kid":"hmac-1","k":"c2VjcmV0"}]}`))
+	assert.Error(t, err)
+
+	_, err = parseJWKS([]byte(`{"keys":[{"kty":"EC","kid":"ec-1","crv":"P-256","x":"AQ","y":"AQ"}]}`))
+	assert.Error(t, err, "points that are not on the curve are rejected")
+}
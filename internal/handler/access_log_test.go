@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/logging"
+	"github.com/nsaltun/packman/internal/middleware"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs sends the default logger to a buffer for the rest of the test and returns a function reading the records
+func captureLogs(t *testing.T) func() []map[string]any {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(logging.NewContextHandler(slog.NewJSONHandler(&buf, nil))))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	return func() []map[string]any {
+		var records []map[string]any
+		scanner := bufio.NewScanner(&buf)
+		for scanner.Scan() {
+			var record map[string]any
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+			records = append(records, record)
+		}
+		return records
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	readLogs := captureLogs(t)
+
+	packService := new(mocks.MockPackService)
+	packService.On("GetDraft", mock.Anything, 7).
+		Run(func(args mock.Arguments) {
+			slog.WarnContext(args.Get(0).(context.Context), "draft lookup")
+		}).
+		Return(nil, apperror.NotFoundError("Draft not found", nil))
+
+	auth := new(mocks.MockAPIKeyService)
+	auth.On("Authenticate", mock.Anything, "pmk_dashboard").
+		Return(&model.Principal{Name: "dashboard", Scopes: []model.Scope{model.ScopeRead}}, nil)
+
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.Use(middleware.AccessLog())
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.Authenticate(auth))
+	NewPackHTTPHandler(packService).registerRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pack-sizes/drafts/7", nil)
+	req.Header.Set("X-Request-ID", "req-123")
+	req.Header.Set("X-API-Key", "pmk_dashboard")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	records := readLogs()
+	require.Len(t, records, 3)
+
+	// logs of the service are linked to the request
+	assert.Equal(t, "draft lookup", records[0]["msg"])
+	assert.Equal(t, "req-123", records[0]["request_id"])
+	assert.Equal(t, "client error", records[1]["msg"])
+	assert.Equal(t, "req-123", records[1]["request_id"])
+
+	access := records[2]
+	assert.Equal(t, "request", access["msg"])
+	assert.Equal(t, "WARN", access["level"])
+	assert.Equal(t, "req-123", access["request_id"])
+	assert.Equal(t, http.MethodGet, access["method"])
+	assert.Equal(t, "/api/v1/pack-sizes/drafts/:id", access["route"])
+	assert.EqualValues(t, http.StatusNotFound, access["status"])
+	assert.EqualValues(t, w.Body.Len(), access["bytes"])
+	assert.Equal(t, "principal:dashboard", access["client"])
+	assert.Contains(t, access, "latency_ms")
+}
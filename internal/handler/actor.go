@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/internal/reqctx"
+)
+
+// requestActor returns who a change is recorded for
+// The name of the authenticated API key wins over the value sent by the client, which is only used when
+// authentication is disabled
+func requestActor(c *gin.Context, fromRequest string) string {
+	if identity := reqctx.FromContext(c.Request.Context()).Identity; identity != "" {
+		return identity
+	}
+	return fromRequest
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/middleware"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/response"
+	"github.com/nsaltun/packman/internal/service"
+)
+
+// APIKeyHTTPHandler defines the interface for API key management HTTP handlers
+type APIKeyHTTPHandler interface {
+	registerRoutes(r *gin.Engine)
+	CreateAPIKey(c *gin.Context)
+	ListAPIKeys(c *gin.Context)
+	RevokeAPIKey(c *gin.Context)
+}
+
+// apiKeyHTTPHandler is the concrete implementation of APIKeyHTTPHandler
+type apiKeyHTTPHandler struct {
+	apiKeyService service.APIKeyService
+}
+
+// NewAPIKeyHTTPHandler creates a new HTTP handler with the given service
+func NewAPIKeyHTTPHandler(apiKeyService service.APIKeyService) APIKeyHTTPHandler {
+	return &apiKeyHTTPHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// registerRoutes registers all routes for the HTTP handler
+func (h *apiKeyHTTPHandler) registerRoutes(r *gin.Engine) {
+	keys := r.Group("/api/v1/api-keys", middleware.RequireScope(model.ScopeAdmin))
+	{
+		keys.POST("", h.CreateAPIKey)
+		keys.GET("", h.ListAPIKeys)
+		keys.DELETE("/:id", h.RevokeAPIKey)
+	}
+}
+
+// CreateAPIKey handles issuing a new API key
+// The response contains the key, it is not returned again
+func (h *apiKeyHTTPHandler) CreateAPIKey(c *gin.Context) {
+	var req model.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperror.BadRequestError("Invalid request format", err))
+		return
+	}
+	req.CreatedBy = requestActor(c, "")
+
+	// validate request
+	if err := validateCreateAPIKeyRequest(&req); err != nil {
+		_ = c.Error(apperror.ValidationError(err.Error(), err))
+		return
+	}
+
+	//deduplicate scopes
+	slices.Sort(req.Scopes)
+	req.Scopes = slices.Compact(req.Scopes)
+
+	res, err := h.apiKeyService.CreateAPIKey(c.Request.Context(), &req)
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	// a retry with the same Idempotency-Key gets the key without its secret
+	redacted := *res
+	redacted.Key = ""
+	middleware.RedactIdempotentResponse(c, &redacted)
+	response.Success(c, http.StatusCreated, res)
+}
+
+// ListAPIKeys handles listing all keys, including revoked keys
+func (h *apiKeyHTTPHandler) ListAPIKeys(c *gin.Context) {
+	res, err := h.apiKeyService.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	response.Success(c, http.StatusOK, res)
+}
+
+// RevokeAPIKey handles revoking a key, the key is kept so its last use stays visible
+func (h *apiKeyHTTPHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		_ = c.Error(apperror.BadRequestError(err.Error(), err))
+		return
+	}
+
+	res, err := h.apiKeyService.RevokeAPIKey(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	response.Success(c, http.StatusOK, res)
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/middleware"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/reqctx"
+	"github.com/nsaltun/packman/internal/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAPIKeyHTTPHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           interface{}
+		mockSetup      func(*mocks.MockAPIKeyService)
+		expectedStatus int
+		expectedCode   apperror.ErrorCode
+	}{
+		{
+			name:   "create deduplicates scopes",
+			method: http.MethodPost,
+			url:    "/api/v1/api-keys",
+			body:   model.CreateAPIKeyRequest{Name: "ci", Scopes: []model.Scope{model.ScopeRead, model.ScopeCalculate, model.ScopeRead}},
+			mockSetup: func(m *mocks.MockAPIKeyService) {
+				m.On("CreateAPIKey", mock.Anything, &model.CreateAPIKeyRequest{Name: "ci", Scopes: []model.Scope{model.ScopeCalculate, model.ScopeRead}}).
+					Return(&model.APIKey{ID: 1, Name: "ci", Key: "pmk_abc"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "create requires a name",
+			method:         http.MethodPost,
+			url:            "/api/v1/api-keys",
+			body:           model.CreateAPIKeyRequest{Scopes: []model.Scope{model.ScopeRead}},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+		{
+			name:           "create rejects the name of the bootstrap key",
+			method:         http.MethodPost,
+			url:            "/api/v1/api-keys",
+			body:           model.CreateAPIKeyRequest{Name: "Bootstrap", Scopes: []model.Scope{model.ScopeAdmin}},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+		{
+			name:           "create rejects unknown scopes",
+			method:         http.MethodPost,
+			url:            "/api/v1/api-keys",
+			body:           model.CreateAPIKeyRequest{Name: "ci", Scopes: []model.Scope{"write"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+		{
+			name:           "create requires scopes",
+			method:         http.MethodPost,
+			url:            "/api/v1/api-keys",
+			body:           model.CreateAPIKeyRequest{Name: "ci"},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+		{
+			name:   "list",
+			method: http.MethodGet,
+			url:    "/api/v1/api-keys",
+			mockSetup: func(m *mocks.MockAPIKeyService) {
+				m.On("ListAPIKeys", mock.Anything).Return([]*model.APIKey{{ID: 1, Name: "ci"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "revoke",
+			method: http.MethodDelete,
+			url:    "/api/v1/api-keys/1",
+			mockSetup: func(m *mocks.MockAPIKeyService) {
+				revokedAt := time.Now()
+				m.On("RevokeAPIKey", mock.Anything, 1).Return(&model.APIKey{ID: 1, RevokedAt: &revokedAt}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "revoke unknown",
+			method: http.MethodDelete,
+			url:    "/api/v1/api-keys/9",
+			mockSetup: func(m *mocks.MockAPIKeyService) {
+				m.On("RevokeAPIKey", mock.Anything, 9).Return(nil, apperror.NotFoundError("API key not found", nil))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   apperror.ErrCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			mockService := new(mocks.MockAPIKeyService)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockService)
+			}
+			handler := NewAPIKeyHTTPHandler(mockService)
+
+			var body []byte
+			if tt.body != nil {
+				body, _ = json.Marshal(tt.body)
+			}
+
+			// create router with the handler routes and execute
+			req := httptest.NewRequest(tt.method, tt.url, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router := setupTestRouter()
+			handler.registerRoutes(router)
+			router.ServeHTTP(w, req)
+
+			// assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, w, tt.expectedCode)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// setupAuthRouter creates a Gin engine with every API route behind authentication with auth
+func setupAuthRouter(auth middleware.Authenticator, packService *mocks.MockPackService) *gin.Engine {
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.Authenticate(auth))
+
+	NewPackHTTPHandler(packService).registerRoutes(router)
+	NewPackStreamHTTPHandler(packService, stream.NewHub(), config.StreamConfig{HeartbeatInterval: time.Hour}).registerRoutes(router)
+	NewWebhookHTTPHandler(new(mocks.MockWebhookService)).registerRoutes(router)
+	NewAPIKeyHTTPHandler(new(mocks.MockAPIKeyService)).registerRoutes(router)
+	return router
+}
+
+func TestAuthentication_EveryRouteRequiresAKey(t *testing.T) {
+	router := setupAuthRouter(new(mocks.MockAPIKeyService), new(mocks.MockPackService))
+
+	routes := router.Routes()
+	assert.NotEmpty(t, routes)
+	for _, route := range routes {
+		path := strings.ReplaceAll(route.Path, ":id", "1")
+		t.Run(route.Method+" "+route.Path, func(t *testing.T) {
+			req := httptest.NewRequest(route.Method, path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+			assertErrorCode(t, w, apperror.ErrCodeUnauthorized)
+		})
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	readKey := &model.Principal{Name: "key:dashboard", Scopes: []model.Scope{model.ScopeRead}}
+	adminKey := &model.Principal{Name: "key:deploy-bot", Scopes: []model.Scope{model.ScopeAdmin}}
+
+	auth := new(mocks.MockAPIKeyService)
+	auth.On("Authenticate", mock.Anything, "pmk_read").Return(readKey, nil)
+	auth.On("Authenticate", mock.Anything, "pmk_admin").Return(adminKey, nil)
+	auth.On("Authenticate", mock.Anything, "pmk_revoked").Return(nil, apperror.UnauthorizedError("Invalid API key", nil))
+
+	t.Run("invalid key is rejected", func(t *testing.T) {
+		router := setupAuthRouter(auth, new(mocks.MockPackService))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/pack-sizes", nil)
+		req.Header.Set("Authorization", "Bearer pmk_revoked")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assertErrorCode(t, w, apperror.ErrCodeUnauthorized)
+	})
+	t.Run("key without the scope is forbidden", func(t *testing.T) {
+		router := setupAuthRouter(auth, new(mocks.MockPackService))
+
+		for _, url := range []string{"/api/v1/calculate", "/api/v1/api-keys"} {
+			req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(`{}`))
+			req.Header.Set("X-API-Key", "pmk_read")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusForbidden, w.Code, url)
+			assertErrorCode(t, w, apperror.ErrCodeForbidden)
+		}
+	})
+	t.Run("key with the scope is allowed and identifies the caller", func(t *testing.T) {
+		packService := new(mocks.MockPackService)
+		callerIsKey := mock.MatchedBy(func(ctx context.Context) bool {
+			return reqctx.FromContext(ctx).Identity == "key:dashboard"
+		})
+		packService.On("GetPackSizes", callerIsKey).Return(&model.GetPackSizesResponse{PackSizes: []int{250}}, nil)
+		router := setupAuthRouter(auth, packService)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/pack-sizes", nil)
+		req.Header.Set("X-API-Key", "pmk_read")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		packService.AssertExpectations(t)
+	})
+	t.Run("updated_by is taken from the key", func(t *testing.T) {
+		packService := new(mocks.MockPackService)
+		packService.On("UpdatePackSizes", mock.Anything, []int{250, 500}, "key:deploy-bot", "peak season").
+			Return(&model.UpdatePackSizesResponse{}, nil)
+		router := setupAuthRouter(auth, packService)
+
+		body := `{"pack_sizes":[250,500],"updated_by":"mallory","reason":"peak season"}`
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/pack-sizes", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer pmk_admin")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		packService.AssertExpectations(t)
+	})
+}
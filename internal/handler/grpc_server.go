@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/app"
+	"github.com/nsaltun/packman/internal/middleware"
+	"github.com/nsaltun/packman/pkg/postgres"
+	packmanv1 "github.com/nsaltun/packman/proto/packman/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+// grpcPublicServices are open without credentials, so load balancers and tools like grpcurl work without a key
+// Every other method must be listed in packGRPCScopes, calls to methods missing there are denied
+var grpcPublicServices = []string{
+	healthpb.Health_ServiceDesc.ServiceName,
+	reflectionv1.ServerReflection_ServiceDesc.ServiceName,
+	reflectionv1alpha.ServerReflection_ServiceDesc.ServiceName,
+}
+
+// GRPCServer serves the PackService, the health service and optionally reflection over gRPC
+// It runs next to the HTTP server on its own port
+type GRPCServer struct {
+	app.AbstractComponent
+	cfg        config.GRPCConfig
+	grpcServer *grpc.Server
+	health     *health.Server
+	pgClient   *postgres.Client
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewGRPCServer creates and configures a new gRPC server
+// pgClient is nil when the service runs without a database, the health service then always reports serving.
+// auth checks the API keys of calls, a nil auth disables authentication.
+// limiter limits the calls of each client, a nil limiter disables rate limiting
+func NewGRPCServer(packHandler packmanv1.PackServiceServer, pgClient *postgres.Client, auth middleware.Authenticator, limiter middleware.RateLimiter, cfg config.GRPCConfig) *GRPCServer {
+	// Add interceptors (order matters!)
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			middleware.UnaryRequestID(),                                            // 1. Generate request ID
+			middleware.UnaryTracing(),                                              // 2. Start the server span with the request ID
+			middleware.UnaryErrorHandler(),                                         // 3. Convert errors and panics to statuses
+			middleware.UnaryLimitAddresses(limiter, grpcPublicServices),            // 4. Limit calls per client IP, before credentials are checked
+			middleware.UnaryAuthenticate(auth, packGRPCScopes, grpcPublicServices), // 5. Resolve the API key, methods require their scope
+			middleware.UnaryRateLimit(limiter, grpcPublicServices),                 // 6. Limit calls per API key or client IP
+		),
+		grpc.ChainStreamInterceptor(
+			middleware.StreamAuthenticate(grpcPublicServices), // Only health watches and reflection are streamed
+		),
+	)
+
+	// Register services
+	packmanv1.RegisterPackServiceServer(grpcServer, packHandler)
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	if cfg.Reflection {
+		reflection.Register(grpcServer)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &GRPCServer{
+		cfg:        cfg,
+		grpcServer: grpcServer,
+		health:     healthServer,
+		pgClient:   pgClient,
+		ctx:        ctx,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+}
+
+// Run starts the gRPC server (blocks until shutdown)
+func (s *GRPCServer) Run() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%s", s.cfg.Port))
+	if err != nil {
+		s.cancel()
+		close(s.done)
+		return fmt.Errorf("grpc server error: %w", err)
+	}
+
+	go s.checkHealth()
+
+	slog.Info("starting gRPC server", slog.String("addr", listener.Addr().String()))
+	if err := s.grpcServer.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return fmt.Errorf("grpc server error: %w", err)
+	}
+	return nil
+}
+
+// Close gracefully shuts down the server, calls still running when ctx is done are canceled
+func (s *GRPCServer) Close(ctx context.Context) error {
+	slog.Info("Shutting down gRPC server...")
+	// load balancers stop sending calls before the server stops accepting them
+	s.health.Shutdown()
+	s.cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		<-stopped
+		return fmt.Errorf("grpc server forced to shutdown: %w", ctx.Err())
+	}
+
+	// wait for the health checks to stop
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	slog.Info("gRPC server stopped")
+	return nil
+}
+
+// checkHealth reports the health of the database to the health service until the server is closed
+// The overall status and the PackService status are the same, the service cannot work without its database
+func (s *GRPCServer) checkHealth() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		s.updateHealth(s.ctx)
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// updateHealth sets the status of the health service from a database health check
+func (s *GRPCServer) updateHealth(ctx context.Context) {
+	status := healthpb.HealthCheckResponse_SERVING
+	if s.pgClient != nil {
+		if dbHealth := s.pgClient.CheckHealth(ctx); dbHealth.Status != "healthy" {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Error("gRPC health check failed", slog.String("error", dbHealth.Error))
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+
+	s.health.SetServingStatus("", status)
+	s.health.SetServingStatus(packmanv1.PackService_ServiceDesc.ServiceName, status)
+}
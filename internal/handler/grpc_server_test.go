@@ -0,0 +1,288 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/middleware"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/ratelimit"
+	"github.com/nsaltun/packman/internal/repository"
+	packmanv1 "github.com/nsaltun/packman/proto/packman/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// setupGRPCServer starts a gRPC server on an in-memory listener and returns a connection to it
+func setupGRPCServer(t *testing.T, packService *mocks.MockPackService, auth middleware.Authenticator, limiter middleware.RateLimiter) *grpc.ClientConn {
+	t.Helper()
+
+	server := NewGRPCServer(NewPackGRPCHandler(packService), nil, auth, limiter, config.GRPCConfig{
+		Reflection:          true,
+		HealthCheckInterval: time.Minute,
+	})
+	server.updateHealth(context.Background())
+
+	listener := bufconn.Listen(1 << 20)
+	go func() {
+		_ = server.grpcServer.Serve(listener)
+	}()
+	t.Cleanup(server.grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// errorInfo returns the status code and the ErrorInfo detail of a failed call
+func errorInfo(t *testing.T, err error) (codes.Code, *errdetails.ErrorInfo) {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return st.Code(), info
+		}
+	}
+	t.Fatalf("status %v has no ErrorInfo detail", st)
+	return st.Code(), nil
+}
+
+func TestGRPCServer_PackService(t *testing.T) {
+	updatedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("calculate returns packs from the largest size", func(t *testing.T) {
+		packService := new(mocks.MockPackService)
+		packService.On("CalculatePacks", mock.Anything, 501).
+			Return(&model.PackCalculationResponse{Quantity: 501, Packs: map[int]int{250: 1, 500: 1}}, nil)
+		client := packmanv1.NewPackServiceClient(setupGRPCServer(t, packService, nil, nil))
+
+		res, err := client.CalculatePacks(context.Background(), &packmanv1.CalculatePacksRequest{Quantity: 501})
+		require.NoError(t, err)
+		assert.Equal(t, int64(501), res.Quantity)
+		require.Len(t, res.Packs, 2)
+		assert.Equal(t, int64(500), res.Packs[0].Size)
+		assert.Equal(t, int64(250), res.Packs[1].Size)
+	})
+
+	t.Run("invalid requests fail with the validation code", func(t *testing.T) {
+		client := packmanv1.NewPackServiceClient(setupGRPCServer(t, new(mocks.MockPackService), nil, nil))
+
+		_, err := client.CalculatePacks(context.Background(), &packmanv1.CalculatePacksRequest{Quantity: 0})
+		code, info := errorInfo(t, err)
+		assert.Equal(t, codes.InvalidArgument, code)
+		assert.Equal(t, string(apperror.ErrCodeValidation), info.Reason)
+		assert.NotEmpty(t, info.Metadata["request_id"])
+	})
+
+	t.Run("service errors keep their code", func(t *testing.T) {
+		packService := new(mocks.MockPackService)
+		packService.On("GetPackSizes", mock.Anything).
+			Return(nil, apperror.NotFoundError("Pack configuration not found", nil))
+		client := packmanv1.NewPackServiceClient(setupGRPCServer(t, packService, nil, nil))
+
+		_, err := client.GetPackSizes(context.Background(), &packmanv1.GetPackSizesRequest{})
+		code, info := errorInfo(t, err)
+		assert.Equal(t, codes.NotFound, code)
+		assert.Equal(t, string(apperror.ErrCodeNotFound), info.Reason)
+	})
+
+	t.Run("history returns the current and archived versions", func(t *testing.T) {
+		packService := new(mocks.MockPackService)
+		packService.On("ExportConfiguration", mock.Anything).Return(&model.PackConfigurationExport{
+			Version:   2,
+			PackSizes: []int{250, 500},
+			UpdatedAt: updatedAt,
+			History:   []model.PackConfigurationVersion{{Version: 1, PackSizes: []int{250}, ArchivedAt: updatedAt}},
+		}, nil)
+		client := packmanv1.NewPackServiceClient(setupGRPCServer(t, packService, nil, nil))
+
+		res, err := client.GetPackSizeHistory(context.Background(), &packmanv1.GetPackSizeHistoryRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), res.Current.Version)
+		assert.Equal(t, []int64{250, 500}, res.Current.PackSizes)
+		require.Len(t, res.Versions, 1)
+		assert.Equal(t, int64(1), res.Versions[0].Version)
+		assert.True(t, updatedAt.Equal(res.Versions[0].ArchivedAt.AsTime()))
+	})
+
+	t.Run("the request ID is returned in the headers", func(t *testing.T) {
+		packService := new(mocks.MockPackService)
+		packService.On("GetPackSizes", mock.Anything).
+			Return(&model.GetPackSizesResponse{PackSizes: []int{250}, Version: 1, UpdatedAt: updatedAt}, nil)
+		client := packmanv1.NewPackServiceClient(setupGRPCServer(t, packService, nil, nil))
+
+		var header metadata.MD
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "req-123")
+		_, err := client.GetPackSizes(ctx, &packmanv1.GetPackSizesRequest{}, grpc.Header(&header))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"req-123"}, header.Get("x-request-id"))
+	})
+	t.Run("unsafe request IDs are replaced", func(t *testing.T) {
+		packService := new(mocks.MockPackService)
+		packService.On("GetPackSizes", mock.Anything).
+			Return(&model.GetPackSizesResponse{PackSizes: []int{250}, Version: 1, UpdatedAt: updatedAt}, nil)
+		client := packmanv1.NewPackServiceClient(setupGRPCServer(t, packService, nil, nil))
+
+		var header metadata.MD
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", strings.Repeat("r", 300))
+		_, err := client.GetPackSizes(ctx, &packmanv1.GetPackSizesRequest{}, grpc.Header(&header))
+		require.NoError(t, err)
+		require.Len(t, header.Get("x-request-id"), 1)
+		assert.NoError(t, uuid.Validate(header.Get("x-request-id")[0]))
+	})
+}
+
+func TestGRPCServer_Authentication(t *testing.T) {
+	packService := new(mocks.MockPackService)
+	packService.On("UpdatePackSizes", mock.Anything, []int{500, 250}, "deploy-bot", "new box").
+		Return(&model.UpdatePackSizesResponse{PackSizes: []int{250, 500}, Version: 2, UpdatedBy: "deploy-bot"}, nil)
+
+	auth := new(mocks.MockAPIKeyService)
+	auth.On("Authenticate", mock.Anything, "pmk_reader").
+		Return(&model.Principal{Name: "reader", Scopes: []model.Scope{model.ScopeRead}}, nil)
+	auth.On("Authenticate", mock.Anything, "pmk_admin").
+		Return(&model.Principal{Name: "deploy-bot", Scopes: []model.Scope{model.ScopeAdmin}}, nil)
+	auth.On("Authenticate", mock.Anything, "pmk_revoked").
+		Return(nil, apperror.UnauthorizedError("Invalid API key", nil))
+
+	conn := setupGRPCServer(t, packService, auth, nil)
+	client := packmanv1.NewPackServiceClient(conn)
+	update := &packmanv1.UpdatePackSizesRequest{PackSizes: []int64{500, 250}, UpdatedBy: "someone else", Reason: "new box"}
+
+	tests := []struct {
+		name         string
+		md           []string
+		expectedCode codes.Code
+	}{
+		{name: "missing credentials", expectedCode: codes.Unauthenticated},
+		{name: "invalid credentials", md: []string{"x-api-key", "pmk_revoked"}, expectedCode: codes.Unauthenticated},
+		{name: "missing scope", md: []string{"authorization", "Bearer pmk_reader"}, expectedCode: codes.PermissionDenied},
+		{name: "admin records the caller", md: []string{"authorization", "Bearer pmk_admin"}, expectedCode: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.AppendToOutgoingContext(context.Background(), tt.md...)
+			res, err := client.UpdatePackSizes(ctx, update)
+			require.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectedCode == codes.OK {
+				assert.Equal(t, "deploy-bot", res.Configuration.UpdatedBy)
+			}
+		})
+	}
+
+	t.Run("health checks are open", func(t *testing.T) {
+		res, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{
+			Service: packmanv1.PackService_ServiceDesc.ServiceName,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.Status)
+	})
+	t.Run("reflection is open", func(t *testing.T) {
+		stream, err := reflectionv1.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&reflectionv1.ServerReflectionRequest{
+			MessageRequest: &reflectionv1.ServerReflectionRequest_ListServices{},
+		}))
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		assert.NotEmpty(t, res.GetListServicesResponse().GetService())
+	})
+	t.Run("methods without a scope are denied", func(t *testing.T) {
+		interceptor := middleware.UnaryAuthenticate(auth, packGRPCScopes, grpcPublicServices)
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer pmk_admin"))
+		info := &grpc.UnaryServerInfo{FullMethod: "/" + packmanv1.PackService_ServiceDesc.ServiceName + "/DeletePackSizes"}
+
+		_, err := interceptor(ctx, nil, info, func(context.Context, any) (any, error) {
+			t.Fatal("the handler of a method without a scope must not run")
+			return nil, nil
+		})
+		appErr, ok := apperror.AsAppError(err)
+		require.True(t, ok)
+		assert.Equal(t, apperror.ErrCodeForbidden, appErr.Code)
+	})
+
+	packService.AssertExpectations(t)
+}
+
+func TestGRPCServer_RateLimit(t *testing.T) {
+	packService := new(mocks.MockPackService)
+	packService.On("GetPackSizes", mock.Anything).Return(&model.GetPackSizesResponse{PackSizes: []int{250}}, nil)
+	limiter := ratelimit.NewLimiter(repository.NewMemoryRateLimitRepo(), config.RateLimitConfig{
+		Default: config.RateLimit{Requests: 1, Period: time.Minute},
+		Address: config.RateLimit{Requests: 10, Period: time.Minute},
+	})
+	client := packmanv1.NewPackServiceClient(setupGRPCServer(t, packService, nil, limiter))
+
+	_, err := client.GetPackSizes(context.Background(), &packmanv1.GetPackSizesRequest{})
+	require.NoError(t, err)
+
+	_, err = client.GetPackSizes(context.Background(), &packmanv1.GetPackSizesRequest{})
+	code, info := errorInfo(t, err)
+	assert.Equal(t, codes.ResourceExhausted, code)
+	assert.Equal(t, string(apperror.ErrCodeRateLimited), info.Reason)
+	assert.NotEmpty(t, info.Metadata["retry_after_seconds"])
+}
+
+func TestGRPCServer_LimitsInvalidCredentials(t *testing.T) {
+	auth := new(mocks.MockAPIKeyService)
+	auth.On("Authenticate", mock.Anything, mock.Anything).Return(nil, apperror.UnauthorizedError("Invalid API key", nil))
+	limiter := ratelimit.NewLimiter(repository.NewMemoryRateLimitRepo(), config.RateLimitConfig{
+		Default: config.RateLimit{Requests: 10, Period: time.Minute},
+		Address: config.RateLimit{Requests: 2, Period: time.Minute},
+	})
+	client := packmanv1.NewPackServiceClient(setupGRPCServer(t, new(mocks.MockPackService), auth, limiter))
+
+	call := func(key string) error {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+		_, err := client.GetPackSizes(ctx, &packmanv1.GetPackSizesRequest{})
+		return err
+	}
+	for _, key := range []string{"pmk_guess_1", "pmk_guess_2"} {
+		code, _ := errorInfo(t, call(key))
+		assert.Equal(t, codes.Unauthenticated, code)
+	}
+	code, info := errorInfo(t, call("pmk_guess_3"))
+	assert.Equal(t, codes.ResourceExhausted, code)
+	assert.Equal(t, string(apperror.ErrCodeRateLimited), info.Reason)
+	auth.AssertNotCalled(t, "Authenticate", mock.Anything, "pmk_guess_3")
+}
+
+func TestGRPCServer_EveryMethodHasAScope(t *testing.T) {
+	for _, method := range packmanv1.PackService_ServiceDesc.Methods {
+		fullMethod := "/" + packmanv1.PackService_ServiceDesc.ServiceName + "/" + method.MethodName
+		assert.Contains(t, packGRPCScopes, fullMethod, "method %s would be open to everyone", fullMethod)
+	}
+}
+
+func TestGRPCCode_EveryErrorCodeIsMapped(t *testing.T) {
+	for _, code := range apperror.Codes {
+		if code != apperror.ErrCodeInternal {
+			assert.NotEqual(t, codes.Internal, middleware.GRPCCode(code), "error code %s", code)
+		}
+	}
+}
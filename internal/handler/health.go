@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"fmt"
 	"log/slog"
 	"net/http"
 
@@ -20,6 +19,7 @@ type healthHandler struct {
 }
 
 // NewHealthHandler creates a new health handler
+// pgClient is nil when the service runs without a database
 func NewHealthHandler(pgClient *postgres.Client) HealthHandler {
 	return &healthHandler{
 		pgClient: pgClient,
@@ -28,15 +28,22 @@ func NewHealthHandler(pgClient *postgres.Client) HealthHandler {
 
 // Check handles the health check endpoint
 func (h *healthHandler) Check(c *gin.Context) {
+	// nothing to check without a database, the service is up if it can answer
+	if h.pgClient == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"status":   "healthy",
+			"database": nil,
+		})
+		return
+	}
+
 	dbHealth := h.pgClient.CheckHealth(c.Request.Context())
 	statusCode := http.StatusOK
 	if dbHealth.Status != "healthy" {
 		statusCode = http.StatusServiceUnavailable
 
 		// Log health check failure
-		requestID, _ := c.Get("request_id")
-		slog.Error("health check failed",
-			slog.String("request_id", fmt.Sprintf("%v", requestID)),
+		slog.ErrorContext(c.Request.Context(), "health check failed",
 			slog.String("error", dbHealth.Error),
 			slog.Int64("response_time_ms", dbHealth.ResponseTime.Milliseconds()),
 		)
@@ -52,13 +59,24 @@ func (h *healthHandler) Check(c *gin.Context) {
 		}
 	}
 
+	database := gin.H{
+		"status":           dbHealth.Status,
+		"response_time_ms": dbHealth.ResponseTime.Milliseconds(),
+		"error":            dbHealth.Error,
+	}
+	// an unhealthy replica is reported but does not fail the check, reads fall back to the primary
+	if dbHealth.Replica != nil {
+		database["replica"] = gin.H{
+			"status":           dbHealth.Replica.Status,
+			"response_time_ms": dbHealth.Replica.ResponseTime.Milliseconds(),
+			"error":            dbHealth.Replica.Error,
+		}
+		database["reads_from"] = dbHealth.ReadsFrom
+	}
+
 	c.JSON(statusCode, gin.H{
-		"status": dbHealth.Status,
-		"database": gin.H{
-			"status":           dbHealth.Status,
-			"response_time_ms": dbHealth.ResponseTime.Milliseconds(),
-			"error":            dbHealth.Error,
-		},
+		"status":          dbHealth.Status,
+		"database":        database,
 		"connection_pool": poolStats,
 	})
 }
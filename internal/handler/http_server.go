@@ -20,8 +20,34 @@ type Server struct {
 	httpServer *http.Server
 }
 
+// ServerOptions holds the handlers and middleware dependencies of the HTTP server
+// The handlers without a comment are required, the other fields may be left nil
+type ServerOptions struct {
+	PackHandler     PackHTTPHandler
+	StreamHandler   PackStreamHTTPHandler
+	APIKeyHandler   APIKeyHTTPHandler
+	OpenAPIHandler  OpenAPIHTTPHandler
+	LogLevelHandler LogLevelHTTPHandler
+	HealthHandler   HealthHandler
+	// WebhookHandler serves the webhook routes, they are not registered when it is nil
+	WebhookHandler WebhookHTTPHandler
+	// MetricsHandler serves /metrics, it is not registered when it is nil, e.g. when it is served on its own port
+	MetricsHandler MetricsHTTPHandler
+
+	// HTTPMetrics records the handled requests, nil disables request metrics
+	HTTPMetrics middleware.HTTPMetrics
+	// Auth checks the API keys and tokens of requests, nil disables authentication
+	Auth middleware.Authenticator
+	// Limiter limits the requests of each client, nil disables rate limiting
+	Limiter middleware.RateLimiter
+	// IdempotencyKeys replays responses to retried requests, nil disables the Idempotency-Key header
+	IdempotencyKeys middleware.IdempotencyStore
+	// IdempotencyMaxBodyBytes bounds the bodies of requests with an Idempotency-Key
+	IdempotencyMaxBodyBytes int64
+}
+
 // NewServer creates and configures a new HTTP server
-func NewServer(packHandler PackHTTPHandler, healthHandler HealthHandler, cfg config.HttpConfig) *Server {
+func NewServer(opts ServerOptions, cfg config.HttpConfig) *Server {
 	// Setup Gin router
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
@@ -37,15 +63,31 @@ func NewServer(packHandler PackHTTPHandler, healthHandler HealthHandler, cfg con
 	}
 
 	// Add middleware (order matters!)
-	router.Use(cors.New(corsConfig))      // 1. CORS should be first
-	router.Use(middleware.RequestID())    // 2. Generate request ID
-	router.Use(gin.Logger())              // 3. Use Gin's built-in logger
-	router.Use(gin.Recovery())            // 4. Recover from panics
-	router.Use(middleware.ErrorHandler()) // 5. Handle errors and format responses
+	router.Use(cors.New(corsConfig))                                                       // 1. CORS should be first
+	router.Use(middleware.RequestID())                                                     // 2. Generate request ID
+	router.Use(middleware.Tracing())                                                       // 3. Start the server span with the request ID
+	router.Use(middleware.AccessLog())                                                     // 4. Log requests with their request and trace IDs
+	router.Use(middleware.Metrics(opts.HTTPMetrics))                                       // 5. Record request counts and latencies per route
+	router.Use(gin.Recovery())                                                             // 6. Recover from panics
+	router.Use(middleware.ErrorHandler())                                                  // 7. Handle errors and format responses
+	router.Use(middleware.LimitAddresses(opts.Limiter))                                    // 8. Limit requests per client IP, before credentials are checked
+	router.Use(middleware.Authenticate(opts.Auth))                                         // 9. Resolve the API key, routes require their scope
+	router.Use(middleware.RateLimit(opts.Limiter))                                         // 10. Limit requests per API key or client IP
+	router.Use(middleware.Idempotency(opts.IdempotencyKeys, opts.IdempotencyMaxBodyBytes)) // 11. Replay responses to retries with an Idempotency-Key
 
 	// Register routes
-	packHandler.registerRoutes(router)
-	router.GET("/health", healthHandler.Check)
+	opts.PackHandler.registerRoutes(router)
+	opts.StreamHandler.registerRoutes(router)
+	opts.APIKeyHandler.registerRoutes(router)
+	opts.LogLevelHandler.registerRoutes(router)
+	if opts.WebhookHandler != nil {
+		opts.WebhookHandler.registerRoutes(router)
+	}
+	if opts.MetricsHandler != nil {
+		opts.MetricsHandler.registerRoutes(router)
+	}
+	router.GET("/health", opts.HealthHandler.Check)
+	opts.OpenAPIHandler.registerRoutes(router)
 
 	// Configure HTTP server with timeouts
 	httpServer := &http.Server{
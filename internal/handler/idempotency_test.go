@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/idempotency"
+	"github.com/nsaltun/packman/internal/middleware"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testIdempotencyMaxBodyBytes is the body limit of requests with an Idempotency-Key in tests
+const testIdempotencyMaxBodyBytes = 1 << 10
+
+func setupIdempotencyRouter(packService *mocks.MockPackService) *gin.Engine {
+	router := setupTestRouter()
+	router.Use(middleware.Idempotency(newTestIdempotencyStore(), testIdempotencyMaxBodyBytes))
+	NewPackHTTPHandler(packService).registerRoutes(router)
+	return router
+}
+
+func newTestIdempotencyStore() middleware.IdempotencyStore {
+	return idempotency.NewStore(repository.NewMemoryIdempotencyRepo(), config.IdempotencyConfig{
+		KeyTTL:          time.Hour,
+		LockTimeout:     time.Minute,
+		CleanupInterval: time.Hour,
+	})
+}
+
+func TestIdempotency(t *testing.T) {
+	put := func(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/pack-sizes", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	const body = `{"pack_sizes":[250,500],"updated_by":"ops","reason":"new supplier"}`
+	updated := &model.UpdatePackSizesResponse{PackSizes: []int{250, 500}, Version: 2, UpdatedBy: "ops"}
+
+	t.Run("retries replay the first response", func(t *testing.T) {
+		packService := new(mocks.MockPackService)
+		packService.On("UpdatePackSizes", mock.Anything, []int{250, 500}, "ops", "new supplier").Return(updated, nil).Once()
+		router := setupIdempotencyRouter(packService)
+
+		first := put(router, "key-1", body)
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Empty(t, first.Header().Get("Idempotent-Replayed"))
+
+		retry := put(router, "key-1", body)
+		assert.Equal(t, http.StatusOK, retry.Code)
+		assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, first.Header().Get("Content-Type"), retry.Header().Get("Content-Type"))
+		assert.JSONEq(t, first.Body.String(), retry.Body.String())
+
+		packService.AssertNumberOfCalls(t, "UpdatePackSizes", 1)
+	})
+	t.Run("reuse with another body is rejected", func(t *testing.T) {
+		packService := new(mocks.MockPackService)
+		packService.On("UpdatePackSizes", mock.Anything, []int{250, 500}, "ops", "new supplier").Return(updated, nil).Once()
+		router := setupIdempotencyRouter(packService)
+
+		assert.Equal(t, http.StatusOK, put(router, "key-1", body).Code)
+
+		w := put(router, "key-1", `{"pack_sizes":[1000],"updated_by":"ops","reason":"new supplier"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assertErrorCode(t, w, apperror.ErrCodeUnprocessable)
+		packService.AssertNumberOfCalls(t, "UpdatePackSizes", 1)
+	})
+	t.Run("client errors are replayed", func(t *testing.T) {
+		router := setupIdempotencyRouter(new(mocks.MockPackService))
+
+		invalid := `{"pack_sizes":[],"updated_by":"ops","reason":"new supplier"}`
+		assert.Equal(t, http.StatusBadRequest, put(router, "key-1", invalid).Code)
+
+		w := put(router, "key-1", invalid)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+		assertErrorCode(t, w, apperror.ErrCodeValidation)
+	})
+	t.Run("server errors run again on retry", func(t *testing.T) {
+		packService := new(mocks.MockPackService)
+		packService.On("UpdatePackSizes", mock.Anything, []int{250, 500}, "ops", "new supplier").
+			Return(nil, apperror.ServiceUnavailableError("", nil)).Once()
+		packService.On("UpdatePackSizes", mock.Anything, []int{250, 500}, "ops", "new supplier").Return(updated, nil).Once()
+		router := setupIdempotencyRouter(packService)
+
+		w := put(router, "key-1", body)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assertErrorCode(t, w, apperror.ErrCodeServiceUnavail)
+
+		w = put(router, "key-1", body)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+		packService.AssertNumberOfCalls(t, "UpdatePackSizes", 2)
+	})
+	t.Run("requests without a key are not affected", func(t *testing.T) {
+		packService := new(mocks.MockPackService)
+		packService.On("UpdatePackSizes", mock.Anything, []int{250, 500}, "ops", "new supplier").Return(updated, nil)
+		router := setupIdempotencyRouter(packService)
+
+		assert.Equal(t, http.StatusOK, put(router, "", body).Code)
+		assert.Equal(t, http.StatusOK, put(router, "", body).Code)
+		packService.AssertNumberOfCalls(t, "UpdatePackSizes", 2)
+	})
+	t.Run("overlong keys are rejected", func(t *testing.T) {
+		router := setupIdempotencyRouter(new(mocks.MockPackService))
+
+		w := put(router, strings.Repeat("k", 256), body)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assertErrorCode(t, w, apperror.ErrCodeValidation)
+	})
+	t.Run("oversized bodies are rejected", func(t *testing.T) {
+		packService := new(mocks.MockPackService)
+		router := setupIdempotencyRouter(packService)
+
+		oversized := `{"pack_sizes":[250,500],"updated_by":"ops","reason":"` + strings.Repeat("r", testIdempotencyMaxBodyBytes) + `"}`
+		w := put(router, "key-1", oversized)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assertErrorCode(t, w, apperror.ErrCodeTooLarge)
+		packService.AssertNotCalled(t, "UpdatePackSizes", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		// the key was not claimed, the request may be sent again with a smaller body
+		packService.On("UpdatePackSizes", mock.Anything, []int{250, 500}, "ops", "new supplier").Return(updated, nil).Once()
+		assert.Equal(t, http.StatusOK, put(router, "key-1", body).Code)
+	})
+}
+
+func TestIdempotency_AuthorizationFailuresAreNotStored(t *testing.T) {
+	updated := &model.UpdatePackSizesResponse{PackSizes: []int{250, 500}, Version: 2, UpdatedBy: "key:deploy-bot"}
+	packService := new(mocks.MockPackService)
+	packService.On("UpdatePackSizes", mock.Anything, []int{250, 500}, "key:deploy-bot", "new supplier").Return(updated, nil).Once()
+
+	// the key is granted the admin scope between the first request and its retry
+	auth := new(mocks.MockAPIKeyService)
+	auth.On("Authenticate", mock.Anything, "pmk_deploy").
+		Return(&model.Principal{Name: "key:deploy-bot", Scopes: []model.Scope{model.ScopeRead}}, nil).Once()
+	auth.On("Authenticate", mock.Anything, "pmk_deploy").
+		Return(&model.Principal{Name: "key:deploy-bot", Scopes: []model.Scope{model.ScopeAdmin}}, nil).Once()
+
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.Authenticate(auth))
+	router.Use(middleware.Idempotency(newTestIdempotencyStore(), testIdempotencyMaxBodyBytes))
+	NewPackHTTPHandler(packService).registerRoutes(router)
+
+	put := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/pack-sizes", strings.NewReader(`{"pack_sizes":[250,500],"reason":"new supplier"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "pmk_deploy")
+		req.Header.Set("Idempotency-Key", "key-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := put()
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assertErrorCode(t, w, apperror.ErrCodeForbidden)
+
+	w = put()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+	packService.AssertNumberOfCalls(t, "UpdatePackSizes", 1)
+}
+
+func TestIdempotency_RedactsSecrets(t *testing.T) {
+	apiKeyService := new(mocks.MockAPIKeyService)
+	apiKeyService.On("CreateAPIKey", mock.Anything, mock.Anything).
+		Return(&model.APIKey{ID: 7, Name: "ci", Prefix: "pmk_34c8", Key: "pmk_34c818b9secret"}, nil).Once()
+	webhookService := new(mocks.MockWebhookService)
+	webhookService.On("CreateWebhook", mock.Anything, mock.Anything).
+		Return(&model.WebhookSubscription{ID: 3, URL: "https://example.com/hook", Secret: "whsec_signing"}, nil).Once()
+
+	router := setupTestRouter()
+	router.Use(middleware.Idempotency(newTestIdempotencyStore(), testIdempotencyMaxBodyBytes))
+	NewAPIKeyHTTPHandler(apiKeyService).registerRoutes(router)
+	NewWebhookHTTPHandler(webhookService).registerRoutes(router)
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", "key-"+path)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		path   string
+		body   string
+		secret string
+	}{
+		{"/api/v1/api-keys", `{"name":"ci","scopes":["read"]}`, "pmk_34c818b9secret"},
+		{"/api/v1/webhooks", `{"url":"https://example.com/hook","created_by":"ops"}`, "whsec_signing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			first := post(tt.path, tt.body)
+			assert.Equal(t, http.StatusCreated, first.Code)
+			assert.Contains(t, first.Body.String(), tt.secret)
+
+			retry := post(tt.path, tt.body)
+			assert.Equal(t, http.StatusCreated, retry.Code)
+			assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+			assert.NotContains(t, retry.Body.String(), tt.secret)
+			assert.Contains(t, retry.Body.String(), `"id":`)
+		})
+	}
+	apiKeyService.AssertNumberOfCalls(t, "CreateAPIKey", 1)
+	webhookService.AssertNumberOfCalls(t, "CreateWebhook", 1)
+}
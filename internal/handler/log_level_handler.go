@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/middleware"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/response"
+)
+
+// LogLevelHTTPHandler defines the interface for changing the log level at runtime
+type LogLevelHTTPHandler interface {
+	registerRoutes(r *gin.Engine)
+	GetLogLevel(c *gin.Context)
+	SetLogLevel(c *gin.Context)
+}
+
+// logLevelHTTPHandler is the concrete implementation of LogLevelHTTPHandler
+type logLevelHTTPHandler struct {
+	level *slog.LevelVar
+}
+
+// NewLogLevelHTTPHandler creates a new HTTP handler for the minimum level of the logger
+// The level is changed for this instance only, other instances keep their level
+func NewLogLevelHTTPHandler(level *slog.LevelVar) LogLevelHTTPHandler {
+	return &logLevelHTTPHandler{
+		level: level,
+	}
+}
+
+// registerRoutes registers all routes for the HTTP handler
+func (h *logLevelHTTPHandler) registerRoutes(r *gin.Engine) {
+	admin := r.Group("/api/v1/admin", middleware.RequireScope(model.ScopeAdmin))
+	{
+		admin.GET("/log-level", h.GetLogLevel)
+		admin.PUT("/log-level", h.SetLogLevel)
+	}
+}
+
+// GetLogLevel handles retrieving the current log level
+func (h *logLevelHTTPHandler) GetLogLevel(c *gin.Context) {
+	response.Success(c, http.StatusOK, model.LogLevel{Level: logLevelName(h.level.Level())})
+}
+
+// SetLogLevel handles changing the log level, it applies to the following logs right away
+func (h *logLevelHTTPHandler) SetLogLevel(c *gin.Context) {
+	var req model.LogLevel
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperror.BadRequestError("Invalid request format", err))
+		return
+	}
+
+	// validate request
+	level, err := validateLogLevelRequest(&req)
+	if err != nil {
+		_ = c.Error(apperror.ValidationError(err.Error(), err))
+		return
+	}
+
+	previous := h.level.Level()
+	h.level.Set(level)
+	// logged at warning level, so the change is recorded whatever the new level is
+	slog.WarnContext(c.Request.Context(), "log level changed",
+		slog.String("from", logLevelName(previous)),
+		slog.String("to", logLevelName(level)),
+		slog.String("by", requestActor(c, "")),
+	)
+
+	response.Success(c, http.StatusOK, model.LogLevel{Level: logLevelName(level)})
+}
+
+// logLevelName returns the configuration name of a level, e.g. debug
+func logLevelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/middleware"
+	"github.com/nsaltun/packman/internal/response"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogLevelHTTPHandler(t *testing.T) {
+	level := new(slog.LevelVar)
+
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.Authenticate(nil))
+	NewLogLevelHTTPHandler(level).registerRoutes(router)
+
+	send := func(method, body string) (*httptest.ResponseRecorder, response.APIResponse) {
+		req := httptest.NewRequest(method, "/api/v1/admin/log-level", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var res response.APIResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		return w, res
+	}
+
+	t.Run("returns the current level", func(t *testing.T) {
+		w, res := send(http.MethodGet, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, map[string]any{"level": "info"}, res.Data)
+	})
+
+	t.Run("changes the level right away", func(t *testing.T) {
+		w, res := send(http.MethodPut, `{"level": "DEBUG"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, map[string]any{"level": "debug"}, res.Data)
+		assert.Equal(t, slog.LevelDebug, level.Level())
+	})
+
+	t.Run("rejects unknown levels", func(t *testing.T) {
+		w, res := send(http.MethodPut, `{"level": "verbose"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		require.NotNil(t, res.Error)
+		assert.Equal(t, apperror.ErrCodeValidation, res.Error.Code)
+		assert.Equal(t, slog.LevelDebug, level.Level())
+	})
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/internal/middleware"
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// MetricsHTTPHandler defines the interface for serving Prometheus metrics on the HTTP server
+type MetricsHTTPHandler interface {
+	registerRoutes(r *gin.Engine)
+	GetMetrics(c *gin.Context)
+}
+
+// metricsHTTPHandler is the concrete implementation of MetricsHTTPHandler
+type metricsHTTPHandler struct {
+	metrics http.Handler
+}
+
+// NewMetricsHTTPHandler creates a new HTTP handler for the metrics served by metrics
+func NewMetricsHTTPHandler(metrics http.Handler) MetricsHTTPHandler {
+	return &metricsHTTPHandler{
+		metrics: metrics,
+	}
+}
+
+// registerRoutes registers all routes for the HTTP handler
+// The metrics reveal the traffic of the service, so unlike /health they require the read scope
+func (h *metricsHTTPHandler) registerRoutes(r *gin.Engine) {
+	r.GET("/metrics", middleware.RequireScope(model.ScopeRead), h.GetMetrics)
+}
+
+// GetMetrics handles serving the metrics in the Prometheus text format
+func (h *metricsHTTPHandler) GetMetrics(c *gin.Context) {
+	h.metrics.ServeHTTP(c.Writer, c.Request)
+}
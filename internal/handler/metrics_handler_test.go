@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/metrics"
+	"github.com/nsaltun/packman/internal/middleware"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsHTTPHandler(t *testing.T) {
+	auth := new(mocks.MockAPIKeyService)
+	auth.On("Authenticate", mock.Anything, "pmk_prometheus").
+		Return(&model.Principal{Name: "prometheus", Scopes: []model.Scope{model.ScopeRead}}, nil)
+	auth.On("Authenticate", mock.Anything, "pmk_shop").
+		Return(&model.Principal{Name: "shop", Scopes: []model.Scope{model.ScopeCalculate}}, nil)
+
+	packService := new(mocks.MockPackService)
+	packService.On("GetPackSizes", mock.Anything).Return(&model.GetPackSizesResponse{PackSizes: []int{250}, Version: 4}, nil)
+
+	appMetrics := metrics.New()
+	appMetrics.RegisterPackConfiguration(packService)
+
+	router := gin.New()
+	router.Use(middleware.Metrics(appMetrics))
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.Authenticate(auth))
+	NewPackHTTPHandler(packService).registerRoutes(router)
+	NewMetricsHTTPHandler(appMetrics.Handler()).registerRoutes(router)
+
+	send := func(path, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("requires the read scope", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, send("/metrics", "").Code)
+		assert.Equal(t, http.StatusForbidden, send("/metrics", "pmk_shop").Code)
+	})
+
+	t.Run("records requests by route template", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, send("/api/v1/pack-sizes", "pmk_prometheus").Code)
+		require.Equal(t, http.StatusNotFound, send("/api/v1/unknown/42", "pmk_prometheus").Code)
+
+		w := send("/metrics", "pmk_prometheus")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+		body := w.Body.String()
+		assert.Contains(t, body, `packman_http_requests_total{method="GET",route="/api/v1/pack-sizes",status="200"} 1`)
+		assert.Contains(t, body, `packman_http_requests_total{method="GET",route="/metrics",status="403"} 1`)
+		assert.Contains(t, body, `packman_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+		assert.Contains(t, body, `packman_http_request_duration_seconds_count{method="GET",route="/api/v1/pack-sizes",status="200"} 1`)
+		assert.Contains(t, body, "packman_pack_configuration_version 4")
+		assert.NotContains(t, body, "/api/v1/unknown/42")
+	})
+}
+
+func TestMetricsServer(t *testing.T) {
+	appMetrics := metrics.New()
+	appMetrics.ObserveCalculation(500, 0)
+	server := NewMetricsServer(appMetrics.Handler(), config.MetricsConfig{Enabled: true, Port: "0"})
+
+	// served without authentication
+	w := httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "packman_pack_calculation_quantity_count 1")
+
+	w = httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/pack-sizes", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
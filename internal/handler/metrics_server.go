@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/app"
+)
+
+// metricsReadTimeout bounds reading the scrape requests, which have no body
+const metricsReadTimeout = 5 * time.Second
+
+// MetricsServer serves Prometheus metrics on their own port
+// It has no authentication, so the port should only be reachable by the scraper
+type MetricsServer struct {
+	app.AbstractComponent
+	httpServer *http.Server
+}
+
+// NewMetricsServer creates a server for the metrics served by metrics on cfg.Port
+func NewMetricsServer(metrics http.Handler, cfg config.MetricsConfig) *MetricsServer {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics)
+
+	return &MetricsServer{
+		httpServer: &http.Server{
+			Addr:              fmt.Sprintf(":%s", cfg.Port),
+			Handler:           mux,
+			ReadHeaderTimeout: metricsReadTimeout,
+			ReadTimeout:       metricsReadTimeout,
+		},
+	}
+}
+
+// Run starts the metrics server (blocks until shutdown)
+func (s *MetricsServer) Run() error {
+	slog.Info("starting metrics server", slog.String("addr", s.httpServer.Addr))
+	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("metrics server error: %w", err)
+	}
+	return nil
+}
+
+// Close gracefully shuts down the server
+func (s *MetricsServer) Close(ctx context.Context) error {
+	slog.Info("Shutting down metrics server...")
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		return fmt.Errorf("metrics server forced to shutdown: %w", err)
+	}
+	slog.Info("metrics server stopped")
+	return nil
+}
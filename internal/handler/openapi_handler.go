@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// swaggerUIPage loads the embedded Swagger UI with the specification of the service
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <meta charset="UTF-8">
+    <title>Packman API</title>
+    <link rel="stylesheet" type="text/css" href="/docs/swagger-ui.css" />
+    <link rel="icon" type="image/png" href="/docs/favicon-32x32.png" sizes="32x32" />
+  </head>
+  <body>
+    <div id="swagger-ui"></div>
+    <script src="/docs/swagger-ui-bundle.js" charset="UTF-8"></script>
+    <script>
+      window.ui = SwaggerUIBundle({
+        url: "/openapi.json",
+        dom_id: "#swagger-ui",
+        deepLinking: true,
+        persistAuthorization: true
+      });
+    </script>
+  </body>
+</html>
+`
+
+// OpenAPIHTTPHandler defines the interface for the API documentation handlers
+type OpenAPIHTTPHandler interface {
+	registerRoutes(r *gin.Engine)
+	GetSpecification(c *gin.Context)
+	SwaggerUI(c *gin.Context)
+}
+
+// openAPIHTTPHandler is the concrete implementation of OpenAPIHTTPHandler
+type openAPIHTTPHandler struct {
+	spec []byte
+}
+
+// NewOpenAPIHTTPHandler creates a handler serving the specification of the routes and Swagger UI
+func NewOpenAPIHTTPHandler() OpenAPIHTTPHandler {
+	spec, err := json.Marshal(newOpenAPIDocument())
+	if err != nil {
+		// the document only holds strings, numbers, maps and slices
+		panic(fmt.Sprintf("failed to encode the OpenAPI specification: %v", err))
+	}
+	return &openAPIHTTPHandler{
+		spec: spec,
+	}
+}
+
+// registerRoutes registers all routes for the HTTP handler
+// The documentation is open to everyone, like /health
+func (h *openAPIHTTPHandler) registerRoutes(r *gin.Engine) {
+	r.GET("/openapi.json", h.GetSpecification)
+	r.GET("/docs", h.SwaggerUI)
+	r.GET("/docs/*filepath", h.SwaggerUI)
+}
+
+// GetSpecification handles serving the OpenAPI 3 document
+func (h *openAPIHTTPHandler) GetSpecification(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", h.spec)
+}
+
+// SwaggerUI handles serving the Swagger UI page and its assets
+func (h *openAPIHTTPHandler) SwaggerUI(c *gin.Context) {
+	switch c.Param("filepath") {
+	case "", "/", "/index.html":
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+	default:
+		c.FileFromFS(c.Param("filepath"), http.FS(swaggerFiles.FS))
+	}
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/metrics"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// undocumentedRoutes are the routes of the documentation itself, they serve HTML and assets
+var undocumentedRoutes = map[string]bool{
+	"GET /docs":           true,
+	"GET /docs/*filepath": true,
+}
+
+// setupServerRouter returns the router of a server with every handler
+func setupServerRouter() *gin.Engine {
+	packService := new(mocks.MockPackService)
+	server := NewServer(ServerOptions{
+		PackHandler:     NewPackHTTPHandler(packService),
+		StreamHandler:   NewPackStreamHTTPHandler(packService, stream.NewHub(), config.StreamConfig{HeartbeatInterval: time.Hour}),
+		APIKeyHandler:   NewAPIKeyHTTPHandler(new(mocks.MockAPIKeyService)),
+		OpenAPIHandler:  NewOpenAPIHTTPHandler(),
+		LogLevelHandler: NewLogLevelHTTPHandler(new(slog.LevelVar)),
+		HealthHandler:   NewHealthHandler(nil),
+		WebhookHandler:  NewWebhookHTTPHandler(new(mocks.MockWebhookService)),
+		MetricsHandler:  NewMetricsHTTPHandler(metrics.New().Handler()),
+	}, config.HttpConfig{CORS: config.CORSConfig{AllowOrigins: []string{"*"}}})
+	return server.httpServer.Handler.(*gin.Engine)
+}
+
+// getSpecification fetches the OpenAPI document served by router
+func getSpecification(t *testing.T, router *gin.Engine) map[string]any {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var spec map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	return spec
+}
+
+func TestOpenAPI_EveryRouteIsDocumented(t *testing.T) {
+	router := setupServerRouter()
+	spec := getSpecification(t, router)
+	paths := spec["paths"].(map[string]any)
+
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		if undocumentedRoutes[key] {
+			continue
+		}
+
+		path, _ := openAPIPath(route.Path)
+		item, ok := paths[path].(map[string]any)
+		if assert.True(t, ok, "%s is missing from the OpenAPI specification", key) {
+			assert.Contains(t, item, strings.ToLower(route.Method), "%s is missing from the OpenAPI specification", key)
+		}
+	}
+
+	// and nothing is documented that is not served
+	for _, op := range apiOperations {
+		assert.True(t, registered[op.method+" "+op.path], "%s %s is documented but not registered", op.method, op.path)
+	}
+}
+
+func TestOpenAPI_Specification(t *testing.T) {
+	spec := getSpecification(t, setupServerRouter())
+	assert.Equal(t, "3.0.3", spec["openapi"])
+
+	components := spec["components"].(map[string]any)
+	schemas := components["schemas"].(map[string]any)
+
+	t.Run("every error code is documented", func(t *testing.T) {
+		codes := schemas["APIError"].(map[string]any)["properties"].(map[string]any)["code"].(map[string]any)["enum"]
+		expected := make([]any, 0, len(apperror.Codes))
+		for _, code := range apperror.Codes {
+			expected = append(expected, string(code))
+		}
+		assert.ElementsMatch(t, expected, codes)
+	})
+	t.Run("responses are wrapped in the envelope", func(t *testing.T) {
+		assert.Contains(t, schemas, "APIResponse")
+		assert.Contains(t, schemas, "ErrorResponse")
+
+		get := spec["paths"].(map[string]any)["/api/v1/pack-sizes"].(map[string]any)["get"].(map[string]any)
+		ok := get["responses"].(map[string]any)["200"].(map[string]any)
+		schema := ok["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+		allOf := schema["allOf"].([]any)
+		assert.Equal(t, "#/components/schemas/APIResponse", allOf[0].(map[string]any)["$ref"])
+		data := allOf[1].(map[string]any)["properties"].(map[string]any)["data"].(map[string]any)
+		assert.Equal(t, "#/components/schemas/GetPackSizesResponse", data["$ref"])
+	})
+	t.Run("every reference resolves", func(t *testing.T) {
+		var walk func(value any)
+		walk = func(value any) {
+			switch v := value.(type) {
+			case map[string]any:
+				if ref, ok := v["$ref"].(string); ok {
+					parts := strings.Split(strings.TrimPrefix(ref, "#/components/"), "/")
+					require.Len(t, parts, 2, ref)
+					section, _ := components[parts[0]].(map[string]any)
+					assert.Contains(t, section, parts[1], "unresolved reference %s", ref)
+				}
+				for _, child := range v {
+					walk(child)
+				}
+			case []any:
+				for _, child := range v {
+					walk(child)
+				}
+			}
+		}
+		walk(spec)
+	})
+}
+
+func TestOpenAPI_SwaggerUI(t *testing.T) {
+	router := setupServerRouter()
+
+	for _, path := range []string{"/docs", "/docs/"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Contains(t, w.Body.String(), `url: "/openapi.json"`, path)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/swagger-ui-bundle.js", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Body.Bytes())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/missing.js", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
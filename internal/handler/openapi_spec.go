@@ -0,0 +1,531 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/openapi"
+	"github.com/nsaltun/packman/internal/response"
+)
+
+// apiParameter is a query or header parameter of a route
+type apiParameter struct {
+	name        string
+	in          string
+	description string
+	schema      *openapi.Schema
+}
+
+// apiOperation documents a route for the OpenAPI specification
+// The bodies are Go values of the request and response types, their schemas are derived from the types
+type apiOperation struct {
+	method      string
+	path        string // as registered with gin
+	id          string
+	tag         string
+	summary     string
+	description string
+	scope       model.Scope // empty for routes open to everyone
+	parameters  []apiParameter
+	request     any // JSON request body, nil without a body
+	status      int
+	data        any // data of the response envelope, nil without a body
+	errors      []int
+
+	// fileFormats marks request and data as configuration files in JSON, YAML or CSV instead of JSON bodies
+	fileFormats bool
+	// responses documents routes that do not answer with the envelope
+	responses map[int]*openapi.Response
+}
+
+// limitParameter is the limit query parameter of the list routes
+var limitParameter = apiParameter{name: "limit", in: "query", description: "Maximum number of items, newest first", schema: &openapi.Schema{Type: "integer", Format: "int32"}}
+
+// transferFormatParameter is the format query parameter of the import and export routes
+var transferFormatParameter = apiParameter{
+	name:        "format",
+	in:          "query",
+	description: "File format, the import defaults to the Content-Type and the export to json",
+	schema:      &openapi.Schema{Type: "string", Enum: []any{"json", "yaml", "csv"}},
+}
+
+// apiOperations documents every route of the server, the OpenAPI test fails when a route is missing
+var apiOperations = []apiOperation{
+	{
+		method: http.MethodPost, path: "/api/v1/calculate", id: "calculatePacks", tag: "Packs", scope: model.ScopeCalculate,
+		summary:     "Calculate packs",
+		description: "Calculates the combination of packs for a quantity with the fewest items and then the fewest packs. as_of_version or as_of calculate against a historical configuration.",
+		request:     model.PackCalculationRequest{}, status: http.StatusOK, data: model.PackCalculationResponse{},
+		errors: []int{http.StatusNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/pack-sizes", id: "getPackSizes", tag: "Packs", scope: model.ScopeRead,
+		summary: "Get the active pack sizes",
+		status:  http.StatusOK, data: model.GetPackSizesResponse{},
+	},
+	{
+		method: http.MethodPut, path: "/api/v1/pack-sizes", id: "updatePackSizes", tag: "Packs", scope: model.ScopeAdmin,
+		summary:     "Replace the pack sizes",
+		description: "Replaces the pack sizes with a new configuration version. Forbidden when PACK_DIRECT_WRITES_ENABLED is false, changes then go through drafts.",
+		request:     model.UpdatePackSizesRequest{}, status: http.StatusOK, data: model.UpdatePackSizesResponse{},
+	},
+	{
+		method: http.MethodPatch, path: "/api/v1/pack-sizes", id: "patchPackSizes", tag: "Packs", scope: model.ScopeAdmin,
+		summary:     "Add or remove pack sizes",
+		description: "Forbidden when PACK_DIRECT_WRITES_ENABLED is false, changes then go through drafts.",
+		request:     model.PatchPackSizesRequest{}, status: http.StatusOK, data: model.UpdatePackSizesResponse{},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/pack-sizes/export", id: "exportConfiguration", tag: "Packs", scope: model.ScopeRead,
+		summary:    "Export the configuration and its history",
+		parameters: []apiParameter{transferFormatParameter},
+		status:     http.StatusOK, data: model.PackConfigurationExport{}, fileFormats: true,
+	},
+	{
+		method: http.MethodPost, path: "/api/v1/pack-sizes/import", id: "importConfiguration", tag: "Packs", scope: model.ScopeAdmin,
+		summary:     "Import a configuration file",
+		description: "Imports a configuration file as a new version. dry_run=true only reports the changes. Applying the file is forbidden when PACK_DIRECT_WRITES_ENABLED is false.",
+		parameters: []apiParameter{
+			transferFormatParameter,
+			{name: "dry_run", in: "query", description: "Report the changes without applying them", schema: &openapi.Schema{Type: "boolean"}},
+			{name: "updated_by", in: "query", description: "Author of the change, ignored when authenticated", schema: &openapi.Schema{Type: "string"}},
+			{name: "reason", in: "query", description: "Reason of the change, takes precedence over the file", schema: &openapi.Schema{Type: "string"}},
+		},
+		request: model.PackConfigurationImport{}, fileFormats: true,
+		status: http.StatusOK, data: model.ImportPackSizesResponse{},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/pack-sizes/stream", id: "streamPackSizes", tag: "Packs", scope: model.ScopeRead,
+		summary:     "Stream the active pack sizes",
+		description: "Server-sent events with the active configuration on connect and every newer version after that. The event ID is the version.",
+		parameters: []apiParameter{
+			{name: "Last-Event-ID", in: "header", description: "Last version the client has, it is not sent again", schema: &openapi.Schema{Type: "string"}},
+		},
+		status: http.StatusOK,
+		responses: map[int]*openapi.Response{
+			http.StatusOK: {
+				Description: "Stream of pack-sizes events, each with a GetPackSizesResponse as data",
+				Content:     map[string]*openapi.MediaType{"text/event-stream": {Schema: &openapi.Schema{Type: "string"}}},
+			},
+		},
+	},
+	{
+		method: http.MethodPost, path: "/api/v1/pack-sizes/drafts", id: "createDraft", tag: "Drafts", scope: model.ScopeAdmin,
+		summary: "Propose pack sizes for review",
+		request: model.UpdatePackSizesRequest{}, status: http.StatusCreated, data: model.PackConfigurationDraft{},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/pack-sizes/drafts", id: "listDrafts", tag: "Drafts", scope: model.ScopeRead,
+		summary: "List drafts",
+		parameters: []apiParameter{
+			{name: "status", in: "query", description: "Only drafts with this status", schema: &openapi.Schema{Type: "string", Enum: []any{model.DraftStatusPending, model.DraftStatusApproved, model.DraftStatusRejected}}},
+			limitParameter,
+		},
+		status: http.StatusOK, data: []model.PackConfigurationDraft{},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/pack-sizes/drafts/:id", id: "getDraft", tag: "Drafts", scope: model.ScopeRead,
+		summary: "Get a draft",
+		status:  http.StatusOK, data: model.PackConfigurationDraft{},
+	},
+	{
+		method: http.MethodPost, path: "/api/v1/pack-sizes/drafts/:id/approve", id: "approveDraft", tag: "Drafts", scope: model.ScopeAdmin,
+		summary:     "Approve a draft",
+		description: "Makes the pack sizes of a pending draft the active configuration. The author of a draft cannot approve it.",
+		request:     model.ReviewDraftRequest{}, status: http.StatusOK, data: model.UpdatePackSizesResponse{},
+	},
+	{
+		method: http.MethodPost, path: "/api/v1/pack-sizes/drafts/:id/reject", id: "rejectDraft", tag: "Drafts", scope: model.ScopeAdmin,
+		summary: "Reject a draft",
+		request: model.ReviewDraftRequest{}, status: http.StatusOK, data: model.PackConfigurationDraft{},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/pack-sizes/audit", id: "listAuditEntries", tag: "Audit", scope: model.ScopeRead,
+		summary: "Query the audit log",
+		parameters: []apiParameter{
+			{name: "updated_by", in: "query", description: "Only changes by this author", schema: &openapi.Schema{Type: "string"}},
+			{name: "request_id", in: "query", description: "Only changes of this request", schema: &openapi.Schema{Type: "string"}},
+			limitParameter,
+		},
+		status: http.StatusOK, data: []model.AuditEntry{},
+	},
+	{
+		method: http.MethodPost, path: "/api/v1/webhooks", id: "createWebhook", tag: "Webhooks", scope: model.ScopeAdmin,
+		summary:     "Subscribe to configuration changes",
+		description: "The response contains the signing secret, it is not returned again.",
+		request:     model.CreateWebhookRequest{}, status: http.StatusCreated, data: model.WebhookSubscription{},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/webhooks", id: "listWebhooks", tag: "Webhooks", scope: model.ScopeAdmin,
+		summary: "List webhook subscriptions",
+		status:  http.StatusOK, data: []model.WebhookSubscription{},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/webhooks/dead-letters", id: "listDeadLetters", tag: "Webhooks", scope: model.ScopeAdmin,
+		summary:    "List deliveries that ran out of attempts",
+		parameters: []apiParameter{limitParameter},
+		status:     http.StatusOK, data: []model.WebhookDelivery{},
+	},
+	{
+		method: http.MethodPost, path: "/api/v1/webhooks/deliveries/:id/retry", id: "retryWebhookDelivery", tag: "Webhooks", scope: model.ScopeAdmin,
+		summary: "Retry a dead-lettered delivery",
+		status:  http.StatusOK, data: model.WebhookDelivery{},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/webhooks/:id", id: "getWebhook", tag: "Webhooks", scope: model.ScopeAdmin,
+		summary: "Get a webhook subscription",
+		status:  http.StatusOK, data: model.WebhookSubscription{},
+	},
+	{
+		method: http.MethodPut, path: "/api/v1/webhooks/:id", id: "updateWebhook", tag: "Webhooks", scope: model.ScopeAdmin,
+		summary: "Change, pause or rotate the secret of a subscription",
+		request: model.UpdateWebhookRequest{}, status: http.StatusOK, data: model.WebhookSubscription{},
+	},
+	{
+		method: http.MethodDelete, path: "/api/v1/webhooks/:id", id: "deleteWebhook", tag: "Webhooks", scope: model.ScopeAdmin,
+		summary: "Remove a subscription and its delivery log",
+		status:  http.StatusNoContent,
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/webhooks/:id/deliveries", id: "listWebhookDeliveries", tag: "Webhooks", scope: model.ScopeAdmin,
+		summary: "Delivery log of a subscription",
+		parameters: []apiParameter{
+			{name: "status", in: "query", description: "Only deliveries with this status", schema: &openapi.Schema{Type: "string", Enum: []any{model.WebhookDeliveryPending, model.WebhookDeliveryDelivered, model.WebhookDeliveryDead}}},
+			limitParameter,
+		},
+		status: http.StatusOK, data: []model.WebhookDelivery{},
+	},
+	{
+		method: http.MethodPost, path: "/api/v1/api-keys", id: "createAPIKey", tag: "API Keys", scope: model.ScopeAdmin,
+		summary:     "Issue an API key",
+		description: "The response contains the key, it is not returned again.",
+		request:     model.CreateAPIKeyRequest{}, status: http.StatusCreated, data: model.APIKey{},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/api-keys", id: "listAPIKeys", tag: "API Keys", scope: model.ScopeAdmin,
+		summary: "List API keys",
+		status:  http.StatusOK, data: []model.APIKey{},
+	},
+	{
+		method: http.MethodDelete, path: "/api/v1/api-keys/:id", id: "revokeAPIKey", tag: "API Keys", scope: model.ScopeAdmin,
+		summary: "Revoke an API key",
+		status:  http.StatusOK, data: model.APIKey{},
+	},
+	{
+		method: http.MethodGet, path: "/api/v1/admin/log-level", id: "getLogLevel", tag: "System", scope: model.ScopeAdmin,
+		summary: "Get the log level of the instance",
+		status:  http.StatusOK, data: model.LogLevel{},
+	},
+	{
+		method: http.MethodPut, path: "/api/v1/admin/log-level", id: "setLogLevel", tag: "System", scope: model.ScopeAdmin,
+		summary:     "Change the log level of the instance",
+		description: "The level is one of `debug`, `info`, `warn` or `error`. It applies right away, to the instance that handles the request only, until the next restart.",
+		request:     model.LogLevel{}, status: http.StatusOK, data: model.LogLevel{},
+	},
+	{
+		method: http.MethodGet, path: "/metrics", id: "getMetrics", tag: "System", scope: model.ScopeRead,
+		summary:     "Prometheus metrics of the instance",
+		description: "Served here when `METRICS_PORT` is empty. With `METRICS_PORT` set, the metrics are served on that port without authentication instead.",
+		status:      http.StatusOK,
+		responses: map[int]*openapi.Response{
+			http.StatusOK: {
+				Description: "Metrics in the Prometheus text exposition format",
+				Content:     map[string]*openapi.MediaType{"text/plain": {Schema: &openapi.Schema{Type: "string"}}},
+			},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/health", id: "checkHealth", tag: "System",
+		summary: "Check the health of the service and its database",
+		status:  http.StatusOK,
+		responses: map[int]*openapi.Response{
+			http.StatusOK: {
+				Description: "The service and its database are healthy",
+				Content:     map[string]*openapi.MediaType{"application/json": {Schema: openapi.SchemaRef("HealthResponse")}},
+			},
+			http.StatusServiceUnavailable: {
+				Description: "The database is unhealthy",
+				Content:     map[string]*openapi.MediaType{"application/json": {Schema: openapi.SchemaRef("HealthResponse")}},
+			},
+		},
+	},
+	{
+		method: http.MethodGet, path: "/openapi.json", id: "getOpenAPISpecification", tag: "System",
+		summary: "This OpenAPI specification",
+		status:  http.StatusOK,
+		responses: map[int]*openapi.Response{
+			http.StatusOK: {
+				Description: "OpenAPI 3 document",
+				Content:     map[string]*openapi.MediaType{"application/json": {Schema: &openapi.Schema{Type: "object"}}},
+			},
+		},
+	},
+}
+
+// errorResponses names the shared error responses by status code
+var errorResponses = map[int]string{
+	http.StatusBadRequest:          "BadRequest",
+	http.StatusUnauthorized:        "Unauthorized",
+	http.StatusForbidden:           "Forbidden",
+	http.StatusNotFound:            "NotFound",
+	http.StatusConflict:            "Conflict",
+	http.StatusUnprocessableEntity: "UnprocessableEntity",
+	http.StatusTooManyRequests:     "TooManyRequests",
+	http.StatusInternalServerError: "InternalError",
+	http.StatusServiceUnavailable:  "ServiceUnavailable",
+}
+
+// newOpenAPIDocument builds the specification of the routes in apiOperations
+func newOpenAPIDocument() *openapi.Document {
+	schemas := openapi.NewGenerator()
+	schemas.Enum(apperror.ErrorCode(""), enumValues(apperror.Codes)...)
+	schemas.Enum(model.Scope(""), model.ScopeCalculate, model.ScopeRead, model.ScopeAdmin)
+	schemas.Enum(model.DraftStatus(""), model.DraftStatusPending, model.DraftStatusApproved, model.DraftStatusRejected)
+	schemas.Enum(model.WebhookDeliveryStatus(""), model.WebhookDeliveryPending, model.WebhookDeliveryDelivered, model.WebhookDeliveryDead)
+	schemas.Enum(model.PackSizeWarningCode(""), model.WarningCommonDivisor, model.WarningUnusedSize, model.WarningExcessiveOvershoot, model.WarningTooManySizes)
+
+	envelope := schemas.Schema(response.APIResponse{})
+	errorBody := schemas.Define("ErrorResponse", &openapi.Schema{
+		AllOf: []*openapi.Schema{envelope, {Type: "object", Required: []string{"error", "request_id"}}},
+	})
+
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "Packman API",
+			Description: "Calculates the packs to ship for an order quantity and manages the pack size configuration.",
+			Version:     "v1",
+		},
+		Tags: []openapi.Tag{
+			{Name: "Packs", Description: "Pack calculation and the active pack sizes"},
+			{Name: "Drafts", Description: "Pack size changes that need a review"},
+			{Name: "Audit", Description: "History of configuration changes"},
+			{Name: "Webhooks", Description: "Subscriptions to configuration changes"},
+			{Name: "API Keys", Description: "Keys for accessing the API"},
+			{Name: "System", Description: "Health, documentation and administration"},
+		},
+		Paths: make(map[string]*openapi.PathItem),
+		Components: openapi.Components{
+			Responses: make(map[string]*openapi.Response),
+			Parameters: map[string]*openapi.Parameter{
+				"IdempotencyKey": {
+					Name:        "Idempotency-Key",
+					In:          "header",
+					Description: "Replays the first response when the request is retried with the same key",
+					Schema:      &openapi.Schema{Type: "string", Description: "At most 255 characters, e.g. a UUID"},
+				},
+			},
+			SecuritySchemes: map[string]*openapi.SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", Description: "API key or OpenID Connect token"},
+				"apiKeyAuth": {Type: "apiKey", In: "header", Name: "X-API-Key", Description: "API key"},
+			},
+		},
+	}
+
+	for status, name := range errorResponses {
+		errorResponse := &openapi.Response{
+			Description: http.StatusText(status),
+			Content:     map[string]*openapi.MediaType{"application/json": {Schema: errorBody}},
+		}
+		switch status {
+		case http.StatusUnauthorized:
+			errorResponse.Headers = map[string]*openapi.Header{
+				"WWW-Authenticate": {Schema: &openapi.Schema{Type: "string"}},
+			}
+		case http.StatusTooManyRequests:
+			errorResponse.Headers = map[string]*openapi.Header{
+				"Retry-After":         {Description: "Seconds until the next request is allowed", Schema: &openapi.Schema{Type: "integer"}},
+				"RateLimit-Limit":     {Description: "Requests allowed per window", Schema: &openapi.Schema{Type: "integer"}},
+				"RateLimit-Remaining": {Description: "Requests that can be made right away", Schema: &openapi.Schema{Type: "integer"}},
+				"RateLimit-Reset":     {Description: "Seconds until the limit is fully restored", Schema: &openapi.Schema{Type: "integer"}},
+				"RateLimit-Policy":    {Description: "The limit as requests;w=seconds", Schema: &openapi.Schema{Type: "string"}},
+			}
+		}
+		doc.Components.Responses[name] = errorResponse
+	}
+
+	for _, op := range apiOperations {
+		path, pathParameters := openAPIPath(op.path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &openapi.PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(op.method)] = newOpenAPIOperation(schemas, envelope, op, pathParameters)
+	}
+
+	// the request and response types are known now
+	doc.Components.Schemas = schemas.Schemas()
+	doc.Components.Schemas["HealthResponse"] = healthSchema()
+	return doc
+}
+
+// newOpenAPIOperation documents op, whose path has pathParameters
+func newOpenAPIOperation(schemas *openapi.Generator, envelope *openapi.Schema, op apiOperation, pathParameters []string) *openapi.Operation {
+	operation := &openapi.Operation{
+		OperationID: op.id,
+		Tags:        []string{op.tag},
+		Summary:     op.summary,
+		Description: op.description,
+		Responses:   make(map[string]*openapi.Response),
+	}
+
+	for _, name := range pathParameters {
+		operation.Parameters = append(operation.Parameters, &openapi.Parameter{
+			Name: name, In: "path", Required: true, Schema: &openapi.Schema{Type: "integer", Format: "int64"},
+		})
+	}
+	for _, parameter := range op.parameters {
+		operation.Parameters = append(operation.Parameters, &openapi.Parameter{
+			Name: parameter.name, In: parameter.in, Description: parameter.description, Schema: parameter.schema,
+		})
+	}
+
+	mutating := op.method != http.MethodGet
+	if op.scope != "" {
+		operation.Security = []openapi.SecurityRequirement{{"bearerAuth": {}}, {"apiKeyAuth": {}}}
+		scopeNote := fmt.Sprintf("Requires the `%s` scope.", op.scope)
+		operation.Description = strings.TrimSpace(operation.Description + " " + scopeNote)
+		if mutating {
+			operation.Parameters = append(operation.Parameters, openapi.ParameterRef("IdempotencyKey"))
+		}
+	}
+
+	switch {
+	case op.request != nil && op.fileFormats:
+		operation.RequestBody = &openapi.RequestBody{Required: true, Content: fileContent(schemas.Schema(op.request))}
+	case op.request != nil:
+		operation.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content:  map[string]*openapi.MediaType{"application/json": {Schema: schemas.Schema(op.request)}},
+		}
+	}
+
+	status := strconv.Itoa(op.status)
+	switch {
+	case op.data != nil && op.fileFormats:
+		operation.Responses[status] = &openapi.Response{
+			Description: "Configuration file",
+			Headers:     map[string]*openapi.Header{"Content-Disposition": {Schema: &openapi.Schema{Type: "string"}}},
+			Content:     fileContent(schemas.Schema(op.data)),
+		}
+	case op.data != nil:
+		operation.Responses[status] = &openapi.Response{
+			Description: http.StatusText(op.status),
+			Content: map[string]*openapi.MediaType{"application/json": {Schema: &openapi.Schema{
+				AllOf: []*openapi.Schema{envelope, {
+					Type:       "object",
+					Properties: map[string]*openapi.Schema{"data": schemas.Schema(op.data)},
+					Required:   []string{"data", "request_id"},
+				}},
+			}}},
+		}
+	case op.responses == nil:
+		operation.Responses[status] = &openapi.Response{Description: http.StatusText(op.status)}
+	}
+	for code, res := range op.responses {
+		operation.Responses[strconv.Itoa(code)] = res
+	}
+
+	// the routes open to everyone do not pass through authentication and only fail as documented
+	if op.scope == "" {
+		return operation
+	}
+
+	// the errors every API route can fail with, and those of its kind
+	errors := append([]int{
+		http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable,
+	}, op.errors...)
+	if operation.RequestBody != nil || len(op.parameters) > 0 || len(pathParameters) > 0 {
+		errors = append(errors, http.StatusBadRequest)
+	}
+	if len(pathParameters) > 0 {
+		errors = append(errors, http.StatusNotFound)
+	}
+	if mutating {
+		// an Idempotency-Key in use or reused for another request
+		errors = append(errors, http.StatusConflict, http.StatusUnprocessableEntity)
+	}
+	for _, code := range errors {
+		operation.Responses[strconv.Itoa(code)] = openapi.ResponseRef(errorResponses[code])
+	}
+	return operation
+}
+
+// fileContent describes a configuration file in JSON or YAML with schema, or in CSV
+func fileContent(schema *openapi.Schema) map[string]*openapi.MediaType {
+	return map[string]*openapi.MediaType{
+		"application/json": {Schema: schema},
+		"application/yaml": {Schema: schema},
+		"text/csv":         {Schema: &openapi.Schema{Type: "string", Description: "One row per pack size, or per version in an export"}},
+	}
+}
+
+// openAPIPath converts a gin path to an OpenAPI path and returns the names of its parameters
+func openAPIPath(path string) (string, []string) {
+	var parameters []string
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+			parameters = append(parameters, name)
+		}
+	}
+	return strings.Join(segments, "/"), parameters
+}
+
+// healthSchema describes the body of the health check, which is not wrapped in the envelope
+func healthSchema() *openapi.Schema {
+	check := &openapi.Schema{
+		Type: "object",
+		Properties: map[string]*openapi.Schema{
+			"status":           {Type: "string", Enum: []any{"healthy", "unhealthy"}},
+			"response_time_ms": {Type: "integer", Format: "int64"},
+			"error":            {Type: "string"},
+		},
+	}
+	database := &openapi.Schema{
+		Type:        "object",
+		Nullable:    true,
+		Description: "null without a database",
+		Properties: map[string]*openapi.Schema{
+			"status":           check.Properties["status"],
+			"response_time_ms": check.Properties["response_time_ms"],
+			"error":            check.Properties["error"],
+			"replica":          check,
+			"reads_from":       {Type: "string", Enum: []any{"replica", "primary"}},
+		},
+	}
+	pool := &openapi.Schema{
+		Type:     "object",
+		Nullable: true,
+		Properties: map[string]*openapi.Schema{
+			"total_conns":    {Type: "integer"},
+			"acquired_conns": {Type: "integer"},
+			"idle_conns":     {Type: "integer"},
+			"max_conns":      {Type: "integer"},
+		},
+	}
+	return &openapi.Schema{
+		Type:     "object",
+		Required: []string{"status", "database"},
+		Properties: map[string]*openapi.Schema{
+			"status":          check.Properties["status"],
+			"database":        database,
+			"connection_pool": pool,
+		},
+	}
+}
+
+// enumValues converts values for an enum of a schema
+func enumValues[T any](values []T) []any {
+	converted := make([]any, len(values))
+	for i, value := range values {
+		converted[i] = value
+	}
+	return converted
+}
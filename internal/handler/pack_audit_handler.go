@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/response"
+)
+
+// ListAuditEntries handles querying the configuration audit log
+// Entries can be filtered with the updated_by and request_id query parameters
+func (h *packHTTPHandler) ListAuditEntries(c *gin.Context) {
+	limit, err := parseLimitQuery(c)
+	if err != nil {
+		_ = c.Error(apperror.ValidationError(err.Error(), err))
+		return
+	}
+
+	filter := model.AuditFilter{
+		UpdatedBy: c.Query("updated_by"),
+		RequestID: c.Query("request_id"),
+		Limit:     limit,
+	}
+
+	res, err := h.packService.ListAuditEntries(c.Request.Context(), filter)
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	response.Success(c, http.StatusOK, res)
+}
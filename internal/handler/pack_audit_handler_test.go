@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/reqctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPackHTTPHandler_ListAuditEntries(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		mockSetup      func(*mocks.MockPackService)
+		expectedStatus int
+		expectedCode   apperror.ErrorCode
+	}{
+		{
+			name: "filters are passed to the service",
+			url:  "/api/v1/pack-sizes/audit?updated_by=alice&request_id=req-1&limit=5",
+			mockSetup: func(m *mocks.MockPackService) {
+				m.On("ListAuditEntries", mock.Anything, model.AuditFilter{UpdatedBy: "alice", RequestID: "req-1", Limit: 5}).
+					Return([]*model.AuditEntry{{ID: 1, Version: 2, PreviousVersion: 1, Reason: "new supplier"}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid limit",
+			url:            "/api/v1/pack-sizes/audit?limit=zero",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			mockService := new(mocks.MockPackService)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockService)
+			}
+			handler := NewPackHTTPHandler(mockService)
+
+			// create router with error handler middleware and execute
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			w := httptest.NewRecorder()
+			router := setupTestRouter()
+			router.GET("/api/v1/pack-sizes/audit", handler.ListAuditEntries)
+			router.ServeHTTP(w, req)
+
+			// assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, w, tt.expectedCode)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRequestMetadataReachesService(t *testing.T) {
+	mockService := new(mocks.MockPackService)
+	handler := NewPackHTTPHandler(mockService)
+
+	hasMetadata := mock.MatchedBy(func(ctx context.Context) bool {
+		md := reqctx.FromContext(ctx)
+		return md.RequestID == "req-42" && md.ClientIP == "192.0.2.10"
+	})
+	mockService.On("ListAuditEntries", hasMetadata, mock.Anything).Return([]*model.AuditEntry{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pack-sizes/audit", nil)
+	req.Header.Set("X-Request-ID", "req-42")
+	req.RemoteAddr = "192.0.2.10:51000"
+	w := httptest.NewRecorder()
+	router := setupTestRouter()
+	router.GET("/api/v1/pack-sizes/audit", handler.ListAuditEntries)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestRequestID_ReplacesUnsafeIDs(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		kept      bool
+	}{
+		{"a UUID is kept", "4f6c1b9e-2d0a-4c1e-9a51-8f0e6f3d2b7a", true},
+		{"a trace style ID is kept", "web:checkout.42_a", true},
+		{"a missing ID is generated", "", false},
+		{"an overlong ID is replaced", strings.Repeat("a", 129), false},
+		{"unsafe characters are replaced", "req-1\" OR 1=1 --", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored string
+			router := setupTestRouter()
+			router.GET("/ping", func(c *gin.Context) {
+				stored = reqctx.FromContext(c.Request.Context()).RequestID
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			req.Header.Set("X-Request-ID", tt.requestID)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, stored, w.Header().Get("X-Request-ID"))
+			if tt.kept {
+				assert.Equal(t, tt.requestID, stored)
+			} else {
+				assert.NoError(t, uuid.Validate(stored))
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/response"
+	"github.com/nsaltun/packman/pkg/sets"
+)
+
+// CreateDraft handles proposing new pack sizes for review
+func (h *packHTTPHandler) CreateDraft(c *gin.Context) {
+	var req model.UpdatePackSizesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperror.BadRequestError("Invalid request format", err))
+		return
+	}
+	req.UpdatedBy = requestActor(c, req.UpdatedBy)
+
+	// validate request
+	if err := validateCreateDraftRequest(&req); err != nil {
+		_ = c.Error(apperror.ValidationError(err.Error(), err))
+		return
+	}
+
+	//deduplicate pack sizes
+	req.PackSizes = sets.DeduplicateIntSlice(req.PackSizes)
+
+	res, err := h.packService.CreateDraft(c.Request.Context(), req.PackSizes, req.UpdatedBy, req.Reason)
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	response.Success(c, http.StatusCreated, res)
+}
+
+// ListDrafts handles listing drafts, optionally filtered by the status query parameter
+func (h *packHTTPHandler) ListDrafts(c *gin.Context) {
+	status := model.DraftStatus(c.Query("status"))
+	switch status {
+	case "", model.DraftStatusPending, model.DraftStatusApproved, model.DraftStatusRejected:
+	default:
+		_ = c.Error(apperror.ValidationError("status must be one of pending, approved, rejected", nil))
+		return
+	}
+
+	limit, err := parseLimitQuery(c)
+	if err != nil {
+		_ = c.Error(apperror.ValidationError(err.Error(), err))
+		return
+	}
+
+	res, err := h.packService.ListDrafts(c.Request.Context(), status, limit)
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	response.Success(c, http.StatusOK, res)
+}
+
+// GetDraft handles retrieving a single draft
+func (h *packHTTPHandler) GetDraft(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		_ = c.Error(apperror.BadRequestError(err.Error(), err))
+		return
+	}
+
+	res, err := h.packService.GetDraft(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	response.Success(c, http.StatusOK, res)
+}
+
+// ApproveDraft handles approving a draft, which replaces the active configuration
+func (h *packHTTPHandler) ApproveDraft(c *gin.Context) {
+	id, req, ok := bindReviewDraftRequest(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.packService.ApproveDraft(c.Request.Context(), id, req.ReviewedBy)
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	response.Success(c, http.StatusOK, res)
+}
+
+// RejectDraft handles rejecting a draft
+func (h *packHTTPHandler) RejectDraft(c *gin.Context) {
+	id, req, ok := bindReviewDraftRequest(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.packService.RejectDraft(c.Request.Context(), id, req.ReviewedBy, req.Comment)
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	response.Success(c, http.StatusOK, res)
+}
+
+// bindReviewDraftRequest parses the draft ID and review body, reporting errors on the context
+func bindReviewDraftRequest(c *gin.Context) (int, *model.ReviewDraftRequest, bool) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		_ = c.Error(apperror.BadRequestError(err.Error(), err))
+		return 0, nil, false
+	}
+
+	var req model.ReviewDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperror.BadRequestError("Invalid request format", err))
+		return 0, nil, false
+	}
+	req.ReviewedBy = requestActor(c, req.ReviewedBy)
+
+	// validate request
+	if err := validateReviewDraftRequest(&req); err != nil {
+		_ = c.Error(apperror.ValidationError(err.Error(), err))
+		return 0, nil, false
+	}
+
+	return id, &req, true
+}
+
+// parseIDParam parses the positive integer :id path parameter
+func parseIDParam(c *gin.Context) (int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("id must be a positive integer")
+	}
+	return id, nil
+}
+
+// parseLimitQuery parses the optional limit query parameter, returning 0 when absent
+func parseLimitQuery(c *gin.Context) (int, error) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 {
+		return 0, fmt.Errorf("limit must be a positive integer")
+	}
+	return limit, nil
+}
@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+	"github.com/nsaltun/packman/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPackHTTPHandler_CreateDraft(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		mockSetup      func(*mocks.MockPackService)
+		expectedStatus int
+		expectedCode   apperror.ErrorCode
+	}{
+		{
+			name: "successful draft",
+			requestBody: model.UpdatePackSizesRequest{
+				PackSizes: []int{250, 500, 250},
+				UpdatedBy: "alice",
+				Reason:    "peak season",
+			},
+			mockSetup: func(m *mocks.MockPackService) {
+				m.On("CreateDraft", mock.Anything, []int{250, 500}, "alice", "peak season").
+					Return(&model.PackConfigurationDraft{
+						ID:        1,
+						PackSizes: []int{250, 500},
+						Status:    model.DraftStatusPending,
+						UpdatedBy: "alice",
+					}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "validation error - missing author",
+			requestBody: model.UpdatePackSizesRequest{
+				PackSizes: []int{250, 500},
+				Reason:    "peak season",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+		{
+			name: "validation error - missing reason",
+			requestBody: model.UpdatePackSizesRequest{
+				PackSizes: []int{250, 500},
+				UpdatedBy: "alice",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			mockService := new(mocks.MockPackService)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockService)
+			}
+			handler := NewPackHTTPHandler(mockService)
+
+			// create request
+			bodyBytes, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/pack-sizes/drafts", bytes.NewBuffer(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+
+			// create router with error handler middleware and execute
+			w := httptest.NewRecorder()
+			router := setupTestRouter()
+			router.POST("/api/v1/pack-sizes/drafts", handler.CreateDraft)
+			router.ServeHTTP(w, req)
+
+			// assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, w, tt.expectedCode)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPackHTTPHandler_ApproveDraft(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		requestBody    interface{}
+		mockSetup      func(*mocks.MockPackService)
+		expectedStatus int
+		expectedCode   apperror.ErrorCode
+	}{
+		{
+			name:        "successful approval",
+			path:        "/api/v1/pack-sizes/drafts/7/approve",
+			requestBody: model.ReviewDraftRequest{ReviewedBy: "bob"},
+			mockSetup: func(m *mocks.MockPackService) {
+				m.On("ApproveDraft", mock.Anything, 7, "bob").
+					Return(&model.UpdatePackSizesResponse{
+						PackSizes:  []int{250, 750},
+						Version:    4,
+						UpdatedBy:  "alice",
+						ApprovedBy: "bob",
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "self approval is forbidden",
+			path:        "/api/v1/pack-sizes/drafts/7/approve",
+			requestBody: model.ReviewDraftRequest{ReviewedBy: "alice"},
+			mockSetup: func(m *mocks.MockPackService) {
+				m.On("ApproveDraft", mock.Anything, 7, "alice").
+					Return(nil, apperror.ForbiddenError("Drafts must be approved by a different user than the author", nil))
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   apperror.ErrCodeForbidden,
+		},
+		{
+			name:           "invalid draft id",
+			path:           "/api/v1/pack-sizes/drafts/abc/approve",
+			requestBody:    model.ReviewDraftRequest{ReviewedBy: "bob"},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeBadRequest,
+		},
+		{
+			name:           "validation error - missing reviewer",
+			path:           "/api/v1/pack-sizes/drafts/7/approve",
+			requestBody:    model.ReviewDraftRequest{},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			mockService := new(mocks.MockPackService)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockService)
+			}
+			handler := NewPackHTTPHandler(mockService)
+
+			// create request
+			bodyBytes, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewBuffer(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+
+			// create router with error handler middleware and execute
+			w := httptest.NewRecorder()
+			router := setupTestRouter()
+			router.POST("/api/v1/pack-sizes/drafts/:id/approve", handler.ApproveDraft)
+			router.ServeHTTP(w, req)
+
+			// assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, w, tt.expectedCode)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPackHTTPHandler_ListDrafts(t *testing.T) {
+	t.Run("filters by status", func(t *testing.T) {
+		mockService := new(mocks.MockPackService)
+		mockService.On("ListDrafts", mock.Anything, model.DraftStatusPending, 5).
+			Return([]*model.PackConfigurationDraft{{ID: 1, Status: model.DraftStatusPending}}, nil)
+		handler := NewPackHTTPHandler(mockService)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/pack-sizes/drafts?status=pending&limit=5", nil)
+		w := httptest.NewRecorder()
+		router := setupTestRouter()
+		router.GET("/api/v1/pack-sizes/drafts", handler.ListDrafts)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+	t.Run("unknown status", func(t *testing.T) {
+		handler := NewPackHTTPHandler(new(mocks.MockPackService))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/pack-sizes/drafts?status=merged", nil)
+		w := httptest.NewRecorder()
+		router := setupTestRouter()
+		router.GET("/api/v1/pack-sizes/drafts", handler.ListDrafts)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assertErrorCode(t, w, apperror.ErrCodeValidation)
+	})
+}
+
+// assertErrorCode checks the error code in a standardized error response
+func TestPackHTTPHandler_DirectWritesRequireApproval(t *testing.T) {
+	packService := service.NewPackService(repository.NewMemoryRepo(), config.PackAnalysisConfig{}, config.PackApprovalConfig{})
+	router := setupTestRouter()
+	NewPackHTTPHandler(packService).registerRoutes(router)
+
+	body, _ := json.Marshal(model.UpdatePackSizesRequest{PackSizes: []int{750}, UpdatedBy: "alice", Reason: "skip review"})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/pack-sizes", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assertErrorCode(t, w, apperror.ErrCodeForbidden)
+
+	// the active version is unchanged
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/pack-sizes", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data model.GetPackSizesResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Data.Version)
+	assert.Equal(t, repository.DefaultPackSizes, response.Data.PackSizes)
+}
+
+func assertErrorCode(t *testing.T, w *httptest.ResponseRecorder, code apperror.ErrorCode) {
+	t.Helper()
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	errorData, ok := response["error"].(map[string]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, string(code), errorData["code"])
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/reqctx"
+	"github.com/nsaltun/packman/internal/service"
+	"github.com/nsaltun/packman/pkg/sets"
+	packmanv1 "github.com/nsaltun/packman/proto/packman/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// packGRPCScopes lists the scope each PackService method requires, like the scopes of the HTTP routes
+var packGRPCScopes = map[string]model.Scope{
+	packmanv1.PackService_CalculatePacks_FullMethodName:     model.ScopeCalculate,
+	packmanv1.PackService_GetPackSizes_FullMethodName:       model.ScopeRead,
+	packmanv1.PackService_UpdatePackSizes_FullMethodName:    model.ScopeAdmin,
+	packmanv1.PackService_GetPackSizeHistory_FullMethodName: model.ScopeRead,
+}
+
+// packGRPCHandler implements the gRPC PackService over the same PackService as the HTTP handlers
+// Requests are validated like their HTTP counterparts and errors are returned as AppErrors,
+// the interceptors of the server convert them to gRPC statuses
+type packGRPCHandler struct {
+	packmanv1.UnimplementedPackServiceServer
+	packService service.PackService
+}
+
+// NewPackGRPCHandler creates a new gRPC handler with the given services
+func NewPackGRPCHandler(packService service.PackService) packmanv1.PackServiceServer {
+	return &packGRPCHandler{
+		packService: packService,
+	}
+}
+
+// CalculatePacks calculates the packs for a quantity, against a historical configuration if requested
+func (h *packGRPCHandler) CalculatePacks(ctx context.Context, req *packmanv1.CalculatePacksRequest) (*packmanv1.CalculatePacksResponse, error) {
+	calculation := model.PackCalculationRequest{
+		Quantity:    int(req.GetQuantity()),
+		AsOfVersion: int(req.GetAsOfVersion()),
+	}
+	if req.GetAsOf() != nil {
+		if err := req.GetAsOf().CheckValid(); err != nil {
+			return nil, apperror.BadRequestError("Invalid as_of timestamp", err)
+		}
+		asOf := req.GetAsOf().AsTime()
+		calculation.AsOf = &asOf
+	}
+
+	// validate request
+	if err := validateCalculatePacksRequest(&calculation); err != nil {
+		return nil, apperror.ValidationError(err.Error(), err)
+	}
+
+	var res *model.PackCalculationResponse
+	var err error
+	switch {
+	case calculation.AsOfVersion > 0:
+		res, err = h.packService.CalculatePacksAsOf(ctx, calculation.Quantity, calculation.AsOfVersion, time.Time{})
+	case calculation.AsOf != nil:
+		res, err = h.packService.CalculatePacksAsOf(ctx, calculation.Quantity, 0, *calculation.AsOf)
+	default:
+		res, err = h.packService.CalculatePacks(ctx, calculation.Quantity)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	packs := make([]*packmanv1.Pack, 0, len(res.Packs))
+	for size, count := range res.Packs {
+		packs = append(packs, &packmanv1.Pack{Size: int64(size), Count: int64(count)})
+	}
+	slices.SortFunc(packs, func(a, b *packmanv1.Pack) int {
+		return cmp.Compare(b.Size, a.Size)
+	})
+
+	return &packmanv1.CalculatePacksResponse{
+		Quantity: int64(res.Quantity),
+		Packs:    packs,
+		Version:  int64(res.Version),
+	}, nil
+}
+
+// GetPackSizes returns the active configuration
+func (h *packGRPCHandler) GetPackSizes(ctx context.Context, req *packmanv1.GetPackSizesRequest) (*packmanv1.GetPackSizesResponse, error) {
+	res, err := h.packService.GetPackSizes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &packmanv1.GetPackSizesResponse{
+		Configuration: newPackConfigurationMessage(res.PackSizes, res.Version, res.UpdatedAt, res.UpdatedBy, res.ApprovedBy),
+	}, nil
+}
+
+// UpdatePackSizes replaces the pack sizes with a new version
+func (h *packGRPCHandler) UpdatePackSizes(ctx context.Context, req *packmanv1.UpdatePackSizesRequest) (*packmanv1.UpdatePackSizesResponse, error) {
+	update := model.UpdatePackSizesRequest{
+		PackSizes: fromInt64s(req.GetPackSizes()),
+		UpdatedBy: req.GetUpdatedBy(),
+		Reason:    req.GetReason(),
+	}
+	// the authenticated caller is recorded instead of the requested author
+	if identity := reqctx.FromContext(ctx).Identity; identity != "" {
+		update.UpdatedBy = identity
+	}
+
+	// validate request
+	if err := validateUpdatePackSizesRequest(&update); err != nil {
+		return nil, apperror.ValidationError(err.Error(), err)
+	}
+	if err := validateReason(update.Reason); err != nil {
+		return nil, apperror.ValidationError(err.Error(), err)
+	}
+
+	res, err := h.packService.UpdatePackSizes(ctx, sets.DeduplicateIntSlice(update.PackSizes), update.UpdatedBy, update.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := make([]*packmanv1.PackSizeWarning, 0, len(res.Warnings))
+	for _, warning := range res.Warnings {
+		warnings = append(warnings, &packmanv1.PackSizeWarning{
+			Code:    string(warning.Code),
+			Message: warning.Message,
+			Sizes:   toInt64s(warning.Sizes),
+		})
+	}
+
+	return &packmanv1.UpdatePackSizesResponse{
+		Configuration: newPackConfigurationMessage(res.PackSizes, res.Version, res.UpdatedAt, res.UpdatedBy, res.ApprovedBy),
+		Warnings:      warnings,
+	}, nil
+}
+
+// GetPackSizeHistory returns the active configuration and its archived versions
+func (h *packGRPCHandler) GetPackSizeHistory(ctx context.Context, req *packmanv1.GetPackSizeHistoryRequest) (*packmanv1.GetPackSizeHistoryResponse, error) {
+	export, err := h.packService.ExportConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]*packmanv1.PackConfigurationVersion, 0, len(export.History))
+	for _, version := range export.History {
+		versions = append(versions, &packmanv1.PackConfigurationVersion{
+			Version:    int64(version.Version),
+			PackSizes:  toInt64s(version.PackSizes),
+			ArchivedAt: timestamppb.New(version.ArchivedAt),
+			UpdatedBy:  version.UpdatedBy,
+			ApprovedBy: version.ApprovedBy,
+		})
+	}
+
+	return &packmanv1.GetPackSizeHistoryResponse{
+		Current:  newPackConfigurationMessage(export.PackSizes, export.Version, export.UpdatedAt, export.UpdatedBy, export.ApprovedBy),
+		Versions: versions,
+	}, nil
+}
+
+// newPackConfigurationMessage converts a configuration to its gRPC message
+func newPackConfigurationMessage(sizes []int, version int, updatedAt time.Time, updatedBy, approvedBy string) *packmanv1.PackConfiguration {
+	return &packmanv1.PackConfiguration{
+		PackSizes:  toInt64s(sizes),
+		Version:    int64(version),
+		UpdatedAt:  timestamppb.New(updatedAt),
+		UpdatedBy:  updatedBy,
+		ApprovedBy: approvedBy,
+	}
+}
+
+// toInt64s converts pack sizes to their gRPC representation
+func toInt64s(values []int) []int64 {
+	converted := make([]int64, len(values))
+	for i, v := range values {
+		converted[i] = int64(v)
+	}
+	return converted
+}
+
+// fromInt64s converts the pack sizes of a gRPC request
+func fromInt64s(values []int64) []int {
+	converted := make([]int, len(values))
+	for i, v := range values {
+		converted[i] = int(v)
+	}
+	return converted
+}
@@ -2,9 +2,11 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/middleware"
 	"github.com/nsaltun/packman/internal/model"
 	"github.com/nsaltun/packman/internal/response"
 	"github.com/nsaltun/packman/internal/service"
@@ -17,6 +19,15 @@ type PackHTTPHandler interface {
 	CalculatePacks(c *gin.Context)
 	GetPackSizes(c *gin.Context)
 	UpdatePackSizes(c *gin.Context)
+	PatchPackSizes(c *gin.Context)
+	ExportConfiguration(c *gin.Context)
+	ImportConfiguration(c *gin.Context)
+	CreateDraft(c *gin.Context)
+	ListDrafts(c *gin.Context)
+	GetDraft(c *gin.Context)
+	ApproveDraft(c *gin.Context)
+	RejectDraft(c *gin.Context)
+	ListAuditEntries(c *gin.Context)
 }
 
 // packHTTPHandler is the concrete implementation of PackHTTPHandler
@@ -33,11 +44,24 @@ func NewPackHTTPHandler(packService service.PackService) PackHTTPHandler {
 
 // registerRoutes registers all routes for the HTTP handler
 func (h *packHTTPHandler) registerRoutes(r *gin.Engine) {
+	calculate := middleware.RequireScope(model.ScopeCalculate)
+	read := middleware.RequireScope(model.ScopeRead)
+	admin := middleware.RequireScope(model.ScopeAdmin)
+
 	packs := r.Group("/api/v1")
 	{
-		packs.POST("/calculate", h.CalculatePacks)
-		packs.GET("/pack-sizes", h.GetPackSizes)
-		packs.PUT("/pack-sizes", h.UpdatePackSizes)
+		packs.POST("/calculate", calculate, h.CalculatePacks)
+		packs.GET("/pack-sizes", read, h.GetPackSizes)
+		packs.PUT("/pack-sizes", admin, h.UpdatePackSizes)
+		packs.PATCH("/pack-sizes", admin, h.PatchPackSizes)
+		packs.GET("/pack-sizes/export", read, h.ExportConfiguration)
+		packs.POST("/pack-sizes/import", admin, h.ImportConfiguration)
+		packs.POST("/pack-sizes/drafts", admin, h.CreateDraft)
+		packs.GET("/pack-sizes/drafts", read, h.ListDrafts)
+		packs.GET("/pack-sizes/drafts/:id", read, h.GetDraft)
+		packs.POST("/pack-sizes/drafts/:id/approve", admin, h.ApproveDraft)
+		packs.POST("/pack-sizes/drafts/:id/reject", admin, h.RejectDraft)
+		packs.GET("/pack-sizes/audit", read, h.ListAuditEntries)
 	}
 }
 
@@ -56,8 +80,17 @@ func (h *packHTTPHandler) CalculatePacks(c *gin.Context) {
 		return
 	}
 
-	// call service to calculate packs
-	res, err := h.packService.CalculatePacks(c.Request.Context(), req.Quantity)
+	// call service to calculate packs, against a historical configuration if requested
+	var res *model.PackCalculationResponse
+	var err error
+	switch {
+	case req.AsOfVersion > 0:
+		res, err = h.packService.CalculatePacksAsOf(c.Request.Context(), req.Quantity, req.AsOfVersion, time.Time{})
+	case req.AsOf != nil:
+		res, err = h.packService.CalculatePacksAsOf(c.Request.Context(), req.Quantity, 0, *req.AsOf)
+	default:
+		res, err = h.packService.CalculatePacks(c.Request.Context(), req.Quantity)
+	}
 	if err != nil {
 		_ = c.Error(err) // Pass through AppError from service/repo
 		return
@@ -85,18 +118,47 @@ func (h *packHTTPHandler) UpdatePackSizes(c *gin.Context) {
 		_ = c.Error(apperror.BadRequestError("Invalid request format", err))
 		return
 	}
+	req.UpdatedBy = requestActor(c, req.UpdatedBy)
 
 	// validate request
 	if err := validateUpdatePackSizesRequest(&req); err != nil {
 		_ = c.Error(apperror.ValidationError(err.Error(), err))
 		return
 	}
+	if err := validateReason(req.Reason); err != nil {
+		_ = c.Error(apperror.ValidationError(err.Error(), err))
+		return
+	}
 
 	//deduplicate pack sizes
 	req.PackSizes = sets.DeduplicateIntSlice(req.PackSizes)
 
 	// call service to update pack sizes
-	res, err := h.packService.UpdatePackSizes(c.Request.Context(), req.PackSizes, req.UpdatedBy)
+	res, err := h.packService.UpdatePackSizes(c.Request.Context(), req.PackSizes, req.UpdatedBy, req.Reason)
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	response.Success(c, http.StatusOK, res)
+}
+
+// PatchPackSizes handles adding or removing individual pack sizes
+func (h *packHTTPHandler) PatchPackSizes(c *gin.Context) {
+	var req model.PatchPackSizesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperror.BadRequestError("Invalid request format", err))
+		return
+	}
+	req.UpdatedBy = requestActor(c, req.UpdatedBy)
+
+	// validate request
+	if err := validatePatchPackSizesRequest(&req); err != nil {
+		_ = c.Error(apperror.ValidationError(err.Error(), err))
+		return
+	}
+
+	// call service to patch pack sizes, it checks the resulting set
+	res, err := h.packService.PatchPackSizes(c.Request.Context(), req.Add, req.Remove, req.UpdatedBy, req.Reason)
 	if err != nil {
 		_ = c.Error(err) // Pass through AppError from service/repo
 		return
@@ -28,6 +28,7 @@ func setupTestRouter() *gin.Engine {
 	router := gin.New()
 	router.Use(middleware.RequestID())
 	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.Authenticate(nil))
 	return router
 }
 
@@ -101,6 +102,74 @@ func TestPackHTTPHandler_CalculatePacks(t *testing.T) {
 				assert.Equal(t, string(apperror.ErrCodeValidation), errorData["code"])
 			},
 		},
+		{
+			name: "calculation against historical version",
+			requestBody: model.PackCalculationRequest{
+				Quantity:    251,
+				AsOfVersion: 3,
+			},
+			mockSetup: func(m *mocks.MockPackService) {
+				m.On("CalculatePacksAsOf", mock.Anything, 251, 3, time.Time{}).
+					Return(&model.PackCalculationResponse{
+						Quantity: 251,
+						Packs:    map[int]int{500: 1},
+						Version:  3,
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+
+				data, ok := response["data"].(map[string]interface{})
+				assert.True(t, ok)
+				assert.Equal(t, float64(3), data["version"])
+			},
+		},
+		{
+			name: "calculation as of timestamp",
+			requestBody: map[string]interface{}{
+				"quantity": 251,
+				"as_of":    "2025-10-01T12:00:00Z",
+			},
+			mockSetup: func(m *mocks.MockPackService) {
+				m.On("CalculatePacksAsOf", mock.Anything, 251, 0, time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)).
+					Return(&model.PackCalculationResponse{
+						Quantity: 251,
+						Packs:    map[int]int{250: 2},
+						Version:  2,
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+
+				data, ok := response["data"].(map[string]interface{})
+				assert.True(t, ok)
+				assert.Equal(t, float64(2), data["version"])
+			},
+		},
+		{
+			name: "validation error - both version and timestamp",
+			requestBody: map[string]interface{}{
+				"quantity":      251,
+				"as_of_version": 3,
+				"as_of":         "2025-10-01T12:00:00Z",
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+
+				errorData, ok := response["error"].(map[string]interface{})
+				assert.True(t, ok)
+				assert.Equal(t, string(apperror.ErrCodeValidation), errorData["code"])
+			},
+		},
 		{
 			name: "service error - not found",
 			requestBody: model.PackCalculationRequest{
@@ -260,9 +329,10 @@ func TestPackHTTPHandler_UpdatePackSizes(t *testing.T) {
 			requestBody: model.UpdatePackSizesRequest{
 				PackSizes: []int{250, 500, 1000},
 				UpdatedBy: "admin",
+				Reason:    "new supplier",
 			},
 			mockSetup: func(m *mocks.MockPackService) {
-				m.On("UpdatePackSizes", mock.Anything, []int{250, 500, 1000}, "admin").
+				m.On("UpdatePackSizes", mock.Anything, []int{250, 500, 1000}, "admin", "new supplier").
 					Return(&model.UpdatePackSizesResponse{
 						PackSizes: []int{250, 500, 1000},
 						Version:   2,
@@ -291,10 +361,11 @@ func TestPackHTTPHandler_UpdatePackSizes(t *testing.T) {
 			requestBody: model.UpdatePackSizesRequest{
 				PackSizes: []int{250, 500, 250, 1000, 500},
 				UpdatedBy: "admin",
+				Reason:    "new supplier",
 			},
 			mockSetup: func(m *mocks.MockPackService) {
 				// After deduplication, should be [250, 500, 1000]
-				m.On("UpdatePackSizes", mock.Anything, []int{250, 500, 1000}, "admin").
+				m.On("UpdatePackSizes", mock.Anything, []int{250, 500, 1000}, "admin", "new supplier").
 					Return(&model.UpdatePackSizesResponse{
 						PackSizes: []int{250, 500, 1000},
 						Version:   2,
@@ -349,6 +420,24 @@ func TestPackHTTPHandler_UpdatePackSizes(t *testing.T) {
 				assert.Equal(t, string(apperror.ErrCodeValidation), errorData["code"])
 			},
 		},
+		{
+			name: "validation error - missing reason",
+			requestBody: model.UpdatePackSizesRequest{
+				PackSizes: []int{250, 500},
+				UpdatedBy: "admin",
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkResponse: func(t *testing.T, w *httptest.ResponseRecorder) {
+				var response map[string]interface{}
+				err := json.Unmarshal(w.Body.Bytes(), &response)
+				assert.NoError(t, err)
+
+				errorData, ok := response["error"].(map[string]interface{})
+				assert.True(t, ok)
+				assert.Equal(t, string(apperror.ErrCodeValidation), errorData["code"])
+				assert.Equal(t, "reason is required", errorData["message"])
+			},
+		},
 		{
 			name: "validation error - zero pack size",
 			requestBody: model.UpdatePackSizesRequest{
@@ -371,9 +460,10 @@ func TestPackHTTPHandler_UpdatePackSizes(t *testing.T) {
 			requestBody: model.UpdatePackSizesRequest{
 				PackSizes: []int{250, 500},
 				UpdatedBy: "admin",
+				Reason:    "new supplier",
 			},
 			mockSetup: func(m *mocks.MockPackService) {
-				m.On("UpdatePackSizes", mock.Anything, []int{250, 500}, "admin").
+				m.On("UpdatePackSizes", mock.Anything, []int{250, 500}, "admin", "new supplier").
 					Return(nil, apperror.InternalError("Database error", errors.New("connection failed")))
 			},
 			expectedStatus: http.StatusInternalServerError,
@@ -416,3 +506,97 @@ func TestPackHTTPHandler_UpdatePackSizes(t *testing.T) {
 		})
 	}
 }
+
+func TestPackHTTPHandler_PatchPackSizes(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		mockSetup      func(*mocks.MockPackService)
+		expectedStatus int
+		expectedCode   apperror.ErrorCode
+	}{
+		{
+			name: "successful patch",
+			requestBody: model.PatchPackSizesRequest{
+				Add:       []int{750},
+				Remove:    []int{250},
+				UpdatedBy: "admin",
+				Reason:    "replace smallest size",
+			},
+			mockSetup: func(m *mocks.MockPackService) {
+				m.On("PatchPackSizes", mock.Anything, []int{750}, []int{250}, "admin", "replace smallest size").
+					Return(&model.UpdatePackSizesResponse{
+						PackSizes: []int{500, 750, 1000},
+						Version:   3,
+						UpdatedBy: "admin",
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "validation error - nothing to change",
+			requestBody:    model.PatchPackSizesRequest{UpdatedBy: "admin"},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+		{
+			name: "validation error - size added and removed",
+			requestBody: model.PatchPackSizesRequest{
+				Add:    []int{750},
+				Remove: []int{750},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+		{
+			name: "validation error - missing reason",
+			requestBody: model.PatchPackSizesRequest{
+				Add: []int{750},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+		{
+			name: "validation error - resulting set rejected by service",
+			requestBody: model.PatchPackSizesRequest{
+				Remove: []int{250},
+				Reason: "drop smallest size",
+			},
+			mockSetup: func(m *mocks.MockPackService) {
+				m.On("PatchPackSizes", mock.Anything, []int(nil), []int{250}, "", "drop smallest size").
+					Return(nil, apperror.ValidationError("pack_sizes cannot be empty", nil))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			mockService := new(mocks.MockPackService)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockService)
+			}
+			handler := NewPackHTTPHandler(mockService)
+
+			// create request
+			bodyBytes, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/pack-sizes", bytes.NewBuffer(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+
+			// create router with error handler middleware and execute
+			w := httptest.NewRecorder()
+			router := setupTestRouter()
+			router.PATCH("/api/v1/pack-sizes", handler.PatchPackSizes)
+			router.ServeHTTP(w, req)
+
+			// assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, w, tt.expectedCode)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
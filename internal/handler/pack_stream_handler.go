@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/middleware"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/service"
+	"github.com/nsaltun/packman/internal/stream"
+)
+
+// packSizesEvent is the name of the server-sent event carrying a configuration version
+const packSizesEvent = "pack_sizes"
+
+// PackStreamHTTPHandler defines the interface for the configuration change stream
+type PackStreamHTTPHandler interface {
+	registerRoutes(r *gin.Engine)
+	StreamPackSizes(c *gin.Context)
+}
+
+// packStreamHTTPHandler is the concrete implementation of PackStreamHTTPHandler
+type packStreamHTTPHandler struct {
+	packService service.PackService
+	hub         *stream.Hub
+	cfg         config.StreamConfig
+}
+
+// NewPackStreamHTTPHandler creates a handler that streams the configurations signalled by hub
+func NewPackStreamHTTPHandler(packService service.PackService, hub *stream.Hub, cfg config.StreamConfig) PackStreamHTTPHandler {
+	return &packStreamHTTPHandler{
+		packService: packService,
+		hub:         hub,
+		cfg:         cfg,
+	}
+}
+
+// registerRoutes registers all routes for the HTTP handler
+func (h *packStreamHTTPHandler) registerRoutes(r *gin.Engine) {
+	r.GET("/api/v1/pack-sizes/stream", middleware.RequireScope(model.ScopeRead), h.StreamPackSizes)
+}
+
+// StreamPackSizes handles the server-sent events stream of the active configuration
+// The current configuration is sent on connect, unless the Last-Event-ID header shows the client has it,
+// and every newer version after that. The event ID is the configuration version
+func (h *packStreamHTTPHandler) StreamPackSizes(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// subscribe before reading the configuration so no change in between is missed
+	changes, unsubscribe := h.hub.Subscribe()
+	defer unsubscribe()
+
+	current, err := h.packService.GetPackSizes(ctx)
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+
+	// the stream outlives the server write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		slog.WarnContext(ctx, "failed to clear the write deadline of the stream", slog.String("error", err.Error()))
+	}
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // disable proxy buffering
+
+	lastVersion, _ := strconv.Atoi(c.GetHeader("Last-Event-ID"))
+	send := func(cfg *model.GetPackSizesResponse) {
+		if cfg.Version <= lastVersion {
+			return
+		}
+		c.Render(-1, sse.Event{
+			Id:    strconv.Itoa(cfg.Version),
+			Event: packSizesEvent,
+			Data:  cfg,
+		})
+		lastVersion = cfg.Version
+	}
+	// refresh sends the current configuration if it is newer than the last one sent
+	refresh := func() {
+		cfg, err := h.packService.GetPackSizes(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.WarnContext(ctx, "failed to read pack sizes for stream", slog.String("error", err.Error()))
+			}
+			return
+		}
+		send(cfg)
+	}
+
+	heartbeat := time.NewTicker(h.cfg.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	// send the headers right away, the client may already have the current version
+	c.Header("Content-Type", "text/event-stream")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	send(current)
+	c.Writer.Flush()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-h.hub.Done():
+			return false
+		case <-changes:
+			refresh()
+		case <-heartbeat.C:
+			// a missed notification is caught up at the latest with the next heartbeat
+			refresh()
+			_, _ = io.WriteString(w, ": heartbeat\n\n")
+		}
+		return true
+	})
+}
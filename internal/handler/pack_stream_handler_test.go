@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// readEvent reads the next server-sent event, skipping comments
+func readEvent(t *testing.T, r *bufio.Reader) map[string]string {
+	t.Helper()
+	event := make(map[string]string)
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			if len(event) > 0 {
+				return event
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		event[field] = value
+	}
+}
+
+func startStream(t *testing.T, mockService *mocks.MockPackService, hub *stream.Hub, lastEventID string) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	handler := NewPackStreamHTTPHandler(mockService, hub, config.StreamConfig{HeartbeatInterval: time.Hour})
+	router := setupTestRouter()
+	handler.registerRoutes(router)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/pack-sizes/stream", nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp, bufio.NewReader(resp.Body)
+}
+
+func TestPackStreamHTTPHandler_StreamPackSizes(t *testing.T) {
+	mockService := new(mocks.MockPackService)
+	mockService.On("GetPackSizes", mock.Anything).
+		Return(&model.GetPackSizesResponse{PackSizes: []int{250, 500}, Version: 4}, nil).Once()
+	hub := stream.NewHub()
+
+	resp, body := startStream(t, mockService, hub, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// the current configuration is sent on connect
+	event := readEvent(t, body)
+	assert.Equal(t, "4", event["id"])
+	assert.Equal(t, packSizesEvent, event["event"])
+	assert.JSONEq(t, `{"pack_sizes":[250,500],"version":4,"updated_at":"0001-01-01T00:00:00Z"}`, event["data"])
+
+	// a signal without a new version sends nothing, the next version is sent
+	unchanged := make(chan struct{})
+	mockService.On("GetPackSizes", mock.Anything).
+		Return(&model.GetPackSizesResponse{PackSizes: []int{250, 500}, Version: 4}, nil).
+		Run(func(mock.Arguments) { close(unchanged) }).Once()
+	hub.Notify()
+	select {
+	case <-unchanged:
+	case <-time.After(time.Second):
+		t.Fatal("the stream did not read the configuration after the signal")
+	}
+	mockService.On("GetPackSizes", mock.Anything).
+		Return(&model.GetPackSizesResponse{PackSizes: []int{250, 750}, Version: 5}, nil).Once()
+	hub.Notify()
+
+	event = readEvent(t, body)
+	assert.Equal(t, "5", event["id"])
+	assert.Contains(t, event["data"], `"pack_sizes":[250,750]`)
+
+	// closing the hub ends the stream so the server can shut down
+	require.NoError(t, hub.Close(context.Background()))
+	_, err := body.ReadString('\n')
+	assert.Error(t, err)
+	assert.Eventually(t, func() bool { return hub.Subscribers() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestPackStreamHTTPHandler_ResumesAfterLastEventID(t *testing.T) {
+	mockService := new(mocks.MockPackService)
+	mockService.On("GetPackSizes", mock.Anything).
+		Return(&model.GetPackSizesResponse{PackSizes: []int{250, 500}, Version: 4}, nil).Once()
+	mockService.On("GetPackSizes", mock.Anything).
+		Return(&model.GetPackSizesResponse{PackSizes: []int{250, 750}, Version: 5}, nil).Once()
+	hub := stream.NewHub()
+
+	// the client already has version 4, so the first event is version 5
+	_, body := startStream(t, mockService, hub, "4")
+	assert.Eventually(t, func() bool { return hub.Subscribers() == 1 }, time.Second, 5*time.Millisecond)
+	hub.Notify()
+
+	event := readEvent(t, body)
+	assert.Equal(t, "5", event["id"])
+	_ = hub.Close(context.Background())
+}
+
+func TestPackStreamHTTPHandler_ErrorBeforeStreaming(t *testing.T) {
+	mockService := new(mocks.MockPackService)
+	mockService.On("GetPackSizes", mock.Anything).Return(nil, apperror.NotFoundError("Pack configuration not found", nil))
+	hub := stream.NewHub()
+
+	resp, _ := startStream(t, mockService, hub, "")
+
+	// errors before the stream started use the regular error response
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, 0, hub.Subscribers())
+}
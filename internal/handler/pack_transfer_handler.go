@@ -0,0 +1,300 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/response"
+	"github.com/nsaltun/packman/pkg/sets"
+	"gopkg.in/yaml.v3"
+)
+
+// transferFormat is a file format supported for configuration import and export
+type transferFormat string
+
+const (
+	formatJSON transferFormat = "json"
+	formatYAML transferFormat = "yaml"
+	formatCSV  transferFormat = "csv"
+
+	// maxImportBytes limits the size of an imported configuration file
+	maxImportBytes = 1 << 20
+)
+
+// csvExportHeader is the header row of a CSV export, one row per configuration version
+// updated_at is when the version became active and archived_at when it was replaced, each empty when unknown or not
+// applicable
+var csvExportHeader = []string{"version", "status", "updated_at", "archived_at", "updated_by", "approved_by", "pack_sizes"}
+
+// ExportConfiguration handles exporting the active configuration and its history as a file
+func (h *packHTTPHandler) ExportConfiguration(c *gin.Context) {
+	format, err := resolveTransferFormat(c.Query("format"), "")
+	if err != nil {
+		_ = c.Error(apperror.ValidationError(err.Error(), err))
+		return
+	}
+
+	export, err := h.packService.ExportConfiguration(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+
+	body, contentType, err := encodeExport(format, export)
+	if err != nil {
+		_ = c.Error(apperror.InternalError("Failed to encode export", err))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="pack-configuration-v%d.%s"`, export.Version, format))
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// ImportConfiguration handles importing a configuration file as a new version
+// The format comes from the format query parameter or the Content-Type header, dry_run=true only reports changes
+func (h *packHTTPHandler) ImportConfiguration(c *gin.Context) {
+	format, err := resolveTransferFormat(c.Query("format"), c.ContentType())
+	if err != nil {
+		_ = c.Error(apperror.ValidationError(err.Error(), err))
+		return
+	}
+
+	dryRun := false
+	if raw := c.Query("dry_run"); raw != "" {
+		dryRun, err = strconv.ParseBool(raw)
+		if err != nil {
+			_ = c.Error(apperror.ValidationError("dry_run must be a boolean", err))
+			return
+		}
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes))
+	if err != nil {
+		_ = c.Error(apperror.BadRequestError("Failed to read import file", err))
+		return
+	}
+
+	imported, err := decodeImport(format, body)
+	if err != nil {
+		_ = c.Error(apperror.BadRequestError("Invalid import file", err).WithDetails("format", format))
+		return
+	}
+
+	// updated_by and reason query parameters take precedence over the file, e.g. to record the CI job,
+	// the authenticated key takes precedence over both
+	req := model.UpdatePackSizesRequest{
+		PackSizes: imported.PackSizes,
+		UpdatedBy: imported.UpdatedBy,
+		Reason:    imported.Reason,
+	}
+	if updatedBy := c.Query("updated_by"); updatedBy != "" {
+		req.UpdatedBy = updatedBy
+	}
+	if reason := c.Query("reason"); reason != "" {
+		req.Reason = reason
+	}
+	req.UpdatedBy = requestActor(c, req.UpdatedBy)
+
+	// imported files get the same validation as a regular update, a dry run changes nothing and needs no reason
+	if err := validateUpdatePackSizesRequest(&req); err != nil {
+		_ = c.Error(apperror.ValidationError(err.Error(), err))
+		return
+	}
+	if !dryRun {
+		if err := validateReason(req.Reason); err != nil {
+			_ = c.Error(apperror.ValidationError(err.Error(), err))
+			return
+		}
+	}
+
+	//deduplicate pack sizes
+	req.PackSizes = sets.DeduplicateIntSlice(req.PackSizes)
+
+	res, err := h.packService.ImportPackSizes(c.Request.Context(), req.PackSizes, req.UpdatedBy, req.Reason, dryRun)
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	response.Success(c, http.StatusOK, res)
+}
+
+// resolveTransferFormat picks the file format from an explicit format name or a content type, defaulting to JSON
+func resolveTransferFormat(name string, contentType string) (transferFormat, error) {
+	switch strings.ToLower(name) {
+	case "json":
+		return formatJSON, nil
+	case "yaml", "yml":
+		return formatYAML, nil
+	case "csv":
+		return formatCSV, nil
+	case "":
+	default:
+		return "", fmt.Errorf("format must be one of json, yaml, csv")
+	}
+
+	switch contentType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return formatYAML, nil
+	case "text/csv":
+		return formatCSV, nil
+	default:
+		return formatJSON, nil
+	}
+}
+
+// encodeExport encodes an export in the given format and returns the body with its content type
+func encodeExport(format transferFormat, export *model.PackConfigurationExport) ([]byte, string, error) {
+	switch format {
+	case formatYAML:
+		body, err := yaml.Marshal(export)
+		return body, "application/yaml", err
+	case formatCSV:
+		body, err := encodeExportCSV(export)
+		return body, "text/csv", err
+	default:
+		body, err := json.MarshalIndent(export, "", "  ")
+		return body, "application/json", err
+	}
+}
+
+// encodeExportCSV writes one row per version, the active one first followed by history from newest to oldest
+// Pack sizes are space separated within their column
+func encodeExportCSV(export *model.PackConfigurationExport) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	rows := [][]string{
+		csvExportHeader,
+		{
+			strconv.Itoa(export.Version),
+			"active",
+			export.UpdatedAt.Format(time.RFC3339),
+			"",
+			export.UpdatedBy,
+			export.ApprovedBy,
+			joinPackSizes(export.PackSizes),
+		},
+	}
+	for i := len(export.History) - 1; i >= 0; i-- {
+		v := export.History[i]
+		validFrom := ""
+		if v.ValidFrom != nil {
+			validFrom = v.ValidFrom.Format(time.RFC3339)
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(v.Version),
+			"archived",
+			validFrom,
+			v.ArchivedAt.Format(time.RFC3339),
+			v.UpdatedBy,
+			v.ApprovedBy,
+			joinPackSizes(v.PackSizes),
+		})
+	}
+
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeImport parses an imported configuration file
+func decodeImport(format transferFormat, body []byte) (*model.PackConfigurationImport, error) {
+	var imported model.PackConfigurationImport
+	switch format {
+	case formatYAML:
+		if err := yaml.Unmarshal(body, &imported); err != nil {
+			return nil, err
+		}
+	case formatCSV:
+		sizes, err := decodeImportCSV(body)
+		if err != nil {
+			return nil, err
+		}
+		imported.PackSizes = sizes
+	default:
+		if err := json.Unmarshal(body, &imported); err != nil {
+			return nil, err
+		}
+	}
+	return &imported, nil
+}
+
+// decodeImportCSV reads pack sizes from either a CSV export (the active row's pack_sizes column),
+// a file with a pack_size header and one size per row, or rows of plain sizes without a header
+func decodeImportCSV(body []byte) ([]int, error) {
+	r := csv.NewReader(bytes.NewReader(body))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	header := records[0]
+	if idx := slices.Index(header, "pack_sizes"); idx >= 0 {
+		statusIdx := slices.Index(header, "status")
+		for _, record := range records[1:] {
+			if statusIdx >= 0 && (statusIdx >= len(record) || record[statusIdx] != "active") {
+				continue
+			}
+			if idx >= len(record) {
+				return nil, errors.New("pack_sizes column is missing")
+			}
+			return parsePackSizes(strings.Fields(record[idx]))
+		}
+		return nil, errors.New("no active configuration row found")
+	}
+
+	if slices.Contains(header, "pack_size") {
+		records = records[1:]
+	}
+
+	var cells []string
+	for _, record := range records {
+		for _, cell := range record {
+			if cell = strings.TrimSpace(cell); cell != "" {
+				cells = append(cells, cell)
+			}
+		}
+	}
+	return parsePackSizes(cells)
+}
+
+// parsePackSizes converts textual pack sizes to integers
+func parsePackSizes(values []string) ([]int, error) {
+	sizes := make([]int, 0, len(values))
+	for _, value := range values {
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pack size %q", value)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// joinPackSizes formats pack sizes as a space separated list
+func joinPackSizes(sizes []int) string {
+	parts := make([]string, len(sizes))
+	for i, size := range sizes {
+		parts[i] = strconv.Itoa(size)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDecodeImport(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    transferFormat
+		body      string
+		expected  *model.PackConfigurationImport
+		expectErr bool
+	}{
+		{
+			name:     "json",
+			format:   formatJSON,
+			body:     `{"pack_sizes": [250, 500], "updated_by": "ci"}`,
+			expected: &model.PackConfigurationImport{PackSizes: []int{250, 500}, UpdatedBy: "ci"},
+		},
+		{
+			name:     "yaml",
+			format:   formatYAML,
+			body:     "pack_sizes:\n  - 250\n  - 500\nupdated_by: ci\n",
+			expected: &model.PackConfigurationImport{PackSizes: []int{250, 500}, UpdatedBy: "ci"},
+		},
+		{
+			name:     "csv with header",
+			format:   formatCSV,
+			body:     "pack_size\n250\n500\n",
+			expected: &model.PackConfigurationImport{PackSizes: []int{250, 500}},
+		},
+		{
+			name:     "csv without header",
+			format:   formatCSV,
+			body:     "250, 500, 1000\n",
+			expected: &model.PackConfigurationImport{PackSizes: []int{250, 500, 1000}},
+		},
+		{
+			name:     "csv export uses active row",
+			format:   formatCSV,
+			body:     "version,status,updated_at,updated_by,approved_by,pack_sizes\n3,active,2025-11-20T09:00:00Z,alice,bob,250 750\n2,archived,2025-11-20T09:00:00Z,alice,,250 500\n",
+			expected: &model.PackConfigurationImport{PackSizes: []int{250, 750}},
+		},
+		{
+			name:      "csv with invalid size",
+			format:    formatCSV,
+			body:      "pack_size\n250\nlarge\n",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := decodeImport(tt.format, []byte(tt.body))
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, res)
+		})
+	}
+}
+
+func TestPackHTTPHandler_ExportConfiguration(t *testing.T) {
+	validFrom := time.Date(2025, 11, 18, 10, 0, 0, 0, time.UTC)
+	export := &model.PackConfigurationExport{
+		Version:   3,
+		PackSizes: []int{250, 750},
+		UpdatedAt: time.Date(2025, 11, 20, 9, 0, 0, 0, time.UTC),
+		UpdatedBy: "alice",
+		History: []model.PackConfigurationVersion{
+			{Version: 1, PackSizes: []int{250}, ArchivedAt: time.Date(2025, 11, 18, 10, 0, 0, 0, time.UTC)},
+			{Version: 2, PackSizes: []int{250, 500}, ValidFrom: &validFrom, ArchivedAt: time.Date(2025, 11, 20, 9, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	t.Run("csv export round trips through import", func(t *testing.T) {
+		mockService := new(mocks.MockPackService)
+		mockService.On("ExportConfiguration", mock.Anything).Return(export, nil)
+		handler := NewPackHTTPHandler(mockService)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/pack-sizes/export?format=csv", nil)
+		w := httptest.NewRecorder()
+		router := setupTestRouter()
+		router.GET("/api/v1/pack-sizes/export", handler.ExportConfiguration)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "pack-configuration-v3.csv")
+
+		// updated_at is when a version became active, not when it was archived
+		assert.Equal(t, "version,status,updated_at,archived_at,updated_by,approved_by,pack_sizes\n"+
+			"3,active,2025-11-20T09:00:00Z,,alice,,250 750\n"+
+			"2,archived,2025-11-18T10:00:00Z,2025-11-20T09:00:00Z,,,250 500\n"+
+			"1,archived,,2025-11-18T10:00:00Z,,,250\n", w.Body.String())
+
+		imported, err := decodeImport(formatCSV, w.Body.Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, []int{250, 750}, imported.PackSizes)
+	})
+	t.Run("unknown format", func(t *testing.T) {
+		handler := NewPackHTTPHandler(new(mocks.MockPackService))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/pack-sizes/export?format=xml", nil)
+		w := httptest.NewRecorder()
+		router := setupTestRouter()
+		router.GET("/api/v1/pack-sizes/export", handler.ExportConfiguration)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assertErrorCode(t, w, apperror.ErrCodeValidation)
+	})
+}
+
+func TestPackHTTPHandler_ImportConfiguration(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		contentType    string
+		body           string
+		mockSetup      func(*mocks.MockPackService)
+		expectedStatus int
+		expectedCode   apperror.ErrorCode
+	}{
+		{
+			name:        "yaml dry run detected from content type",
+			url:         "/api/v1/pack-sizes/import?dry_run=true",
+			contentType: "application/yaml",
+			body:        "pack_sizes: [750, 250, 250]\nupdated_by: ci\n",
+			mockSetup: func(m *mocks.MockPackService) {
+				m.On("ImportPackSizes", mock.Anything, []int{750, 250}, "ci", "", true).
+					Return(&model.ImportPackSizesResponse{DryRun: true, Changed: true, Added: []int{750}, Removed: []int{}, CurrentVersion: 3}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "updated_by and reason query parameters override file",
+			url:         "/api/v1/pack-sizes/import?format=csv&updated_by=pipeline&reason=nightly+sync",
+			contentType: "text/plain",
+			body:        "pack_size\n250\n500\n",
+			mockSetup: func(m *mocks.MockPackService) {
+				m.On("ImportPackSizes", mock.Anything, []int{250, 500}, "pipeline", "nightly sync", false).
+					Return(&model.ImportPackSizesResponse{Changed: false, Added: []int{}, Removed: []int{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "imported sizes are validated",
+			url:            "/api/v1/pack-sizes/import",
+			contentType:    "application/json",
+			body:           `{"pack_sizes": [250, -1]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+		{
+			name:           "reason is required when applying",
+			url:            "/api/v1/pack-sizes/import",
+			contentType:    "application/json",
+			body:           `{"pack_sizes": [250, 500]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+		{
+			name:           "malformed file",
+			url:            "/api/v1/pack-sizes/import?format=json",
+			contentType:    "application/json",
+			body:           `{"pack_sizes": `,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			mockService := new(mocks.MockPackService)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockService)
+			}
+			handler := NewPackHTTPHandler(mockService)
+
+			// create request
+			req := httptest.NewRequest(http.MethodPost, tt.url, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			// create router with error handler middleware and execute
+			w := httptest.NewRecorder()
+			router := setupTestRouter()
+			router.POST("/api/v1/pack-sizes/import", handler.ImportConfiguration)
+			router.ServeHTTP(w, req)
+
+			// assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, w, tt.expectedCode)
+			} else {
+				var response map[string]interface{}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.True(t, strings.Contains(w.Body.String(), `"changed"`))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
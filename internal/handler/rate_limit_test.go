@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/middleware"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/ratelimit"
+	"github.com/nsaltun/packman/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupRateLimitRouter(auth middleware.Authenticator, packService *mocks.MockPackService) *gin.Engine {
+	limiter := ratelimit.NewLimiter(repository.NewMemoryRateLimitRepo(), config.RateLimitConfig{
+		Default: config.RateLimit{Requests: 2, Period: time.Minute},
+		Address: config.RateLimit{Requests: 4, Period: time.Minute},
+	})
+
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.LimitAddresses(limiter))
+	router.Use(middleware.Authenticate(auth))
+	router.Use(middleware.RateLimit(limiter))
+
+	NewPackHTTPHandler(packService).registerRoutes(router)
+	router.GET("/health", NewHealthHandler(nil).Check)
+	return router
+}
+
+func TestRateLimit(t *testing.T) {
+	packService := new(mocks.MockPackService)
+	packService.On("GetPackSizes", mock.Anything).Return(&model.GetPackSizesResponse{PackSizes: []int{250}}, nil)
+
+	auth := new(mocks.MockAPIKeyService)
+	auth.On("Authenticate", mock.Anything, "pmk_dashboard").
+		Return(&model.Principal{Name: "dashboard", Scopes: []model.Scope{model.ScopeRead}}, nil)
+	auth.On("Authenticate", mock.Anything, mock.Anything).Return(nil, apperror.UnauthorizedError("Invalid API key", nil))
+
+	get := func(router *gin.Engine, url, ip, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.RemoteAddr = ip + ":1234"
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("requests over the limit are rejected", func(t *testing.T) {
+		router := setupRateLimitRouter(auth, packService)
+
+		w := get(router, "/api/v1/pack-sizes", "10.0.0.1", "pmk_dashboard")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "30", w.Header().Get("RateLimit-Reset"))
+		assert.Equal(t, "2;w=60", w.Header().Get("RateLimit-Policy"))
+		assert.Empty(t, w.Header().Get("Retry-After"))
+
+		// the key is limited across addresses
+		w = get(router, "/api/v1/pack-sizes", "10.0.0.2", "pmk_dashboard")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+		w = get(router, "/api/v1/pack-sizes", "10.0.0.3", "pmk_dashboard")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "30", w.Header().Get("Retry-After"))
+		assertErrorCode(t, w, apperror.ErrCodeRateLimited)
+
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		details := response["error"].(map[string]interface{})["details"].(map[string]interface{})
+		assert.Equal(t, float64(30), details["retry_after_seconds"])
+	})
+	t.Run("anonymous callers are limited per address", func(t *testing.T) {
+		router := setupRateLimitRouter(nil, packService)
+
+		for range 2 {
+			assert.Equal(t, http.StatusOK, get(router, "/api/v1/pack-sizes", "10.0.0.1", "").Code)
+		}
+		assert.Equal(t, http.StatusTooManyRequests, get(router, "/api/v1/pack-sizes", "10.0.0.1", "").Code)
+		assert.Equal(t, http.StatusOK, get(router, "/api/v1/pack-sizes", "10.0.0.2", "").Code)
+	})
+	t.Run("guessing credentials is limited per address", func(t *testing.T) {
+		router := setupRateLimitRouter(auth, packService)
+
+		for i := range 4 {
+			w := get(router, "/api/v1/pack-sizes", "10.0.0.1", fmt.Sprintf("pmk_guess_%d", i))
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		}
+		w := get(router, "/api/v1/pack-sizes", "10.0.0.1", "pmk_guess_4")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "15", w.Header().Get("Retry-After"))
+		assertErrorCode(t, w, apperror.ErrCodeRateLimited)
+		// rejected before the key is looked up
+		auth.AssertNotCalled(t, "Authenticate", mock.Anything, "pmk_guess_4")
+
+		// other addresses are not affected
+		assert.Equal(t, http.StatusOK, get(router, "/api/v1/pack-sizes", "10.0.0.2", "pmk_dashboard").Code)
+	})
+	t.Run("health checks are not limited", func(t *testing.T) {
+		router := setupRateLimitRouter(nil, packService)
+
+		for range 5 {
+			w := get(router, "/health", "10.0.0.1", "")
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+		}
+	})
+}
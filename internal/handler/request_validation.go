@@ -2,14 +2,25 @@ package handler
 
 import (
 	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
 
 	"github.com/nsaltun/packman/internal/model"
 )
 
 const (
-	maxQuantityLimit   = 10000000
-	maxPackSizeLimit   = 1000000
-	maxUpdatedByLength = 100
+	maxQuantityLimit       = 10000000
+	maxPackSizeLimit       = 1000000
+	maxUpdatedByLength     = 100
+	maxReviewCommentLength = 1000
+	maxReasonLength        = 500
+	maxWebhookURLLength    = 2048
+	maxDescriptionLength   = 500
+	minWebhookSecretLength = 16
+	maxWebhookSecretLength = 256
+	maxAPIKeyNameLength    = 100
 )
 
 func validateCalculatePacksRequest(req *model.PackCalculationRequest) error {
@@ -27,6 +38,14 @@ func validateCalculatePacksRequest(req *model.PackCalculationRequest) error {
 		return fmt.Errorf("quantity must be less than or equal to %d", maxQuantityLimit)
 	}
 
+	// validate historical configuration selection
+	if req.AsOfVersion < 0 {
+		return fmt.Errorf("as_of_version must be greater than zero")
+	}
+	if req.AsOfVersion > 0 && req.AsOf != nil {
+		return fmt.Errorf("only one of as_of_version and as_of can be set")
+	}
+
 	return nil
 }
 
@@ -36,22 +55,222 @@ func validateUpdatePackSizesRequest(req *model.UpdatePackSizesRequest) error {
 		return fmt.Errorf("request cannot be nil")
 	}
 	// validate pack sizes
-	if len(req.PackSizes) == 0 {
+	if err := validatePackSizes(req.PackSizes); err != nil {
+		return err
+	}
+	// validate updated_by
+	if len(req.UpdatedBy) > maxUpdatedByLength {
+		return fmt.Errorf("updated_by must be less than or equal to %d characters", maxUpdatedByLength)
+	}
+
+	return nil
+}
+
+// validateReason validates the mandatory reason recorded in the audit log for configuration changes
+func validateReason(reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("reason is required")
+	}
+	if len(reason) > maxReasonLength {
+		return fmt.Errorf("reason must be less than or equal to %d characters", maxReasonLength)
+	}
+
+	return nil
+}
+
+// validatePackSizes validates a complete set of pack sizes
+func validatePackSizes(sizes []int) error {
+	if len(sizes) == 0 {
 		return fmt.Errorf("pack_sizes cannot be empty")
 	}
 	// validate each pack size
-	for _, size := range req.PackSizes {
-		if size <= 0 {
-			return fmt.Errorf("pack sizes must be greater than zero")
+	for _, size := range sizes {
+		if err := validatePackSize(size); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validatePackSize validates a single pack size
+func validatePackSize(size int) error {
+	if size <= 0 {
+		return fmt.Errorf("pack sizes must be greater than zero")
+	}
+	if size > maxPackSizeLimit {
+		return fmt.Errorf("pack sizes must be less than or equal to %d", maxPackSizeLimit)
+	}
+
+	return nil
+}
+
+func validatePatchPackSizesRequest(req *model.PatchPackSizesRequest) error {
+	// validate request
+	if req == nil {
+		return fmt.Errorf("request cannot be nil")
+	}
+	if len(req.Add) == 0 && len(req.Remove) == 0 {
+		return fmt.Errorf("at least one of add or remove is required")
+	}
+	// validate added pack sizes, the service checks the resulting set
+	for _, size := range req.Add {
+		if err := validatePackSize(size); err != nil {
+			return err
 		}
-		if size > maxPackSizeLimit {
-			return fmt.Errorf("pack sizes must be less than or equal to %d", maxPackSizeLimit)
+	}
+	// a size cannot be added and removed in the same request
+	for _, size := range req.Remove {
+		if slices.Contains(req.Add, size) {
+			return fmt.Errorf("pack size %d cannot be both added and removed", size)
 		}
 	}
 	// validate updated_by
 	if len(req.UpdatedBy) > maxUpdatedByLength {
 		return fmt.Errorf("updated_by must be less than or equal to %d characters", maxUpdatedByLength)
 	}
+	// validate reason
+	if err := validateReason(req.Reason); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func validateCreateDraftRequest(req *model.UpdatePackSizesRequest) error {
+	if err := validateUpdatePackSizesRequest(req); err != nil {
+		return err
+	}
+	// the author is required to enforce the two-person rule on approval
+	if req.UpdatedBy == "" {
+		return fmt.Errorf("updated_by is required for drafts")
+	}
+	// validate reason
+	if err := validateReason(req.Reason); err != nil {
+		return err
+	}
 
 	return nil
 }
+
+func validateReviewDraftRequest(req *model.ReviewDraftRequest) error {
+	if req == nil {
+		return fmt.Errorf("request cannot be nil")
+	}
+	// validate reviewed_by
+	if req.ReviewedBy == "" {
+		return fmt.Errorf("reviewed_by is required")
+	}
+	if len(req.ReviewedBy) > maxUpdatedByLength {
+		return fmt.Errorf("reviewed_by must be less than or equal to %d characters", maxUpdatedByLength)
+	}
+	// validate comment
+	if len(req.Comment) > maxReviewCommentLength {
+		return fmt.Errorf("comment must be less than or equal to %d characters", maxReviewCommentLength)
+	}
+
+	return nil
+}
+
+func validateCreateWebhookRequest(req *model.CreateWebhookRequest) error {
+	if req == nil {
+		return fmt.Errorf("request cannot be nil")
+	}
+	if err := validateWebhookURL(req.URL); err != nil {
+		return err
+	}
+	if len(req.Description) > maxDescriptionLength {
+		return fmt.Errorf("description must be less than or equal to %d characters", maxDescriptionLength)
+	}
+	// a secret is generated when none is given
+	if req.Secret != "" && (len(req.Secret) < minWebhookSecretLength || len(req.Secret) > maxWebhookSecretLength) {
+		return fmt.Errorf("secret must be between %d and %d characters", minWebhookSecretLength, maxWebhookSecretLength)
+	}
+	// validate created_by
+	if len(req.CreatedBy) > maxUpdatedByLength {
+		return fmt.Errorf("created_by must be less than or equal to %d characters", maxUpdatedByLength)
+	}
+
+	return nil
+}
+
+func validateUpdateWebhookRequest(req *model.UpdateWebhookRequest) error {
+	if req == nil {
+		return fmt.Errorf("request cannot be nil")
+	}
+	if req.URL == nil && req.Description == nil && req.Active == nil && !req.RotateSecret {
+		return fmt.Errorf("at least one of url, description, active or rotate_secret is required")
+	}
+	if req.URL != nil {
+		if err := validateWebhookURL(*req.URL); err != nil {
+			return err
+		}
+	}
+	if req.Description != nil && len(*req.Description) > maxDescriptionLength {
+		return fmt.Errorf("description must be less than or equal to %d characters", maxDescriptionLength)
+	}
+
+	return nil
+}
+
+// validateWebhookURL requires an absolute http or https URL
+func validateWebhookURL(raw string) error {
+	if raw == "" {
+		return fmt.Errorf("url is required")
+	}
+	if len(raw) > maxWebhookURLLength {
+		return fmt.Errorf("url must be less than or equal to %d characters", maxWebhookURLLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+
+	return nil
+}
+
+func validateCreateAPIKeyRequest(req *model.CreateAPIKeyRequest) error {
+	if req == nil {
+		return fmt.Errorf("request cannot be nil")
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(req.Name) > maxAPIKeyNameLength {
+		return fmt.Errorf("name must be less than or equal to %d characters", maxAPIKeyNameLength)
+	}
+	if strings.EqualFold(strings.TrimSpace(req.Name), model.BootstrapPrincipal) {
+		return fmt.Errorf("name %q is reserved for the bootstrap key", model.BootstrapPrincipal)
+	}
+	if len(req.Scopes) == 0 {
+		return fmt.Errorf("scopes cannot be empty")
+	}
+	for _, scope := range req.Scopes {
+		switch scope {
+		case model.ScopeCalculate, model.ScopeRead, model.ScopeAdmin:
+		default:
+			return fmt.Errorf("scopes must be one of calculate, read, admin")
+		}
+	}
+
+	return nil
+}
+
+// logLevels are the levels that can be set at runtime
+var logLevels = map[string]slog.Level{
+	"debug": slog.LevelDebug,
+	"info":  slog.LevelInfo,
+	"warn":  slog.LevelWarn,
+	"error": slog.LevelError,
+}
+
+func validateLogLevelRequest(req *model.LogLevel) (slog.Level, error) {
+	if req == nil {
+		return 0, fmt.Errorf("request cannot be nil")
+	}
+	level, ok := logLevels[strings.ToLower(strings.TrimSpace(req.Level))]
+	if !ok {
+		return 0, fmt.Errorf("level must be one of debug, info, warn, error")
+	}
+	return level, nil
+}
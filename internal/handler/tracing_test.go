@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/internal/middleware"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/tracing"
+	packmanv1 "github.com/nsaltun/packman/proto/packman/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// traceParent is a W3C traceparent header of a sampled trace started by a caller
+const (
+	traceParent   = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	callerTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	callerSpanID  = "00f067aa0ba902b7"
+)
+
+// recordSpans installs a tracer provider recording every span for the test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+// spanAttributes returns the attributes of span by key
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, attr := range span.Attributes() {
+		attrs[attr.Key] = attr.Value
+	}
+	return attrs
+}
+
+func TestTracing(t *testing.T) {
+	recorder := recordSpans(t)
+
+	packService := new(mocks.MockPackService)
+	packService.On("CalculatePacks", mock.Anything, 501).
+		Return(&model.PackCalculationResponse{Quantity: 501, Packs: map[int]int{500: 1, 250: 1}}, nil)
+	packService.On("GetPackSizes", mock.Anything).Return(nil, errors.New("connection refused"))
+
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Tracing())
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.Authenticate(nil))
+	NewPackHTTPHandler(tracing.NewPackService(packService)).registerRoutes(router)
+
+	t.Run("continues the trace of the caller", func(t *testing.T) {
+		recorder.Reset()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/calculate", bytes.NewBufferString(`{"quantity": 501}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("traceparent", traceParent)
+		req.Header.Set("X-Request-ID", "req-123")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		serviceSpan, serverSpan := spans[0], spans[1]
+
+		assert.Equal(t, "POST /api/v1/calculate", serverSpan.Name())
+		assert.Equal(t, trace.SpanKindServer, serverSpan.SpanKind())
+		assert.Equal(t, callerTraceID, serverSpan.SpanContext().TraceID().String())
+		assert.Equal(t, callerSpanID, serverSpan.Parent().SpanID().String())
+		attrs := spanAttributes(serverSpan)
+		assert.Equal(t, "req-123", attrs["request.id"].AsString())
+		assert.Equal(t, "/api/v1/calculate", attrs["http.route"].AsString())
+		assert.Equal(t, int64(http.StatusOK), attrs["http.response.status_code"].AsInt64())
+		assert.Equal(t, codes.Unset, serverSpan.Status().Code)
+
+		assert.Equal(t, "PackService.CalculatePacks", serviceSpan.Name())
+		assert.Equal(t, serverSpan.SpanContext().SpanID(), serviceSpan.Parent().SpanID())
+		assert.Equal(t, int64(501), spanAttributes(serviceSpan)["packman.quantity"].AsInt64())
+	})
+
+	t.Run("server errors mark the spans as failed", func(t *testing.T) {
+		recorder.Reset()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/pack-sizes", nil))
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		for _, span := range spans {
+			assert.Equal(t, codes.Error, span.Status().Code, span.Name())
+		}
+		// a new trace is started without a traceparent header
+		assert.False(t, spans[1].Parent().IsValid())
+	})
+
+	t.Run("client errors do not fail the server span", func(t *testing.T) {
+		recorder.Reset()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/calculate", bytes.NewBufferString(`{"quantity": -1}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	})
+}
+
+func TestUnaryTracing(t *testing.T) {
+	recorder := recordSpans(t)
+
+	packService := new(mocks.MockPackService)
+	packService.On("CalculatePacks", mock.Anything, 501).
+		Return(&model.PackCalculationResponse{Quantity: 501, Packs: map[int]int{500: 1, 250: 1}}, nil)
+	client := packmanv1.NewPackServiceClient(setupGRPCServer(t, packService, nil, nil))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "traceparent", traceParent, "x-request-id", "req-456")
+	_, err := client.CalculatePacks(ctx, &packmanv1.CalculatePacksRequest{Quantity: 501})
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "packman.v1.PackService/CalculatePacks", span.Name())
+	assert.Equal(t, callerTraceID, span.SpanContext().TraceID().String())
+	assert.Equal(t, callerSpanID, span.Parent().SpanID().String())
+	attrs := spanAttributes(span)
+	assert.Equal(t, "req-456", attrs["request.id"].AsString())
+	assert.Equal(t, "OK", attrs["rpc.response.status_code"].AsString())
+}
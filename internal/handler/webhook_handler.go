@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/middleware"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/response"
+	"github.com/nsaltun/packman/internal/service"
+)
+
+// WebhookHTTPHandler defines the interface for webhook subscription HTTP handlers
+type WebhookHTTPHandler interface {
+	registerRoutes(r *gin.Engine)
+	CreateWebhook(c *gin.Context)
+	ListWebhooks(c *gin.Context)
+	GetWebhook(c *gin.Context)
+	UpdateWebhook(c *gin.Context)
+	DeleteWebhook(c *gin.Context)
+	ListWebhookDeliveries(c *gin.Context)
+	ListDeadLetters(c *gin.Context)
+	RetryWebhookDelivery(c *gin.Context)
+}
+
+// webhookHTTPHandler is the concrete implementation of WebhookHTTPHandler
+type webhookHTTPHandler struct {
+	webhookService service.WebhookService
+}
+
+// NewWebhookHTTPHandler creates a new HTTP handler with the given service
+func NewWebhookHTTPHandler(webhookService service.WebhookService) WebhookHTTPHandler {
+	return &webhookHTTPHandler{
+		webhookService: webhookService,
+	}
+}
+
+// registerRoutes registers all routes for the HTTP handler
+func (h *webhookHTTPHandler) registerRoutes(r *gin.Engine) {
+	webhooks := r.Group("/api/v1/webhooks", middleware.RequireScope(model.ScopeAdmin))
+	{
+		webhooks.POST("", h.CreateWebhook)
+		webhooks.GET("", h.ListWebhooks)
+		webhooks.GET("/dead-letters", h.ListDeadLetters)
+		webhooks.POST("/deliveries/:id/retry", h.RetryWebhookDelivery)
+		webhooks.GET("/:id", h.GetWebhook)
+		webhooks.PUT("/:id", h.UpdateWebhook)
+		webhooks.DELETE("/:id", h.DeleteWebhook)
+		webhooks.GET("/:id/deliveries", h.ListWebhookDeliveries)
+	}
+}
+
+// CreateWebhook handles subscribing an endpoint to configuration changes
+// The response contains the signing secret, it is not returned again
+func (h *webhookHTTPHandler) CreateWebhook(c *gin.Context) {
+	var req model.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperror.BadRequestError("Invalid request format", err))
+		return
+	}
+	req.CreatedBy = requestActor(c, req.CreatedBy)
+
+	// validate request
+	if err := validateCreateWebhookRequest(&req); err != nil {
+		_ = c.Error(apperror.ValidationError(err.Error(), err))
+		return
+	}
+
+	res, err := h.webhookService.CreateWebhook(c.Request.Context(), &req)
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	redactSecret(c, res)
+	response.Success(c, http.StatusCreated, res)
+}
+
+// ListWebhooks handles listing all subscriptions
+func (h *webhookHTTPHandler) ListWebhooks(c *gin.Context) {
+	res, err := h.webhookService.ListWebhooks(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	response.Success(c, http.StatusOK, res)
+}
+
+// GetWebhook handles retrieving a single subscription
+func (h *webhookHTTPHandler) GetWebhook(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		_ = c.Error(apperror.BadRequestError(err.Error(), err))
+		return
+	}
+
+	res, err := h.webhookService.GetWebhook(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	response.Success(c, http.StatusOK, res)
+}
+
+// UpdateWebhook handles changing, pausing or rotating the secret of a subscription
+func (h *webhookHTTPHandler) UpdateWebhook(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		_ = c.Error(apperror.BadRequestError(err.Error(), err))
+		return
+	}
+
+	var req model.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperror.BadRequestError("Invalid request format", err))
+		return
+	}
+
+	// validate request
+	if err := validateUpdateWebhookRequest(&req); err != nil {
+		_ = c.Error(apperror.ValidationError(err.Error(), err))
+		return
+	}
+
+	res, err := h.webhookService.UpdateWebhook(c.Request.Context(), id, &req)
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	redactSecret(c, res)
+	response.Success(c, http.StatusOK, res)
+}
+
+// redactSecret keeps the signing secret of sub out of the responses replayed for an Idempotency-Key
+func redactSecret(c *gin.Context, sub *model.WebhookSubscription) {
+	if sub.Secret == "" {
+		return
+	}
+	redacted := *sub
+	redacted.Secret = ""
+	middleware.RedactIdempotentResponse(c, &redacted)
+}
+
+// DeleteWebhook handles removing a subscription
+func (h *webhookHTTPHandler) DeleteWebhook(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		_ = c.Error(apperror.BadRequestError(err.Error(), err))
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(c.Request.Context(), id); err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries handles querying the delivery log of a subscription
+// Deliveries can be filtered with the status query parameter
+func (h *webhookHTTPHandler) ListWebhookDeliveries(c *gin.Context) {
+	id, err := parseIDParam(c)
+	if err != nil {
+		_ = c.Error(apperror.BadRequestError(err.Error(), err))
+		return
+	}
+
+	status := model.WebhookDeliveryStatus(c.Query("status"))
+	switch status {
+	case "", model.WebhookDeliveryPending, model.WebhookDeliveryDelivered, model.WebhookDeliveryDead:
+	default:
+		_ = c.Error(apperror.ValidationError("status must be one of pending, delivered, dead", nil))
+		return
+	}
+
+	limit, err := parseLimitQuery(c)
+	if err != nil {
+		_ = c.Error(apperror.ValidationError(err.Error(), err))
+		return
+	}
+
+	filter := model.WebhookDeliveryFilter{SubscriptionID: id, Status: status, Limit: limit}
+	res, err := h.webhookService.ListWebhookDeliveries(c.Request.Context(), filter)
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	response.Success(c, http.StatusOK, res)
+}
+
+// ListDeadLetters handles listing deliveries that ran out of attempts across all subscriptions
+func (h *webhookHTTPHandler) ListDeadLetters(c *gin.Context) {
+	limit, err := parseLimitQuery(c)
+	if err != nil {
+		_ = c.Error(apperror.ValidationError(err.Error(), err))
+		return
+	}
+
+	filter := model.WebhookDeliveryFilter{Status: model.WebhookDeliveryDead, Limit: limit}
+	res, err := h.webhookService.ListWebhookDeliveries(c.Request.Context(), filter)
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	response.Success(c, http.StatusOK, res)
+}
+
+// RetryWebhookDelivery handles moving a dead-lettered delivery back to the delivery queue
+func (h *webhookHTTPHandler) RetryWebhookDelivery(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		err = fmt.Errorf("id must be a positive integer")
+		_ = c.Error(apperror.BadRequestError(err.Error(), err))
+		return
+	}
+
+	res, err := h.webhookService.RetryWebhookDelivery(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err) // Pass through AppError from service/repo
+		return
+	}
+	response.Success(c, http.StatusOK, res)
+}
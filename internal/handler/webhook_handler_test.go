@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWebhookHTTPHandler(t *testing.T) {
+	active := false
+
+	tests := []struct {
+		name           string
+		method         string
+		url            string
+		body           interface{}
+		mockSetup      func(*mocks.MockWebhookService)
+		expectedStatus int
+		expectedCode   apperror.ErrorCode
+	}{
+		{
+			name:   "create",
+			method: http.MethodPost,
+			url:    "/api/v1/webhooks",
+			body:   model.CreateWebhookRequest{URL: "https://erp.example.com/hooks/packs", CreatedBy: "alice"},
+			mockSetup: func(m *mocks.MockWebhookService) {
+				m.On("CreateWebhook", mock.Anything, &model.CreateWebhookRequest{URL: "https://erp.example.com/hooks/packs", CreatedBy: "alice"}).
+					Return(&model.WebhookSubscription{ID: 1, Secret: "whsec_abc"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "create rejects non http urls",
+			method:         http.MethodPost,
+			url:            "/api/v1/webhooks",
+			body:           model.CreateWebhookRequest{URL: "ftp://erp.example.com/hooks"},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+		{
+			name:           "create rejects short secrets",
+			method:         http.MethodPost,
+			url:            "/api/v1/webhooks",
+			body:           model.CreateWebhookRequest{URL: "https://erp.example.com/hooks", Secret: "short"},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+		{
+			name:   "list",
+			method: http.MethodGet,
+			url:    "/api/v1/webhooks",
+			mockSetup: func(m *mocks.MockWebhookService) {
+				m.On("ListWebhooks", mock.Anything).Return([]*model.WebhookSubscription{{ID: 1}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "get unknown",
+			method: http.MethodGet,
+			url:    "/api/v1/webhooks/9",
+			mockSetup: func(m *mocks.MockWebhookService) {
+				m.On("GetWebhook", mock.Anything, 9).Return(nil, apperror.NotFoundError("Webhook not found", nil))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   apperror.ErrCodeNotFound,
+		},
+		{
+			name:           "get invalid id",
+			method:         http.MethodGet,
+			url:            "/api/v1/webhooks/abc",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeBadRequest,
+		},
+		{
+			name:   "pause",
+			method: http.MethodPut,
+			url:    "/api/v1/webhooks/1",
+			body:   model.UpdateWebhookRequest{Active: &active},
+			mockSetup: func(m *mocks.MockWebhookService) {
+				m.On("UpdateWebhook", mock.Anything, 1, &model.UpdateWebhookRequest{Active: &active}).
+					Return(&model.WebhookSubscription{ID: 1}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "update without changes",
+			method:         http.MethodPut,
+			url:            "/api/v1/webhooks/1",
+			body:           map[string]interface{}{},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+		{
+			name:   "delete",
+			method: http.MethodDelete,
+			url:    "/api/v1/webhooks/1",
+			mockSetup: func(m *mocks.MockWebhookService) {
+				m.On("DeleteWebhook", mock.Anything, 1).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "delivery log",
+			method: http.MethodGet,
+			url:    "/api/v1/webhooks/1/deliveries?status=dead&limit=5",
+			mockSetup: func(m *mocks.MockWebhookService) {
+				filter := model.WebhookDeliveryFilter{SubscriptionID: 1, Status: model.WebhookDeliveryDead, Limit: 5}
+				m.On("ListWebhookDeliveries", mock.Anything, filter).Return([]*model.WebhookDelivery{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "delivery log with invalid status",
+			method:         http.MethodGet,
+			url:            "/api/v1/webhooks/1/deliveries?status=lost",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apperror.ErrCodeValidation,
+		},
+		{
+			name:   "dead letters",
+			method: http.MethodGet,
+			url:    "/api/v1/webhooks/dead-letters",
+			mockSetup: func(m *mocks.MockWebhookService) {
+				filter := model.WebhookDeliveryFilter{Status: model.WebhookDeliveryDead}
+				m.On("ListWebhookDeliveries", mock.Anything, filter).Return([]*model.WebhookDelivery{{ID: 3}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "retry a dead letter",
+			method: http.MethodPost,
+			url:    "/api/v1/webhooks/deliveries/3/retry",
+			mockSetup: func(m *mocks.MockWebhookService) {
+				m.On("RetryWebhookDelivery", mock.Anything, int64(3)).
+					Return(&model.WebhookDelivery{ID: 3, Status: model.WebhookDeliveryPending}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "retry a delivery that is not dead",
+			method: http.MethodPost,
+			url:    "/api/v1/webhooks/deliveries/4/retry",
+			mockSetup: func(m *mocks.MockWebhookService) {
+				m.On("RetryWebhookDelivery", mock.Anything, int64(4)).
+					Return(nil, apperror.ConflictError("Only dead-lettered deliveries can be retried", nil))
+			},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   apperror.ErrCodeConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// setup
+			mockService := new(mocks.MockWebhookService)
+			if tt.mockSetup != nil {
+				tt.mockSetup(mockService)
+			}
+			handler := NewWebhookHTTPHandler(mockService)
+
+			var body []byte
+			if tt.body != nil {
+				body, _ = json.Marshal(tt.body)
+			}
+
+			// create router with the handler routes and execute
+			req := httptest.NewRequest(tt.method, tt.url, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router := setupTestRouter()
+			handler.registerRoutes(router)
+			router.ServeHTTP(w, req)
+
+			// assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedCode != "" {
+				assertErrorCode(t, w, tt.expectedCode)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package idempotency
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/app"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+)
+
+// Store keeps the requests made with an Idempotency-Key for the configured ttl
+// Expired keys are removed periodically until Close
+type Store struct {
+	app.AbstractComponent
+	repo repository.IdempotencyRepository
+	cfg  config.IdempotencyConfig
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewStore creates a store that keeps the keys in repo
+func NewStore(repo repository.IdempotencyRepository, cfg config.IdempotencyConfig) *Store {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Store{
+		repo:   repo,
+		cfg:    cfg,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+}
+
+// Claim records a request under key, it returns nil when the request may proceed and the earlier request otherwise
+func (s *Store) Claim(ctx context.Context, key, fingerprint string) (*model.IdempotencyRecord, error) {
+	return s.repo.ClaimIdempotencyKey(ctx, key, fingerprint, s.cfg.KeyTTL, s.cfg.LockTimeout)
+}
+
+// Complete stores the response of a claimed request to replay it on retries
+func (s *Store) Complete(ctx context.Context, key string, response *model.IdempotentResponse) error {
+	return s.repo.CompleteIdempotencyKey(ctx, key, response)
+}
+
+// Release forgets a claimed request, a retry with the key then runs again
+func (s *Store) Release(ctx context.Context, key string) error {
+	return s.repo.ReleaseIdempotencyKey(ctx, key)
+}
+
+// Run removes expired keys every cleanup interval until Close
+func (s *Store) Run() error {
+	defer close(s.done)
+	slog.Info("idempotency key store started", slog.Duration("key_ttl", s.cfg.KeyTTL))
+
+	ticker := time.NewTicker(s.cfg.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return nil
+		case <-ticker.C:
+			s.cleanup(s.ctx)
+		}
+	}
+}
+
+// Close stops the cleanup loop
+func (s *Store) Close(ctx context.Context) error {
+	slog.InfoContext(ctx, "closing idempotency key store")
+	s.cancel()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cleanup removes the expired keys
+func (s *Store) cleanup(ctx context.Context) {
+	deleted, err := s.repo.DeleteExpiredIdempotencyKeys(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.ErrorContext(ctx, "failed to delete expired idempotency keys", slog.String("error", err.Error()))
+		}
+		return
+	}
+	if deleted > 0 {
+		slog.DebugContext(ctx, "deleted expired idempotency keys", slog.Int("count", deleted))
+	}
+}
@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/nsaltun/packman/internal/reqctx"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ContextHandler adds the request a record was logged for to the record
+// The request ID is taken from the request metadata of the context, so every log call with a request
+// context, e.g. slog.WarnContext(ctx, ...) in services and repositories, is linked to the access log
+// without passing a logger around. When the request is traced, the trace and span IDs are added as well,
+// linking the logs to the trace
+type ContextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps handler with a ContextHandler
+func NewContextHandler(handler slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: handler}
+}
+
+// Handle adds the request_id, trace_id and span_id of ctx to the record and passes it on
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	requestID := reqctx.FromContext(ctx).RequestID
+	spanContext := trace.SpanContextFromContext(ctx)
+	if requestID == "" && !spanContext.IsValid() {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	r = r.Clone()
+	if requestID != "" {
+		r.AddAttrs(slog.String("request_id", requestID))
+	}
+	if spanContext.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a ContextHandler whose handler has the given attributes
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a ContextHandler whose handler has the given group
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,63 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/nsaltun/packman/internal/reqctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestContextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewContextHandler(slog.NewJSONHandler(&buf, nil))).With(slog.String("component", "test"))
+
+	decode := func() map[string]any {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		buf.Reset()
+		return record
+	}
+
+	t.Run("adds the request ID of the context", func(t *testing.T) {
+		ctx := reqctx.WithMetadata(context.Background(), reqctx.Metadata{RequestID: "req-123"})
+		logger.InfoContext(ctx, "changed")
+
+		record := decode()
+		assert.Equal(t, "req-123", record["request_id"])
+		assert.Equal(t, "test", record["component"])
+	})
+
+	t.Run("adds the trace and span IDs of the context", func(t *testing.T) {
+		traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		require.NoError(t, err)
+		spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+		require.NoError(t, err)
+		ctx := reqctx.WithMetadata(context.Background(), reqctx.Metadata{RequestID: "req-123"})
+		ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+		}))
+		logger.InfoContext(ctx, "calculated")
+
+		record := decode()
+		assert.Equal(t, "req-123", record["request_id"])
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["trace_id"])
+		assert.Equal(t, "00f067aa0ba902b7", record["span_id"])
+	})
+
+	t.Run("logs without a request are unchanged", func(t *testing.T) {
+		logger.Info("started")
+
+		record := decode()
+		assert.NotContains(t, record, "request_id")
+		assert.NotContains(t, record, "trace_id")
+		assert.Equal(t, "started", record["msg"])
+	})
+}
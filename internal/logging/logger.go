@@ -0,0 +1,30 @@
+package logging
+
+import (
+	"io"
+	"log/slog"
+
+	"github.com/nsaltun/packman/config"
+)
+
+// NewLogger creates the logger of the application writing to w
+// The returned level is the minimum level of the logger, setting it changes the level at runtime
+func NewLogger(cfg config.LogConfig, w io.Writer) (*slog.Logger, *slog.LevelVar) {
+	level := new(slog.LevelVar)
+	level.Set(cfg.Level)
+
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if cfg.Format == config.LogFormatText {
+		handler = slog.NewTextHandler(w, options)
+	} else {
+		handler = slog.NewJSONHandler(w, options)
+	}
+
+	if cfg.SamplingEnabled {
+		handler = NewSamplingHandler(handler, cfg.SamplingInitial, cfg.SamplingThereafter, cfg.SamplingInterval)
+	}
+
+	// the context handler adds the request ID to the logs of a request
+	return slog.New(NewContextHandler(handler)), level
+}
@@ -0,0 +1,93 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// samplerKey identifies records that are sampled together
+type samplerKey struct {
+	level   slog.Level
+	message string
+}
+
+// samplerCounter counts the records of a key in the current interval
+type samplerCounter struct {
+	start time.Time
+	count int
+}
+
+// samplerState is shared by a SamplingHandler and the handlers derived from it with WithAttrs and WithGroup
+type samplerState struct {
+	mu       sync.Mutex
+	counters map[samplerKey]*samplerCounter
+	now      func() time.Time
+}
+
+// SamplingHandler drops high-volume records, e.g. the access logs of a busy route
+// In each interval, the first initial records with the same level and message are logged and after that
+// every thereafter-th. Records at warning level or above are always logged
+type SamplingHandler struct {
+	slog.Handler
+	initial    int
+	thereafter int
+	interval   time.Duration
+	state      *samplerState
+}
+
+// NewSamplingHandler wraps handler with a SamplingHandler
+func NewSamplingHandler(handler slog.Handler, initial, thereafter int, interval time.Duration) *SamplingHandler {
+	return &SamplingHandler{
+		Handler:    handler,
+		initial:    initial,
+		thereafter: thereafter,
+		interval:   interval,
+		state: &samplerState{
+			counters: make(map[samplerKey]*samplerCounter),
+			now:      time.Now,
+		},
+	}
+}
+
+// Handle passes the record on unless it is sampled out
+func (h *SamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelWarn || h.sample(samplerKey{level: r.Level, message: r.Message}) {
+		return h.Handler.Handle(ctx, r)
+	}
+	return nil
+}
+
+// sample counts a record of key and reports whether it is logged
+func (h *SamplingHandler) sample(key samplerKey) bool {
+	h.state.mu.Lock()
+	defer h.state.mu.Unlock()
+
+	now := h.state.now()
+	counter, ok := h.state.counters[key]
+	if !ok || now.Sub(counter.start) >= h.interval {
+		counter = &samplerCounter{start: now}
+		h.state.counters[key] = counter
+	}
+	counter.count++
+
+	if counter.count <= h.initial {
+		return true
+	}
+	return (counter.count-h.initial)%h.thereafter == 0
+}
+
+// WithAttrs returns a SamplingHandler whose handler has the given attributes, sharing the counters
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	derived := *h
+	derived.Handler = h.Handler.WithAttrs(attrs)
+	return &derived
+}
+
+// WithGroup returns a SamplingHandler whose handler has the given group, sharing the counters
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	derived := *h
+	derived.Handler = h.Handler.WithGroup(name)
+	return &derived
+}
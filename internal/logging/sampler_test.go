@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSamplingHandler(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	sampler := NewSamplingHandler(slog.NewTextHandler(&buf, nil), 2, 3, time.Second)
+	sampler.state.now = func() time.Time { return now }
+	logger := slog.New(sampler)
+
+	count := func(message string) int {
+		return strings.Count(buf.String(), "msg="+message)
+	}
+
+	t.Run("logs the first records and then every n-th", func(t *testing.T) {
+		for range 8 {
+			logger.Info("request")
+		}
+		// records 1 and 2, then 5 and 8
+		assert.Equal(t, 4, count("request"))
+	})
+
+	t.Run("derived loggers share the counters of a message", func(t *testing.T) {
+		buf.Reset()
+		logger.Info("other")
+		logger.With(slog.String("route", "/health")).Info("other")
+		logger.Info("other")
+		assert.Equal(t, 2, count("other"))
+	})
+
+	t.Run("warnings are never sampled", func(t *testing.T) {
+		buf.Reset()
+		for range 8 {
+			logger.Warn("client error")
+		}
+		assert.Equal(t, 8, count(`"client error"`))
+	})
+
+	t.Run("counters restart after the interval", func(t *testing.T) {
+		buf.Reset()
+		now = now.Add(time.Second)
+		logger.Info("request")
+		logger.Info("request")
+		logger.Info("request")
+		assert.Equal(t, 2, count("request"))
+	})
+}
@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the names of all metrics of the service
+const namespace = "packman"
+
+// collectTimeout bounds the reads of collectors that query the service during a scrape
+const collectTimeout = 2 * time.Second
+
+// Metrics holds the Prometheus metrics of the service
+type Metrics struct {
+	registry            *prometheus.Registry
+	httpRequests        *prometheus.CounterVec
+	httpDuration        *prometheus.HistogramVec
+	calculationDuration prometheus.Histogram
+	calculationQuantity prometheus.Histogram
+}
+
+// New creates the metrics of the service, together with the Go runtime and process metrics
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of HTTP requests by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		calculationDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "pack_calculation_duration_seconds",
+			Help:      "Duration of successful pack calculations, including reading the pack sizes.",
+			// 10µs to 2.6s, large quantities take much longer than small ones
+			Buckets: prometheus.ExponentialBuckets(0.00001, 4, 10),
+		}),
+		calculationQuantity: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "pack_calculation_quantity",
+			Help:      "Ordered quantities of successful pack calculations.",
+			// 1 to 10,000,000, the largest quantity that can be ordered
+			Buckets: prometheus.ExponentialBuckets(1, 10, 8),
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.calculationDuration,
+		m.calculationQuantity,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTPRequest records a handled HTTP request
+// route is the registered path template, so the number of series does not grow with IDs in paths
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	labels := prometheus.Labels{"method": method, "route": route, "status": strconv.Itoa(status)}
+	m.httpRequests.With(labels).Inc()
+	m.httpDuration.With(labels).Observe(duration.Seconds())
+}
+
+// ObserveCalculation records a successful pack calculation
+func (m *Metrics) ObserveCalculation(quantity int, duration time.Duration) {
+	m.calculationDuration.Observe(duration.Seconds())
+	m.calculationQuantity.Observe(float64(quantity))
+}
+
+// PackSizesReader reads the active pack configuration
+type PackSizesReader interface {
+	GetPackSizes(ctx context.Context) (*model.GetPackSizesResponse, error)
+}
+
+// RegisterPackConfiguration reports the version of the active configuration as a gauge
+// The version is read on every scrape, so it also follows changes made through other instances
+func (m *Metrics) RegisterPackConfiguration(packSizes PackSizesReader) {
+	m.registry.MustRegister(&versionCollector{
+		packSizes: packSizes,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "pack_configuration_version"),
+			"Version of the active pack size configuration.",
+			nil, nil,
+		),
+	})
+}
+
+// RegisterPool reports the statistics of a database connection pool, name distinguishes the primary and the replica
+func (m *Metrics) RegisterPool(name string, pool *pgxpool.Pool) {
+	m.registry.MustRegister(newPoolCollector(name, pool))
+}
+
+// versionCollector reads the version of the active configuration during a scrape
+type versionCollector struct {
+	packSizes PackSizesReader
+	desc      *prometheus.Desc
+}
+
+// Describe implements prometheus.Collector
+func (c *versionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector, the gauge is left out while the configuration cannot be read
+func (c *versionCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	res, err := c.packSizes.GetPackSizes(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read pack configuration version for metrics", slog.String("error", err.Error()))
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(res.Version))
+}
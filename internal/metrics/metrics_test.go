@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_ObserveHTTPRequest(t *testing.T) {
+	m := New()
+	m.ObserveHTTPRequest("GET", "/api/v1/pack-sizes", 200, 20*time.Millisecond)
+	m.ObserveHTTPRequest("GET", "/api/v1/pack-sizes", 200, 30*time.Millisecond)
+	m.ObserveHTTPRequest("POST", "/api/v1/calculate", 400, time.Millisecond)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/api/v1/pack-sizes", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("POST", "/api/v1/calculate", "400")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.httpDuration))
+}
+
+func TestNewPackService(t *testing.T) {
+	m := New()
+	packService := new(mocks.MockPackService)
+	packService.On("CalculatePacks", mock.Anything, 501).Return(&model.PackCalculationResponse{Quantity: 501}, nil)
+	packService.On("CalculatePacks", mock.Anything, 0).Return(nil, errors.New("invalid quantity"))
+	packService.On("CalculatePacksAsOf", mock.Anything, 12001, 2, time.Time{}).Return(&model.PackCalculationResponse{Quantity: 12001}, nil)
+	packService.On("GetPackSizes", mock.Anything).Return(&model.GetPackSizesResponse{Version: 3}, nil)
+	instrumented := NewPackService(packService, m)
+
+	_, err := instrumented.CalculatePacks(context.Background(), 501)
+	require.NoError(t, err)
+	_, err = instrumented.CalculatePacks(context.Background(), 0)
+	require.Error(t, err)
+	_, err = instrumented.CalculatePacksAsOf(context.Background(), 12001, 2, time.Time{})
+	require.NoError(t, err)
+	// other methods are passed through unrecorded
+	res, err := instrumented.GetPackSizes(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Version)
+
+	// failed calculations are not recorded
+	expected := `
+# HELP packman_pack_calculation_quantity Ordered quantities of successful pack calculations.
+# TYPE packman_pack_calculation_quantity histogram
+packman_pack_calculation_quantity_bucket{le="1"} 0
+packman_pack_calculation_quantity_bucket{le="10"} 0
+packman_pack_calculation_quantity_bucket{le="100"} 0
+packman_pack_calculation_quantity_bucket{le="1000"} 1
+packman_pack_calculation_quantity_bucket{le="10000"} 1
+packman_pack_calculation_quantity_bucket{le="100000"} 2
+packman_pack_calculation_quantity_bucket{le="1e+06"} 2
+packman_pack_calculation_quantity_bucket{le="1e+07"} 2
+packman_pack_calculation_quantity_bucket{le="+Inf"} 2
+packman_pack_calculation_quantity_sum 12502
+packman_pack_calculation_quantity_count 2
+`
+	assert.NoError(t, testutil.CollectAndCompare(m.calculationQuantity, strings.NewReader(expected)))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.calculationDuration))
+}
+
+func TestMetrics_RegisterPackConfiguration(t *testing.T) {
+	const expected = `
+# HELP packman_pack_configuration_version Version of the active pack size configuration.
+# TYPE packman_pack_configuration_version gauge
+packman_pack_configuration_version 7
+`
+
+	t.Run("reports the version of the active configuration", func(t *testing.T) {
+		m := New()
+		packService := new(mocks.MockPackService)
+		packService.On("GetPackSizes", mock.Anything).Return(&model.GetPackSizesResponse{Version: 7}, nil)
+		m.RegisterPackConfiguration(packService)
+
+		assert.NoError(t, testutil.GatherAndCompare(m.registry, strings.NewReader(expected), "packman_pack_configuration_version"))
+	})
+
+	t.Run("leaves the version out while the configuration cannot be read", func(t *testing.T) {
+		m := New()
+		packService := new(mocks.MockPackService)
+		packService.On("GetPackSizes", mock.Anything).Return(nil, errors.New("database is down"))
+		m.RegisterPackConfiguration(packService)
+
+		count, err := testutil.GatherAndCount(m.registry, "packman_pack_configuration_version")
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+}
+
+func TestMetrics_RegisterPool(t *testing.T) {
+	// the pool connects lazily, its statistics are available without a database
+	pool, err := pgxpool.New(context.Background(), "postgres://packman@127.0.0.1:1/packman?pool_max_conns=4")
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	m := New()
+	m.RegisterPool("replica", pool)
+
+	expected := `
+# HELP packman_db_pool_max_conns Maximum size of the pool.
+# TYPE packman_db_pool_max_conns gauge
+packman_db_pool_max_conns{pool="replica"} 4
+# HELP packman_db_pool_total_conns Connections in the pool.
+# TYPE packman_db_pool_total_conns gauge
+packman_db_pool_total_conns{pool="replica"} 0
+`
+	assert.NoError(t, testutil.GatherAndCompare(m.registry, strings.NewReader(expected),
+		"packman_db_pool_max_conns", "packman_db_pool_total_conns"))
+
+	count, err := testutil.GatherAndCount(m.registry)
+	require.NoError(t, err)
+	assert.Greater(t, count, 12)
+}
@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/service"
+)
+
+// instrumentedPackService records the calculations of a PackService, the other methods are passed through
+type instrumentedPackService struct {
+	service.PackService
+	metrics *Metrics
+}
+
+// NewPackService wraps packService so its calculations are recorded in metrics
+// Both the HTTP and the gRPC handlers use the wrapped service, so every calculation is counted once
+func NewPackService(packService service.PackService, metrics *Metrics) service.PackService {
+	return &instrumentedPackService{PackService: packService, metrics: metrics}
+}
+
+// CalculatePacks calculates packs with the active configuration and records the calculation
+func (s *instrumentedPackService) CalculatePacks(ctx context.Context, quantity int) (*model.PackCalculationResponse, error) {
+	start := time.Now()
+	res, err := s.PackService.CalculatePacks(ctx, quantity)
+	if err == nil {
+		s.metrics.ObserveCalculation(quantity, time.Since(start))
+	}
+	return res, err
+}
+
+// CalculatePacksAsOf calculates packs with a historical configuration and records the calculation
+func (s *instrumentedPackService) CalculatePacksAsOf(ctx context.Context, quantity int, version int, at time.Time) (*model.PackCalculationResponse, error) {
+	start := time.Now()
+	res, err := s.PackService.CalculatePacksAsOf(ctx, quantity, version, at)
+	if err == nil {
+		s.metrics.ObserveCalculation(quantity, time.Since(start))
+	}
+	return res, err
+}
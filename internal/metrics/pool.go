@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolMetric is a statistic of a connection pool
+type poolMetric struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	value     func(stat *pgxpool.Stat) float64
+}
+
+// poolCollector reports the statistics of a connection pool, read from Pool.Stat like the health check
+type poolCollector struct {
+	pool    *pgxpool.Pool
+	metrics []poolMetric
+}
+
+// newPoolCollector creates a collector for pool, its metrics are labeled with pool=name
+func newPoolCollector(name string, pool *pgxpool.Pool) *poolCollector {
+	labels := prometheus.Labels{"pool": name}
+	metric := func(metricName, help string, valueType prometheus.ValueType, value func(stat *pgxpool.Stat) float64) poolMetric {
+		return poolMetric{
+			desc:      prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", metricName), help, nil, labels),
+			valueType: valueType,
+			value:     value,
+		}
+	}
+
+	return &poolCollector{
+		pool: pool,
+		metrics: []poolMetric{
+			metric("total_conns", "Connections in the pool.", prometheus.GaugeValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.TotalConns()) }),
+			metric("acquired_conns", "Connections currently in use.", prometheus.GaugeValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.AcquiredConns()) }),
+			metric("idle_conns", "Idle connections.", prometheus.GaugeValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.IdleConns()) }),
+			metric("constructing_conns", "Connections being established.", prometheus.GaugeValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.ConstructingConns()) }),
+			metric("max_conns", "Maximum size of the pool.", prometheus.GaugeValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.MaxConns()) }),
+			metric("acquires_total", "Connections acquired from the pool.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.AcquireCount()) }),
+			metric("acquire_duration_seconds_total", "Time spent acquiring connections.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return s.AcquireDuration().Seconds() }),
+			metric("empty_acquires_total", "Acquires that had to wait for a connection because the pool was empty.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.EmptyAcquireCount()) }),
+			metric("canceled_acquires_total", "Acquires canceled by their context.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.CanceledAcquireCount()) }),
+			metric("new_conns_total", "Connections opened.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.NewConnsCount()) }),
+			metric("max_lifetime_destroys_total", "Connections closed because they reached their maximum lifetime.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.MaxLifetimeDestroyCount()) }),
+			metric("max_idle_destroys_total", "Connections closed because they were idle for too long.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.MaxIdleDestroyCount()) }),
+		},
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c.metrics {
+		ch <- m.desc
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	for _, m := range c.metrics {
+		ch <- prometheus.MustNewConstMetric(m.desc, m.valueType, m.value(stat))
+	}
+}
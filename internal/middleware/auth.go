@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/reqctx"
+)
+
+// principalContextKey is the gin context key the authenticated caller is stored under
+const principalContextKey = "principal"
+
+// Authenticator resolves the caller from the API key or token a request was made with
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*model.Principal, error)
+}
+
+// anonymousAdmin is the caller of every request when authentication is disabled
+var anonymousAdmin = model.Principal{Scopes: []model.Scope{model.ScopeAdmin}}
+
+// Authenticate resolves the API key or token sent in the Authorization: Bearer or X-API-Key header
+// Requests without credentials continue anonymously, RequireScope rejects them on routes that need a caller.
+// Requests with an unknown, revoked, expired or otherwise invalid credential are rejected here.
+// A nil auth disables authentication, every request is then allowed everything
+func Authenticate(auth Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if auth == nil {
+			principal := anonymousAdmin
+			c.Set(principalContextKey, &principal)
+			c.Next()
+			return
+		}
+
+		credential := requestCredential(c)
+		if credential == "" {
+			c.Next()
+			return
+		}
+
+		principal, err := auth.Authenticate(c.Request.Context(), credential)
+		if err != nil {
+			if appErr, ok := apperror.AsAppError(err); ok && appErr.Code == apperror.ErrCodeUnauthorized {
+				c.Header("WWW-Authenticate", "Bearer")
+			}
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Set(principalContextKey, principal)
+
+		// Store the caller as the identity for services and repositories, e.g. audit records
+		c.Request = c.Request.WithContext(reqctx.WithIdentity(c.Request.Context(), principal.Name))
+
+		c.Next()
+	}
+}
+
+// RequireScope rejects requests whose caller was not granted scope
+func RequireScope(scope model.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := CurrentPrincipal(c)
+		if !ok {
+			c.Header("WWW-Authenticate", "Bearer")
+			_ = c.Error(apperror.UnauthorizedError("", nil))
+			c.Abort()
+			return
+		}
+		if !principal.HasScope(scope) {
+			_ = c.Error(apperror.ForbiddenError("Caller does not have the required scope", nil).
+				WithDetails("required_scope", scope))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CurrentPrincipal returns the caller the request was authenticated as
+func CurrentPrincipal(c *gin.Context) (*model.Principal, bool) {
+	value, ok := c.Get(principalContextKey)
+	if !ok {
+		return nil, false
+	}
+	principal, ok := value.(*model.Principal)
+	return principal, ok
+}
+
+// callerKey identifies the caller for rate limits and idempotency keys
+// Callers with credentials are identified by their name across addresses, anonymous callers by their address
+func callerKey(c *gin.Context) string {
+	if principal, ok := CurrentPrincipal(c); ok && principal.Name != "" {
+		return "principal:" + principal.Name
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// requestCredential returns the Authorization: Bearer header, or else the X-API-Key header
+func requestCredential(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return strings.TrimSpace(c.GetHeader("X-API-Key"))
+}
@@ -15,47 +15,54 @@ import (
 func ErrorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
+		writeError(c)
+	}
+}
 
-		// Check if there were any errors during request processing
-		if len(c.Errors) > 0 {
-			err := c.Errors.Last().Err
-			requestID, _ := c.Get("request_id")
-
-			// Check if it's an AppError (our custom error type)
-			if appErr, ok := apperror.AsAppError(err); ok {
-				// Log full error details (including internal error)
-				if appErr.StatusCode >= 500 {
-					slog.Error("server error",
-						slog.String("request_id", requestID.(string)),
-						slog.String("code", string(appErr.Code)),
-						slog.String("message", appErr.Message),
-						slog.String("error", fmt.Sprintf("%+v", err)), // %+v includes stack trace if available
-						slog.Any("internal", appErr.Internal),         // This is logged but not exposed
-					)
-				} else {
-					slog.Warn("client error",
-						slog.String("request_id", requestID.(string)),
-						slog.String("code", string(appErr.Code)),
-						slog.String("message", appErr.Message),
-					)
-				}
-
-				// Return sanitized response (no internal details)
-				response.FromAppError(c, appErr)
-			} else {
-				// Unknown error - log and return generic error
-				slog.ErrorContext(c, "unhandled error",
-					slog.String("request_id", requestID.(string)),
+// writeError sends the response of the last error of the request, unless a response was written already
+// It runs after the handlers, middleware that needs the final response, e.g. Idempotency, calls it earlier
+func writeError(c *gin.Context) {
+	if c.Writer.Written() {
+		return
+	}
+
+	// Check if there were any errors during request processing
+	if len(c.Errors) > 0 {
+		err := c.Errors.Last().Err
+		// the request context adds the request ID to the logs
+		ctx := c.Request.Context()
+
+		// Check if it's an AppError (our custom error type)
+		if appErr, ok := apperror.AsAppError(err); ok {
+			// Log full error details (including internal error)
+			if appErr.StatusCode >= 500 {
+				slog.ErrorContext(ctx, "server error",
+					slog.String("code", string(appErr.Code)),
+					slog.String("message", appErr.Message),
 					slog.String("error", fmt.Sprintf("%+v", err)), // %+v includes stack trace if available
+					slog.Any("internal", appErr.Internal),         // This is logged but not exposed
+				)
+			} else {
+				slog.WarnContext(ctx, "client error",
+					slog.String("code", string(appErr.Code)),
+					slog.String("message", appErr.Message),
 				)
-
-				response.Error(c, http.StatusInternalServerError,
-					apperror.ErrCodeInternal,
-					"An internal error occurred",
-					nil)
 			}
 
-			c.Abort()
+			// Return sanitized response (no internal details)
+			response.FromAppError(c, appErr)
+		} else {
+			// Unknown error - log and return generic error
+			slog.ErrorContext(ctx, "unhandled error",
+				slog.String("error", fmt.Sprintf("%+v", err)), // %+v includes stack trace if available
+			)
+
+			response.Error(c, http.StatusInternalServerError,
+				apperror.ErrCodeInternal,
+				"An internal error occurred",
+				nil)
 		}
+
+		c.Abort()
 	}
 }
@@ -0,0 +1,372 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/reqctx"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDMetadataKey is the gRPC metadata key of the request ID, like the X-Request-ID header
+const requestIDMetadataKey = "x-request-id"
+
+// grpcErrorDomain is the domain of the google.rpc.ErrorInfo detail of failed calls
+const grpcErrorDomain = "packman"
+
+// grpcCodes maps the error codes of the API to gRPC status codes
+var grpcCodes = map[apperror.ErrorCode]codes.Code{
+	apperror.ErrCodeValidation:     codes.InvalidArgument,
+	apperror.ErrCodeNotFound:       codes.NotFound,
+	apperror.ErrCodeConflict:       codes.Aborted,
+	apperror.ErrCodeBadRequest:     codes.InvalidArgument,
+	apperror.ErrCodeUnauthorized:   codes.Unauthenticated,
+	apperror.ErrCodeForbidden:      codes.PermissionDenied,
+	apperror.ErrCodeTooLarge:       codes.ResourceExhausted,
+	apperror.ErrCodeUnprocessable:  codes.FailedPrecondition,
+	apperror.ErrCodeRateLimited:    codes.ResourceExhausted,
+	apperror.ErrCodeInternal:       codes.Internal,
+	apperror.ErrCodeServiceUnavail: codes.Unavailable,
+}
+
+// GRPCCode returns the gRPC status code of an error code, Internal for unknown codes
+func GRPCCode(code apperror.ErrorCode) codes.Code {
+	if grpcCode, ok := grpcCodes[code]; ok {
+		return grpcCode
+	}
+	return codes.Internal
+}
+
+type grpcPrincipalKey struct{}
+
+// GRPCPrincipal returns the caller a gRPC call was authenticated as
+func GRPCPrincipal(ctx context.Context) (*model.Principal, bool) {
+	principal, ok := ctx.Value(grpcPrincipalKey{}).(*model.Principal)
+	return principal, ok
+}
+
+// UnaryRequestID adds a request ID to each call, taken from the x-request-id metadata or generated
+// It is sent back in the response headers so the caller can reference it
+func UnaryRequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		requestID := requestIDOrNew(firstMetadataValue(ctx, requestIDMetadataKey))
+
+		// Store in context for services and repositories, e.g. audit records
+		ctx = reqctx.WithMetadata(ctx, reqctx.Metadata{
+			RequestID: requestID,
+			ClientIP:  peerIP(ctx),
+		})
+
+		if err := grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadataKey, requestID)); err != nil {
+			slog.WarnContext(ctx, "failed to send request ID header", slog.String("error", err.Error()))
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// grpcServerErrors are the status codes that mark the span of a call as failed, like 5xx responses
+var grpcServerErrors = map[codes.Code]bool{
+	codes.Unknown:          true,
+	codes.Internal:         true,
+	codes.Unavailable:      true,
+	codes.DataLoss:         true,
+	codes.DeadlineExceeded: true,
+	codes.Unimplemented:    true,
+}
+
+// grpcCodeNames are the names of the status codes in the gRPC specification, e.g. DEADLINE_EXCEEDED
+var grpcCodeNames = map[codes.Code]string{
+	codes.OK:                 "OK",
+	codes.Canceled:           "CANCELLED",
+	codes.Unknown:            "UNKNOWN",
+	codes.InvalidArgument:    "INVALID_ARGUMENT",
+	codes.DeadlineExceeded:   "DEADLINE_EXCEEDED",
+	codes.NotFound:           "NOT_FOUND",
+	codes.AlreadyExists:      "ALREADY_EXISTS",
+	codes.PermissionDenied:   "PERMISSION_DENIED",
+	codes.ResourceExhausted:  "RESOURCE_EXHAUSTED",
+	codes.FailedPrecondition: "FAILED_PRECONDITION",
+	codes.Aborted:            "ABORTED",
+	codes.OutOfRange:         "OUT_OF_RANGE",
+	codes.Unimplemented:      "UNIMPLEMENTED",
+	codes.Internal:           "INTERNAL",
+	codes.Unavailable:        "UNAVAILABLE",
+	codes.DataLoss:           "DATA_LOSS",
+	codes.Unauthenticated:    "UNAUTHENTICATED",
+}
+
+// UnaryTracing starts the server span of each call like Tracing, continuing the trace of the traceparent metadata
+// It runs outside UnaryErrorHandler, so the span sees the status the caller receives
+func UnaryTracing() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+		ctx, span := otel.Tracer(tracerName).Start(ctx, strings.TrimPrefix(info.FullMethod, "/"),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.RPCSystemNameGRPC,
+				semconv.RPCMethod(info.FullMethod),
+				semconv.ClientAddress(peerIP(ctx)),
+				requestIDAttribute.String(reqctx.FromContext(ctx).RequestID),
+			),
+		)
+		defer span.End()
+
+		res, err := handler(ctx, req)
+
+		code := status.Code(err)
+		span.SetAttributes(semconv.RPCResponseStatusCode(grpcCodeNames[code]))
+		if grpcServerErrors[code] {
+			span.RecordError(err)
+			span.SetStatus(otelcodes.Error, status.Convert(err).Message())
+		}
+		return res, err
+	}
+}
+
+// UnaryErrorHandler converts the errors of calls to gRPC statuses, like ErrorHandler does for HTTP responses
+// The status carries a google.rpc.ErrorInfo detail with the error code as reason, its details and the
+// request ID as metadata. Internal error details are logged but not exposed. Panics are reported as
+// internal errors
+func UnaryErrorHandler() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res any, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				res = nil
+				err = apperror.InternalError("", fmt.Errorf("panic: %v", recovered))
+			}
+			if err != nil {
+				err = grpcStatus(ctx, info.FullMethod, err).Err()
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// UnaryAuthenticate resolves the API key or token sent in the authorization: Bearer or x-api-key metadata
+// and requires the scope of the method. The methods of publicServices, e.g. health checks, are open, and
+// every other method without a scope is denied, so a new method cannot be left open by mistake.
+// A nil auth disables authentication, every call is then allowed everything
+func UnaryAuthenticate(auth Authenticator, scopes map[string]model.Scope, publicServices []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if isPublicMethod(info.FullMethod, publicServices) {
+			return handler(ctx, req)
+		}
+		scope, scoped := scopes[info.FullMethod]
+		if !scoped {
+			return nil, apperror.ForbiddenError(errMethodNotAccessible, nil)
+		}
+
+		if auth == nil {
+			principal := anonymousAdmin
+			return handler(context.WithValue(ctx, grpcPrincipalKey{}, &principal), req)
+		}
+
+		credential := grpcCredential(ctx)
+		if credential == "" {
+			return nil, apperror.UnauthorizedError("", nil)
+		}
+		principal, err := auth.Authenticate(ctx, credential)
+		if err != nil {
+			return nil, err
+		}
+		if !principal.HasScope(scope) {
+			return nil, apperror.ForbiddenError("Caller does not have the required scope", nil).
+				WithDetails("required_scope", scope)
+		}
+
+		// Store the caller as the identity for services and repositories, e.g. audit records
+		ctx = context.WithValue(ctx, grpcPrincipalKey{}, principal)
+		ctx = reqctx.WithIdentity(ctx, principal.Name)
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthenticate allows the streams of publicServices, e.g. health watches and reflection, and denies
+// every other stream, none of the services with scopes has streaming methods
+func StreamAuthenticate(publicServices []string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isPublicMethod(info.FullMethod, publicServices) {
+			return handler(srv, ss)
+		}
+		return status.Error(codes.PermissionDenied, errMethodNotAccessible)
+	}
+}
+
+// errMethodNotAccessible is the message of calls to methods that are neither public nor have a scope
+const errMethodNotAccessible = "Method is not accessible"
+
+// isPublicMethod reports whether the full method name belongs to one of services
+func isPublicMethod(fullMethod string, services []string) bool {
+	for _, service := range services {
+		if strings.HasPrefix(fullMethod, "/"+service+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// UnaryLimitAddresses limits the calls of each IP address like LimitAddresses, before the credentials are checked
+// The methods of publicServices are not limited.
+// A nil limiter disables rate limiting
+func UnaryLimitAddresses(limiter RateLimiter, publicServices []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if limiter == nil || isPublicMethod(info.FullMethod, publicServices) {
+			return handler(ctx, req)
+		}
+
+		decision := limiter.AllowAddress(ctx, reqctx.FromContext(ctx).ClientIP)
+		if !decision.Allowed {
+			retryAfter := max(ceilSeconds(decision.RetryAfter), 1)
+			return nil, apperror.RateLimitedError("", nil).WithDetails("retry_after_seconds", retryAfter)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// UnaryRateLimit limits the calls of each client like RateLimit, the route of a call is its full method name
+// The methods of publicServices are not limited, so health checks of the load balancer always pass.
+// A nil limiter disables rate limiting
+func UnaryRateLimit(limiter RateLimiter, publicServices []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if limiter == nil || isPublicMethod(info.FullMethod, publicServices) {
+			return handler(ctx, req)
+		}
+
+		client := "ip:" + reqctx.FromContext(ctx).ClientIP
+		if principal, ok := GRPCPrincipal(ctx); ok && principal.Name != "" {
+			client = "principal:" + principal.Name
+		}
+
+		decision := limiter.Allow(ctx, info.FullMethod, client)
+		if !decision.Allowed {
+			retryAfter := max(ceilSeconds(decision.RetryAfter), 1)
+			return nil, apperror.RateLimitedError("", nil).WithDetails("retry_after_seconds", retryAfter)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// grpcStatus converts err to the status of a call and logs it
+func grpcStatus(ctx context.Context, method string, err error) *status.Status {
+	requestID := reqctx.FromContext(ctx).RequestID
+
+	var appErr *apperror.AppError
+	if !errors.As(err, &appErr) {
+		// errors of gRPC itself, e.g. a canceled call, keep their status
+		if st, ok := status.FromError(err); ok {
+			return st
+		}
+		slog.ErrorContext(ctx, "unhandled error",
+			slog.String("method", method),
+			slog.String("error", fmt.Sprintf("%+v", err)),
+		)
+		appErr = apperror.InternalError("", nil)
+	} else if appErr.StatusCode >= 500 {
+		slog.ErrorContext(ctx, "server error",
+			slog.String("method", method),
+			slog.String("code", string(appErr.Code)),
+			slog.String("message", appErr.Message),
+			slog.Any("internal", appErr.Internal),
+		)
+	} else {
+		slog.WarnContext(ctx, "client error",
+			slog.String("method", method),
+			slog.String("code", string(appErr.Code)),
+			slog.String("message", appErr.Message),
+		)
+	}
+
+	info := &errdetails.ErrorInfo{
+		Reason:   string(appErr.Code),
+		Domain:   grpcErrorDomain,
+		Metadata: map[string]string{"request_id": requestID},
+	}
+	for key, value := range appErr.Details {
+		info.Metadata[key] = fmt.Sprint(value)
+	}
+
+	st := status.New(GRPCCode(appErr.Code), appErr.Message)
+	if detailed, err := st.WithDetails(info); err == nil {
+		st = detailed
+	}
+	return st
+}
+
+// grpcCredential returns the authorization: Bearer metadata, or else the x-api-key metadata
+func grpcCredential(ctx context.Context) string {
+	if header := firstMetadataValue(ctx, "authorization"); header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return strings.TrimSpace(firstMetadataValue(ctx, "x-api-key"))
+}
+
+// firstMetadataValue returns the first value of the incoming metadata key, or an empty string
+func firstMetadataValue(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// metadataCarrier reads and writes trace context in gRPC metadata for the propagators
+type metadataCarrier metadata.MD
+
+// Get returns the first value of key
+func (m metadataCarrier) Get(key string) string {
+	if values := metadata.MD(m).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Set replaces the values of key with value
+func (m metadataCarrier) Set(key, value string) {
+	metadata.MD(m).Set(key, value)
+}
+
+// Keys returns the keys of the metadata
+func (m metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// peerIP returns the IP address of the caller
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
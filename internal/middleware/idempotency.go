@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/response"
+)
+
+// maxIdempotencyKeyLength bounds the Idempotency-Key header, a UUID is plenty
+const maxIdempotencyKeyLength = 255
+
+// redactedResponseKey is the context key of the data stored in place of a response that carries a secret
+const redactedResponseKey = "idempotency_redacted_data"
+
+// RedactIdempotentResponse makes Idempotency store data instead of the response of the request
+// Handlers returning a secret that is shown only once, such as a new API key, call it with the response
+// without the secret. Retries then get the same status without the secret, and it never reaches the store
+func RedactIdempotentResponse(c *gin.Context, data any) {
+	c.Set(redactedResponseKey, data)
+}
+
+// IdempotencyStore keeps the requests made with an Idempotency-Key and their responses
+type IdempotencyStore interface {
+	Claim(ctx context.Context, key, fingerprint string) (*model.IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, response *model.IdempotentResponse) error
+	Release(ctx context.Context, key string) error
+}
+
+// Idempotency replays the first response to a POST, PUT, PATCH or DELETE sent with an Idempotency-Key header
+// Keys are scoped to the caller. A retry with the same key but another method, path or body is rejected,
+// as is a retry while the first request is still in progress. Server errors are not stored, the request
+// then runs again on retry. Neither are 401 and 403, which the scope checks of routes return after this
+// middleware, so a retry once the caller was granted the scope runs the request. Secrets are replaced as
+// set by RedactIdempotentResponse. Requests without
+// the header are not affected. Their bodies are read to fingerprint the request, bodies larger than
+// maxBodyBytes are rejected. A nil store disables idempotency keys
+func Idempotency(store IdempotencyStore, maxBodyBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if store == nil || key == "" || !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			_ = c.Error(apperror.ValidationError(fmt.Sprintf("Idempotency-Key must be at most %d characters", maxIdempotencyKeyLength), nil))
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			_ = c.Error(apperror.PayloadTooLargeError(fmt.Sprintf("Request body must be at most %d bytes", maxBodyBytes), err))
+			c.Abort()
+			return
+		}
+		if err != nil {
+			_ = c.Error(apperror.BadRequestError("Failed to read request body", err))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		storeKey := callerKey(c) + " " + key
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		record, err := store.Claim(ctx, storeKey, fingerprint)
+		if err != nil {
+			_ = c.Error(apperror.ServiceUnavailableError("Idempotency-Key could not be checked", err))
+			c.Abort()
+			return
+		}
+		if record != nil {
+			switch {
+			case record.Fingerprint != fingerprint:
+				_ = c.Error(apperror.UnprocessableError("Idempotency-Key was already used for a different request", nil))
+			case record.Response == nil:
+				c.Header("Retry-After", "1")
+				_ = c.Error(apperror.ConflictError("A request with this Idempotency-Key is still in progress", nil))
+			default:
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(record.Response.StatusCode, record.Response.ContentType, record.Response.Body)
+			}
+			c.Abort()
+			return
+		}
+
+		// the response is stored after it was written, even if the client has gone by then
+		storeCtx := context.WithoutCancel(ctx)
+		completed := false
+		defer func() {
+			// the handler panicked or failed, a retry should run the request again
+			if !completed {
+				if err := store.Release(storeCtx, storeKey); err != nil {
+					slog.ErrorContext(ctx, "failed to release idempotency key", slog.String("error", err.Error()))
+				}
+			}
+		}()
+
+		writer := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		// errors are written by ErrorHandler once every middleware returned, store their final response too
+		writeError(c)
+
+		if !isStoredStatus(writer.Status()) {
+			return
+		}
+		completed = true
+		stored := &model.IdempotentResponse{
+			StatusCode:  writer.Status(),
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		}
+		if data, redacted := c.Get(redactedResponseKey); redacted {
+			body, err := json.Marshal(response.APIResponse{Data: data, RequestID: c.GetString("request_id")})
+			if err != nil {
+				// the response must not be stored with its secret, the key stays claimed until the lock times out
+				slog.ErrorContext(ctx, "failed to redact idempotent response", slog.String("error", err.Error()))
+				return
+			}
+			stored.Body = body
+		}
+		if err := store.Complete(storeCtx, storeKey, stored); err != nil {
+			// the request took effect, keep the key claimed so retries are rejected until the lock times out
+			slog.ErrorContext(ctx, "failed to store idempotent response", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// isMutatingMethod reports whether requests with method change state, only those take an Idempotency-Key
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isStoredStatus reports whether a response with status is replayed to retries
+// Server errors may not recur, and authorization failures only mean the request did not run
+func isStoredStatus(status int) bool {
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return false
+	}
+	return status < http.StatusInternalServerError
+}
+
+// requestFingerprint identifies a request by its method, path and body
+func requestFingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// capturingWriter keeps a copy of the response body while writing it
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write writes data to the response and the copy
+func (w *capturingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString writes s to the response and the copy
+func (w *capturingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute is the route of requests that match no registered route, e.g. 404s
+const unmatchedRoute = "unmatched"
+
+// HTTPMetrics records the handled requests of the HTTP server
+type HTTPMetrics interface {
+	ObserveHTTPRequest(method, route string, status int, duration time.Duration)
+}
+
+// Metrics records every request once it has been handled
+// Requests are recorded by their route template, like the access log, so paths with IDs or unknown
+// paths do not create a series each. A nil metrics disables the middleware
+func Metrics(metrics HTTPMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if metrics == nil {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
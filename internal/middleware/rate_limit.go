@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/ratelimit"
+)
+
+// RateLimiter decides whether a client may make another request to a route
+type RateLimiter interface {
+	Allow(ctx context.Context, route, client string) ratelimit.Decision
+	AllowAddress(ctx context.Context, address string) ratelimit.Decision
+}
+
+// rateLimitExempt lists the routes that are never limited, e.g. probes of the load balancer
+var rateLimitExempt = map[string]bool{
+	"GET /health": true,
+}
+
+// RateLimit limits the requests of each client, identified by its API key or token or else its IP address
+// It runs after Authenticate, behind the per address limit of LimitAddresses, and reports the limit in the RateLimit-* headers of every response.
+// Rejected requests get a Retry-After header and a RATE_LIMITED error.
+// A nil limiter disables rate limiting
+func RateLimit(limiter RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		if limiter == nil || rateLimitExempt[route] {
+			c.Next()
+			return
+		}
+
+		decision := limiter.Allow(c.Request.Context(), route, callerKey(c))
+		if !decision.Allowed {
+			rejectRateLimited(c, decision)
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(decision.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", decision.Limit, ceilSeconds(decision.Window)))
+
+		c.Next()
+	}
+}
+
+// LimitAddresses limits the requests of each IP address across all routes
+// It runs before Authenticate, so requests with unknown API keys or invalid tokens are limited too and guessing
+// credentials cannot bypass the limits. RateLimit then applies the limits per caller.
+// A nil limiter disables rate limiting
+func LimitAddresses(limiter RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter == nil || rateLimitExempt[c.Request.Method+" "+c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		decision := limiter.AllowAddress(c.Request.Context(), c.ClientIP())
+		if !decision.Allowed {
+			rejectRateLimited(c, decision)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rejectRateLimited aborts a request that exceeded decision with a RATE_LIMITED error
+func rejectRateLimited(c *gin.Context, decision ratelimit.Decision) {
+	c.Header("RateLimit-Limit", strconv.Itoa(decision.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", decision.Limit, ceilSeconds(decision.Window)))
+
+	retryAfter := max(ceilSeconds(decision.RetryAfter), 1)
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	_ = c.Error(apperror.RateLimitedError("", nil).WithDetails("retry_after_seconds", retryAfter))
+	c.Abort()
+}
+
+// ceilSeconds rounds d up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
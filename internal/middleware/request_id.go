@@ -3,23 +3,47 @@ package middleware
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/nsaltun/packman/internal/reqctx"
 )
 
+// maxRequestIDLength bounds request IDs sent by clients, they are stored with audit records
+const maxRequestIDLength = 128
+
 // RequestID adds a unique request ID to each request
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Generate or use existing request ID from header
-		requestID := c.GetHeader("X-Request-ID")
-		if requestID == "" {
-			requestID = uuid.New().String()
-		}
+		requestID := requestIDOrNew(c.GetHeader("X-Request-ID"))
 
 		// Store in context for handlers and middleware
 		c.Set("request_id", requestID)
 
+		// Store in request context for services and repositories, e.g. audit records
+		c.Request = c.Request.WithContext(reqctx.WithMetadata(c.Request.Context(), reqctx.Metadata{
+			RequestID: requestID,
+			ClientIP:  c.ClientIP(),
+		}))
+
 		// Set in response header so client can reference it
 		c.Header("X-Request-ID", requestID)
 
 		c.Next()
 	}
 }
+
+// requestIDOrNew returns the request ID sent by a client, or a new one if it sent none or one that is too long
+// or has characters other than letters, digits and -_.:
+// A replaced ID is sent back in the response like a generated one, so the client can still match the logs
+func requestIDOrNew(requestID string) string {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return uuid.New().String()
+	}
+	for _, r := range requestID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == ':':
+		default:
+			return uuid.New().String()
+		}
+	}
+	return requestID
+}
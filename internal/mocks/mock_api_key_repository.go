@@ -0,0 +1,55 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAPIKeyRepository is a mock implementation of repository.APIKeyRepository
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+// CreateAPIKey mocks the CreateAPIKey method
+func (m *MockAPIKeyRepository) CreateAPIKey(ctx context.Context, key *model.APIKey, hash string) (*model.APIKey, error) {
+	args := m.Called(ctx, key, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.APIKey), args.Error(1)
+}
+
+// GetAPIKeyByHash mocks the GetAPIKeyByHash method
+func (m *MockAPIKeyRepository) GetAPIKeyByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	args := m.Called(ctx, hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.APIKey), args.Error(1)
+}
+
+// ListAPIKeys mocks the ListAPIKeys method
+func (m *MockAPIKeyRepository) ListAPIKeys(ctx context.Context) ([]*model.APIKey, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.APIKey), args.Error(1)
+}
+
+// RevokeAPIKey mocks the RevokeAPIKey method
+func (m *MockAPIKeyRepository) RevokeAPIKey(ctx context.Context, id int) (*model.APIKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.APIKey), args.Error(1)
+}
+
+// TouchAPIKey mocks the TouchAPIKey method
+func (m *MockAPIKeyRepository) TouchAPIKey(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
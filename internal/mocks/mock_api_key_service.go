@@ -0,0 +1,49 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAPIKeyService is a mock implementation of service.APIKeyService
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+// CreateAPIKey mocks the CreateAPIKey method
+func (m *MockAPIKeyService) CreateAPIKey(ctx context.Context, req *model.CreateAPIKeyRequest) (*model.APIKey, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.APIKey), args.Error(1)
+}
+
+// ListAPIKeys mocks the ListAPIKeys method
+func (m *MockAPIKeyService) ListAPIKeys(ctx context.Context) ([]*model.APIKey, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.APIKey), args.Error(1)
+}
+
+// RevokeAPIKey mocks the RevokeAPIKey method
+func (m *MockAPIKeyService) RevokeAPIKey(ctx context.Context, id int) (*model.APIKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.APIKey), args.Error(1)
+}
+
+// Authenticate mocks the Authenticate method
+func (m *MockAPIKeyService) Authenticate(ctx context.Context, rawKey string) (*model.Principal, error) {
+	args := m.Called(ctx, rawKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Principal), args.Error(1)
+}
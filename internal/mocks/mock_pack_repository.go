@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/nsaltun/packman/internal/model"
 	"github.com/stretchr/testify/mock"
@@ -30,9 +31,36 @@ func (m *MockPackRepository) GetPackConfiguration(ctx context.Context) (*model.P
 	return args.Get(0).(*model.PackConfiguration), args.Error(1)
 }
 
+// GetPackConfigurationByVersion mocks the GetPackConfigurationByVersion method
+func (m *MockPackRepository) GetPackConfigurationByVersion(ctx context.Context, version int) (*model.PackConfiguration, error) {
+	args := m.Called(ctx, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PackConfiguration), args.Error(1)
+}
+
+// GetPackConfigurationAsOf mocks the GetPackConfigurationAsOf method
+func (m *MockPackRepository) GetPackConfigurationAsOf(ctx context.Context, at time.Time) (*model.PackConfiguration, error) {
+	args := m.Called(ctx, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PackConfiguration), args.Error(1)
+}
+
 // UpdatePackSizes mocks the UpdatePackSizes method
-func (m *MockPackRepository) UpdatePackSizes(ctx context.Context, sizes []int, updatedBy string) (*model.PackConfiguration, error) {
-	args := m.Called(ctx, sizes, updatedBy)
+func (m *MockPackRepository) UpdatePackSizes(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.PackConfiguration, error) {
+	args := m.Called(ctx, sizes, updatedBy, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PackConfiguration), args.Error(1)
+}
+
+// ModifyPackSizes mocks the ModifyPackSizes method
+func (m *MockPackRepository) ModifyPackSizes(ctx context.Context, updatedBy string, reason string, change func(current []int) ([]int, error)) (*model.PackConfiguration, error) {
+	args := m.Called(ctx, updatedBy, reason, change)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -47,3 +75,66 @@ func (m *MockPackRepository) GetPackConfigurationHistory(ctx context.Context, li
 	}
 	return args.Get(0).([]*model.PackConfiguration), args.Error(1)
 }
+
+// CreateDraft mocks the CreateDraft method
+func (m *MockPackRepository) CreateDraft(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.PackConfigurationDraft, error) {
+	args := m.Called(ctx, sizes, updatedBy, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PackConfigurationDraft), args.Error(1)
+}
+
+// GetDraft mocks the GetDraft method
+func (m *MockPackRepository) GetDraft(ctx context.Context, id int) (*model.PackConfigurationDraft, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PackConfigurationDraft), args.Error(1)
+}
+
+// ListDrafts mocks the ListDrafts method
+func (m *MockPackRepository) ListDrafts(ctx context.Context, status model.DraftStatus, limit int) ([]*model.PackConfigurationDraft, error) {
+	args := m.Called(ctx, status, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PackConfigurationDraft), args.Error(1)
+}
+
+// ApproveDraft mocks the ApproveDraft method
+func (m *MockPackRepository) ApproveDraft(ctx context.Context, id int, reviewedBy string) (*model.PackConfiguration, error) {
+	args := m.Called(ctx, id, reviewedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PackConfiguration), args.Error(1)
+}
+
+// RejectDraft mocks the RejectDraft method
+func (m *MockPackRepository) RejectDraft(ctx context.Context, id int, reviewedBy string, comment string) (*model.PackConfigurationDraft, error) {
+	args := m.Called(ctx, id, reviewedBy, comment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PackConfigurationDraft), args.Error(1)
+}
+
+// GetFullPackConfigurationHistory mocks the GetFullPackConfigurationHistory method
+func (m *MockPackRepository) GetFullPackConfigurationHistory(ctx context.Context) ([]*model.PackConfiguration, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PackConfiguration), args.Error(1)
+}
+
+// ListAuditEntries mocks the ListAuditEntries method
+func (m *MockPackRepository) ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.AuditEntry), args.Error(1)
+}
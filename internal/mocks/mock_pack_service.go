@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/nsaltun/packman/internal/model"
 	"github.com/stretchr/testify/mock"
@@ -19,6 +20,14 @@ func (m *MockPackService) CalculatePacks(ctx context.Context, quantity int) (*mo
 	return args.Get(0).(*model.PackCalculationResponse), args.Error(1)
 }
 
+func (m *MockPackService) CalculatePacksAsOf(ctx context.Context, quantity int, version int, at time.Time) (*model.PackCalculationResponse, error) {
+	args := m.Called(ctx, quantity, version, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PackCalculationResponse), args.Error(1)
+}
+
 func (m *MockPackService) GetPackSizes(ctx context.Context) (*model.GetPackSizesResponse, error) {
 	args := m.Called(ctx)
 	if args.Get(0) == nil {
@@ -27,10 +36,82 @@ func (m *MockPackService) GetPackSizes(ctx context.Context) (*model.GetPackSizes
 	return args.Get(0).(*model.GetPackSizesResponse), args.Error(1)
 }
 
-func (m *MockPackService) UpdatePackSizes(ctx context.Context, sizes []int, updatedBy string) (*model.UpdatePackSizesResponse, error) {
-	args := m.Called(ctx, sizes, updatedBy)
+func (m *MockPackService) UpdatePackSizes(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.UpdatePackSizesResponse, error) {
+	args := m.Called(ctx, sizes, updatedBy, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UpdatePackSizesResponse), args.Error(1)
+}
+
+func (m *MockPackService) PatchPackSizes(ctx context.Context, add, remove []int, updatedBy string, reason string) (*model.UpdatePackSizesResponse, error) {
+	args := m.Called(ctx, add, remove, updatedBy, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UpdatePackSizesResponse), args.Error(1)
+}
+
+func (m *MockPackService) ExportConfiguration(ctx context.Context) (*model.PackConfigurationExport, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PackConfigurationExport), args.Error(1)
+}
+
+func (m *MockPackService) ImportPackSizes(ctx context.Context, sizes []int, updatedBy string, reason string, dryRun bool) (*model.ImportPackSizesResponse, error) {
+	args := m.Called(ctx, sizes, updatedBy, reason, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ImportPackSizesResponse), args.Error(1)
+}
+
+func (m *MockPackService) CreateDraft(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.PackConfigurationDraft, error) {
+	args := m.Called(ctx, sizes, updatedBy, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PackConfigurationDraft), args.Error(1)
+}
+
+func (m *MockPackService) GetDraft(ctx context.Context, id int) (*model.PackConfigurationDraft, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PackConfigurationDraft), args.Error(1)
+}
+
+func (m *MockPackService) ListDrafts(ctx context.Context, status model.DraftStatus, limit int) ([]*model.PackConfigurationDraft, error) {
+	args := m.Called(ctx, status, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PackConfigurationDraft), args.Error(1)
+}
+
+func (m *MockPackService) ApproveDraft(ctx context.Context, id int, reviewedBy string) (*model.UpdatePackSizesResponse, error) {
+	args := m.Called(ctx, id, reviewedBy)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*model.UpdatePackSizesResponse), args.Error(1)
 }
+
+func (m *MockPackService) RejectDraft(ctx context.Context, id int, reviewedBy string, comment string) (*model.PackConfigurationDraft, error) {
+	args := m.Called(ctx, id, reviewedBy, comment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PackConfigurationDraft), args.Error(1)
+}
+
+func (m *MockPackService) ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.AuditEntry), args.Error(1)
+}
@@ -0,0 +1,96 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWebhookRepository is a mock implementation of repository.WebhookRepository
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+// CreateWebhook mocks the CreateWebhook method
+func (m *MockWebhookRepository) CreateWebhook(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	args := m.Called(ctx, sub)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookSubscription), args.Error(1)
+}
+
+// GetWebhook mocks the GetWebhook method
+func (m *MockWebhookRepository) GetWebhook(ctx context.Context, id int) (*model.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookSubscription), args.Error(1)
+}
+
+// ListWebhooks mocks the ListWebhooks method
+func (m *MockWebhookRepository) ListWebhooks(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookSubscription), args.Error(1)
+}
+
+// UpdateWebhook mocks the UpdateWebhook method
+func (m *MockWebhookRepository) UpdateWebhook(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	args := m.Called(ctx, sub)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookSubscription), args.Error(1)
+}
+
+// DeleteWebhook mocks the DeleteWebhook method
+func (m *MockWebhookRepository) DeleteWebhook(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// EnqueueWebhookDeliveries mocks the EnqueueWebhookDeliveries method
+func (m *MockWebhookRepository) EnqueueWebhookDeliveries(ctx context.Context, event *model.OutboxEvent, payload []byte) (int, error) {
+	args := m.Called(ctx, event, payload)
+	return args.Int(0), args.Error(1)
+}
+
+// ClaimWebhookDeliveries mocks the ClaimWebhookDeliveries method
+func (m *MockWebhookRepository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDelivery, error) {
+	args := m.Called(ctx, limit, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookDelivery), args.Error(1)
+}
+
+// RecordWebhookAttempt mocks the RecordWebhookAttempt method
+func (m *MockWebhookRepository) RecordWebhookAttempt(ctx context.Context, id int64, attempt repository.WebhookAttempt) error {
+	args := m.Called(ctx, id, attempt)
+	return args.Error(0)
+}
+
+// ListWebhookDeliveries mocks the ListWebhookDeliveries method
+func (m *MockWebhookRepository) ListWebhookDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter) ([]*model.WebhookDelivery, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookDelivery), args.Error(1)
+}
+
+// RetryWebhookDelivery mocks the RetryWebhookDelivery method
+func (m *MockWebhookRepository) RetryWebhookDelivery(ctx context.Context, id int64) (*model.WebhookDelivery, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookDelivery), args.Error(1)
+}
@@ -0,0 +1,73 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWebhookService is a mock implementation of service.WebhookService
+type MockWebhookService struct {
+	mock.Mock
+}
+
+// CreateWebhook mocks the CreateWebhook method
+func (m *MockWebhookService) CreateWebhook(ctx context.Context, req *model.CreateWebhookRequest) (*model.WebhookSubscription, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookSubscription), args.Error(1)
+}
+
+// GetWebhook mocks the GetWebhook method
+func (m *MockWebhookService) GetWebhook(ctx context.Context, id int) (*model.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookSubscription), args.Error(1)
+}
+
+// ListWebhooks mocks the ListWebhooks method
+func (m *MockWebhookService) ListWebhooks(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookSubscription), args.Error(1)
+}
+
+// UpdateWebhook mocks the UpdateWebhook method
+func (m *MockWebhookService) UpdateWebhook(ctx context.Context, id int, req *model.UpdateWebhookRequest) (*model.WebhookSubscription, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookSubscription), args.Error(1)
+}
+
+// DeleteWebhook mocks the DeleteWebhook method
+func (m *MockWebhookService) DeleteWebhook(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// ListWebhookDeliveries mocks the ListWebhookDeliveries method
+func (m *MockWebhookService) ListWebhookDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter) ([]*model.WebhookDelivery, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookDelivery), args.Error(1)
+}
+
+// RetryWebhookDelivery mocks the RetryWebhookDelivery method
+func (m *MockWebhookService) RetryWebhookDelivery(ctx context.Context, id int64) (*model.WebhookDelivery, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookDelivery), args.Error(1)
+}
@@ -0,0 +1,18 @@
+package model
+
+// PackSizeWarningCode identifies a kind of problem found in a pack size set
+type PackSizeWarningCode string
+
+const (
+	WarningCommonDivisor      PackSizeWarningCode = "COMMON_DIVISOR"
+	WarningUnusedSize         PackSizeWarningCode = "UNUSED_SIZE"
+	WarningExcessiveOvershoot PackSizeWarningCode = "EXCESSIVE_OVERSHOOT"
+	WarningTooManySizes       PackSizeWarningCode = "TOO_MANY_SIZES"
+)
+
+// PackSizeWarning describes a potential problem with a pack size set
+type PackSizeWarning struct {
+	Code    PackSizeWarningCode `json:"code"`
+	Message string              `json:"message"`
+	Sizes   []int               `json:"sizes,omitempty"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// APIKey identifies a client of the API, only a hash of the key itself is stored
+type APIKey struct {
+	ID   int    `json:"id" db:"id"`
+	Name string `json:"name" db:"name"`
+	// Prefix is the start of the key, it tells keys apart without revealing them
+	Prefix     string     `json:"prefix" db:"prefix"`
+	Scopes     []Scope    `json:"scopes" db:"scopes"`
+	CreatedBy  string     `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	// Key is only returned when the key is created
+	Key string `json:"key,omitempty" db:"-"`
+}
+
+// CreateAPIKeyRequest represents a request to issue a new API key
+type CreateAPIKeyRequest struct {
+	Name   string  `json:"name"`
+	Scopes []Scope `json:"scopes"`
+	// CreatedBy is the authenticated caller, it is not read from the request body
+	CreatedBy string `json:"-"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// AuditEntry records a single change of the pack configuration
+type AuditEntry struct {
+	ID                int       `json:"id" db:"id"`
+	Version           int       `json:"version" db:"version"`
+	PreviousVersion   int       `json:"previous_version" db:"previous_version"`
+	PackSizes         []int     `json:"pack_sizes"`
+	PreviousPackSizes []int     `json:"previous_pack_sizes"`
+	Reason            string    `json:"reason" db:"reason"`
+	UpdatedBy         string    `json:"updated_by,omitempty" db:"updated_by"`
+	ApprovedBy        string    `json:"approved_by,omitempty" db:"approved_by"`
+	DraftID           *int      `json:"draft_id,omitempty" db:"draft_id"`
+	Identity          string    `json:"identity,omitempty" db:"identity"`
+	RequestID         string    `json:"request_id,omitempty" db:"request_id"`
+	ClientIP          string    `json:"client_ip,omitempty" db:"client_ip"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+}
+
+// AuditFilter selects audit entries, empty fields match everything
+type AuditFilter struct {
+	UpdatedBy string
+	RequestID string
+	Limit     int
+}
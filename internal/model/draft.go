@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// DraftStatus represents the review state of a configuration draft
+type DraftStatus string
+
+const (
+	DraftStatusPending  DraftStatus = "pending"
+	DraftStatusApproved DraftStatus = "approved"
+	DraftStatusRejected DraftStatus = "rejected"
+)
+
+// PackConfigurationDraft represents a proposed pack size change waiting for review
+type PackConfigurationDraft struct {
+	ID             int         `json:"id" db:"id"`
+	PackSizes      []int       `json:"pack_sizes"`
+	Status         DraftStatus `json:"status" db:"status"`
+	BaseVersion    int         `json:"base_version" db:"base_version"`
+	CreatedAt      time.Time   `json:"created_at" db:"created_at"`
+	UpdatedBy      string      `json:"updated_by" db:"created_by"`
+	Reason         string      `json:"reason" db:"reason"`
+	ReviewedAt     *time.Time  `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	ReviewedBy     string      `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewComment  string      `json:"review_comment,omitempty" db:"review_comment"`
+	AppliedVersion *int        `json:"applied_version,omitempty" db:"applied_version"`
+
+	// Warnings are computed when the draft is created and not stored
+	Warnings []PackSizeWarning `json:"warnings,omitempty" db:"-"`
+}
+
+// ReviewDraftRequest represents a request to approve or reject a draft
+type ReviewDraftRequest struct {
+	ReviewedBy string `json:"reviewed_by"`
+	Comment    string `json:"comment,omitempty"`
+}
@@ -0,0 +1,16 @@
+package model
+
+// IdempotentResponse is the stored response to a request made with an Idempotency-Key, replayed on retries
+type IdempotentResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// IdempotencyRecord is a request made with an Idempotency-Key
+type IdempotencyRecord struct {
+	// Fingerprint identifies the method, path and body of the request, a retry has to match it
+	Fingerprint string
+	// Response is nil while the request is in progress
+	Response *IdempotentResponse
+}
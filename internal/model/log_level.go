@@ -0,0 +1,6 @@
+package model
+
+// LogLevel represents the minimum level of the logs of an instance, one of debug, info, warn or error
+type LogLevel struct {
+	Level string `json:"level"`
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventPackSizesChanged is the type of events emitted when the active pack sizes change
+const EventPackSizesChanged = "pack_sizes.changed"
+
+// PackSizesChangedEvent is the payload of an EventPackSizesChanged event
+// Consumers should ignore events with a version lower than the last one they applied,
+// delivery is at least once and retried events may arrive out of order
+type PackSizesChangedEvent struct {
+	Version           int       `json:"version"`
+	PreviousVersion   int       `json:"previous_version"`
+	PackSizes         []int     `json:"pack_sizes"`
+	PreviousPackSizes []int     `json:"previous_pack_sizes"`
+	Reason            string    `json:"reason"`
+	UpdatedBy         string    `json:"updated_by,omitempty"`
+	ApprovedBy        string    `json:"approved_by,omitempty"`
+	RequestID         string    `json:"request_id,omitempty"`
+	ChangedAt         time.Time `json:"changed_at"`
+}
+
+// OutboxStatus is the delivery state of an outbox event
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "pending"
+	OutboxStatusDelivered OutboxStatus = "delivered"
+	OutboxStatusFailed    OutboxStatus = "failed"
+)
+
+// OutboxEvent is an event written in the same transaction as the change it describes
+// and relayed to the configured sinks afterwards
+// DeliveredSinks lists the sinks that already accepted the event so retries skip them
+type OutboxEvent struct {
+	ID             int64           `json:"id" db:"id"`
+	Type           string          `json:"type" db:"event_type"`
+	Payload        json.RawMessage `json:"data" db:"payload"`
+	Status         OutboxStatus    `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	LastError      string          `json:"last_error,omitempty" db:"last_error"`
+	DeliveredSinks []string        `json:"delivered_sinks" db:"delivered_sinks"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+}
@@ -2,43 +2,65 @@ package model
 
 import "time"
 
+// PackCalculationRequest represents a request to calculate packs for a quantity
+// AsOfVersion or AsOf select a historical configuration instead of the active one
 type PackCalculationRequest struct {
-	Quantity int `json:"quantity"`
+	Quantity    int        `json:"quantity"`
+	AsOfVersion int        `json:"as_of_version,omitempty"`
+	AsOf        *time.Time `json:"as_of,omitempty"`
 }
 
 // PackCalculationResponse represents the result of pack calculation
+// Version is set when the calculation used a historical configuration
 type PackCalculationResponse struct {
 	Quantity int         `json:"quantity"`
 	Packs    map[int]int `json:"packs"`
+	Version  int         `json:"version,omitempty"`
 }
 
 // GetPackSizesResponse represents the response for getting pack sizes
 type GetPackSizesResponse struct {
-	PackSizes []int     `json:"pack_sizes"`
-	Version   int       `json:"version"`
-	UpdatedAt time.Time `json:"updated_at"`
-	UpdatedBy string    `json:"updated_by,omitempty"`
+	PackSizes  []int     `json:"pack_sizes"`
+	Version    int       `json:"version"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	UpdatedBy  string    `json:"updated_by,omitempty"`
+	ApprovedBy string    `json:"approved_by,omitempty"`
 }
 
 // UpdatePackSizesRequest represents a request to update pack sizes
 type UpdatePackSizesRequest struct {
 	PackSizes []int  `json:"pack_sizes"`
 	UpdatedBy string `json:"updated_by,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// PatchPackSizesRequest represents a request to add or remove individual pack sizes
+type PatchPackSizesRequest struct {
+	Add       []int  `json:"add,omitempty"`
+	Remove    []int  `json:"remove,omitempty"`
+	UpdatedBy string `json:"updated_by,omitempty"`
+	Reason    string `json:"reason"`
 }
 
 // UpdatePackSizesResponse represents the response for updating pack sizes
 type UpdatePackSizesResponse struct {
-	PackSizes []int     `json:"pack_sizes"`
-	Version   int       `json:"version"`
-	UpdatedAt time.Time `json:"updated_at"`
-	UpdatedBy string    `json:"updated_by,omitempty"`
+	PackSizes  []int             `json:"pack_sizes"`
+	Version    int               `json:"version"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	UpdatedBy  string            `json:"updated_by,omitempty"`
+	ApprovedBy string            `json:"approved_by,omitempty"`
+	Warnings   []PackSizeWarning `json:"warnings,omitempty"`
 }
 
 // PackConfiguration represents the current pack size configuration
+// For an archived configuration UpdatedAt is when it was superseded and ValidFrom when it became active,
+// ValidFrom is nil for the current configuration and for versions archived before it was recorded
 type PackConfiguration struct {
-	ID        int       `json:"id" db:"id"`
-	Version   int       `json:"version" db:"version"`
-	PackSizes []int     `json:"pack_sizes"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
-	UpdatedBy string    `json:"updated_by,omitempty" db:"updated_by"`
+	ID         int        `json:"id" db:"id"`
+	Version    int        `json:"version" db:"version"`
+	PackSizes  []int      `json:"pack_sizes"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+	UpdatedBy  string     `json:"updated_by,omitempty" db:"updated_by"`
+	ApprovedBy string     `json:"approved_by,omitempty" db:"approved_by"`
+	ValidFrom  *time.Time `json:"valid_from,omitempty" db:"valid_from"`
 }
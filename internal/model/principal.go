@@ -0,0 +1,39 @@
+package model
+
+import "slices"
+
+// Scope grants access to a group of endpoints
+// API keys are issued with scopes, token roles are mapped to them
+type Scope string
+
+const (
+	// ScopeCalculate allows calculating packs
+	ScopeCalculate Scope = "calculate"
+	// ScopeRead allows reading the configuration, its history, drafts and the audit log
+	ScopeRead Scope = "read"
+	// ScopeAdmin allows everything, including changing pack sizes and managing API keys and webhooks
+	ScopeAdmin Scope = "admin"
+)
+
+// Principal names are namespaced by how the caller authenticated, so an API key and a token subject with the same
+// name are different callers, e.g. in the audit log or when a draft needs a different user to approve it
+const (
+	// APIKeyPrincipalPrefix prefixes the name of an API key
+	APIKeyPrincipalPrefix = "key:"
+	// TokenPrincipalPrefix prefixes the name claim of a token, tokens come from the single configured issuer
+	TokenPrincipalPrefix = "jwt:"
+	// BootstrapPrincipal is the name of the configured bootstrap key, no API key may be named like it
+	BootstrapPrincipal = "bootstrap"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// Name is recorded as the author of changes, key:<API key name> or jwt:<name claim>
+	Name   string
+	Scopes []Scope
+}
+
+// HasScope reports whether the principal was granted scope, admin grants every scope
+func (p *Principal) HasScope(scope Scope) bool {
+	return slices.Contains(p.Scopes, ScopeAdmin) || slices.Contains(p.Scopes, scope)
+}
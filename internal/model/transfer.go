@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// PackConfigurationExport represents the active configuration and its full history for export
+// The top level pack_sizes/updated_by fields make an export file importable as is
+type PackConfigurationExport struct {
+	Version    int                        `json:"version" yaml:"version"`
+	PackSizes  []int                      `json:"pack_sizes" yaml:"pack_sizes"`
+	UpdatedAt  time.Time                  `json:"updated_at" yaml:"updated_at"`
+	UpdatedBy  string                     `json:"updated_by,omitempty" yaml:"updated_by,omitempty"`
+	ApprovedBy string                     `json:"approved_by,omitempty" yaml:"approved_by,omitempty"`
+	ExportedAt time.Time                  `json:"exported_at" yaml:"exported_at"`
+	History    []PackConfigurationVersion `json:"history" yaml:"history"`
+}
+
+// PackConfigurationVersion represents a single archived configuration version
+// ValidFrom is when the version became active, it is unknown for versions archived before it was recorded
+type PackConfigurationVersion struct {
+	Version    int        `json:"version" yaml:"version"`
+	PackSizes  []int      `json:"pack_sizes" yaml:"pack_sizes"`
+	ValidFrom  *time.Time `json:"valid_from,omitempty" yaml:"valid_from,omitempty"`
+	ArchivedAt time.Time  `json:"archived_at" yaml:"archived_at"`
+	UpdatedBy  string     `json:"updated_by,omitempty" yaml:"updated_by,omitempty"`
+	ApprovedBy string     `json:"approved_by,omitempty" yaml:"approved_by,omitempty"`
+}
+
+// PackConfigurationImport represents an imported configuration file
+type PackConfigurationImport struct {
+	PackSizes []int  `json:"pack_sizes" yaml:"pack_sizes"`
+	UpdatedBy string `json:"updated_by,omitempty" yaml:"updated_by,omitempty"`
+	Reason    string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// ImportPackSizesResponse represents the outcome of an import, or what it would be for a dry run
+// A dry run reports the version the changes were compared against, an applied import the resulting configuration
+type ImportPackSizesResponse struct {
+	DryRun         bool                     `json:"dry_run"`
+	Changed        bool                     `json:"changed"`
+	Added          []int                    `json:"added"`
+	Removed        []int                    `json:"removed"`
+	CurrentVersion int                      `json:"current_version,omitempty"`
+	Warnings       []PackSizeWarning        `json:"warnings,omitempty"`
+	Configuration  *UpdatePackSizesResponse `json:"configuration,omitempty"`
+}
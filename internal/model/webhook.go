@@ -0,0 +1,74 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookSubscription is an endpoint that receives signed configuration change events
+type WebhookSubscription struct {
+	ID          int    `json:"id" db:"id"`
+	URL         string `json:"url" db:"url"`
+	Description string `json:"description,omitempty" db:"description"`
+	Active      bool   `json:"active" db:"active"`
+	// Secret signs the deliveries, it is only returned when it is created or rotated
+	Secret    string    `json:"secret,omitempty" db:"secret"`
+	CreatedBy string    `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateWebhookRequest represents a request to subscribe an endpoint to configuration changes
+// A secret is generated when none is given
+type CreateWebhookRequest struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+	Secret      string `json:"secret,omitempty"`
+	CreatedBy   string `json:"created_by"`
+}
+
+// UpdateWebhookRequest represents a request to change a subscription, omitted fields are kept
+type UpdateWebhookRequest struct {
+	URL          *string `json:"url,omitempty"`
+	Description  *string `json:"description,omitempty"`
+	Active       *bool   `json:"active,omitempty"`
+	RotateSecret bool    `json:"rotate_secret,omitempty"`
+}
+
+// WebhookDeliveryStatus represents the state of a delivery to a subscription
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryDead marks deliveries that ran out of attempts, they stay in the dead-letter list until retried
+	WebhookDeliveryDead WebhookDeliveryStatus = "dead"
+)
+
+// WebhookDelivery is the delivery of one event to one subscription and the outcome of its last attempt
+type WebhookDelivery struct {
+	ID                 int64                 `json:"id" db:"id"`
+	SubscriptionID     int                   `json:"subscription_id" db:"subscription_id"`
+	EventID            int64                 `json:"event_id" db:"event_id"`
+	EventType          string                `json:"event_type" db:"event_type"`
+	Status             WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts           int                   `json:"attempts" db:"attempts"`
+	LastError          string                `json:"last_error,omitempty" db:"last_error"`
+	LastResponseStatus *int                  `json:"last_response_status,omitempty" db:"last_response_status"`
+	NextAttemptAt      time.Time             `json:"next_attempt_at" db:"next_attempt_at"`
+	CreatedAt          time.Time             `json:"created_at" db:"created_at"`
+	DeliveredAt        *time.Time            `json:"delivered_at,omitempty" db:"delivered_at"`
+
+	// Payload is the exact body sent on every attempt
+	Payload json.RawMessage `json:"-" db:"payload"`
+	// URL and Secret of the subscription, only set on claimed deliveries
+	URL    string `json:"-" db:"-"`
+	Secret string `json:"-" db:"-"`
+}
+
+// WebhookDeliveryFilter selects deliveries, empty fields match everything
+type WebhookDeliveryFilter struct {
+	SubscriptionID int
+	Status         WebhookDeliveryStatus
+	Limit          int
+}
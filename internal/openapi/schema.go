@@ -0,0 +1,162 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Generator derives schemas from Go types the way encoding/json marshals them
+// Named structs become schemas of the components and are referenced, so the document follows the models
+type Generator struct {
+	schemas map[string]*Schema
+	types   map[string]reflect.Type
+	enums   map[reflect.Type][]any
+}
+
+// NewGenerator creates a generator without schemas
+func NewGenerator() *Generator {
+	return &Generator{
+		schemas: make(map[string]*Schema),
+		types:   make(map[string]reflect.Type),
+		enums:   make(map[reflect.Type][]any),
+	}
+}
+
+// Enum restricts the values of the type of value to values, e.g. the constants of a named string type
+func (g *Generator) Enum(value any, values ...any) {
+	g.enums[reflect.TypeOf(value)] = values
+}
+
+// Schema returns the schema of the type of value, a reference for named structs
+func (g *Generator) Schema(value any) *Schema {
+	return g.schemaOf(reflect.TypeOf(value))
+}
+
+// Define adds a schema to the components under name, for values without a Go type of their own
+func (g *Generator) Define(name string, schema *Schema) *Schema {
+	g.schemas[name] = schema
+	return SchemaRef(name)
+}
+
+// Schemas returns the schemas of the components generated so far
+func (g *Generator) Schemas() map[string]*Schema {
+	return g.schemas
+}
+
+// schemaOf returns the schema of t
+func (g *Generator) schemaOf(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		return g.schemaOf(t.Elem())
+	}
+	schema := g.typeSchema(t)
+	if values, ok := g.enums[t]; ok {
+		schema.Enum = values
+	}
+	return schema
+}
+
+// typeSchema returns the schema of the kind of t
+func (g *Generator) typeSchema(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return g.structSchema(t)
+	default:
+		// interfaces hold any value
+		return &Schema{}
+	}
+}
+
+// structSchema defines the schema of a named struct once and references it, anonymous structs are inlined
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	name := t.Name()
+	if name == "" {
+		return g.objectSchema(t)
+	}
+	if existing, ok := g.types[name]; ok {
+		if existing != t {
+			panic(fmt.Sprintf("openapi: schema name %s is used by %s and %s", name, existing, t))
+		}
+		return SchemaRef(name)
+	}
+
+	// register before descending, so recursive types end in a reference
+	g.types[name] = t
+	g.schemas[name] = &Schema{}
+	*g.schemas[name] = *g.objectSchema(t)
+	return SchemaRef(name)
+}
+
+// objectSchema returns the properties of the exported fields of t by their json names
+// Fields without omitempty are always present, so they are required
+func (g *Generator) objectSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		// embedded structs without a name of their own are flattened like encoding/json does
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			embedded := g.objectSchema(field.Type)
+			for property, propertySchema := range embedded.Properties {
+				schema.Properties[property] = propertySchema
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+
+		property := g.schemaOf(field.Type)
+		if field.Type.Kind() == reflect.Pointer && property.Ref == "" {
+			property.Nullable = true
+		}
+		schema.Properties[name] = property
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
@@ -0,0 +1,182 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/app"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+)
+
+// maxRetryBackoff caps the exponential backoff between delivery attempts
+const maxRetryBackoff = time.Hour
+
+// Relay delivers the events of the outbox to the sinks
+// It polls for due events and can be woken up early, e.g. by a change notification
+// Several instances may run concurrently, each event is leased by one relay at a time
+type Relay struct {
+	app.AbstractComponent
+	store repository.OutboxRepository
+	sinks []Sink
+	cfg   config.OutboxConfig
+
+	wake   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRelay creates a relay that delivers the events of store to sinks
+func NewRelay(store repository.OutboxRepository, sinks []Sink, cfg config.OutboxConfig) *Relay {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Relay{
+		store:  store,
+		sinks:  sinks,
+		cfg:    cfg,
+		wake:   make(chan struct{}, 1),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+}
+
+// Wake makes the relay look for due events right away instead of waiting for the next poll
+func (r *Relay) Wake() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run relays due events until Close
+func (r *Relay) Run() error {
+	defer close(r.done)
+	slog.Info("outbox relay started", slog.Int("sinks", len(r.sinks)))
+
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		r.relayPending(r.ctx)
+
+		select {
+		case <-r.ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-r.wake:
+		}
+	}
+}
+
+// Close stops the relay and waits for the current batch, unfinished events are retried after their lease
+func (r *Relay) Close(ctx context.Context) error {
+	slog.InfoContext(ctx, "closing outbox relay")
+	r.cancel()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// relayPending delivers batches of due events until there are no more
+func (r *Relay) relayPending(ctx context.Context) {
+	// a batch is delivered sequentially, so the lease has to cover every delivery in it
+	lease := r.cfg.DeliveryTimeout*time.Duration(len(r.sinks)*r.cfg.BatchSize) + r.cfg.PollInterval
+
+	for ctx.Err() == nil {
+		events, err := r.store.ClaimOutboxEvents(ctx, r.cfg.BatchSize, lease)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.ErrorContext(ctx, "failed to claim outbox events", slog.String("error", err.Error()))
+			}
+			return
+		}
+
+		for _, event := range events {
+			delivery := r.deliver(ctx, event)
+			if err := r.store.RecordOutboxDelivery(ctx, event.ID, delivery); err != nil {
+				slog.ErrorContext(ctx, "failed to record outbox delivery",
+					slog.Int64("event_id", event.ID),
+					slog.String("error", err.Error()),
+				)
+			}
+		}
+
+		if len(events) < r.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// deliver sends the event to every sink that has not accepted it yet and returns the outcome
+func (r *Relay) deliver(ctx context.Context, event *model.OutboxEvent) repository.OutboxDelivery {
+	delivered := slices.Clone(event.DeliveredSinks)
+	var failures []string
+
+	for _, sink := range r.sinks {
+		if slices.Contains(delivered, sink.Name()) {
+			continue
+		}
+
+		sinkCtx, cancel := context.WithTimeout(ctx, r.cfg.DeliveryTimeout)
+		err := sink.Deliver(sinkCtx, event)
+		cancel()
+		if err != nil {
+			failures = append(failures, sink.Name()+": "+err.Error())
+			continue
+		}
+		delivered = append(delivered, sink.Name())
+	}
+
+	if len(failures) == 0 {
+		slog.DebugContext(ctx, "outbox event delivered",
+			slog.Int64("event_id", event.ID),
+			slog.String("type", event.Type),
+		)
+		return repository.OutboxDelivery{Status: model.OutboxStatusDelivered, DeliveredSinks: delivered}
+	}
+
+	lastError := strings.Join(failures, "; ")
+	if event.Attempts >= r.cfg.MaxAttempts {
+		slog.ErrorContext(ctx, "outbox event delivery failed, giving up",
+			slog.Int64("event_id", event.ID),
+			slog.Int("attempts", event.Attempts),
+			slog.String("error", lastError),
+		)
+		return repository.OutboxDelivery{Status: model.OutboxStatusFailed, DeliveredSinks: delivered, LastError: lastError}
+	}
+
+	retryAfter := retryBackoff(r.cfg.RetryBackoff, event.Attempts)
+	slog.WarnContext(ctx, "outbox event delivery failed, retrying",
+		slog.Int64("event_id", event.ID),
+		slog.Int("attempts", event.Attempts),
+		slog.Duration("retry_after", retryAfter),
+		slog.String("error", lastError),
+	)
+	return repository.OutboxDelivery{
+		Status:         model.OutboxStatusPending,
+		DeliveredSinks: delivered,
+		LastError:      lastError,
+		RetryAfter:     retryAfter,
+	}
+}
+
+// retryBackoff doubles base for every attempt made so far, capped at maxRetryBackoff
+func retryBackoff(base time.Duration, attempts int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	backoff := base << min(max(attempts-1, 0), 16)
+	if backoff > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,214 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore is an in-memory outbox that hands out pending events once per claim
+type fakeStore struct {
+	mu         sync.Mutex
+	events     []*model.OutboxEvent
+	deliveries map[int64][]repository.OutboxDelivery
+}
+
+func newFakeStore(events ...*model.OutboxEvent) *fakeStore {
+	return &fakeStore{events: events, deliveries: make(map[int64][]repository.OutboxDelivery)}
+}
+
+func (s *fakeStore) ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var claimed []*model.OutboxEvent
+	for _, event := range s.events {
+		if event.Status != model.OutboxStatusPending || event.NextAttemptAt.After(time.Now()) || len(claimed) == limit {
+			continue
+		}
+		event.Attempts++
+		event.NextAttemptAt = time.Now().Add(lease)
+		copied := *event
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+func (s *fakeStore) RecordOutboxDelivery(ctx context.Context, id int64, delivery repository.OutboxDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, event := range s.events {
+		if event.ID == id {
+			event.Status = delivery.Status
+			event.DeliveredSinks = delivery.DeliveredSinks
+			event.LastError = delivery.LastError
+			event.NextAttemptAt = time.Now().Add(delivery.RetryAfter)
+			s.deliveries[id] = append(s.deliveries[id], delivery)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (s *fakeStore) event(id int64) model.OutboxEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range s.events {
+		if event.ID == id {
+			return *event
+		}
+	}
+	return model.OutboxEvent{}
+}
+
+// fakeSink records delivered event IDs and fails the first failures deliveries
+type fakeSink struct {
+	name      string
+	mu        sync.Mutex
+	failures  int
+	delivered []int64
+}
+
+func (s *fakeSink) Name() string { return s.name }
+
+func (s *fakeSink) Deliver(ctx context.Context, event *model.OutboxEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("downstream unavailable")
+	}
+	s.delivered = append(s.delivered, event.ID)
+	return nil
+}
+
+func (s *fakeSink) deliveredIDs() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int64{}, s.delivered...)
+}
+
+func pendingEvent(id int64) *model.OutboxEvent {
+	return &model.OutboxEvent{
+		ID:      id,
+		Type:    model.EventPackSizesChanged,
+		Payload: []byte(`{"version": 2}`),
+		Status:  model.OutboxStatusPending,
+	}
+}
+
+var testOutboxConfig = config.OutboxConfig{
+	PollInterval:    time.Hour,
+	BatchSize:       2,
+	MaxAttempts:     3,
+	DeliveryTimeout: time.Second,
+}
+
+func TestRelay_DeliversAllDueEvents(t *testing.T) {
+	store := newFakeStore(pendingEvent(1), pendingEvent(2), pendingEvent(3))
+	webhook := &fakeSink{name: "webhook"}
+	stdout := &fakeSink{name: "stdout"}
+	relay := NewRelay(store, []Sink{webhook, stdout}, testOutboxConfig)
+
+	relay.relayPending(context.Background())
+
+	// more events than the batch size are delivered in one pass
+	assert.Equal(t, []int64{1, 2, 3}, webhook.deliveredIDs())
+	assert.Equal(t, []int64{1, 2, 3}, stdout.deliveredIDs())
+	for id := int64(1); id <= 3; id++ {
+		event := store.event(id)
+		assert.Equal(t, model.OutboxStatusDelivered, event.Status)
+		assert.Equal(t, []string{"webhook", "stdout"}, event.DeliveredSinks)
+	}
+}
+
+func TestRelay_RetriesOnlyFailedSinks(t *testing.T) {
+	store := newFakeStore(pendingEvent(1))
+	webhook := &fakeSink{name: "webhook", failures: 1}
+	stdout := &fakeSink{name: "stdout"}
+	cfg := testOutboxConfig
+	cfg.RetryBackoff = 0
+	relay := NewRelay(store, []Sink{webhook, stdout}, cfg)
+
+	relay.relayPending(context.Background())
+	event := store.event(1)
+	assert.Equal(t, model.OutboxStatusPending, event.Status)
+	assert.Equal(t, []string{"stdout"}, event.DeliveredSinks)
+	assert.Contains(t, event.LastError, "webhook: downstream unavailable")
+
+	relay.relayPending(context.Background())
+	event = store.event(1)
+	assert.Equal(t, model.OutboxStatusDelivered, event.Status)
+	assert.Equal(t, 2, event.Attempts)
+	assert.Equal(t, []int64{1}, webhook.deliveredIDs())
+	assert.Equal(t, []int64{1}, stdout.deliveredIDs(), "stdout must not get the event twice")
+}
+
+func TestRelay_GivesUpAfterMaxAttempts(t *testing.T) {
+	store := newFakeStore(pendingEvent(1))
+	webhook := &fakeSink{name: "webhook", failures: 100}
+	cfg := testOutboxConfig
+	cfg.RetryBackoff = 0
+	relay := NewRelay(store, []Sink{webhook}, cfg)
+
+	for range 5 {
+		relay.relayPending(context.Background())
+	}
+
+	event := store.event(1)
+	assert.Equal(t, model.OutboxStatusFailed, event.Status)
+	assert.Equal(t, 3, event.Attempts)
+	assert.Len(t, store.deliveries[1], 3)
+}
+
+func TestRelay_BacksOffBetweenAttempts(t *testing.T) {
+	store := newFakeStore(pendingEvent(1))
+	cfg := testOutboxConfig
+	cfg.RetryBackoff = time.Minute
+	relay := NewRelay(store, []Sink{&fakeSink{name: "webhook", failures: 1}}, cfg)
+
+	relay.relayPending(context.Background())
+	relay.relayPending(context.Background())
+
+	// the second pass finds nothing due
+	require.Len(t, store.deliveries[1], 1)
+	assert.Equal(t, time.Minute, store.deliveries[1][0].RetryAfter)
+}
+
+func TestRelay_RunAndWake(t *testing.T) {
+	store := newFakeStore()
+	sink := &fakeSink{name: "stdout"}
+	relay := NewRelay(store, []Sink{sink}, testOutboxConfig)
+
+	go func() { _ = relay.Run() }()
+
+	// an event committed after the first poll is picked up when the relay is woken
+	store.mu.Lock()
+	store.events = append(store.events, pendingEvent(7))
+	store.mu.Unlock()
+	relay.Wake()
+
+	assert.Eventually(t, func() bool {
+		return len(sink.deliveredIDs()) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, relay.Close(ctx))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	assert.Equal(t, 5*time.Second, retryBackoff(5*time.Second, 1))
+	assert.Equal(t, 20*time.Second, retryBackoff(5*time.Second, 3))
+	assert.Equal(t, maxRetryBackoff, retryBackoff(5*time.Second, 50))
+	assert.Zero(t, retryBackoff(0, 3))
+}
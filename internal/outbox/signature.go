@@ -0,0 +1,66 @@
+package outbox
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the HMAC signature of a webhook delivery
+const SignatureHeader = "X-Packman-Signature"
+
+// ErrInvalidSignature indicates a signature header that does not match the body
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// SignPayload returns the signature header value for body sent at timestamp
+// The format is "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<unix seconds>.<body>">", as used by common webhook providers
+func SignPayload(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + ts + ",v1=" + computeSignature(secret, ts, body)
+}
+
+// VerifySignature checks a signature header produced by SignPayload
+// Signatures older than tolerance are rejected to limit replays, a tolerance of zero disables the check
+func VerifySignature(secret string, header string, body []byte, tolerance time.Duration) error {
+	var ts, signature string
+	for part := range strings.SplitSeq(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			signature = value
+		}
+	}
+	if ts == "" || signature == "" {
+		return ErrInvalidSignature
+	}
+
+	if tolerance > 0 {
+		unix, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return ErrInvalidSignature
+		}
+		if age := time.Since(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+			return ErrInvalidSignature
+		}
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(computeSignature(secret, ts, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// computeSignature returns the hex HMAC-SHA256 of "<ts>.<body>"
+func computeSignature(secret string, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
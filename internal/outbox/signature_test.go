@@ -0,0 +1,59 @@
+package outbox
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignPayload(t *testing.T) {
+	// a known vector keeps the format stable for receivers implementing the verification themselves
+	assert.Equal(t,
+		"t=1792310400,v1=dab7b7bf8f1bbfd66e30d8ed5d1d01de08a6031c90014f363b05d0efe9b55378",
+		SignPayload("whsec_test", time.Unix(1792310400, 0), []byte(`{"id":1}`)))
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	header := SignPayload("whsec_test", time.Now(), body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		header    string
+		body      []byte
+		tolerance time.Duration
+		wantErr   bool
+	}{
+		{name: "valid", secret: "whsec_test", header: header, body: body, tolerance: time.Minute},
+		{name: "wrong secret", secret: "whsec_other", header: header, body: body, wantErr: true},
+		{name: "tampered body", secret: "whsec_test", header: header, body: []byte(`{"id":2}`), wantErr: true},
+		{name: "missing signature", secret: "whsec_test", header: "t=1792310400", body: body, wantErr: true},
+		{
+			name:      "too old",
+			secret:    "whsec_test",
+			header:    SignPayload("whsec_test", time.Now().Add(-time.Hour), body),
+			body:      body,
+			tolerance: time.Minute,
+			wantErr:   true,
+		},
+		{
+			name:   "old signatures are accepted without tolerance",
+			secret: "whsec_test",
+			header: SignPayload("whsec_test", time.Now().Add(-time.Hour), body),
+			body:   body,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignature(tt.secret, tt.header, tt.body, tt.tolerance)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSignature)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// Sink delivers events to a downstream system
+// Delivery is at least once, so Deliver may be called again with an event it already accepted
+type Sink interface {
+	// Name identifies the sink in the delivery status of events, it must not change between releases
+	Name() string
+	Deliver(ctx context.Context, event *model.OutboxEvent) error
+}
+
+// Envelope is the document delivered by every sink
+type Envelope struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// NewEnvelope wraps the payload of event with its metadata
+func NewEnvelope(event *model.OutboxEvent) Envelope {
+	return Envelope{
+		ID:        event.ID,
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Payload,
+	}
+}
+
+// NewSinks creates the sinks listed in cfg.Sinks
+func NewSinks(cfg config.OutboxConfig) ([]Sink, error) {
+	sinks := make([]Sink, 0, len(cfg.Sinks))
+	for _, name := range cfg.Sinks {
+		switch name {
+		case config.OutboxSinkWebhook:
+			sinks = append(sinks, NewWebhookSink(cfg.WebhookURL, cfg.DeliveryTimeout, cfg.WebhookAllowPrivateNetworks))
+		case config.OutboxSinkFile:
+			sink, err := NewFileSink(cfg.FilePath)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		case config.OutboxSinkStdout:
+			sinks = append(sinks, NewWriterSink(config.OutboxSinkStdout, os.Stdout))
+		default:
+			return nil, fmt.Errorf("unknown outbox sink %q", name)
+		}
+	}
+	return sinks, nil
+}
+
+// webhookSink POSTs events as JSON to a URL, any non-2xx response is a failed delivery
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink creates a sink that POSTs events to url
+// Internal addresses are refused unless allowPrivateNetworks is set, see newWebhookClient
+func NewWebhookSink(url string, timeout time.Duration, allowPrivateNetworks bool) Sink {
+	return &webhookSink{
+		url:    url,
+		client: newWebhookClient(timeout, allowPrivateNetworks),
+	}
+}
+
+// Name returns the name of the sink
+func (s *webhookSink) Name() string {
+	return config.OutboxSinkWebhook
+}
+
+// Deliver POSTs the event envelope, the event ID header lets receivers drop duplicates
+func (s *webhookSink) Deliver(ctx context.Context, event *model.OutboxEvent) error {
+	body, err := json.Marshal(NewEnvelope(event))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", strconv.FormatInt(event.ID, 10))
+	req.Header.Set("X-Event-Type", event.Type)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// fileSink appends events as JSON lines to a file
+type fileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileSink creates a sink that appends events to the JSON lines file at path
+func NewFileSink(path string) (Sink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create outbox file directory: %w", err)
+	}
+	return &fileSink{path: path}, nil
+}
+
+// Name returns the name of the sink
+func (s *fileSink) Name() string {
+	return config.OutboxSinkFile
+}
+
+// Deliver appends the event envelope as a single line and syncs it to disk
+func (s *fileSink) Deliver(ctx context.Context, event *model.OutboxEvent) error {
+	line, err := json.Marshal(NewEnvelope(event))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writerSink writes events as JSON lines to a writer such as stdout
+type writerSink struct {
+	name string
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+// NewWriterSink creates a sink that writes events as JSON lines to w
+func NewWriterSink(name string, w io.Writer) Sink {
+	return &writerSink{name: name, w: w}
+}
+
+// Name returns the name of the sink
+func (s *writerSink) Name() string {
+	return s.name
+}
+
+// Deliver writes the event envelope as a single line
+func (s *writerSink) Deliver(ctx context.Context, event *model.OutboxEvent) error {
+	line, err := json.Marshal(NewEnvelope(event))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
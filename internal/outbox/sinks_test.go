@@ -0,0 +1,128 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSink(t *testing.T) {
+	event := pendingEvent(42)
+	event.CreatedAt = time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	t.Run("posts the envelope", func(t *testing.T) {
+		var received Envelope
+		var headers http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers = r.Header
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &received)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		sink := NewWebhookSink(server.URL, time.Second, true)
+		require.NoError(t, sink.Deliver(context.Background(), event))
+
+		assert.Equal(t, "application/json", headers.Get("Content-Type"))
+		assert.Equal(t, "42", headers.Get("X-Event-ID"))
+		assert.Equal(t, model.EventPackSizesChanged, headers.Get("X-Event-Type"))
+		assert.Equal(t, int64(42), received.ID)
+		assert.Equal(t, event.CreatedAt, received.CreatedAt)
+		assert.JSONEq(t, `{"version": 2}`, string(received.Data))
+	})
+	t.Run("non 2xx response fails the delivery", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		err := NewWebhookSink(server.URL, time.Second, true).Deliver(context.Background(), event)
+		assert.ErrorContains(t, err, "502")
+	})
+	t.Run("internal addresses are refused", func(t *testing.T) {
+		delivered := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			delivered = true
+		}))
+		defer server.Close()
+
+		err := NewWebhookSink(server.URL, time.Second, false).Deliver(context.Background(), event)
+		assert.ErrorIs(t, err, ErrAddressNotAllowed)
+		assert.False(t, delivered)
+	})
+	t.Run("redirects are not followed", func(t *testing.T) {
+		redirected := false
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			redirected = true
+		}))
+		defer target.Close()
+		server := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+		defer server.Close()
+
+		err := NewWebhookSink(server.URL, time.Second, true).Deliver(context.Background(), event)
+		assert.ErrorContains(t, err, "307")
+		assert.False(t, redirected)
+	})
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events", "pack-configuration-events.jsonl")
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Deliver(context.Background(), pendingEvent(1)))
+	require.NoError(t, sink.Deliver(context.Background(), pendingEvent(2)))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var envelope Envelope
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &envelope))
+	assert.Equal(t, int64(2), envelope.ID)
+	assert.Equal(t, model.EventPackSizesChanged, envelope.Type)
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(config.OutboxSinkStdout, &buf)
+
+	require.NoError(t, sink.Deliver(context.Background(), pendingEvent(3)))
+	assert.Equal(t, config.OutboxSinkStdout, sink.Name())
+	assert.True(t, strings.HasSuffix(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), `"type":"pack_sizes.changed"`)
+}
+
+func TestNewSinks(t *testing.T) {
+	sinks, err := NewSinks(config.OutboxConfig{
+		Sinks:           []string{config.OutboxSinkWebhook, config.OutboxSinkFile, config.OutboxSinkStdout},
+		WebhookURL:      "http://localhost/hook",
+		FilePath:        filepath.Join(t.TempDir(), "events.jsonl"),
+		DeliveryTimeout: time.Second,
+	})
+	require.NoError(t, err)
+
+	var names []string
+	for _, sink := range sinks {
+		names = append(names, sink.Name())
+	}
+	assert.Equal(t, []string{"webhook", "file", "stdout"}, names)
+
+	_, err = NewSinks(config.OutboxConfig{Sinks: []string{"kafka"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrAddressNotAllowed indicates a webhook host resolved to an address of a private network
+var ErrAddressNotAllowed = errors.New("webhook address is not allowed")
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, not covered by netip.Addr.IsPrivate
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// newWebhookClient creates the client webhooks are POSTed with
+// Unless allowPrivateNetworks is set, connections to loopback, link-local (such as the 169.254.169.254 metadata
+// endpoint), private and other internal addresses are refused. The address is checked when connecting, after the
+// host was resolved, so a host that resolves to an internal address at delivery time is refused as well.
+// Redirects are not followed, a redirect response is a failed delivery
+func newWebhookClient(timeout time.Duration, allowPrivateNetworks bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivateNetworks {
+		dialer.Control = checkWebhookAddress
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would be dialed instead of the webhook host and hide its address
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkWebhookAddress refuses to connect to internal addresses, it runs for every address a host resolves to
+func checkWebhookAddress(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, address)
+	}
+	if !isPublicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, addrPort.Addr())
+	}
+	return nil
+}
+
+// isPublicAddress reports whether addr may be reached from the internet
+func isPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!sharedAddressSpace.Contains(addr)
+}
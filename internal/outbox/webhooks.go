@@ -0,0 +1,227 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/app"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+)
+
+// Headers of webhook deliveries besides SignatureHeader
+const (
+	EventTypeHeader  = "X-Packman-Event"
+	EventIDHeader    = "X-Packman-Event-ID"
+	DeliveryIDHeader = "X-Packman-Delivery"
+)
+
+// subscriptionsSinkName identifies the subscriptions sink in the delivered sinks of outbox events
+const subscriptionsSinkName = "subscriptions"
+
+// subscriptionsSink fans events out into one pending delivery per active webhook subscription
+// The deliveries themselves are made by the WebhookDispatcher, so a slow subscriber does not hold up the outbox
+type subscriptionsSink struct {
+	store repository.WebhookRepository
+	wake  func()
+}
+
+// NewSubscriptionsSink creates a sink that enqueues events for the webhook subscriptions in store
+// wake is called after deliveries were enqueued, it may be nil
+func NewSubscriptionsSink(store repository.WebhookRepository, wake func()) Sink {
+	return &subscriptionsSink{store: store, wake: wake}
+}
+
+// Name returns the name of the sink
+func (s *subscriptionsSink) Name() string {
+	return subscriptionsSinkName
+}
+
+// Deliver enqueues the event envelope for every active subscription
+func (s *subscriptionsSink) Deliver(ctx context.Context, event *model.OutboxEvent) error {
+	payload, err := json.Marshal(NewEnvelope(event))
+	if err != nil {
+		return err
+	}
+
+	enqueued, err := s.store.EnqueueWebhookDeliveries(ctx, event, payload)
+	if err != nil {
+		return err
+	}
+	if enqueued > 0 && s.wake != nil {
+		s.wake()
+	}
+	return nil
+}
+
+// WebhookDispatcher delivers pending webhook deliveries to their subscriptions
+// Every request is signed with the secret of the subscription, see SignPayload
+// Failed deliveries are retried with exponential backoff and dead-lettered after the maximum number of attempts
+type WebhookDispatcher struct {
+	app.AbstractComponent
+	store  repository.WebhookRepository
+	client *http.Client
+	cfg    config.WebhookConfig
+	now    func() time.Time
+
+	wake   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewWebhookDispatcher creates a dispatcher for the deliveries in store
+func NewWebhookDispatcher(store repository.WebhookRepository, cfg config.WebhookConfig) *WebhookDispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &WebhookDispatcher{
+		store:  store,
+		client: newWebhookClient(cfg.Timeout, cfg.AllowPrivateNetworks),
+		cfg:    cfg,
+		now:    time.Now,
+		wake:   make(chan struct{}, 1),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+}
+
+// Wake makes the dispatcher look for due deliveries right away instead of waiting for the next poll
+func (d *WebhookDispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run dispatches due deliveries until Close
+func (d *WebhookDispatcher) Run() error {
+	defer close(d.done)
+	slog.Info("webhook dispatcher started")
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.dispatchPending(d.ctx)
+
+		select {
+		case <-d.ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// Close stops the dispatcher and waits for the current batch, unfinished deliveries are retried after their lease
+func (d *WebhookDispatcher) Close(ctx context.Context) error {
+	slog.InfoContext(ctx, "closing webhook dispatcher")
+	d.cancel()
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dispatchPending delivers batches of due deliveries until there are no more
+func (d *WebhookDispatcher) dispatchPending(ctx context.Context) {
+	// a batch is delivered sequentially, so the lease has to cover every request in it
+	lease := d.cfg.Timeout*time.Duration(d.cfg.BatchSize) + d.cfg.PollInterval
+
+	for ctx.Err() == nil {
+		deliveries, err := d.store.ClaimWebhookDeliveries(ctx, d.cfg.BatchSize, lease)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.ErrorContext(ctx, "failed to claim webhook deliveries", slog.String("error", err.Error()))
+			}
+			return
+		}
+
+		for _, delivery := range deliveries {
+			attempt := d.attempt(ctx, delivery)
+			if err := d.store.RecordWebhookAttempt(ctx, delivery.ID, attempt); err != nil {
+				slog.ErrorContext(ctx, "failed to record webhook attempt",
+					slog.Int64("delivery_id", delivery.ID),
+					slog.String("error", err.Error()),
+				)
+			}
+		}
+
+		if len(deliveries) < d.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// attempt POSTs the delivery to its subscription and returns the outcome
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery *model.WebhookDelivery) repository.WebhookAttempt {
+	status, err := d.post(ctx, delivery)
+	if err == nil {
+		slog.DebugContext(ctx, "webhook delivered",
+			slog.Int64("delivery_id", delivery.ID),
+			slog.Int("subscription_id", delivery.SubscriptionID),
+		)
+		return repository.WebhookAttempt{Status: model.WebhookDeliveryDelivered, ResponseStatus: status}
+	}
+
+	if delivery.Attempts >= d.cfg.MaxAttempts {
+		slog.ErrorContext(ctx, "webhook delivery failed, moving it to the dead letters",
+			slog.Int64("delivery_id", delivery.ID),
+			slog.Int("subscription_id", delivery.SubscriptionID),
+			slog.Int("attempts", delivery.Attempts),
+			slog.String("error", err.Error()),
+		)
+		return repository.WebhookAttempt{Status: model.WebhookDeliveryDead, LastError: err.Error(), ResponseStatus: status}
+	}
+
+	retryAfter := retryBackoff(d.cfg.RetryBackoff, delivery.Attempts)
+	slog.WarnContext(ctx, "webhook delivery failed, retrying",
+		slog.Int64("delivery_id", delivery.ID),
+		slog.Int("subscription_id", delivery.SubscriptionID),
+		slog.Int("attempts", delivery.Attempts),
+		slog.Duration("retry_after", retryAfter),
+		slog.String("error", err.Error()),
+	)
+	return repository.WebhookAttempt{
+		Status:         model.WebhookDeliveryPending,
+		LastError:      err.Error(),
+		ResponseStatus: status,
+		RetryAfter:     retryAfter,
+	}
+}
+
+// post sends the signed payload and returns the response status, zero when no response was received
+func (d *WebhookDispatcher) post(ctx context.Context, delivery *model.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventTypeHeader, delivery.EventType)
+	req.Header.Set(EventIDHeader, strconv.FormatInt(delivery.EventID, 10))
+	req.Header.Set(DeliveryIDHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(SignatureHeader, SignPayload(delivery.Secret, d.now(), delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,256 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWebhookStore is an in-memory delivery queue for a single subscription
+// Methods the dispatcher and the subscriptions sink do not use panic through the nil embedded interface
+type fakeWebhookStore struct {
+	repository.WebhookRepository
+	mu         sync.Mutex
+	url        string
+	secret     string
+	deliveries []*model.WebhookDelivery
+}
+
+func (s *fakeWebhookStore) EnqueueWebhookDeliveries(ctx context.Context, event *model.OutboxEvent, payload []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, delivery := range s.deliveries {
+		if delivery.EventID == event.ID {
+			return 0, nil
+		}
+	}
+	s.deliveries = append(s.deliveries, &model.WebhookDelivery{
+		ID:             int64(len(s.deliveries) + 1),
+		SubscriptionID: 1,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Status:         model.WebhookDeliveryPending,
+		Payload:        payload,
+	})
+	return 1, nil
+}
+
+func (s *fakeWebhookStore) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var claimed []*model.WebhookDelivery
+	for _, delivery := range s.deliveries {
+		if delivery.Status != model.WebhookDeliveryPending || delivery.NextAttemptAt.After(time.Now()) || len(claimed) == limit {
+			continue
+		}
+		delivery.Attempts++
+		delivery.NextAttemptAt = time.Now().Add(lease)
+		copied := *delivery
+		copied.URL = s.url
+		copied.Secret = s.secret
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+func (s *fakeWebhookStore) RecordWebhookAttempt(ctx context.Context, id int64, attempt repository.WebhookAttempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, delivery := range s.deliveries {
+		if delivery.ID == id {
+			delivery.Status = attempt.Status
+			delivery.LastError = attempt.LastError
+			if attempt.ResponseStatus != 0 {
+				status := attempt.ResponseStatus
+				delivery.LastResponseStatus = &status
+			}
+			delivery.NextAttemptAt = time.Now().Add(attempt.RetryAfter)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (s *fakeWebhookStore) delivery(id int64) model.WebhookDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.deliveries[id-1]
+}
+
+var testWebhookConfig = config.WebhookConfig{
+	PollInterval: time.Hour,
+	BatchSize:    10,
+	MaxAttempts:  3,
+	Timeout:      time.Second,
+	// the receivers listen on loopback
+	AllowPrivateNetworks: true,
+}
+
+// receiver is a local webhook endpoint that verifies signatures and fails the first failures requests
+type receiver struct {
+	mu       sync.Mutex
+	secret   string
+	failures int
+	received []Envelope
+	headers  []http.Header
+	invalid  int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := VerifySignature(r.secret, req.Header.Get(SignatureHeader), body, time.Minute); err != nil {
+		r.invalid++
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var envelope Envelope
+	_ = json.Unmarshal(body, &envelope)
+	r.received = append(r.received, envelope)
+	r.headers = append(r.headers, req.Header.Clone())
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestWebhookDispatcher_DeliversSignedPayloads(t *testing.T) {
+	recv := &receiver{secret: "whsec_test"}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	store := &fakeWebhookStore{url: server.URL, secret: "whsec_test"}
+	dispatcher := NewWebhookDispatcher(store, testWebhookConfig)
+
+	var woken atomic.Bool
+	sink := NewSubscriptionsSink(store, func() { woken.Store(true) })
+	event := pendingEvent(42)
+	require.NoError(t, sink.Deliver(context.Background(), event))
+	// the relay may deliver an event again, it is only enqueued once
+	require.NoError(t, sink.Deliver(context.Background(), event))
+	assert.True(t, woken.Load())
+
+	dispatcher.dispatchPending(context.Background())
+
+	require.Len(t, recv.received, 1)
+	assert.Zero(t, recv.invalid)
+	assert.Equal(t, int64(42), recv.received[0].ID)
+	assert.JSONEq(t, `{"version": 2}`, string(recv.received[0].Data))
+	assert.Equal(t, model.EventPackSizesChanged, recv.headers[0].Get(EventTypeHeader))
+	assert.Equal(t, "42", recv.headers[0].Get(EventIDHeader))
+	assert.Equal(t, "1", recv.headers[0].Get(DeliveryIDHeader))
+
+	delivery := store.delivery(1)
+	assert.Equal(t, model.WebhookDeliveryDelivered, delivery.Status)
+	assert.Equal(t, http.StatusOK, *delivery.LastResponseStatus)
+}
+
+func TestWebhookDispatcher_RetriesWithBackoff(t *testing.T) {
+	recv := &receiver{secret: "whsec_test", failures: 1}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	store := &fakeWebhookStore{url: server.URL, secret: "whsec_test"}
+	_, _ = store.EnqueueWebhookDeliveries(context.Background(), pendingEvent(1), []byte(`{}`))
+	cfg := testWebhookConfig
+	cfg.RetryBackoff = time.Minute
+	dispatcher := NewWebhookDispatcher(store, cfg)
+
+	dispatcher.dispatchPending(context.Background())
+	delivery := store.delivery(1)
+	assert.Equal(t, model.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, http.StatusServiceUnavailable, *delivery.LastResponseStatus)
+	assert.Contains(t, delivery.LastError, "503")
+	assert.WithinDuration(t, time.Now().Add(time.Minute), delivery.NextAttemptAt, 5*time.Second)
+
+	// not due yet
+	dispatcher.dispatchPending(context.Background())
+	assert.Empty(t, recv.received)
+
+	store.mu.Lock()
+	store.deliveries[0].NextAttemptAt = time.Time{}
+	store.mu.Unlock()
+	dispatcher.dispatchPending(context.Background())
+	assert.Len(t, recv.received, 1)
+	assert.Equal(t, model.WebhookDeliveryDelivered, store.delivery(1).Status)
+}
+
+func TestWebhookDispatcher_DeadLettersAfterMaxAttempts(t *testing.T) {
+	// the receiver rejects every request signed with the wrong secret
+	recv := &receiver{secret: "whsec_rotated"}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	store := &fakeWebhookStore{url: server.URL, secret: "whsec_test"}
+	_, _ = store.EnqueueWebhookDeliveries(context.Background(), pendingEvent(1), []byte(`{}`))
+	dispatcher := NewWebhookDispatcher(store, testWebhookConfig)
+
+	for range 5 {
+		dispatcher.dispatchPending(context.Background())
+	}
+
+	delivery := store.delivery(1)
+	assert.Equal(t, model.WebhookDeliveryDead, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Equal(t, 3, recv.invalid)
+	assert.Equal(t, http.StatusUnauthorized, *delivery.LastResponseStatus)
+}
+
+func TestWebhookDispatcher_UnreachableEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	store := &fakeWebhookStore{url: url, secret: "whsec_test"}
+	_, _ = store.EnqueueWebhookDeliveries(context.Background(), pendingEvent(1), []byte(`{}`))
+	NewWebhookDispatcher(store, testWebhookConfig).dispatchPending(context.Background())
+
+	delivery := store.delivery(1)
+	assert.Equal(t, model.WebhookDeliveryPending, delivery.Status)
+	assert.Nil(t, delivery.LastResponseStatus)
+	assert.NotEmpty(t, delivery.LastError)
+}
+
+func TestWebhookDispatcher_RunAndWake(t *testing.T) {
+	recv := &receiver{secret: "whsec_test"}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	store := &fakeWebhookStore{url: server.URL, secret: "whsec_test"}
+	dispatcher := NewWebhookDispatcher(store, testWebhookConfig)
+	go func() { _ = dispatcher.Run() }()
+
+	// a delivery enqueued after the first poll is picked up when the dispatcher is woken
+	sink := NewSubscriptionsSink(store, dispatcher.Wake)
+	require.NoError(t, sink.Deliver(context.Background(), pendingEvent(7)))
+
+	assert.Eventually(t, func() bool {
+		recv.mu.Lock()
+		defer recv.mu.Unlock()
+		return len(recv.received) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, dispatcher.Close(ctx))
+}
@@ -0,0 +1,162 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/app"
+	"github.com/nsaltun/packman/internal/repository"
+)
+
+// defaultRoute is the bucket name shared by the routes without a limit of their own
+const defaultRoute = "default"
+
+// addressBucket is the bucket name of the limit per IP address checked before authentication
+const addressBucket = "address"
+
+// Decision is the outcome of a request against its limit
+type Decision struct {
+	// Allowed reports whether the request may proceed
+	Allowed bool
+	// Limit is the number of requests allowed per Window
+	Limit  int
+	Window time.Duration
+	// Remaining is the number of requests that can be made right away
+	Remaining int
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, zero when Allowed
+	RetryAfter time.Duration
+}
+
+// Limiter applies the configured token bucket limits per route and client
+// Each client has a bucket per route with its own limit and one shared by all other routes.
+// Idle buckets are removed periodically until Close
+type Limiter struct {
+	app.AbstractComponent
+	store repository.RateLimitRepository
+	cfg   config.RateLimitConfig
+	idle  time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewLimiter creates a limiter that keeps its buckets in store
+func NewLimiter(store repository.RateLimitRepository, cfg config.RateLimitConfig) *Limiter {
+	// a bucket not used for the longest period is full again, removing it changes nothing
+	idle := max(cfg.Default.Period, cfg.Address.Period)
+	for _, limit := range cfg.Routes {
+		idle = max(idle, limit.Period)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Limiter{
+		store:  store,
+		cfg:    cfg,
+		idle:   idle,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+}
+
+// Allow takes a token from the bucket of client for route, route is "METHOD /path" as registered
+// The request is allowed when the store fails, an outage of the store should not take the API down with it
+func (l *Limiter) Allow(ctx context.Context, route, client string) Decision {
+	name, limit := defaultRoute, l.cfg.Default
+	if routeLimit, ok := l.cfg.Routes[route]; ok {
+		name, limit = route, routeLimit
+	}
+	return l.take(ctx, name, client, limit)
+}
+
+// AllowAddress takes a token from the bucket of an IP address, shared by all routes
+// It is checked before the credentials of a request, so requests with invalid credentials count as well
+func (l *Limiter) AllowAddress(ctx context.Context, address string) Decision {
+	return l.take(ctx, addressBucket, "ip:"+address, l.cfg.Address)
+}
+
+// take takes a token from the bucket name of client
+func (l *Limiter) take(ctx context.Context, name, client string, limit config.RateLimit) Decision {
+	rate := float64(limit.Requests) / limit.Period.Seconds()
+	bucket, err := l.store.TakeRateLimitToken(ctx, fmt.Sprintf("%s|%s", name, client), limit.Requests, rate)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to take rate limit token, allowing the request",
+			slog.String("route", name),
+			slog.String("error", err.Error()),
+		)
+		return Decision{Allowed: true, Limit: limit.Requests, Window: limit.Period, Remaining: limit.Requests}
+	}
+
+	decision := Decision{
+		Allowed:   bucket.Allowed,
+		Limit:     limit.Requests,
+		Window:    limit.Period,
+		Remaining: int(math.Floor(bucket.Tokens)),
+		Reset:     secondsToDuration((float64(limit.Requests) - bucket.Tokens) / rate),
+	}
+	if !bucket.Allowed {
+		decision.RetryAfter = secondsToDuration((1 - bucket.Tokens) / rate)
+	}
+	return decision
+}
+
+// Run removes idle buckets every cleanup interval until Close
+func (l *Limiter) Run() error {
+	defer close(l.done)
+	slog.Info("rate limiter started",
+		slog.String("backend", l.cfg.Backend),
+		slog.String("default", l.cfg.Default.String()),
+		slog.Int("routes", len(l.cfg.Routes)),
+	)
+
+	ticker := time.NewTicker(l.cfg.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.ctx.Done():
+			return nil
+		case <-ticker.C:
+			l.cleanup(l.ctx)
+		}
+	}
+}
+
+// Close stops the cleanup loop
+func (l *Limiter) Close(ctx context.Context) error {
+	slog.InfoContext(ctx, "closing rate limiter")
+	l.cancel()
+
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cleanup removes the buckets that are full again
+func (l *Limiter) cleanup(ctx context.Context) {
+	deleted, err := l.store.DeleteIdleRateLimitBuckets(ctx, l.idle)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.ErrorContext(ctx, "failed to delete idle rate limit buckets", slog.String("error", err.Error()))
+		}
+		return
+	}
+	if deleted > 0 {
+		slog.DebugContext(ctx, "deleted idle rate limit buckets", slog.Int("count", deleted))
+	}
+}
+
+// secondsToDuration converts fractional seconds to a duration, negative values are clamped to zero
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(max(seconds, 0) * float64(time.Second))
+}
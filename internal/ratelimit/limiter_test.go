@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingStore is a rate limit repository that is unavailable
+type failingStore struct{}
+
+func (failingStore) TakeRateLimitToken(ctx context.Context, key string, capacity int, rate float64) (repository.RateLimitBucket, error) {
+	return repository.RateLimitBucket{}, errors.New("connection refused")
+}
+
+func (failingStore) DeleteIdleRateLimitBuckets(ctx context.Context, idle time.Duration) (int, error) {
+	return 0, errors.New("connection refused")
+}
+
+func testConfig() config.RateLimitConfig {
+	return config.RateLimitConfig{
+		Enabled:         true,
+		Backend:         config.RateLimitBackendMemory,
+		Default:         config.RateLimit{Requests: 2, Period: time.Minute},
+		Address:         config.RateLimit{Requests: 3, Period: time.Minute},
+		Routes:          map[string]config.RateLimit{"POST /api/v1/calculate": {Requests: 1, Period: time.Hour}},
+		CleanupInterval: time.Minute,
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewLimiter(repository.NewMemoryRateLimitRepo(), testConfig())
+	assert.Equal(t, time.Hour, limiter.idle)
+
+	first := limiter.Allow(ctx, "GET /api/v1/pack-sizes", "ip:10.0.0.1")
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 2, first.Limit)
+	assert.Equal(t, time.Minute, first.Window)
+	assert.Equal(t, 1, first.Remaining)
+	assert.InDelta(t, 30*time.Second, first.Reset, float64(time.Second))
+
+	// routes without a limit of their own share the default bucket
+	second := limiter.Allow(ctx, "GET /api/v1/pack-sizes/history", "ip:10.0.0.1")
+	assert.True(t, second.Allowed)
+	assert.Equal(t, 0, second.Remaining)
+
+	rejected := limiter.Allow(ctx, "GET /api/v1/pack-sizes", "ip:10.0.0.1")
+	assert.False(t, rejected.Allowed)
+	assert.InDelta(t, 30*time.Second, rejected.RetryAfter, float64(time.Second))
+
+	// other clients and routes with their own limit are counted separately
+	assert.True(t, limiter.Allow(ctx, "GET /api/v1/pack-sizes", "ip:10.0.0.2").Allowed)
+	calculate := limiter.Allow(ctx, "POST /api/v1/calculate", "ip:10.0.0.1")
+	assert.True(t, calculate.Allowed)
+	assert.Equal(t, 1, calculate.Limit)
+	assert.Equal(t, time.Hour, calculate.Window)
+	assert.False(t, limiter.Allow(ctx, "POST /api/v1/calculate", "ip:10.0.0.1").Allowed)
+}
+
+func TestLimiter_AllowAddress(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewLimiter(repository.NewMemoryRateLimitRepo(), testConfig())
+
+	for range 3 {
+		assert.True(t, limiter.AllowAddress(ctx, "10.0.0.1").Allowed)
+	}
+	rejected := limiter.AllowAddress(ctx, "10.0.0.1")
+	assert.False(t, rejected.Allowed)
+	assert.Equal(t, 3, rejected.Limit)
+
+	// the address bucket is separate from the buckets of anonymous callers on routes
+	assert.True(t, limiter.Allow(ctx, "GET /api/v1/pack-sizes", "ip:10.0.0.1").Allowed)
+	assert.True(t, limiter.AllowAddress(ctx, "10.0.0.2").Allowed)
+}
+
+func TestLimiter_AllowsWhenStoreFails(t *testing.T) {
+	limiter := NewLimiter(failingStore{}, testConfig())
+
+	decision := limiter.Allow(context.Background(), "GET /api/v1/pack-sizes", "ip:10.0.0.1")
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 2, decision.Remaining)
+}
+
+func TestLimiter_RunUntilClose(t *testing.T) {
+	limiter := NewLimiter(failingStore{}, testConfig())
+
+	done := make(chan error)
+	go func() { done <- limiter.Run() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, limiter.Close(ctx))
+	assert.NoError(t, <-done)
+}
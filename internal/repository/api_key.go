@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// ErrAPIKeyNameTaken indicates another key, possibly revoked, already has the name
+var ErrAPIKeyNameTaken = errors.New("api key name is already taken")
+
+// APIKeyRepository defines the data access for API keys
+// Keys are looked up by the hex SHA-256 hash of the key, the key itself is never stored
+type APIKeyRepository interface {
+	// CreateAPIKey stores a new key with the hash of its secret
+	CreateAPIKey(ctx context.Context, key *model.APIKey, hash string) (*model.APIKey, error)
+
+	// GetAPIKeyByHash returns the key with the given hash, including revoked keys
+	GetAPIKeyByHash(ctx context.Context, hash string) (*model.APIKey, error)
+
+	// ListAPIKeys returns all keys ordered by ID, including revoked keys
+	ListAPIKeys(ctx context.Context) ([]*model.APIKey, error)
+
+	// RevokeAPIKey marks a key as revoked, revoking a revoked key keeps its original revocation time
+	RevokeAPIKey(ctx context.Context, id int) (*model.APIKey, error)
+
+	// TouchAPIKey records that the key was used now
+	TouchAPIKey(ctx context.Context, id int) error
+}
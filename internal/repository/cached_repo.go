@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// CachedRepo is a PackRepository decorator that serves the active configuration from memory
+// Writes through the decorator invalidate the cache right away, changes made by other instances
+// are picked up via Invalidate (wired to database notifications) or at the latest after the TTL
+type CachedRepo struct {
+	PackRepository
+	ttl time.Duration
+	now func() time.Time
+
+	mu         sync.RWMutex
+	cfg        *model.PackConfiguration
+	loadedAt   time.Time
+	generation uint64
+}
+
+// NewCachedRepo wraps repo with a cache of the active configuration that expires after ttl
+func NewCachedRepo(repo PackRepository, ttl time.Duration) *CachedRepo {
+	return &CachedRepo{
+		PackRepository: repo,
+		ttl:            ttl,
+		now:            time.Now,
+	}
+}
+
+// Invalidate drops the cached configuration so the next read loads it from the underlying repository
+func (r *CachedRepo) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cfg = nil
+	r.generation++
+}
+
+// GetPackSizes returns the current active pack sizes from the cache
+func (r *CachedRepo) GetPackSizes(ctx context.Context) ([]int, error) {
+	cfg, err := r.configuration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.PackSizes, nil
+}
+
+// GetPackConfiguration returns the current active configuration from the cache
+func (r *CachedRepo) GetPackConfiguration(ctx context.Context) (*model.PackConfiguration, error) {
+	return r.configuration(ctx)
+}
+
+// UpdatePackSizes updates the pack sizes and invalidates the cache
+func (r *CachedRepo) UpdatePackSizes(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.PackConfiguration, error) {
+	defer r.Invalidate()
+	return r.PackRepository.UpdatePackSizes(ctx, sizes, updatedBy, reason)
+}
+
+// ModifyPackSizes modifies the pack sizes and invalidates the cache
+func (r *CachedRepo) ModifyPackSizes(ctx context.Context, updatedBy string, reason string, change func(current []int) ([]int, error)) (*model.PackConfiguration, error) {
+	defer r.Invalidate()
+	return r.PackRepository.ModifyPackSizes(ctx, updatedBy, reason, change)
+}
+
+// ApproveDraft applies a pending draft and invalidates the cache
+func (r *CachedRepo) ApproveDraft(ctx context.Context, id int, reviewedBy string) (*model.PackConfiguration, error) {
+	defer r.Invalidate()
+	return r.PackRepository.ApproveDraft(ctx, id, reviewedBy)
+}
+
+// configuration returns a copy of the cached configuration, loading it when missing or expired
+func (r *CachedRepo) configuration(ctx context.Context) (*model.PackConfiguration, error) {
+	r.mu.RLock()
+	cfg, loadedAt, generation := r.cfg, r.loadedAt, r.generation
+	r.mu.RUnlock()
+
+	if cfg != nil && r.now().Sub(loadedAt) < r.ttl {
+		return copyConfiguration(cfg), nil
+	}
+
+	cfg, err := r.PackRepository.GetPackConfiguration(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// an invalidation during the load means cfg may already be stale, so it is returned but not cached
+	r.mu.Lock()
+	if r.generation == generation {
+		r.cfg = copyConfiguration(cfg)
+		r.loadedAt = r.now()
+	}
+	r.mu.Unlock()
+
+	return cfg, nil
+}
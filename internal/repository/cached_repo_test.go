@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingRepo counts the reads of the active configuration that reach the underlying repository
+type countingRepo struct {
+	PackRepository
+	loads  atomic.Int32
+	onLoad func()
+}
+
+func (r *countingRepo) GetPackConfiguration(ctx context.Context) (*model.PackConfiguration, error) {
+	r.loads.Add(1)
+	if r.onLoad != nil {
+		r.onLoad()
+	}
+	return r.PackRepository.GetPackConfiguration(ctx)
+}
+
+func TestCachedRepo_ServesReadsFromCache(t *testing.T) {
+	ctx := context.Background()
+	backing := &countingRepo{PackRepository: NewMemoryRepo()}
+	repo := NewCachedRepo(backing, time.Minute)
+
+	for range 3 {
+		sizes, err := repo.GetPackSizes(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, DefaultPackSizes, sizes)
+	}
+	cfg, err := repo.GetPackConfiguration(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cfg.Version)
+	assert.Equal(t, int32(1), backing.loads.Load())
+
+	// callers get copies and cannot corrupt the cache
+	cfg.PackSizes[0] = 1
+	sizes, err := repo.GetPackSizes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPackSizes, sizes)
+}
+
+func TestCachedRepo_Invalidation(t *testing.T) {
+	ctx := context.Background()
+	backing := &countingRepo{PackRepository: NewMemoryRepo()}
+	repo := NewCachedRepo(backing, time.Minute)
+
+	_, err := repo.GetPackSizes(ctx)
+	require.NoError(t, err)
+
+	t.Run("writes through the decorator invalidate", func(t *testing.T) {
+		_, err := repo.UpdatePackSizes(ctx, []int{23, 31}, "alice", "new boxes")
+		require.NoError(t, err)
+
+		sizes, err := repo.GetPackSizes(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []int{23, 31}, sizes)
+
+		_, err = repo.ModifyPackSizes(ctx, "alice", "bigger boxes", func(current []int) ([]int, error) {
+			return append(current, 53), nil
+		})
+		require.NoError(t, err)
+
+		sizes, err = repo.GetPackSizes(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []int{23, 31, 53}, sizes)
+	})
+	t.Run("changes by other instances are seen after Invalidate", func(t *testing.T) {
+		_, err := backing.UpdatePackSizes(ctx, []int{100}, "bob", "elsewhere")
+		require.NoError(t, err)
+
+		sizes, err := repo.GetPackSizes(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []int{23, 31, 53}, sizes)
+
+		repo.Invalidate()
+		sizes, err = repo.GetPackSizes(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []int{100}, sizes)
+	})
+}
+
+func TestCachedRepo_TTL(t *testing.T) {
+	ctx := context.Background()
+	backing := &countingRepo{PackRepository: NewMemoryRepo()}
+	repo := NewCachedRepo(backing, time.Minute)
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	_, err := repo.GetPackSizes(ctx)
+	require.NoError(t, err)
+	_, err = backing.UpdatePackSizes(ctx, []int{100}, "bob", "elsewhere")
+	require.NoError(t, err)
+
+	now = now.Add(59 * time.Second)
+	sizes, err := repo.GetPackSizes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPackSizes, sizes)
+
+	// a missed notification is corrected once the entry expires
+	now = now.Add(time.Second)
+	sizes, err = repo.GetPackSizes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int{100}, sizes)
+	assert.Equal(t, int32(2), backing.loads.Load())
+}
+
+func TestCachedRepo_InvalidationDuringLoad(t *testing.T) {
+	ctx := context.Background()
+	backing := &countingRepo{PackRepository: NewMemoryRepo()}
+	repo := NewCachedRepo(backing, time.Minute)
+
+	// the notification arrives while the first read is loading, so its result must not be cached
+	backing.onLoad = func() {
+		backing.onLoad = nil
+		repo.Invalidate()
+	}
+	_, err := repo.GetPackSizes(ctx)
+	require.NoError(t, err)
+	_, err = repo.GetPackSizes(ctx)
+	require.NoError(t, err)
+	_, err = repo.GetPackSizes(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(2), backing.loads.Load())
+}
+
+func TestCachedRepo_ErrorsAreNotCached(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCachedRepo(&failingRepo{PackRepository: NewMemoryRepo()}, time.Minute)
+
+	_, err := repo.GetPackSizes(ctx)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Nil(t, repo.cfg)
+}
+
+// failingRepo fails to read the active configuration
+type failingRepo struct {
+	PackRepository
+}
+
+func (r *failingRepo) GetPackConfiguration(ctx context.Context) (*model.PackConfiguration, error) {
+	return nil, ErrNotFound
+}
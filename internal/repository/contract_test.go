@@ -0,0 +1,68 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nsaltun/packman/internal/repository"
+	"github.com/nsaltun/packman/internal/repository/repotest"
+	"github.com/nsaltun/packman/migrations"
+	"github.com/nsaltun/packman/pkg/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRepoContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.PackRepository {
+		return repository.NewMemoryRepo()
+	})
+}
+
+func TestCachedRepoContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.PackRepository {
+		return repository.NewCachedRepo(repository.NewMemoryRepo(), time.Minute)
+	})
+}
+
+func TestFileRepoContract(t *testing.T) {
+	for _, name := range []string{"config.json", "config.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			repotest.Run(t, func(t *testing.T) repository.PackRepository {
+				repo, err := repository.NewFileRepo(filepath.Join(t.TempDir(), name))
+				require.NoError(t, err)
+				return repo
+			})
+		})
+	}
+}
+
+// TestPostgresRepoContract runs against the database in TEST_DATABASE_URL, its pack tables are reset for every test
+func TestPostgresRepoContract(t *testing.T) {
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	require.NoError(t, migrations.RunMigrations(pool, databaseURL))
+
+	repotest.Run(t, func(t *testing.T) repository.PackRepository {
+		_, err := pool.Exec(ctx, `
+			TRUNCATE idempotency_keys, rate_limit_buckets, api_keys, webhook_deliveries, webhook_subscriptions, pack_configuration_outbox, pack_configuration_audit, pack_configuration_drafts, pack_configuration_history RESTART IDENTITY;
+			UPDATE pack_configuration
+			SET version = 1,
+			    pack_sizes = '[250, 500, 1000, 2000, 5000]',
+			    updated_at = CURRENT_TIMESTAMP,
+			    updated_by = 'system',
+			    approved_by = NULL
+			WHERE id = 1;`)
+		require.NoError(t, err)
+		return repository.NewPostgresRepo(&postgres.Client{Pool: pool})
+	})
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package repository
+
+import "os"
+
+// lockFile is a no-op on platforms without flock, only access within the process is serialized there
+func lockFile(f *os.File, exclusive bool) error {
+	return nil
+}
+
+// unlockFile is a no-op on platforms without flock
+func unlockFile(f *os.File) error {
+	return nil
+}
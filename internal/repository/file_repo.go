@@ -0,0 +1,589 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nsaltun/packman/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// fileRepo implements the PackRepository interface on top of files for deployments without a database
+//
+// The active configuration and drafts live in a JSON or YAML state file that is replaced atomically.
+// Every change is appended to a JSON lines log next to it (<path>.log), which holds the history and audit records.
+// A lock file (<path>.lock) serializes access between processes. Every operation checks the files under
+// that lock, reads reuse the data loaded last while neither file changed, and the same semantics as the
+// in-memory repository apply
+type fileRepo struct {
+	path     string
+	logPath  string
+	lockPath string
+	yaml     bool
+
+	// mu serializes access within the process, the lock file only guards against other processes
+	mu  sync.Mutex
+	now func() time.Time
+
+	// the data of the last load and the files it was loaded from, guarded by mu
+	cached      *memoryRepo
+	cachedStamp fileRepoStamp
+}
+
+// racyStampWindow is how long after a change a file may change again without a new modification time,
+// the cache is not trusted for files changed that recently before it was filled
+const racyStampWindow = 2 * time.Second
+
+// fileStamp identifies the content of a file by its modification time and size
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// fileRepoStamp identifies the content of the state file and change log
+type fileRepoStamp struct {
+	state fileStamp
+	log   fileStamp
+}
+
+// fileState is the content of the state file
+type fileState struct {
+	Version    int         `json:"version" yaml:"version"`
+	PackSizes  []int       `json:"pack_sizes" yaml:"pack_sizes"`
+	UpdatedAt  time.Time   `json:"updated_at" yaml:"updated_at"`
+	UpdatedBy  string      `json:"updated_by,omitempty" yaml:"updated_by,omitempty"`
+	ApprovedBy string      `json:"approved_by,omitempty" yaml:"approved_by,omitempty"`
+	Drafts     []fileDraft `json:"drafts,omitempty" yaml:"drafts,omitempty"`
+}
+
+// fileDraft is a draft as stored in the state file
+type fileDraft struct {
+	ID             int               `json:"id" yaml:"id"`
+	PackSizes      []int             `json:"pack_sizes" yaml:"pack_sizes"`
+	Status         model.DraftStatus `json:"status" yaml:"status"`
+	BaseVersion    int               `json:"base_version" yaml:"base_version"`
+	CreatedAt      time.Time         `json:"created_at" yaml:"created_at"`
+	CreatedBy      string            `json:"created_by" yaml:"created_by"`
+	Reason         string            `json:"reason,omitempty" yaml:"reason,omitempty"`
+	ReviewedAt     *time.Time        `json:"reviewed_at,omitempty" yaml:"reviewed_at,omitempty"`
+	ReviewedBy     string            `json:"reviewed_by,omitempty" yaml:"reviewed_by,omitempty"`
+	ReviewComment  string            `json:"review_comment,omitempty" yaml:"review_comment,omitempty"`
+	AppliedVersion *int              `json:"applied_version,omitempty" yaml:"applied_version,omitempty"`
+}
+
+// fileLogRecord is one line of the change log: the superseded configuration and the audit entry of the change
+type fileLogRecord struct {
+	Archived  model.PackConfiguration `json:"archived"`
+	ValidFrom time.Time               `json:"valid_from"`
+	Audit     model.AuditEntry        `json:"audit"`
+}
+
+// NewFileRepo creates a file-backed repository storing its state at path
+// The format is YAML when path ends in .yaml or .yml and JSON otherwise
+// A missing state file is created with DefaultPackSizes
+func NewFileRepo(path string) (PackRepository, error) {
+	return newFileRepo(path)
+}
+
+func newFileRepo(path string) (*fileRepo, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	r := &fileRepo{
+		path:     path,
+		logPath:  path + ".log",
+		lockPath: path + ".lock",
+		yaml:     ext == ".yaml" || ext == ".yml",
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	// create the initial state unless another process already did
+	err := r.update(func(m *memoryRepo) error { return nil })
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetPackSizes returns the current active pack sizes
+func (r *fileRepo) GetPackSizes(ctx context.Context) ([]int, error) {
+	var sizes []int
+	err := r.view(func(m *memoryRepo) (err error) {
+		sizes, err = m.GetPackSizes(ctx)
+		return err
+	})
+	return sizes, err
+}
+
+// GetPackConfiguration returns the full configuration with metadata
+func (r *fileRepo) GetPackConfiguration(ctx context.Context) (*model.PackConfiguration, error) {
+	var cfg *model.PackConfiguration
+	err := r.view(func(m *memoryRepo) (err error) {
+		cfg, err = m.GetPackConfiguration(ctx)
+		return err
+	})
+	return cfg, err
+}
+
+// GetPackConfigurationByVersion returns the configuration with the given version from the current state or history
+func (r *fileRepo) GetPackConfigurationByVersion(ctx context.Context, version int) (*model.PackConfiguration, error) {
+	var cfg *model.PackConfiguration
+	err := r.view(func(m *memoryRepo) (err error) {
+		cfg, err = m.GetPackConfigurationByVersion(ctx, version)
+		return err
+	})
+	return cfg, err
+}
+
+// GetPackConfigurationAsOf returns the configuration that was active at the given time
+func (r *fileRepo) GetPackConfigurationAsOf(ctx context.Context, at time.Time) (*model.PackConfiguration, error) {
+	var cfg *model.PackConfiguration
+	err := r.view(func(m *memoryRepo) (err error) {
+		cfg, err = m.GetPackConfigurationAsOf(ctx, at)
+		return err
+	})
+	return cfg, err
+}
+
+// UpdatePackSizes replaces the pack sizes and returns the updated configuration
+func (r *fileRepo) UpdatePackSizes(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.PackConfiguration, error) {
+	var cfg *model.PackConfiguration
+	err := r.update(func(m *memoryRepo) (err error) {
+		cfg, err = m.UpdatePackSizes(ctx, sizes, updatedBy, reason)
+		return err
+	})
+	return cfg, err
+}
+
+// ModifyPackSizes applies a change function to the current pack sizes while holding the file lock
+func (r *fileRepo) ModifyPackSizes(ctx context.Context, updatedBy string, reason string, change func(current []int) ([]int, error)) (*model.PackConfiguration, error) {
+	var cfg *model.PackConfiguration
+	err := r.update(func(m *memoryRepo) (err error) {
+		cfg, err = m.ModifyPackSizes(ctx, updatedBy, reason, change)
+		return err
+	})
+	return cfg, err
+}
+
+// GetPackConfigurationHistory returns historical configurations, newest first
+func (r *fileRepo) GetPackConfigurationHistory(ctx context.Context, limit int) ([]*model.PackConfiguration, error) {
+	var configs []*model.PackConfiguration
+	err := r.view(func(m *memoryRepo) (err error) {
+		configs, err = m.GetPackConfigurationHistory(ctx, limit)
+		return err
+	})
+	return configs, err
+}
+
+// GetFullPackConfigurationHistory returns every historical configuration ordered from oldest to newest
+func (r *fileRepo) GetFullPackConfigurationHistory(ctx context.Context) ([]*model.PackConfiguration, error) {
+	var configs []*model.PackConfiguration
+	err := r.view(func(m *memoryRepo) (err error) {
+		configs, err = m.GetFullPackConfigurationHistory(ctx)
+		return err
+	})
+	return configs, err
+}
+
+// CreateDraft stores a pending draft based on the current configuration version
+func (r *fileRepo) CreateDraft(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.PackConfigurationDraft, error) {
+	var draft *model.PackConfigurationDraft
+	err := r.update(func(m *memoryRepo) (err error) {
+		draft, err = m.CreateDraft(ctx, sizes, updatedBy, reason)
+		return err
+	})
+	return draft, err
+}
+
+// GetDraft returns a single draft by ID
+func (r *fileRepo) GetDraft(ctx context.Context, id int) (*model.PackConfigurationDraft, error) {
+	var draft *model.PackConfigurationDraft
+	err := r.view(func(m *memoryRepo) (err error) {
+		draft, err = m.GetDraft(ctx, id)
+		return err
+	})
+	return draft, err
+}
+
+// ListDrafts returns drafts ordered by creation time descending, optionally filtered by status
+func (r *fileRepo) ListDrafts(ctx context.Context, status model.DraftStatus, limit int) ([]*model.PackConfigurationDraft, error) {
+	var drafts []*model.PackConfigurationDraft
+	err := r.view(func(m *memoryRepo) (err error) {
+		drafts, err = m.ListDrafts(ctx, status, limit)
+		return err
+	})
+	return drafts, err
+}
+
+// ApproveDraft applies a pending draft as the active configuration and records the reviewer
+func (r *fileRepo) ApproveDraft(ctx context.Context, id int, reviewedBy string) (*model.PackConfiguration, error) {
+	var cfg *model.PackConfiguration
+	err := r.update(func(m *memoryRepo) (err error) {
+		cfg, err = m.ApproveDraft(ctx, id, reviewedBy)
+		return err
+	})
+	return cfg, err
+}
+
+// RejectDraft marks a pending draft as rejected
+func (r *fileRepo) RejectDraft(ctx context.Context, id int, reviewedBy string, comment string) (*model.PackConfigurationDraft, error) {
+	var draft *model.PackConfigurationDraft
+	err := r.update(func(m *memoryRepo) (err error) {
+		draft, err = m.RejectDraft(ctx, id, reviewedBy, comment)
+		return err
+	})
+	return draft, err
+}
+
+// ListAuditEntries returns configuration changes ordered from newest to oldest
+func (r *fileRepo) ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	var entries []*model.AuditEntry
+	err := r.view(func(m *memoryRepo) (err error) {
+		entries, err = m.ListAuditEntries(ctx, filter)
+		return err
+	})
+	return entries, err
+}
+
+// view loads the stored data under a shared lock and passes it to fn
+func (r *fileRepo) view(fn func(m *memoryRepo) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unlock, err := r.lock(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	m, err := r.loadCached()
+	if err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+// update loads the stored data under an exclusive lock, passes it to fn and persists what fn changed
+// New history records are appended to the log before the state file is replaced, so a crash in between
+// leaves a record for a version the state never reached, which load ignores
+func (r *fileRepo) update(fn func(m *memoryRepo) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unlock, err := r.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	m, stored, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	before := len(m.history)
+	if err := fn(m); err != nil {
+		return err
+	}
+
+	if len(m.history) > before {
+		records := make([]fileLogRecord, 0, len(m.history)-before)
+		for i := before; i < len(m.history); i++ {
+			records = append(records, fileLogRecord{
+				Archived:  m.history[i].cfg,
+				ValidFrom: m.history[i].validFrom,
+				Audit:     *m.audit[i],
+			})
+		}
+		if err := r.appendLog(records); err != nil {
+			return err
+		}
+	}
+
+	state, err := r.encodeState(toFileState(m))
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(state, stored) {
+		if err := writeFileAtomic(r.path, state); err != nil {
+			return err
+		}
+	}
+
+	// reads can start from the data just written
+	stamp, err := r.stat()
+	if err != nil {
+		r.cached = nil
+		return nil
+	}
+	r.cache(m, stamp)
+	return nil
+}
+
+// lock takes the inter-process lock and returns a function releasing it
+func (r *fileRepo) lock(exclusive bool) (func(), error) {
+	f, err := os.OpenFile(r.lockPath, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := lockFile(f, exclusive); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", r.lockPath, err)
+	}
+	return func() {
+		_ = unlockFile(f)
+		_ = f.Close()
+	}, nil
+}
+
+// loadCached returns the data of the last load while neither file changed since, and loads it otherwise
+// The returned repository is shared by reads and must not be changed
+func (r *fileRepo) loadCached() (*memoryRepo, error) {
+	stamp, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+	if r.cached != nil && r.cachedStamp == stamp {
+		return r.cached, nil
+	}
+
+	m, _, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.cache(m, stamp)
+	return m, nil
+}
+
+// cache keeps m as the data of the files with stamp
+// Files changed within racyStampWindow are not cached, a change right after could keep their stamp
+func (r *fileRepo) cache(m *memoryRepo, stamp fileRepoStamp) {
+	now := r.now()
+	if now.Sub(stamp.state.modTime) < racyStampWindow || now.Sub(stamp.log.modTime) < racyStampWindow {
+		r.cached = nil
+		return
+	}
+	r.cached, r.cachedStamp = m, stamp
+}
+
+// stat returns the stamp of the state file and change log, missing files have a zero stamp
+func (r *fileRepo) stat() (fileRepoStamp, error) {
+	var stamp fileRepoStamp
+	for path, s := range map[string]*fileStamp{r.path: &stamp.state, r.logPath: &stamp.log} {
+		info, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fileRepoStamp{}, fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		*s = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamp, nil
+}
+
+// load reads the state file and change log into a memory repository
+// It also returns the raw state file content, which is nil when the file does not exist yet
+func (r *fileRepo) load() (*memoryRepo, []byte, error) {
+	m := newMemoryRepo()
+	m.now = r.now
+
+	raw, err := os.ReadFile(r.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", r.path, err)
+	}
+
+	var state fileState
+	if r.yaml {
+		err = yaml.Unmarshal(raw, &state)
+	} else {
+		err = json.Unmarshal(raw, &state)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", r.path, err)
+	}
+	if state.Version <= 0 || len(state.PackSizes) == 0 {
+		return nil, nil, fmt.Errorf("invalid state in %s: version and pack_sizes are required", r.path)
+	}
+
+	m.current = model.PackConfiguration{
+		ID:         1,
+		Version:    state.Version,
+		PackSizes:  state.PackSizes,
+		UpdatedAt:  state.UpdatedAt,
+		UpdatedBy:  state.UpdatedBy,
+		ApprovedBy: state.ApprovedBy,
+	}
+	for _, d := range state.Drafts {
+		m.drafts = append(m.drafts, &model.PackConfigurationDraft{
+			ID:             d.ID,
+			PackSizes:      d.PackSizes,
+			Status:         d.Status,
+			BaseVersion:    d.BaseVersion,
+			CreatedAt:      d.CreatedAt,
+			UpdatedBy:      d.CreatedBy,
+			Reason:         d.Reason,
+			ReviewedAt:     d.ReviewedAt,
+			ReviewedBy:     d.ReviewedBy,
+			ReviewComment:  d.ReviewComment,
+			AppliedVersion: d.AppliedVersion,
+		})
+	}
+
+	records, err := r.readLog(state.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, rec := range records {
+		m.history = append(m.history, memoryHistoryEntry{cfg: rec.Archived, validFrom: rec.ValidFrom})
+		audit := rec.Audit
+		m.audit = append(m.audit, &audit)
+	}
+
+	return m, raw, nil
+}
+
+// readLog returns the change log records up to the given version, oldest first
+// Records of versions the state file never reached are left over from an interrupted write and skipped,
+// when a version was logged more than once the last record wins
+func (r *fileRepo) readLog(version int) ([]fileLogRecord, error) {
+	f, err := os.Open(r.logPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", r.logPath, err)
+	}
+	defer f.Close()
+
+	var records []fileLogRecord
+	byVersion := make(map[int]int)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var rec fileLogRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse %s line %d: %w", r.logPath, line, err)
+		}
+		if rec.Audit.Version > version {
+			continue
+		}
+		if i, ok := byVersion[rec.Audit.Version]; ok {
+			records[i] = rec
+			continue
+		}
+		byVersion[rec.Audit.Version] = len(records)
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", r.logPath, err)
+	}
+
+	return records, nil
+}
+
+// appendLog appends records to the change log and syncs it to disk
+func (r *fileRepo) appendLog(records []fileLogRecord) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(r.logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", r.logPath, err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to append to %s: %w", r.logPath, err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to sync %s: %w", r.logPath, err)
+	}
+	return f.Close()
+}
+
+// encodeState encodes the state in the configured format
+func (r *fileRepo) encodeState(state *fileState) ([]byte, error) {
+	if r.yaml {
+		return yaml.Marshal(state)
+	}
+	return json.MarshalIndent(state, "", "  ")
+}
+
+// toFileState converts the current configuration and drafts of a memory repository to the state file layout
+func toFileState(m *memoryRepo) *fileState {
+	state := &fileState{
+		Version:    m.current.Version,
+		PackSizes:  m.current.PackSizes,
+		UpdatedAt:  m.current.UpdatedAt,
+		UpdatedBy:  m.current.UpdatedBy,
+		ApprovedBy: m.current.ApprovedBy,
+	}
+	for _, d := range m.drafts {
+		state.Drafts = append(state.Drafts, fileDraft{
+			ID:             d.ID,
+			PackSizes:      d.PackSizes,
+			Status:         d.Status,
+			BaseVersion:    d.BaseVersion,
+			CreatedAt:      d.CreatedAt,
+			CreatedBy:      d.UpdatedBy,
+			Reason:         d.Reason,
+			ReviewedAt:     d.ReviewedAt,
+			ReviewedBy:     d.ReviewedBy,
+			ReviewComment:  d.ReviewComment,
+			AppliedVersion: d.AppliedVersion,
+		})
+	}
+	return state
+}
+
+// writeFileAtomic replaces path with data so readers see either the old or the new content
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	// no-op once the file has been renamed
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync %s: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
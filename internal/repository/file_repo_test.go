@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRepo_PersistsAcrossInstances(t *testing.T) {
+	for _, name := range []string{"config.json", "config.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			path := filepath.Join(t.TempDir(), "nested", name)
+
+			repo, err := NewFileRepo(path)
+			require.NoError(t, err)
+
+			_, err = repo.UpdatePackSizes(ctx, []int{23, 31, 53}, "alice", "new boxes")
+			require.NoError(t, err)
+			draft, err := repo.CreateDraft(ctx, []int{100}, "carol", "try 100")
+			require.NoError(t, err)
+
+			reopened, err := NewFileRepo(path)
+			require.NoError(t, err)
+
+			cfg, err := reopened.GetPackConfiguration(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 2, cfg.Version)
+			assert.Equal(t, []int{23, 31, 53}, cfg.PackSizes)
+
+			history, err := reopened.GetFullPackConfigurationHistory(ctx)
+			require.NoError(t, err)
+			require.Len(t, history, 1)
+			assert.Equal(t, DefaultPackSizes, history[0].PackSizes)
+
+			stored, err := reopened.GetDraft(ctx, draft.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "try 100", stored.Reason)
+
+			entries, err := reopened.ListAuditEntries(ctx, model.AuditFilter{})
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			assert.Equal(t, "new boxes", entries[0].Reason)
+
+			raw, err := os.ReadFile(path)
+			require.NoError(t, err)
+			if strings.HasSuffix(name, ".yaml") {
+				assert.Contains(t, string(raw), "pack_sizes:")
+			} else {
+				assert.Contains(t, string(raw), `"pack_sizes"`)
+			}
+		})
+	}
+}
+
+func TestFileRepo_IgnoresInterruptedWrites(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	repo, err := NewFileRepo(path)
+	require.NoError(t, err)
+	_, err = repo.UpdatePackSizes(ctx, []int{100}, "alice", "v2")
+	require.NoError(t, err)
+
+	// simulate a crash after the log append of version 3 but before the state file was replaced
+	state, err := os.ReadFile(path)
+	require.NoError(t, err)
+	_, err = repo.UpdatePackSizes(ctx, []int{200}, "alice", "lost")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, state, 0o644))
+
+	cfg, err := repo.GetPackConfiguration(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.Version)
+
+	history, err := repo.GetFullPackConfigurationHistory(ctx)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	// the next write reuses version 3 and replaces the orphaned record
+	_, err = repo.UpdatePackSizes(ctx, []int{300}, "alice", "v3")
+	require.NoError(t, err)
+
+	entries, err := repo.ListAuditEntries(ctx, model.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "v3", entries[0].Reason)
+	assert.Equal(t, []int{300}, entries[0].PackSizes)
+}
+
+func TestFileRepo_ConcurrentInstances(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	// separate instances share nothing but the files, like separate processes
+	first, err := NewFileRepo(path)
+	require.NoError(t, err)
+	second, err := NewFileRepo(path)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i, repo := range []PackRepository{first, second, first, second} {
+		for size := 1; size <= 5; size++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := repo.ModifyPackSizes(ctx, "worker", "add", func(current []int) ([]int, error) {
+					return append(current, i*10+size), nil
+				})
+				assert.NoError(t, err)
+			}()
+		}
+	}
+	wg.Wait()
+
+	cfg, err := first.GetPackConfiguration(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 21, cfg.Version)
+	assert.Len(t, cfg.PackSizes, len(DefaultPackSizes)+20)
+
+	history, err := second.GetFullPackConfigurationHistory(ctx)
+	require.NoError(t, err)
+	assert.Len(t, history, 20)
+}
+
+func TestFileRepo_InvalidState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 1, "pack_sizes": []}`), 0o644))
+
+	_, err := NewFileRepo(path)
+	assert.ErrorContains(t, err, "pack_sizes are required")
+}
+
+func TestFileRepo_CachesReads(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	repo, err := newFileRepo(path)
+	require.NoError(t, err)
+	// the files look old enough to be cached
+	repo.now = func() time.Time { return time.Now().Add(time.Hour) }
+
+	_, err = repo.GetPackConfiguration(ctx)
+	require.NoError(t, err)
+	cached := repo.cached
+	require.NotNil(t, cached)
+
+	_, err = repo.GetFullPackConfigurationHistory(ctx)
+	require.NoError(t, err)
+	assert.Same(t, cached, repo.cached)
+
+	// another process changes the files
+	other, err := NewFileRepo(path)
+	require.NoError(t, err)
+	_, err = other.UpdatePackSizes(ctx, []int{23, 31, 53}, "alice", "new boxes")
+	require.NoError(t, err)
+
+	cfg, err := repo.GetPackConfiguration(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.Version)
+	history, err := repo.GetFullPackConfigurationHistory(ctx)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+	assert.NotSame(t, cached, repo.cached)
+}
+
+func TestFileRepo_SkipsCacheForRecentChanges(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	repo, err := newFileRepo(path)
+	require.NoError(t, err)
+	_, err = repo.GetPackConfiguration(ctx)
+	require.NoError(t, err)
+	// the file was just written, a change within the same timestamp tick would go unnoticed
+	assert.Nil(t, repo.cached)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// IdempotencyRepository stores the requests made with an Idempotency-Key and their responses
+type IdempotencyRepository interface {
+	// ClaimIdempotencyKey records a request with fingerprint under key, unless the key is already in use
+	// It returns nil when the request was claimed and may proceed, otherwise the existing record.
+	// An expired key, or a request with the same fingerprint still in progress after lockTimeout, is claimed again
+	ClaimIdempotencyKey(ctx context.Context, key, fingerprint string, ttl, lockTimeout time.Duration) (*model.IdempotencyRecord, error)
+
+	// CompleteIdempotencyKey stores the response of a claimed request to be replayed
+	CompleteIdempotencyKey(ctx context.Context, key string, response *model.IdempotentResponse) error
+
+	// ReleaseIdempotencyKey removes a claimed request without response, so a retry runs again
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+
+	// DeleteExpiredIdempotencyKeys removes the keys past their ttl
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int, error)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// memoryAPIKeyRepo implements the APIKeyRepository interface in memory
+// Keys are lost on restart, it backs the memory and file storage where only the bootstrap key survives restarts
+type memoryAPIKeyRepo struct {
+	mu     sync.Mutex
+	keys   []*model.APIKey
+	hashes []string
+}
+
+// NewMemoryAPIKeyRepo creates an empty in-memory API key repository
+func NewMemoryAPIKeyRepo() APIKeyRepository {
+	return &memoryAPIKeyRepo{}
+}
+
+// CreateAPIKey stores a new key with the hash of its secret
+func (r *memoryAPIKeyRepo) CreateAPIKey(ctx context.Context, key *model.APIKey, hash string) (*model.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.keys {
+		if existing.Name == key.Name {
+			return nil, ErrAPIKeyNameTaken
+		}
+	}
+
+	created := &model.APIKey{
+		ID:        len(r.keys) + 1,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scopes:    slices.Clone(key.Scopes),
+		CreatedBy: key.CreatedBy,
+		CreatedAt: time.Now().UTC(),
+	}
+	r.keys = append(r.keys, created)
+	r.hashes = append(r.hashes, hash)
+	return copyAPIKey(created), nil
+}
+
+// GetAPIKeyByHash returns the key with the given hash, including revoked keys
+func (r *memoryAPIKeyRepo) GetAPIKeyByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := slices.Index(r.hashes, hash)
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	return copyAPIKey(r.keys[i]), nil
+}
+
+// ListAPIKeys returns all keys ordered by ID, including revoked keys
+func (r *memoryAPIKeyRepo) ListAPIKeys(ctx context.Context) ([]*model.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]*model.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, copyAPIKey(key))
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey marks a key as revoked, revoking a revoked key keeps its original revocation time
+func (r *memoryAPIKeyRepo) RevokeAPIKey(ctx context.Context, id int) (*model.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// key IDs are assigned sequentially from 1
+	if id <= 0 || id > len(r.keys) {
+		return nil, ErrNotFound
+	}
+	key := r.keys[id-1]
+	if key.RevokedAt == nil {
+		now := time.Now().UTC()
+		key.RevokedAt = &now
+	}
+	return copyAPIKey(key), nil
+}
+
+// TouchAPIKey records that the key was used now
+func (r *memoryAPIKeyRepo) TouchAPIKey(ctx context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id <= 0 || id > len(r.keys) {
+		return ErrNotFound
+	}
+	now := time.Now().UTC()
+	r.keys[id-1].LastUsedAt = &now
+	return nil
+}
+
+// copyAPIKey returns a copy that does not share mutable fields with the store
+func copyAPIKey(key *model.APIKey) *model.APIKey {
+	copied := *key
+	copied.Scopes = slices.Clone(key.Scopes)
+	if key.LastUsedAt != nil {
+		lastUsedAt := *key.LastUsedAt
+		copied.LastUsedAt = &lastUsedAt
+	}
+	if key.RevokedAt != nil {
+		revokedAt := *key.RevokedAt
+		copied.RevokedAt = &revokedAt
+	}
+	return &copied
+}
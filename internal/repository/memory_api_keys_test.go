@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryAPIKeyRepo(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryAPIKeyRepo()
+
+	created, err := repo.CreateAPIKey(ctx, &model.APIKey{Name: "ci", Prefix: "pmk_01234567", Scopes: []model.Scope{model.ScopeRead}}, "hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, created.ID)
+
+	_, err = repo.CreateAPIKey(ctx, &model.APIKey{Name: "ci"}, "hash-2")
+	assert.ErrorIs(t, err, ErrAPIKeyNameTaken)
+
+	_, err = repo.GetAPIKeyByHash(ctx, "hash-2")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, repo.TouchAPIKey(ctx, created.ID))
+	found, err := repo.GetAPIKeyByHash(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.NotNil(t, found.LastUsedAt)
+
+	// revoking twice keeps the first revocation time
+	revoked, err := repo.RevokeAPIKey(ctx, created.ID)
+	require.NoError(t, err)
+	require.NotNil(t, revoked.RevokedAt)
+	again, err := repo.RevokeAPIKey(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, *revoked.RevokedAt, *again.RevokedAt)
+
+	_, err = repo.RevokeAPIKey(ctx, 2)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	keys, err := repo.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+}
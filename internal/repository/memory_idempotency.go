@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// memoryIdempotencyRepo implements the IdempotencyRepository interface in memory
+// Keys are lost on restart and not shared between instances, it backs the memory and file storage
+type memoryIdempotencyRepo struct {
+	mu   sync.Mutex
+	keys map[string]*memoryIdempotencyKey
+	now  func() time.Time
+}
+
+// memoryIdempotencyKey is a claimed key with its expiry
+type memoryIdempotencyKey struct {
+	record      model.IdempotencyRecord
+	lockedUntil time.Time
+	expiresAt   time.Time
+}
+
+// NewMemoryIdempotencyRepo creates an empty in-memory idempotency repository
+func NewMemoryIdempotencyRepo() IdempotencyRepository {
+	return &memoryIdempotencyRepo{
+		keys: make(map[string]*memoryIdempotencyKey),
+		now:  time.Now,
+	}
+}
+
+// ClaimIdempotencyKey records a request with fingerprint under key, unless the key is already in use
+func (r *memoryIdempotencyRepo) ClaimIdempotencyKey(ctx context.Context, key, fingerprint string, ttl, lockTimeout time.Duration) (*model.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if existing, ok := r.keys[key]; ok {
+		abandoned := existing.record.Response == nil && !now.Before(existing.lockedUntil) && existing.record.Fingerprint == fingerprint
+		if now.Before(existing.expiresAt) && !abandoned {
+			record := existing.record
+			return &record, nil
+		}
+	}
+
+	r.keys[key] = &memoryIdempotencyKey{
+		record:      model.IdempotencyRecord{Fingerprint: fingerprint},
+		lockedUntil: now.Add(lockTimeout),
+		expiresAt:   now.Add(ttl),
+	}
+	return nil, nil
+}
+
+// CompleteIdempotencyKey stores the response of a claimed request to be replayed
+func (r *memoryIdempotencyRepo) CompleteIdempotencyKey(ctx context.Context, key string, response *model.IdempotentResponse) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.keys[key]
+	if !ok || existing.record.Response != nil {
+		return ErrNotFound
+	}
+	stored := *response
+	stored.Body = append([]byte(nil), response.Body...)
+	existing.record.Response = &stored
+	return nil
+}
+
+// ReleaseIdempotencyKey removes a claimed request without response
+func (r *memoryIdempotencyRepo) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.keys[key]; ok && existing.record.Response == nil {
+		delete(r.keys, key)
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys removes the keys past their ttl
+func (r *memoryIdempotencyRepo) DeleteExpiredIdempotencyKeys(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+	now := r.now()
+	for key, existing := range r.keys {
+		if !now.Before(existing.expiresAt) {
+			delete(r.keys, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
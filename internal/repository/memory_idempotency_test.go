@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryIdempotencyRepo(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &memoryIdempotencyRepo{keys: make(map[string]*memoryIdempotencyKey), now: func() time.Time { return now }}
+
+	record, err := repo.ClaimIdempotencyKey(ctx, "ops key-1", "fp-1", time.Hour, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	// in progress
+	record, err = repo.ClaimIdempotencyKey(ctx, "ops key-1", "fp-1", time.Hour, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "fp-1", record.Fingerprint)
+	assert.Nil(t, record.Response)
+
+	response := &model.IdempotentResponse{StatusCode: 200, ContentType: "application/json", Body: []byte(`{"data":{}}`)}
+	require.NoError(t, repo.CompleteIdempotencyKey(ctx, "ops key-1", response))
+	assert.ErrorIs(t, repo.CompleteIdempotencyKey(ctx, "ops key-1", response), ErrNotFound)
+
+	// completed requests are replayed, also after the lock timed out
+	now = now.Add(30 * time.Minute)
+	record, err = repo.ClaimIdempotencyKey(ctx, "ops key-1", "fp-1", time.Hour, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, response, record.Response)
+
+	// released requests can be claimed again
+	_, err = repo.ClaimIdempotencyKey(ctx, "ops key-2", "fp-2", time.Hour, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, repo.ReleaseIdempotencyKey(ctx, "ops key-2"))
+	record, err = repo.ClaimIdempotencyKey(ctx, "ops key-2", "fp-2", time.Hour, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	// an abandoned request can be claimed again by a retry of the same request only
+	now = now.Add(2 * time.Minute)
+	record, err = repo.ClaimIdempotencyKey(ctx, "ops key-2", "fp-other", time.Hour, time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	record, err = repo.ClaimIdempotencyKey(ctx, "ops key-2", "fp-2", time.Hour, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	// expired keys are removed and can be reused for another request
+	now = now.Add(40 * time.Minute)
+	deleted, err := repo.DeleteExpiredIdempotencyKeys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	record, err = repo.ClaimIdempotencyKey(ctx, "ops key-2", "fp-other", time.Hour, time.Minute)
+	require.NoError(t, err)
+	assert.NotNil(t, record)
+	now = now.Add(time.Hour)
+	record, err = repo.ClaimIdempotencyKey(ctx, "ops key-2", "fp-other", time.Hour, time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, record)
+}
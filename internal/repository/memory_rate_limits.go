@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryRateLimitRepo implements the RateLimitRepository interface in memory, for a single instance
+type memoryRateLimitRepo struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	now     func() time.Time
+}
+
+// memoryBucket is a token bucket as of updatedAt
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// NewMemoryRateLimitRepo creates an empty in-memory rate limit repository
+func NewMemoryRateLimitRepo() RateLimitRepository {
+	return &memoryRateLimitRepo{
+		buckets: make(map[string]*memoryBucket),
+		now:     time.Now,
+	}
+}
+
+// TakeRateLimitToken refills the bucket with rate tokens per second up to capacity and takes a token if there is one
+func (r *memoryRateLimitRepo) TakeRateLimitToken(ctx context.Context, key string, capacity int, rate float64) (RateLimitBucket, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	bucket, ok := r.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{tokens: float64(capacity), updatedAt: now}
+		r.buckets[key] = bucket
+	}
+
+	bucket.tokens = min(float64(capacity), bucket.tokens+max(now.Sub(bucket.updatedAt).Seconds(), 0)*rate)
+	bucket.updatedAt = now
+
+	if bucket.tokens < 1 {
+		return RateLimitBucket{Allowed: false, Tokens: bucket.tokens}, nil
+	}
+	bucket.tokens--
+	return RateLimitBucket{Allowed: true, Tokens: bucket.tokens}, nil
+}
+
+// DeleteIdleRateLimitBuckets removes buckets not used for idle
+func (r *memoryRateLimitRepo) DeleteIdleRateLimitBuckets(ctx context.Context, idle time.Duration) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+	cutoff := r.now().Add(-idle)
+	for key, bucket := range r.buckets {
+		if bucket.updatedAt.Before(cutoff) {
+			delete(r.buckets, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRateLimitRepo(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &memoryRateLimitRepo{buckets: make(map[string]*memoryBucket), now: func() time.Time { return now }}
+
+	// a new bucket starts full
+	for i := 2; i >= 0; i-- {
+		bucket, err := repo.TakeRateLimitToken(ctx, "client", 3, 1)
+		require.NoError(t, err)
+		assert.True(t, bucket.Allowed)
+		assert.Equal(t, float64(i), bucket.Tokens)
+	}
+	bucket, err := repo.TakeRateLimitToken(ctx, "client", 3, 1)
+	require.NoError(t, err)
+	assert.False(t, bucket.Allowed)
+
+	// other keys have their own bucket
+	bucket, err = repo.TakeRateLimitToken(ctx, "other", 3, 1)
+	require.NoError(t, err)
+	assert.True(t, bucket.Allowed)
+
+	// half a second refills half a token, not enough for a request
+	now = now.Add(500 * time.Millisecond)
+	bucket, err = repo.TakeRateLimitToken(ctx, "client", 3, 1)
+	require.NoError(t, err)
+	assert.False(t, bucket.Allowed)
+	assert.Equal(t, 0.5, bucket.Tokens)
+
+	// the refill stops at the capacity
+	now = now.Add(time.Hour)
+	bucket, err = repo.TakeRateLimitToken(ctx, "client", 3, 1)
+	require.NoError(t, err)
+	assert.True(t, bucket.Allowed)
+	assert.Equal(t, float64(2), bucket.Tokens)
+
+	// only the bucket not used for a minute is removed
+	deleted, err := repo.DeleteIdleRateLimitBuckets(ctx, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.Len(t, repo.buckets, 1)
+	assert.Contains(t, repo.buckets, "client")
+}
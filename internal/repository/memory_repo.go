@@ -0,0 +1,400 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/reqctx"
+)
+
+// DefaultPackSizes is the configuration a new store starts with, matching the initial migration
+var DefaultPackSizes = []int{250, 500, 1000, 2000, 5000}
+
+// memoryHistoryEntry is an archived configuration, UpdatedAt holds the time it was superseded
+type memoryHistoryEntry struct {
+	cfg       model.PackConfiguration
+	validFrom time.Time
+}
+
+// configuration returns a copy of the archived configuration with the time it became active
+func (e *memoryHistoryEntry) configuration() *model.PackConfiguration {
+	cfg := copyConfiguration(&e.cfg)
+	// entries of file logs written before the time was recorded have none
+	if !e.validFrom.IsZero() {
+		validFrom := e.validFrom
+		cfg.ValidFrom = &validFrom
+	}
+	return cfg
+}
+
+// memoryRepo implements the PackRepository interface in process memory
+// It follows the same versioning and history semantics as the PostgreSQL repository
+// and is meant for local development and tests, all data is lost on restart
+type memoryRepo struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	current model.PackConfiguration
+	history []memoryHistoryEntry
+	drafts  []*model.PackConfigurationDraft
+	audit   []*model.AuditEntry
+}
+
+// NewMemoryRepo creates a new in-memory repository seeded with DefaultPackSizes
+func NewMemoryRepo() PackRepository {
+	return newMemoryRepo()
+}
+
+func newMemoryRepo() *memoryRepo {
+	r := &memoryRepo{
+		now: func() time.Time { return time.Now().UTC() },
+	}
+	r.current = model.PackConfiguration{
+		ID:        1,
+		Version:   1,
+		PackSizes: slices.Clone(DefaultPackSizes),
+		UpdatedAt: r.now(),
+		UpdatedBy: "system",
+	}
+	return r
+}
+
+// GetPackSizes returns the current active pack sizes
+func (r *memoryRepo) GetPackSizes(ctx context.Context) ([]int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.current.PackSizes), nil
+}
+
+// GetPackConfiguration returns the full configuration with metadata
+func (r *memoryRepo) GetPackConfiguration(ctx context.Context) (*model.PackConfiguration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return copyConfiguration(&r.current), nil
+}
+
+// GetPackConfigurationByVersion returns the configuration with the given version,
+// either the current one or an archived one from history
+func (r *memoryRepo) GetPackConfigurationByVersion(ctx context.Context, version int) (*model.PackConfiguration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.current.Version == version {
+		return copyConfiguration(&r.current), nil
+	}
+	for i := len(r.history) - 1; i >= 0; i-- {
+		if r.history[i].cfg.Version == version {
+			return copyConfiguration(&r.history[i].cfg), nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// GetPackConfigurationAsOf returns the configuration that was active at the given time
+func (r *memoryRepo) GetPackConfigurationAsOf(ctx context.Context, at time.Time) (*model.PackConfiguration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// history is appended in supersede order, the first entry archived after the given time was active then
+	for _, entry := range r.history {
+		if !entry.cfg.UpdatedAt.After(at) {
+			continue
+		}
+		if entry.validFrom.After(at) {
+			return nil, ErrNotFound
+		}
+		return entry.configuration(), nil
+	}
+
+	// nothing superseded since then, the current configuration was already active
+	if r.current.UpdatedAt.After(at) {
+		return nil, ErrNotFound
+	}
+	return copyConfiguration(&r.current), nil
+}
+
+// UpdatePackSizes replaces the pack sizes and returns the updated configuration
+func (r *memoryRepo) UpdatePackSizes(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.PackConfiguration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg := r.replacePackSizes(ctx, packSizesChange{
+		sizes:     sizes,
+		updatedBy: updatedBy,
+		reason:    reason,
+	})
+	return cfg, nil
+}
+
+// ModifyPackSizes applies a change function to the current pack sizes while holding the lock
+// When the resulting set equals the current one no new version is written
+func (r *memoryRepo) ModifyPackSizes(ctx context.Context, updatedBy string, reason string, change func(current []int) ([]int, error)) (*model.PackConfiguration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sizes, err := change(slices.Clone(r.current.PackSizes))
+	if err != nil {
+		return nil, err
+	}
+
+	if slices.Equal(slices.Sorted(slices.Values(sizes)), slices.Sorted(slices.Values(r.current.PackSizes))) {
+		return copyConfiguration(&r.current), nil
+	}
+
+	cfg := r.replacePackSizes(ctx, packSizesChange{
+		sizes:     sizes,
+		updatedBy: updatedBy,
+		reason:    reason,
+	})
+	return cfg, nil
+}
+
+// replacePackSizes archives the current configuration, replaces it and records the change in the audit log
+// The caller must hold the lock
+func (r *memoryRepo) replacePackSizes(ctx context.Context, change packSizesChange) *model.PackConfiguration {
+	now := r.now()
+	previous := r.current
+
+	archived := previous
+	archived.ID = len(r.history) + 1
+	archived.UpdatedAt = now
+	r.history = append(r.history, memoryHistoryEntry{cfg: archived, validFrom: previous.UpdatedAt})
+
+	r.current = model.PackConfiguration{
+		ID:         1,
+		Version:    previous.Version + 1,
+		PackSizes:  slices.Clone(change.sizes),
+		UpdatedAt:  now,
+		UpdatedBy:  change.updatedBy,
+		ApprovedBy: change.approvedBy,
+	}
+
+	md := reqctx.FromContext(ctx)
+	r.audit = append(r.audit, &model.AuditEntry{
+		ID:                len(r.audit) + 1,
+		Version:           r.current.Version,
+		PreviousVersion:   previous.Version,
+		PackSizes:         slices.Clone(r.current.PackSizes),
+		PreviousPackSizes: slices.Clone(previous.PackSizes),
+		Reason:            change.reason,
+		UpdatedBy:         change.updatedBy,
+		ApprovedBy:        change.approvedBy,
+		DraftID:           change.draftID,
+		Identity:          md.Identity,
+		RequestID:         md.RequestID,
+		ClientIP:          md.ClientIP,
+		CreatedAt:         now,
+	})
+
+	return copyConfiguration(&r.current)
+}
+
+// GetPackConfigurationHistory returns historical configurations, newest first
+func (r *memoryRepo) GetPackConfigurationHistory(ctx context.Context, limit int) ([]*model.PackConfiguration, error) {
+	// Validate and cap limit to prevent resource exhaustion
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var configs []*model.PackConfiguration
+	for i := len(r.history) - 1; i >= 0 && len(configs) < limit; i-- {
+		configs = append(configs, r.history[i].configuration())
+	}
+	return configs, nil
+}
+
+// GetFullPackConfigurationHistory returns every historical configuration ordered from oldest to newest
+func (r *memoryRepo) GetFullPackConfigurationHistory(ctx context.Context) ([]*model.PackConfiguration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var configs []*model.PackConfiguration
+	for i := range r.history {
+		configs = append(configs, r.history[i].configuration())
+	}
+	return configs, nil
+}
+
+// CreateDraft stores a pending draft based on the current configuration version
+func (r *memoryRepo) CreateDraft(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.PackConfigurationDraft, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	draft := &model.PackConfigurationDraft{
+		ID:          len(r.drafts) + 1,
+		PackSizes:   slices.Clone(sizes),
+		Status:      model.DraftStatusPending,
+		BaseVersion: r.current.Version,
+		CreatedAt:   r.now(),
+		UpdatedBy:   updatedBy,
+		Reason:      reason,
+	}
+	r.drafts = append(r.drafts, draft)
+
+	return copyDraft(draft), nil
+}
+
+// GetDraft returns a single draft by ID
+func (r *memoryRepo) GetDraft(ctx context.Context, id int) (*model.PackConfigurationDraft, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	draft, err := r.findDraft(id)
+	if err != nil {
+		return nil, err
+	}
+	return copyDraft(draft), nil
+}
+
+// ListDrafts returns drafts ordered by creation time descending, optionally filtered by status
+func (r *memoryRepo) ListDrafts(ctx context.Context, status model.DraftStatus, limit int) ([]*model.PackConfigurationDraft, error) {
+	// Validate and cap limit to prevent resource exhaustion
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var drafts []*model.PackConfigurationDraft
+	for i := len(r.drafts) - 1; i >= 0 && len(drafts) < limit; i-- {
+		if status == "" || r.drafts[i].Status == status {
+			drafts = append(drafts, copyDraft(r.drafts[i]))
+		}
+	}
+	return drafts, nil
+}
+
+// ApproveDraft applies a pending draft as the active configuration and records the reviewer
+func (r *memoryRepo) ApproveDraft(ctx context.Context, id int, reviewedBy string) (*model.PackConfiguration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	draft, err := r.findDraft(id)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status != model.DraftStatusPending {
+		return nil, ErrDraftNotPending
+	}
+
+	// Reviewer approved a change against a configuration that no longer exists
+	if r.current.Version != draft.BaseVersion {
+		return nil, ErrVersionConflict
+	}
+
+	draftID := draft.ID
+	cfg := r.replacePackSizes(ctx, packSizesChange{
+		sizes:      draft.PackSizes,
+		updatedBy:  draft.UpdatedBy,
+		approvedBy: reviewedBy,
+		reason:     draft.Reason,
+		draftID:    &draftID,
+	})
+
+	reviewedAt := r.now()
+	appliedVersion := cfg.Version
+	draft.Status = model.DraftStatusApproved
+	draft.ReviewedAt = &reviewedAt
+	draft.ReviewedBy = reviewedBy
+	draft.AppliedVersion = &appliedVersion
+
+	return cfg, nil
+}
+
+// RejectDraft marks a pending draft as rejected
+func (r *memoryRepo) RejectDraft(ctx context.Context, id int, reviewedBy string, comment string) (*model.PackConfigurationDraft, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	draft, err := r.findDraft(id)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status != model.DraftStatusPending {
+		return nil, ErrDraftNotPending
+	}
+
+	reviewedAt := r.now()
+	draft.Status = model.DraftStatusRejected
+	draft.ReviewedAt = &reviewedAt
+	draft.ReviewedBy = reviewedBy
+	draft.ReviewComment = comment
+
+	return copyDraft(draft), nil
+}
+
+// findDraft returns the stored draft with the given ID, the caller must hold the lock
+func (r *memoryRepo) findDraft(id int) (*model.PackConfigurationDraft, error) {
+	// draft IDs are assigned sequentially from 1
+	if id <= 0 || id > len(r.drafts) {
+		return nil, ErrNotFound
+	}
+	return r.drafts[id-1], nil
+}
+
+// ListAuditEntries returns configuration changes ordered from newest to oldest
+func (r *memoryRepo) ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	// Validate and cap limit to prevent resource exhaustion
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var entries []*model.AuditEntry
+	for i := len(r.audit) - 1; i >= 0 && len(entries) < limit; i-- {
+		entry := r.audit[i]
+		if filter.UpdatedBy != "" && entry.UpdatedBy != filter.UpdatedBy {
+			continue
+		}
+		if filter.RequestID != "" && entry.RequestID != filter.RequestID {
+			continue
+		}
+		copied := *entry
+		copied.PackSizes = slices.Clone(entry.PackSizes)
+		copied.PreviousPackSizes = slices.Clone(entry.PreviousPackSizes)
+		entries = append(entries, &copied)
+	}
+	return entries, nil
+}
+
+// copyConfiguration returns a copy that does not share the pack sizes slice with the store
+func copyConfiguration(cfg *model.PackConfiguration) *model.PackConfiguration {
+	copied := *cfg
+	copied.PackSizes = slices.Clone(cfg.PackSizes)
+	return &copied
+}
+
+// copyDraft returns a copy that does not share mutable fields with the store
+func copyDraft(draft *model.PackConfigurationDraft) *model.PackConfigurationDraft {
+	copied := *draft
+	copied.PackSizes = slices.Clone(draft.PackSizes)
+	if draft.ReviewedAt != nil {
+		reviewedAt := *draft.ReviewedAt
+		copied.ReviewedAt = &reviewedAt
+	}
+	if draft.AppliedVersion != nil {
+		appliedVersion := *draft.AppliedVersion
+		copied.AppliedVersion = &appliedVersion
+	}
+	return &copied
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/reqctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// steppingClock returns a clock that advances by a minute on every call
+func steppingClock(start time.Time) func() time.Time {
+	now := start
+	return func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+}
+
+func TestMemoryRepo_UpdateAndHistory(t *testing.T) {
+	ctx := reqctx.WithMetadata(context.Background(), reqctx.Metadata{RequestID: "req-1", ClientIP: "192.0.2.1"})
+	repo := newMemoryRepo()
+
+	cfg, err := repo.UpdatePackSizes(ctx, []int{23, 31, 53}, "alice", "new boxes")
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.Version)
+	assert.Equal(t, []int{23, 31, 53}, cfg.PackSizes)
+
+	// returned slices are copies
+	cfg.PackSizes[0] = 1
+	sizes, err := repo.GetPackSizes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int{23, 31, 53}, sizes)
+
+	history, err := repo.GetPackConfigurationHistory(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, 1, history[0].Version)
+	assert.Equal(t, DefaultPackSizes, history[0].PackSizes)
+	assert.Equal(t, "system", history[0].UpdatedBy)
+
+	entries, err := repo.ListAuditEntries(ctx, model.AuditFilter{RequestID: "req-1"})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, 2, entries[0].Version)
+	assert.Equal(t, DefaultPackSizes, entries[0].PreviousPackSizes)
+	assert.Equal(t, "new boxes", entries[0].Reason)
+	assert.Equal(t, "192.0.2.1", entries[0].ClientIP)
+}
+
+func TestMemoryRepo_ModifyPackSizes(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo()
+
+	t.Run("unchanged set keeps the version", func(t *testing.T) {
+		cfg, err := repo.ModifyPackSizes(ctx, "alice", "noop", func(current []int) ([]int, error) {
+			return current, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, cfg.Version)
+	})
+	t.Run("change error is returned as is", func(t *testing.T) {
+		errRejected := errors.New("rejected")
+		_, err := repo.ModifyPackSizes(ctx, "alice", "bad", func(current []int) ([]int, error) {
+			return nil, errRejected
+		})
+		assert.ErrorIs(t, err, errRejected)
+	})
+	t.Run("concurrent changes are not lost", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for size := 1; size <= 20; size++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := repo.ModifyPackSizes(ctx, "worker", "add", func(current []int) ([]int, error) {
+					return append(current, size), nil
+				})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		cfg, err := repo.GetPackConfiguration(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 21, cfg.Version)
+		assert.Len(t, cfg.PackSizes, len(DefaultPackSizes)+20)
+	})
+}
+
+func TestMemoryRepo_AsOf(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := newMemoryRepo()
+	repo.now = steppingClock(start)
+	repo.current.UpdatedAt = start
+
+	// version 2 becomes active at start+1m, version 3 at start+2m
+	_, err := repo.UpdatePackSizes(ctx, []int{100}, "alice", "v2")
+	require.NoError(t, err)
+	_, err = repo.UpdatePackSizes(ctx, []int{200}, "alice", "v3")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		version int
+		err     error
+	}{
+		{name: "before first version", at: start.Add(-time.Second), err: ErrNotFound},
+		{name: "first version", at: start.Add(30 * time.Second), version: 1},
+		{name: "archived version", at: start.Add(90 * time.Second), version: 2},
+		{name: "current version", at: start.Add(time.Hour), version: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := repo.GetPackConfigurationAsOf(ctx, tt.at)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.version, cfg.Version)
+		})
+	}
+
+	cfg, err := repo.GetPackConfigurationByVersion(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{100}, cfg.PackSizes)
+
+	_, err = repo.GetPackConfigurationByVersion(ctx, 9)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryRepo_Drafts(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo()
+
+	draft, err := repo.CreateDraft(ctx, []int{250, 750}, "alice", "peak season")
+	require.NoError(t, err)
+	assert.Equal(t, model.DraftStatusPending, draft.Status)
+	assert.Equal(t, 1, draft.BaseVersion)
+
+	stale, err := repo.CreateDraft(ctx, []int{300}, "carol", "smaller boxes")
+	require.NoError(t, err)
+
+	cfg, err := repo.ApproveDraft(ctx, draft.ID, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.Version)
+	assert.Equal(t, "bob", cfg.ApprovedBy)
+
+	approved, err := repo.GetDraft(ctx, draft.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.DraftStatusApproved, approved.Status)
+	assert.Equal(t, 2, *approved.AppliedVersion)
+
+	_, err = repo.ApproveDraft(ctx, draft.ID, "bob")
+	assert.ErrorIs(t, err, ErrDraftNotPending)
+
+	_, err = repo.ApproveDraft(ctx, stale.ID, "bob")
+	assert.ErrorIs(t, err, ErrVersionConflict)
+
+	rejected, err := repo.RejectDraft(ctx, stale.ID, "bob", "outdated")
+	require.NoError(t, err)
+	assert.Equal(t, model.DraftStatusRejected, rejected.Status)
+
+	pending, err := repo.ListDrafts(ctx, model.DraftStatusPending, 0)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	_, err = repo.GetDraft(ctx, 42)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
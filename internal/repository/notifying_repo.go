@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// NotifyingRepo is a PackRepository decorator that calls onChange after every successful change of the active configuration
+// It only sees writes made through this process, PostgreSQL deployments use database notifications instead
+type NotifyingRepo struct {
+	PackRepository
+	onChange func()
+}
+
+// NewNotifyingRepo wraps repo so that onChange is called after the active configuration changed
+func NewNotifyingRepo(repo PackRepository, onChange func()) *NotifyingRepo {
+	return &NotifyingRepo{
+		PackRepository: repo,
+		onChange:       onChange,
+	}
+}
+
+// UpdatePackSizes replaces the pack sizes and reports the change
+func (r *NotifyingRepo) UpdatePackSizes(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.PackConfiguration, error) {
+	return r.notify(r.PackRepository.UpdatePackSizes(ctx, sizes, updatedBy, reason))
+}
+
+// ModifyPackSizes changes the pack sizes and reports the change
+func (r *NotifyingRepo) ModifyPackSizes(ctx context.Context, updatedBy string, reason string, change func(current []int) ([]int, error)) (*model.PackConfiguration, error) {
+	return r.notify(r.PackRepository.ModifyPackSizes(ctx, updatedBy, reason, change))
+}
+
+// ApproveDraft applies the draft and reports the change
+func (r *NotifyingRepo) ApproveDraft(ctx context.Context, id int, reviewedBy string) (*model.PackConfiguration, error) {
+	return r.notify(r.PackRepository.ApproveDraft(ctx, id, reviewedBy))
+}
+
+// notify calls onChange when the write succeeded and passes its result through
+func (r *NotifyingRepo) notify(cfg *model.PackConfiguration, err error) (*model.PackConfiguration, error) {
+	if err == nil {
+		r.onChange()
+	}
+	return cfg, err
+}
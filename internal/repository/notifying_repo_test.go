@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifyingRepo(t *testing.T) {
+	ctx := context.Background()
+	changes := 0
+	repo := NewNotifyingRepo(NewMemoryRepo(), func() { changes++ })
+
+	_, err := repo.UpdatePackSizes(ctx, []int{23, 31}, "alice", "new boxes")
+	require.NoError(t, err)
+	_, err = repo.ModifyPackSizes(ctx, "alice", "add 53", func(current []int) ([]int, error) {
+		return append(current, 53), nil
+	})
+	require.NoError(t, err)
+	draft, err := repo.CreateDraft(ctx, []int{250}, "alice", "peak season")
+	require.NoError(t, err)
+	_, err = repo.ApproveDraft(ctx, draft.ID, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, 3, changes)
+
+	// failed writes and reads are not changes
+	_, err = repo.ModifyPackSizes(ctx, "alice", "no-op", func(current []int) ([]int, error) {
+		return nil, assert.AnError
+	})
+	assert.Error(t, err)
+	_, err = repo.GetPackSizes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, changes)
+}
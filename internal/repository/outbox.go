@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// OutboxDelivery is the outcome of relaying an outbox event
+// Pending events are attempted again after RetryAfter, delivered and failed events are final
+type OutboxDelivery struct {
+	Status         model.OutboxStatus
+	DeliveredSinks []string
+	LastError      string
+	RetryAfter     time.Duration
+}
+
+// OutboxRepository gives the outbox relay access to the change events written with configuration changes
+type OutboxRepository interface {
+	// ClaimOutboxEvents leases up to limit pending events that are due, oldest first, and counts the attempt
+	// Leased events are skipped by other relays until the lease expires
+	ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxEvent, error)
+
+	// RecordOutboxDelivery stores the outcome of relaying the event
+	RecordOutboxDelivery(ctx context.Context, id int64, delivery OutboxDelivery) error
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// uniqueViolation is the SQLSTATE of a unique constraint violation
+const uniqueViolation = "23505"
+
+// apiKeyColumns lists the api_keys columns in the order scanAPIKey expects
+const apiKeyColumns = `id, name, prefix, scopes, COALESCE(created_by, ''), created_at, last_used_at, revoked_at`
+
+// postgresAPIKeyRepo implements the APIKeyRepository interface using PostgreSQL
+type postgresAPIKeyRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresAPIKeyRepo creates a new PostgreSQL API key repository
+func NewPostgresAPIKeyRepo(pool *pgxpool.Pool) APIKeyRepository {
+	return &postgresAPIKeyRepo{
+		pool: pool,
+	}
+}
+
+// CreateAPIKey stores a new key with the hash of its secret
+func (s *postgresAPIKeyRepo) CreateAPIKey(ctx context.Context, key *model.APIKey, hash string) (*model.APIKey, error) {
+	row := s.pool.QueryRow(ctx, `
+		INSERT INTO api_keys (name, prefix, key_hash, scopes, created_by)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		RETURNING `+apiKeyColumns, key.Name, key.Prefix, hash, scopeStrings(key.Scopes), key.CreatedBy)
+
+	created, err := scanAPIKey(row)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == "api_keys_name_key" {
+			return nil, ErrAPIKeyNameTaken
+		}
+		return nil, err
+	}
+	return created, nil
+}
+
+// GetAPIKeyByHash returns the key with the given hash, including revoked keys
+func (s *postgresAPIKeyRepo) GetAPIKeyByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	row := s.pool.QueryRow(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE key_hash = $1`, hash)
+
+	key, err := scanAPIKey(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// ListAPIKeys returns all keys ordered by ID, including revoked keys
+func (s *postgresAPIKeyRepo) ListAPIKeys(ctx context.Context) ([]*model.APIKey, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*model.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey marks a key as revoked, revoking a revoked key keeps its original revocation time
+func (s *postgresAPIKeyRepo) RevokeAPIKey(ctx context.Context, id int) (*model.APIKey, error) {
+	row := s.pool.QueryRow(ctx, `
+		UPDATE api_keys
+		SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP)
+		WHERE id = $1
+		RETURNING `+apiKeyColumns, id)
+
+	key, err := scanAPIKey(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// TouchAPIKey records that the key was used now
+func (s *postgresAPIKeyRepo) TouchAPIKey(ctx context.Context, id int) error {
+	_, err := s.pool.Exec(ctx, `UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP WHERE id = $1`, id)
+	return err
+}
+
+// scanAPIKey scans a row selected with apiKeyColumns
+func scanAPIKey(row pgx.Row) (*model.APIKey, error) {
+	var key model.APIKey
+	var scopes []string
+	var createdAt, lastUsedAt, revokedAt pgtype.Timestamp
+
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&scopes,
+		&key.CreatedBy,
+		&createdAt,
+		&lastUsedAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, model.Scope(scope))
+	}
+	key.CreatedAt = createdAt.Time
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, nil
+}
+
+// scopeStrings converts scopes for storage in a TEXT[] column
+func scopeStrings(scopes []model.Scope) []string {
+	values := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		values = append(values, string(scope))
+	}
+	return values
+}
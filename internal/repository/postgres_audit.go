@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// ListAuditEntries returns configuration changes ordered from newest to oldest
+func (s *postgresRepo) ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	// Validate and cap limit to prevent resource exhaustion
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, version, previous_version, pack_sizes, previous_pack_sizes, reason,
+		       COALESCE(updated_by, ''), COALESCE(approved_by, ''), draft_id,
+		       COALESCE(identity, ''), COALESCE(request_id, ''), COALESCE(client_ip, ''), created_at
+		FROM pack_configuration_audit
+		WHERE ($1 = '' OR updated_by = $1)
+		  AND ($2 = '' OR request_id = $2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3`, filter.UpdatedBy, filter.RequestID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*model.AuditEntry
+	for rows.Next() {
+		var entry model.AuditEntry
+		var createdAt pgtype.Timestamp
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.Version,
+			&entry.PreviousVersion,
+			&entry.PackSizes,
+			&entry.PreviousPackSizes,
+			&entry.Reason,
+			&entry.UpdatedBy,
+			&entry.ApprovedBy,
+			&entry.DraftID,
+			&entry.Identity,
+			&entry.RequestID,
+			&entry.ClientIP,
+			&createdAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.CreatedAt = createdAt.Time
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/nsaltun/packman/internal/model"
+)
+
+const draftColumns = `id, pack_sizes, status, base_version, created_at, created_by, reason,
+		reviewed_at, COALESCE(reviewed_by, ''), COALESCE(review_comment, ''), applied_version`
+
+// CreateDraft stores a pending draft based on the current configuration version
+func (s *postgresRepo) CreateDraft(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.PackConfigurationDraft, error) {
+	row := s.pool.QueryRow(ctx, `
+		INSERT INTO pack_configuration_drafts (pack_sizes, base_version, created_by, reason)
+		SELECT $1, version, $2, $3
+		FROM pack_configuration
+		WHERE id = 1
+		RETURNING `+draftColumns, sizes, updatedBy, reason)
+
+	draft, err := scanDraft(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+// GetDraft returns a single draft by ID
+func (s *postgresRepo) GetDraft(ctx context.Context, id int) (*model.PackConfigurationDraft, error) {
+	row := s.pool.QueryRow(ctx, `
+		SELECT `+draftColumns+`
+		FROM pack_configuration_drafts
+		WHERE id = $1`, id)
+
+	draft, err := scanDraft(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+// ListDrafts returns drafts ordered by creation time descending, optionally filtered by status
+func (s *postgresRepo) ListDrafts(ctx context.Context, status model.DraftStatus, limit int) ([]*model.PackConfigurationDraft, error) {
+	// Validate and cap limit to prevent resource exhaustion
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+draftColumns+`
+		FROM pack_configuration_drafts
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2`, string(status), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var drafts []*model.PackConfigurationDraft
+	for rows.Next() {
+		draft, err := scanDraft(rows)
+		if err != nil {
+			return nil, err
+		}
+		drafts = append(drafts, draft)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return drafts, nil
+}
+
+// ApproveDraft applies a pending draft as the active configuration and records the reviewer
+// The draft and configuration rows are locked in the same transaction so a draft can only be applied once
+func (s *postgresRepo) ApproveDraft(ctx context.Context, id int, reviewedBy string) (*model.PackConfiguration, error) {
+	var cfg *model.PackConfiguration
+	err := s.inTx(ctx, pgx.Serializable, func(tx pgx.Tx) error {
+		draft, err := lockPendingDraft(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		current, err := lockPackConfiguration(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		// Reviewer approved a change against a configuration that no longer exists
+		if current.Version != draft.BaseVersion {
+			return ErrVersionConflict
+		}
+
+		cfg, err = replacePackSizes(ctx, tx, current, packSizesChange{
+			sizes:      draft.PackSizes,
+			updatedBy:  draft.UpdatedBy,
+			approvedBy: reviewedBy,
+			reason:     draft.Reason,
+			draftID:    &draft.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+			UPDATE pack_configuration_drafts
+			SET status = 'approved',
+			    reviewed_at = CURRENT_TIMESTAMP,
+			    reviewed_by = $2,
+			    applied_version = $3
+			WHERE id = $1`, id, reviewedBy, cfg.Version)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// RejectDraft marks a pending draft as rejected
+func (s *postgresRepo) RejectDraft(ctx context.Context, id int, reviewedBy string, comment string) (*model.PackConfigurationDraft, error) {
+	var draft *model.PackConfigurationDraft
+	err := s.inTx(ctx, pgx.ReadCommitted, func(tx pgx.Tx) error {
+		if _, err := lockPendingDraft(ctx, tx, id); err != nil {
+			return err
+		}
+
+		row := tx.QueryRow(ctx, `
+			UPDATE pack_configuration_drafts
+			SET status = 'rejected',
+			    reviewed_at = CURRENT_TIMESTAMP,
+			    reviewed_by = $2,
+			    review_comment = NULLIF($3, '')
+			WHERE id = $1
+			RETURNING `+draftColumns, id, reviewedBy, comment)
+
+		var err error
+		draft, err = scanDraft(row)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+// lockPendingDraft locks a draft row and ensures it has not been reviewed yet
+func lockPendingDraft(ctx context.Context, tx pgx.Tx, id int) (*model.PackConfigurationDraft, error) {
+	row := tx.QueryRow(ctx, `
+		SELECT `+draftColumns+`
+		FROM pack_configuration_drafts
+		WHERE id = $1
+		FOR UPDATE`, id)
+
+	draft, err := scanDraft(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if draft.Status != model.DraftStatusPending {
+		return nil, ErrDraftNotPending
+	}
+
+	return draft, nil
+}
+
+// scanDraft scans a row selected with draftColumns into a draft
+func scanDraft(row pgx.Row) (*model.PackConfigurationDraft, error) {
+	var draft model.PackConfigurationDraft
+	var status string
+	var createdAt, reviewedAt pgtype.Timestamp
+
+	err := row.Scan(
+		&draft.ID,
+		&draft.PackSizes,
+		&status,
+		&draft.BaseVersion,
+		&createdAt,
+		&draft.UpdatedBy,
+		&draft.Reason,
+		&reviewedAt,
+		&draft.ReviewedBy,
+		&draft.ReviewComment,
+		&draft.AppliedVersion,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	draft.Status = model.DraftStatus(status)
+	draft.CreatedAt = createdAt.Time
+	if reviewedAt.Valid {
+		draft.ReviewedAt = &reviewedAt.Time
+	}
+
+	return &draft, nil
+}
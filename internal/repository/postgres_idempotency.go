@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// maxClaimAttempts bounds the retries when a key is removed between the claim and the read of the existing request
+const maxClaimAttempts = 3
+
+// postgresIdempotencyRepo implements the IdempotencyRepository interface using PostgreSQL, shared by all instances
+type postgresIdempotencyRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresIdempotencyRepo creates a new PostgreSQL idempotency repository
+func NewPostgresIdempotencyRepo(pool *pgxpool.Pool) IdempotencyRepository {
+	return &postgresIdempotencyRepo{
+		pool: pool,
+	}
+}
+
+// ClaimIdempotencyKey records a request with fingerprint under key, unless the key is already in use
+// Concurrent requests with the same key are serialized by the primary key, only one of them claims it
+func (s *postgresIdempotencyRepo) ClaimIdempotencyKey(ctx context.Context, key, fingerprint string, ttl, lockTimeout time.Duration) (*model.IdempotencyRecord, error) {
+	for range maxClaimAttempts {
+		tag, err := s.pool.Exec(ctx, `
+			INSERT INTO idempotency_keys AS k (key, fingerprint, locked_until, expires_at)
+			VALUES ($1, $2, now() + make_interval(secs => $3), now() + make_interval(secs => $4))
+			ON CONFLICT (key) DO UPDATE
+			SET fingerprint = EXCLUDED.fingerprint,
+			    status_code = NULL,
+			    content_type = NULL,
+			    body = NULL,
+			    locked_until = EXCLUDED.locked_until,
+			    expires_at = EXCLUDED.expires_at,
+			    created_at = now()
+			WHERE k.expires_at <= now()
+			   OR (k.status_code IS NULL AND k.locked_until <= now() AND k.fingerprint = EXCLUDED.fingerprint)`,
+			key, fingerprint, lockTimeout.Seconds(), ttl.Seconds())
+		if err != nil {
+			return nil, err
+		}
+		if tag.RowsAffected() == 1 {
+			return nil, nil
+		}
+
+		var record model.IdempotencyRecord
+		var statusCode *int
+		var contentType *string
+		var body []byte
+		err = s.pool.QueryRow(ctx, `
+			SELECT fingerprint, status_code, content_type, body
+			FROM idempotency_keys
+			WHERE key = $1`, key).Scan(&record.Fingerprint, &statusCode, &contentType, &body)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// released or expired in the meantime, claim it again
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if statusCode != nil {
+			record.Response = &model.IdempotentResponse{StatusCode: *statusCode, Body: body}
+			if contentType != nil {
+				record.Response.ContentType = *contentType
+			}
+		}
+		return &record, nil
+	}
+	return nil, fmt.Errorf("idempotency key %q changed during %d claim attempts", key, maxClaimAttempts)
+}
+
+// CompleteIdempotencyKey stores the response of a claimed request to be replayed
+func (s *postgresIdempotencyRepo) CompleteIdempotencyKey(ctx context.Context, key string, response *model.IdempotentResponse) error {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE idempotency_keys
+		SET status_code = $2, content_type = NULLIF($3, ''), body = $4
+		WHERE key = $1 AND status_code IS NULL`,
+		key, response.StatusCode, response.ContentType, response.Body)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey removes a claimed request without response
+func (s *postgresIdempotencyRepo) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	_, err := s.pool.Exec(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE key = $1 AND status_code IS NULL`, key)
+	return err
+}
+
+// DeleteExpiredIdempotencyKeys removes the keys past their ttl
+func (s *postgresIdempotencyRepo) DeleteExpiredIdempotencyKeys(ctx context.Context) (int, error) {
+	tag, err := s.pool.Exec(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE expires_at <= now()`)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
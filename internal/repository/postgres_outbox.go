@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// postgresOutboxRepo implements the OutboxRepository interface using PostgreSQL
+type postgresOutboxRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresOutboxRepo creates a new PostgreSQL outbox repository
+func NewPostgresOutboxRepo(pool *pgxpool.Pool) OutboxRepository {
+	return &postgresOutboxRepo{
+		pool: pool,
+	}
+}
+
+// insertOutboxEvent writes an event into the outbox as part of the transaction
+func insertOutboxEvent(ctx context.Context, tx pgx.Tx, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO pack_configuration_outbox (event_type, payload)
+		VALUES ($1, $2)`, eventType, data)
+	return err
+}
+
+// ClaimOutboxEvents leases due pending events by moving their next attempt past the lease
+// SKIP LOCKED lets several relays claim disjoint batches concurrently
+func (s *postgresOutboxRepo) ClaimOutboxEvents(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxEvent, error) {
+	rows, err := s.pool.Query(ctx, `
+		UPDATE pack_configuration_outbox
+		SET attempts = attempts + 1,
+		    next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id
+			FROM pack_configuration_outbox
+			WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED)
+		RETURNING id, event_type, payload, status, attempts, COALESCE(last_error, ''),
+		          delivered_sinks, next_attempt_at, created_at, delivered_at`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*model.OutboxEvent
+	for rows.Next() {
+		var event model.OutboxEvent
+		var status string
+		var nextAttemptAt, createdAt, deliveredAt pgtype.Timestamp
+
+		err := rows.Scan(
+			&event.ID,
+			&event.Type,
+			&event.Payload,
+			&status,
+			&event.Attempts,
+			&event.LastError,
+			&event.DeliveredSinks,
+			&nextAttemptAt,
+			&createdAt,
+			&deliveredAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		event.Status = model.OutboxStatus(status)
+		event.NextAttemptAt = nextAttemptAt.Time
+		event.CreatedAt = createdAt.Time
+		if deliveredAt.Valid {
+			event.DeliveredAt = &deliveredAt.Time
+		}
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the order of the subquery
+	slices.SortFunc(events, func(a, b *model.OutboxEvent) int {
+		return int(a.ID - b.ID)
+	})
+	return events, nil
+}
+
+// RecordOutboxDelivery stores the outcome of relaying the event
+func (s *postgresOutboxRepo) RecordOutboxDelivery(ctx context.Context, id int64, delivery OutboxDelivery) error {
+	deliveredSinks := delivery.DeliveredSinks
+	if deliveredSinks == nil {
+		deliveredSinks = []string{}
+	}
+
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE pack_configuration_outbox
+		SET status = $2,
+		    delivered_sinks = $3,
+		    last_error = NULLIF($4, ''),
+		    next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $5),
+		    delivered_at = CASE WHEN $2::VARCHAR = 'delivered' THEN CURRENT_TIMESTAMP END
+		WHERE id = $1`, id, string(delivery.Status), deliveredSinks, delivery.LastError, delivery.RetryAfter.Seconds())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
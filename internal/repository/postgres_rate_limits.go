@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// availableTokens is the content of an existing bucket refilled up to now, $2 is the capacity and $3 the rate
+const availableTokens = `LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM now() - b.updated_at)::float8, 0) * $3::float8)`
+
+// postgresRateLimitRepo implements the RateLimitRepository interface using PostgreSQL, shared by all instances
+type postgresRateLimitRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRateLimitRepo creates a new PostgreSQL rate limit repository
+func NewPostgresRateLimitRepo(pool *pgxpool.Pool) RateLimitRepository {
+	return &postgresRateLimitRepo{
+		pool: pool,
+	}
+}
+
+// TakeRateLimitToken refills the bucket with rate tokens per second up to capacity and takes a token if there is one
+// The refill and the take are a single statement, concurrent requests of the same client queue on the row lock
+func (s *postgresRateLimitRepo) TakeRateLimitToken(ctx context.Context, key string, capacity int, rate float64) (RateLimitBucket, error) {
+	var bucket RateLimitBucket
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+		VALUES ($1, $2::float8 - 1, true, now())
+		ON CONFLICT (key) DO UPDATE
+		SET tokens = CASE WHEN `+availableTokens+` >= 1 THEN `+availableTokens+` - 1 ELSE `+availableTokens+` END,
+		    allowed = `+availableTokens+` >= 1,
+		    updated_at = now()
+		RETURNING allowed, tokens`, key, float64(capacity), rate).Scan(&bucket.Allowed, &bucket.Tokens)
+	if err != nil {
+		return RateLimitBucket{}, err
+	}
+	return bucket, nil
+}
+
+// DeleteIdleRateLimitBuckets removes buckets not used for idle
+func (s *postgresRateLimitRepo) DeleteIdleRateLimitBuckets(ctx context.Context, idle time.Duration) (int, error) {
+	tag, err := s.pool.Exec(ctx, `
+		DELETE FROM rate_limit_buckets
+		WHERE updated_at < now() - make_interval(secs => $1)`, idle.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
@@ -3,38 +3,61 @@ package repository
 import (
 	"context"
 	"errors"
-	"log/slog"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/reqctx"
+	"github.com/nsaltun/packman/pkg/postgres"
 )
 
 var (
 	// ErrNotFound indicates the requested resource was not found
 	ErrNotFound = errors.New("resource not found")
+	// ErrDraftNotPending indicates the draft has already been approved or rejected
+	ErrDraftNotPending = errors.New("draft is not pending")
+	// ErrVersionConflict indicates the active configuration changed since the draft was created
+	ErrVersionConflict = errors.New("configuration version conflict")
+	// ErrConcurrentUpdate indicates a write kept conflicting with concurrent writes and was given up
+	ErrConcurrentUpdate = errors.New("concurrent update")
+	// ErrUnavailable indicates the storage kept refusing a write and it was given up
+	ErrUnavailable = errors.New("storage unavailable")
 )
 
+// PackConfigurationChannel is the notification channel the database signals configuration changes on
+const PackConfigurationChannel = "pack_configuration_changed"
+
 // postgresRepo implements the PackRepository interface using PostgreSQL
+// Reads of the active configuration and history go through db.Read and may be served by a read replica,
+// drafts, audit entries and everything inside transactions use the primary
 type postgresRepo struct {
-	pool *pgxpool.Pool
+	db    *postgres.Client
+	pool  *pgxpool.Pool
+	retry postgres.TxRetryPolicy
 }
 
 // NewPostgresRepo creates a new PostgreSQL repository
-func NewPostgresRepo(pool *pgxpool.Pool) PackRepository {
+func NewPostgresRepo(db *postgres.Client) PackRepository {
 	return &postgresRepo{
-		pool: pool,
+		db:    db,
+		pool:  db.Pool,
+		retry: postgres.DefaultTxRetryPolicy,
 	}
 }
 
 // GetPackSizes returns the current active pack sizes
 func (s *postgresRepo) GetPackSizes(ctx context.Context) ([]int, error) {
 	var sizes []int
-	err := s.pool.QueryRow(ctx, `
-		SELECT pack_sizes 
-		FROM pack_configuration 
-		WHERE id = 1`).Scan(&sizes)
+	err := s.db.Read(ctx, func(q postgres.Querier) error {
+		return q.QueryRow(ctx, `
+			SELECT pack_sizes 
+			FROM pack_configuration 
+			WHERE id = 1`).Scan(&sizes)
+	})
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, ErrNotFound
@@ -47,11 +70,29 @@ func (s *postgresRepo) GetPackSizes(ctx context.Context) ([]int, error) {
 
 // GetPackConfiguration returns the full configuration with metadata
 func (s *postgresRepo) GetPackConfiguration(ctx context.Context) (*model.PackConfiguration, error) {
+	var cfg *model.PackConfiguration
+	err := s.db.Read(ctx, func(q postgres.Querier) error {
+		var err error
+		cfg, err = getPackConfiguration(ctx, q)
+		return err
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// getPackConfiguration reads the active configuration row
+func getPackConfiguration(ctx context.Context, q postgres.Querier) (*model.PackConfiguration, error) {
 	var cfg model.PackConfiguration
 	var updatedAt pgtype.Timestamp
 
-	err := s.pool.QueryRow(ctx, `
-		SELECT id, version, pack_sizes, updated_at, COALESCE(updated_by, '') 
+	err := q.QueryRow(ctx, `
+		SELECT id, version, pack_sizes, updated_at, COALESCE(updated_by, ''), COALESCE(approved_by, '') 
 		FROM pack_configuration 
 		WHERE id = 1`).Scan(
 		&cfg.ID,
@@ -59,8 +100,42 @@ func (s *postgresRepo) GetPackConfiguration(ctx context.Context) (*model.PackCon
 		&cfg.PackSizes,
 		&updatedAt,
 		&cfg.UpdatedBy,
+		&cfg.ApprovedBy,
 	)
+	if err != nil {
+		return nil, err
+	}
 
+	cfg.UpdatedAt = updatedAt.Time
+	return &cfg, nil
+}
+
+// GetPackConfigurationByVersion returns the configuration with the given version,
+// either the current row or an archived one from history
+func (s *postgresRepo) GetPackConfigurationByVersion(ctx context.Context, version int) (*model.PackConfiguration, error) {
+	var cfg model.PackConfiguration
+	var updatedAt pgtype.Timestamp
+
+	err := s.db.Read(ctx, func(q postgres.Querier) error {
+		return q.QueryRow(ctx, `
+			SELECT id, version, pack_sizes, updated_at, COALESCE(updated_by, ''), COALESCE(approved_by, '')
+			FROM pack_configuration
+			WHERE id = 1 AND version = $1
+			UNION ALL
+			(SELECT id, version, pack_sizes, created_at, COALESCE(created_by, ''), COALESCE(approved_by, '')
+			FROM pack_configuration_history
+			WHERE version = $1
+			ORDER BY id DESC
+			LIMIT 1)
+			LIMIT 1`, version).Scan(
+			&cfg.ID,
+			&cfg.Version,
+			&cfg.PackSizes,
+			&updatedAt,
+			&cfg.UpdatedBy,
+			&cfg.ApprovedBy,
+		)
+	})
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, ErrNotFound
@@ -72,36 +147,172 @@ func (s *postgresRepo) GetPackConfiguration(ctx context.Context) (*model.PackCon
 	return &cfg, nil
 }
 
+// GetPackConfigurationAsOf returns the configuration that was active at the given time
+// History rows are archived when they are superseded, so the active one is the first archived
+// after the given time, or the current row when nothing has been archived since
+func (s *postgresRepo) GetPackConfigurationAsOf(ctx context.Context, at time.Time) (*model.PackConfiguration, error) {
+	// timestamps are stored without time zone, compare in UTC like they are read
+	at = at.UTC()
+
+	var cfg *model.PackConfiguration
+	err := s.db.Read(ctx, func(q postgres.Querier) error {
+		var err error
+		cfg, err = getPackConfigurationAsOf(ctx, q, at)
+		return err
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// getPackConfigurationAsOf reads the configuration active at the given UTC time from history or the current row
+// Returns pgx.ErrNoRows when no configuration was active yet
+func getPackConfigurationAsOf(ctx context.Context, q postgres.Querier, at time.Time) (*model.PackConfiguration, error) {
+	var cfg model.PackConfiguration
+	var updatedAt, validFrom pgtype.Timestamp
+
+	err := q.QueryRow(ctx, `
+		SELECT id, version, pack_sizes, created_at, COALESCE(created_by, ''), COALESCE(approved_by, ''), valid_from
+		FROM pack_configuration_history
+		WHERE created_at > $1
+		ORDER BY created_at ASC, id ASC
+		LIMIT 1`, at).Scan(
+		&cfg.ID,
+		&cfg.Version,
+		&cfg.PackSizes,
+		&updatedAt,
+		&cfg.UpdatedBy,
+		&cfg.ApprovedBy,
+		&validFrom,
+	)
+	if err == pgx.ErrNoRows {
+		// nothing superseded since then, the current configuration was already active
+		current, err := getPackConfiguration(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		if current.UpdatedAt.After(at) {
+			return nil, pgx.ErrNoRows
+		}
+		return current, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the archived configuration only became active after the requested time
+	if validFrom.Valid && validFrom.Time.After(at) {
+		return nil, pgx.ErrNoRows
+	}
+
+	cfg.UpdatedAt = updatedAt.Time
+	if validFrom.Valid {
+		cfg.ValidFrom = &validFrom.Time
+	}
+	return &cfg, nil
+}
+
 // UpdatePackSizes updates the pack size configuration with ACID guarantees
 // Uses pessimistic locking (FOR UPDATE) to prevent lost updates caused by concurrent transactions
 // Returns the updated configuration immediately after the update
-func (s *postgresRepo) UpdatePackSizes(ctx context.Context, sizes []int, updatedBy string) (*model.PackConfiguration, error) {
-	// Begin transaction with serializable isolation
-	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{
-		IsoLevel: pgx.Serializable,
+// Serialization failures and deadlocks are retried a bounded number of times
+func (s *postgresRepo) UpdatePackSizes(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.PackConfiguration, error) {
+	var cfg *model.PackConfiguration
+	err := s.inTx(ctx, pgx.Serializable, func(tx pgx.Tx) error {
+		// Lock row to prevent concurrent modifications (pessimistic locking)
+		current, err := lockPackConfiguration(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		cfg, err = replacePackSizes(ctx, tx, current, packSizesChange{
+			sizes:     sizes,
+			updatedBy: updatedBy,
+			reason:    reason,
+		})
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Ensure transaction is rolled back only on error
-	defer func() {
+	return cfg, nil
+}
+
+// ModifyPackSizes applies a change function to the current pack sizes while holding the row lock
+// so the change is computed from, and written over, the same version
+// If the change function returns an error the transaction is rolled back and the error is returned as is
+// When the resulting set equals the current one no new version is written
+// change runs again when the transaction is retried, so it must not have side effects
+func (s *postgresRepo) ModifyPackSizes(ctx context.Context, updatedBy string, reason string, change func(current []int) ([]int, error)) (*model.PackConfiguration, error) {
+	var cfg *model.PackConfiguration
+	err := s.inTx(ctx, pgx.Serializable, func(tx pgx.Tx) error {
+		current, err := lockPackConfiguration(ctx, tx)
 		if err != nil {
-			if rbErr := tx.Rollback(ctx); rbErr != nil {
-				slog.ErrorContext(ctx, "failed to rollback transaction",
-					slog.String("error", rbErr.Error()),
-				)
-			}
+			return err
 		}
-	}()
 
-	// Lock row to prevent concurrent modifications (pessimistic locking)
-	var currentVersion int
-	err = tx.QueryRow(ctx, `
-		SELECT version 
+		sizes, err := change(slices.Clone(current.PackSizes))
+		if err != nil {
+			return err
+		}
+
+		cfg = current
+		if !slices.Equal(slices.Sorted(slices.Values(sizes)), slices.Sorted(slices.Values(current.PackSizes))) {
+			cfg, err = replacePackSizes(ctx, tx, current, packSizesChange{
+				sizes:     sizes,
+				updatedBy: updatedBy,
+				reason:    reason,
+			})
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// inTx runs fn in a transaction with the given isolation level, retrying serialization failures and deadlocks
+// Exhausted retries are reported as ErrConcurrentUpdate or ErrUnavailable
+func (s *postgresRepo) inTx(ctx context.Context, isoLevel pgx.TxIsoLevel, fn func(tx pgx.Tx) error) error {
+	err := postgres.RunInTx(ctx, s.pool, pgx.TxOptions{IsoLevel: isoLevel}, s.retry, fn)
+	// even a failed commit may have been applied, keep reading our own writes from the primary
+	s.db.MarkWrite()
+	switch {
+	case errors.Is(err, postgres.ErrTxConflict):
+		return fmt.Errorf("%w: %w", ErrConcurrentUpdate, err)
+	case errors.Is(err, postgres.ErrTxUnavailable):
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	default:
+		return err
+	}
+}
+
+// lockPackConfiguration locks the configuration row for the rest of the transaction
+// and returns its current state
+func lockPackConfiguration(ctx context.Context, tx pgx.Tx) (*model.PackConfiguration, error) {
+	var cfg model.PackConfiguration
+	var updatedAt pgtype.Timestamp
+
+	err := tx.QueryRow(ctx, `
+		SELECT id, version, pack_sizes, updated_at, COALESCE(updated_by, ''), COALESCE(approved_by, '') 
 		FROM pack_configuration 
 		WHERE id = 1 
-		FOR UPDATE`).Scan(&currentVersion)
+		FOR UPDATE`).Scan(
+		&cfg.ID,
+		&cfg.Version,
+		&cfg.PackSizes,
+		&updatedAt,
+		&cfg.UpdatedBy,
+		&cfg.ApprovedBy,
+	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, ErrNotFound
@@ -109,10 +320,27 @@ func (s *postgresRepo) UpdatePackSizes(ctx context.Context, sizes []int, updated
 		return nil, err
 	}
 
+	cfg.UpdatedAt = updatedAt.Time
+	return &cfg, nil
+}
+
+// packSizesChange describes a new pack size set and why and by whom it is applied
+type packSizesChange struct {
+	sizes      []int
+	updatedBy  string
+	approvedBy string
+	reason     string
+	draftID    *int
+}
+
+// replacePackSizes archives the current configuration, replaces it with the new sizes and records the change
+// in the audit log together with the request metadata from ctx
+// The caller must hold the row lock taken by lockPackConfiguration and pass the locked state as current
+func replacePackSizes(ctx context.Context, tx pgx.Tx, current *model.PackConfiguration, change packSizesChange) (*model.PackConfiguration, error) {
 	// Archive current configuration before updating
-	_, err = tx.Exec(ctx, `
-		INSERT INTO pack_configuration_history (version, pack_sizes, created_by)
-		SELECT version, pack_sizes, updated_by
+	_, err := tx.Exec(ctx, `
+		INSERT INTO pack_configuration_history (version, pack_sizes, created_by, approved_by, valid_from)
+		SELECT version, pack_sizes, updated_by, approved_by, updated_at
 		FROM pack_configuration
 		WHERE id = 1`)
 	if err != nil {
@@ -128,23 +356,49 @@ func (s *postgresRepo) UpdatePackSizes(ctx context.Context, sizes []int, updated
 		SET pack_sizes = $1,
 		    version = version + 1,
 		    updated_at = CURRENT_TIMESTAMP,
-		    updated_by = $2
+		    updated_by = $2,
+		    approved_by = NULLIF($3, '')
 		WHERE id = 1
-		RETURNING id, version, pack_sizes, updated_at, updated_by`, sizes, updatedBy).Scan(
+		RETURNING id, version, pack_sizes, updated_at, updated_by, COALESCE(approved_by, '')`, change.sizes, change.updatedBy, change.approvedBy).Scan(
 		&cfg.ID,
 		&cfg.Version,
 		&cfg.PackSizes,
 		&updatedAt,
 		&cfg.UpdatedBy,
+		&cfg.ApprovedBy,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	// Record the change itself, history only holds the superseded state
+	md := reqctx.FromContext(ctx)
+	_, err = tx.Exec(ctx, `
+		INSERT INTO pack_configuration_audit (
+			version, previous_version, pack_sizes, previous_pack_sizes, reason,
+			updated_by, approved_by, draft_id, identity, request_id, client_ip)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), $8, NULLIF($9, ''), NULLIF($10, ''), NULLIF($11, ''))`,
+		cfg.Version, current.Version, cfg.PackSizes, current.PackSizes, change.reason,
+		change.updatedBy, change.approvedBy, change.draftID, md.Identity, md.RequestID, md.ClientIP)
+	if err != nil {
+		return nil, err
+	}
+
 	cfg.UpdatedAt = updatedAt.Time
 
-	// Commit transaction
-	if err = tx.Commit(ctx); err != nil {
+	// Publish the change through the outbox, it commits or rolls back together with the change
+	err = insertOutboxEvent(ctx, tx, model.EventPackSizesChanged, model.PackSizesChangedEvent{
+		Version:           cfg.Version,
+		PreviousVersion:   current.Version,
+		PackSizes:         cfg.PackSizes,
+		PreviousPackSizes: current.PackSizes,
+		Reason:            change.reason,
+		UpdatedBy:         change.updatedBy,
+		ApprovedBy:        change.approvedBy,
+		RequestID:         md.RequestID,
+		ChangedAt:         cfg.UpdatedAt,
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -163,21 +417,58 @@ func (s *postgresRepo) GetPackConfigurationHistory(ctx context.Context, limit in
 	}
 
 	// Query historical configurations ordered by creation time descending
-	rows, err := s.pool.Query(ctx, `
-		SELECT id, version, pack_sizes, created_at, COALESCE(created_by, '') 
-		FROM pack_configuration_history 
-		ORDER BY created_at DESC 
-		LIMIT $1`, limit)
+	var configs []*model.PackConfiguration
+	err := s.db.Read(ctx, func(q postgres.Querier) error {
+		rows, err := q.Query(ctx, `
+			SELECT id, version, pack_sizes, created_at, COALESCE(created_by, ''), COALESCE(approved_by, ''), valid_from
+			FROM pack_configuration_history 
+			ORDER BY created_at DESC 
+			LIMIT $1`, limit)
+		if err != nil {
+			return err
+		}
+
+		configs, err = scanHistoryRows(rows)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+// GetFullPackConfigurationHistory returns every historical configuration ordered from oldest to newest
+func (s *postgresRepo) GetFullPackConfigurationHistory(ctx context.Context) ([]*model.PackConfiguration, error) {
+	var configs []*model.PackConfiguration
+	err := s.db.Read(ctx, func(q postgres.Querier) error {
+		rows, err := q.Query(ctx, `
+			SELECT id, version, pack_sizes, created_at, COALESCE(created_by, ''), COALESCE(approved_by, ''), valid_from
+			FROM pack_configuration_history 
+			ORDER BY created_at ASC, id ASC`)
+		if err != nil {
+			return err
+		}
+
+		configs, err = scanHistoryRows(rows)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+
+	return configs, nil
+}
+
+// scanHistoryRows scans pack_configuration_history rows into configurations and closes rows
+func scanHistoryRows(rows pgx.Rows) ([]*model.PackConfiguration, error) {
 	defer rows.Close()
 
 	var configs []*model.PackConfiguration
 	// Iterate over rows and scan into structs
 	for rows.Next() {
 		var cfg model.PackConfiguration
-		var createdAt pgtype.Timestamp
+		var createdAt, validFrom pgtype.Timestamp
 
 		err := rows.Scan(
 			&cfg.ID,
@@ -185,12 +476,17 @@ func (s *postgresRepo) GetPackConfigurationHistory(ctx context.Context, limit in
 			&cfg.PackSizes,
 			&createdAt,
 			&cfg.UpdatedBy,
+			&cfg.ApprovedBy,
+			&validFrom,
 		)
 		if err != nil {
 			return nil, err
 		}
 
 		cfg.UpdatedAt = createdAt.Time
+		if validFrom.Valid {
+			cfg.ValidFrom = &validFrom.Time
+		}
 		configs = append(configs, &cfg)
 	}
 
@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// webhookColumns lists the webhook_subscriptions columns in the order scanWebhook expects
+const webhookColumns = `id, url, description, secret, active, COALESCE(created_by, ''), created_at, updated_at`
+
+// deliveryColumns lists the webhook_deliveries columns in the order scanDelivery expects
+const deliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts,
+	COALESCE(last_error, ''), last_response_status, next_attempt_at, created_at, delivered_at`
+
+// postgresWebhookRepo implements the WebhookRepository interface using PostgreSQL
+type postgresWebhookRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresWebhookRepo creates a new PostgreSQL webhook repository
+func NewPostgresWebhookRepo(pool *pgxpool.Pool) WebhookRepository {
+	return &postgresWebhookRepo{
+		pool: pool,
+	}
+}
+
+// CreateWebhook stores a new subscription
+func (s *postgresWebhookRepo) CreateWebhook(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	row := s.pool.QueryRow(ctx, `
+		INSERT INTO webhook_subscriptions (url, description, secret, active, created_by)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		RETURNING `+webhookColumns, sub.URL, sub.Description, sub.Secret, sub.Active, sub.CreatedBy)
+
+	return scanWebhook(row)
+}
+
+// GetWebhook returns a subscription including its secret
+func (s *postgresWebhookRepo) GetWebhook(ctx context.Context, id int) (*model.WebhookSubscription, error) {
+	row := s.pool.QueryRow(ctx, `
+		SELECT `+webhookColumns+`
+		FROM webhook_subscriptions
+		WHERE id = $1`, id)
+
+	sub, err := scanWebhook(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
+// ListWebhooks returns all subscriptions ordered by ID
+func (s *postgresWebhookRepo) ListWebhooks(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+webhookColumns+`
+		FROM webhook_subscriptions
+		ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []*model.WebhookSubscription
+	for rows.Next() {
+		sub, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subs, nil
+}
+
+// UpdateWebhook stores the URL, description, active flag and secret of a subscription
+func (s *postgresWebhookRepo) UpdateWebhook(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	row := s.pool.QueryRow(ctx, `
+		UPDATE webhook_subscriptions
+		SET url = $2,
+		    description = $3,
+		    active = $4,
+		    secret = $5,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING `+webhookColumns, sub.ID, sub.URL, sub.Description, sub.Active, sub.Secret)
+
+	updated, err := scanWebhook(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteWebhook removes a subscription, its deliveries are removed by the foreign key
+func (s *postgresWebhookRepo) DeleteWebhook(ctx context.Context, id int) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// EnqueueWebhookDeliveries creates a pending delivery for every active subscription
+// The unique (subscription_id, event_id) key makes a repeated fan-out of the same event a no-op
+func (s *postgresWebhookRepo) EnqueueWebhookDeliveries(ctx context.Context, event *model.OutboxEvent, payload []byte) (int, error) {
+	tag, err := s.pool.Exec(ctx, `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+		SELECT id, $1, $2, $3
+		FROM webhook_subscriptions
+		WHERE active
+		ON CONFLICT (subscription_id, event_id) DO NOTHING`, event.ID, event.Type, payload)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// ClaimWebhookDeliveries leases due pending deliveries by moving their next attempt past the lease
+// Deliveries of deactivated subscriptions are not claimed, they resume when the subscription is activated again
+func (s *postgresWebhookRepo) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDelivery, error) {
+	// the outer query reads the claimed rows from RETURNING, it does not see the update in webhook_deliveries
+	rows, err := s.pool.Query(ctx, `
+		WITH claimed AS (
+			UPDATE webhook_deliveries
+			SET attempts = attempts + 1,
+			    next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+			WHERE id IN (
+				SELECT d.id
+				FROM webhook_deliveries d
+				JOIN webhook_subscriptions s ON s.id = d.subscription_id
+				WHERE d.status = 'pending' AND d.next_attempt_at <= CURRENT_TIMESTAMP AND s.active
+				ORDER BY d.id
+				LIMIT $1
+				FOR UPDATE OF d SKIP LOCKED)
+			RETURNING *)
+		SELECT c.id, c.subscription_id, c.event_id, c.event_type, c.payload, c.status, c.attempts,
+		       COALESCE(c.last_error, ''), c.last_response_status, c.next_attempt_at, c.created_at, c.delivered_at,
+		       s.url, s.secret
+		FROM claimed c
+		JOIN webhook_subscriptions s ON s.id = c.subscription_id
+		ORDER BY c.id`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*model.WebhookDelivery
+	for rows.Next() {
+		var url, secret string
+		delivery, err := scanDelivery(rows, &url, &secret)
+		if err != nil {
+			return nil, err
+		}
+		delivery.URL = url
+		delivery.Secret = secret
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// RecordWebhookAttempt stores the outcome of an attempt
+func (s *postgresWebhookRepo) RecordWebhookAttempt(ctx context.Context, id int64, attempt WebhookAttempt) error {
+	var responseStatus *int
+	if attempt.ResponseStatus != 0 {
+		responseStatus = &attempt.ResponseStatus
+	}
+
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2,
+		    last_error = NULLIF($3, ''),
+		    last_response_status = $4,
+		    next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $5),
+		    delivered_at = CASE WHEN $2::VARCHAR = 'delivered' THEN CURRENT_TIMESTAMP END
+		WHERE id = $1`, id, string(attempt.Status), attempt.LastError, responseStatus, attempt.RetryAfter.Seconds())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ListWebhookDeliveries returns deliveries ordered from newest to oldest
+func (s *postgresWebhookRepo) ListWebhookDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter) ([]*model.WebhookDelivery, error) {
+	// Validate and cap limit to prevent resource exhaustion
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries
+		WHERE ($1 = 0 OR subscription_id = $1)
+		  AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3`, filter.SubscriptionID, string(filter.Status), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*model.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// RetryWebhookDelivery moves a dead delivery back to pending with a fresh set of attempts
+func (s *postgresWebhookRepo) RetryWebhookDelivery(ctx context.Context, id int64) (*model.WebhookDelivery, error) {
+	row := s.pool.QueryRow(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'pending',
+		    attempts = 0,
+		    next_attempt_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'dead'
+		RETURNING `+deliveryColumns, id)
+
+	delivery, err := scanDelivery(row)
+	if err == nil {
+		return delivery, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	// tell a missing delivery apart from one that is not dead
+	var exists bool
+	if err := s.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM webhook_deliveries WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return nil, ErrDeliveryNotDead
+}
+
+// scanWebhook scans a row selected with webhookColumns
+func scanWebhook(row pgx.Row) (*model.WebhookSubscription, error) {
+	var sub model.WebhookSubscription
+	var createdAt, updatedAt pgtype.Timestamp
+
+	err := row.Scan(
+		&sub.ID,
+		&sub.URL,
+		&sub.Description,
+		&sub.Secret,
+		&sub.Active,
+		&sub.CreatedBy,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sub.CreatedAt = createdAt.Time
+	sub.UpdatedAt = updatedAt.Time
+	return &sub, nil
+}
+
+// scanDelivery scans a row selected with deliveryColumns followed by the extra destinations
+func scanDelivery(row pgx.Row, extra ...any) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	var status string
+	var nextAttemptAt, createdAt, deliveredAt pgtype.Timestamp
+
+	dest := []any{
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&status,
+		&delivery.Attempts,
+		&delivery.LastError,
+		&delivery.LastResponseStatus,
+		&nextAttemptAt,
+		&createdAt,
+		&deliveredAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	delivery.Status = model.WebhookDeliveryStatus(status)
+	delivery.NextAttemptAt = nextAttemptAt.Time
+	delivery.CreatedAt = createdAt.Time
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+	return &delivery, nil
+}
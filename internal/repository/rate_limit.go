@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimitBucket is the state of a token bucket after taking a token from it
+type RateLimitBucket struct {
+	// Allowed reports whether a token was taken, the request is rejected otherwise
+	Allowed bool
+	// Tokens is what is left in the bucket, a fraction of a token is refilled so far
+	Tokens float64
+}
+
+// RateLimitRepository stores the token buckets of the rate limiter
+type RateLimitRepository interface {
+	// TakeRateLimitToken refills the bucket with rate tokens per second up to capacity and takes a token if there is one
+	// A bucket that does not exist yet starts full
+	TakeRateLimitToken(ctx context.Context, key string, capacity int, rate float64) (RateLimitBucket, error)
+
+	// DeleteIdleRateLimitBuckets removes buckets not used for idle, they would be full again by now
+	DeleteIdleRateLimitBuckets(ctx context.Context, idle time.Duration) (int, error)
+}
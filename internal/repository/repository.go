@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/nsaltun/packman/internal/model"
 )
@@ -14,9 +15,40 @@ type PackRepository interface {
 	// GetPackConfiguration returns the current active pack sizes
 	GetPackConfiguration(ctx context.Context) (*model.PackConfiguration, error)
 
+	// GetPackConfigurationByVersion returns the configuration with the given version from the current row or history
+	GetPackConfigurationByVersion(ctx context.Context, version int) (*model.PackConfiguration, error)
+
+	// GetPackConfigurationAsOf returns the configuration that was active at the given time
+	GetPackConfigurationAsOf(ctx context.Context, at time.Time) (*model.PackConfiguration, error)
+
 	// UpdatePackSizes updates the pack size configuration and returns the updated configuration
-	UpdatePackSizes(ctx context.Context, sizes []int, updatedBy string) (*model.PackConfiguration, error)
+	UpdatePackSizes(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.PackConfiguration, error)
+
+	// ModifyPackSizes computes new pack sizes from the current ones under the row lock and stores them
+	// change may be called more than once when the write is retried
+	ModifyPackSizes(ctx context.Context, updatedBy string, reason string, change func(current []int) ([]int, error)) (*model.PackConfiguration, error)
 
 	// GetConfigurationHistory returns historical configurations
 	GetPackConfigurationHistory(ctx context.Context, limit int) ([]*model.PackConfiguration, error)
+
+	// GetFullPackConfigurationHistory returns every historical configuration ordered from oldest to newest
+	GetFullPackConfigurationHistory(ctx context.Context) ([]*model.PackConfiguration, error)
+
+	// CreateDraft stores a pending draft based on the current configuration version
+	CreateDraft(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.PackConfigurationDraft, error)
+
+	// GetDraft returns a single draft by ID
+	GetDraft(ctx context.Context, id int) (*model.PackConfigurationDraft, error)
+
+	// ListDrafts returns drafts ordered by creation time descending, optionally filtered by status
+	ListDrafts(ctx context.Context, status model.DraftStatus, limit int) ([]*model.PackConfigurationDraft, error)
+
+	// ApproveDraft applies a pending draft as the active configuration and records the reviewer
+	ApproveDraft(ctx context.Context, id int, reviewedBy string) (*model.PackConfiguration, error)
+
+	// RejectDraft marks a pending draft as rejected
+	RejectDraft(ctx context.Context, id int, reviewedBy string, comment string) (*model.PackConfigurationDraft, error)
+
+	// ListAuditEntries returns configuration changes ordered from newest to oldest
+	ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error)
 }
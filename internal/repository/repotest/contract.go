@@ -0,0 +1,318 @@
+// Package repotest provides a conformance test suite for repository.PackRepository implementations
+package repotest
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+	"github.com/nsaltun/packman/internal/reqctx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns an empty repository for a single test
+// The repository must start at version 1 with repository.DefaultPackSizes, no history and no drafts
+type Factory func(t *testing.T) repository.PackRepository
+
+// Run runs the contract test suite against repositories created by newRepo
+func Run(t *testing.T, newRepo Factory) {
+	t.Run("initial configuration", func(t *testing.T) { testInitialConfiguration(t, newRepo(t)) })
+	t.Run("versioning", func(t *testing.T) { testVersioning(t, newRepo(t)) })
+	t.Run("history ordering", func(t *testing.T) { testHistoryOrdering(t, newRepo(t)) })
+	t.Run("history limit clamping", func(t *testing.T) { testHistoryLimit(t, newRepo(t)) })
+	t.Run("lookup by version and time", func(t *testing.T) { testLookup(t, newRepo(t)) })
+	t.Run("modify", func(t *testing.T) { testModify(t, newRepo(t)) })
+	t.Run("concurrent updates", func(t *testing.T) { testConcurrentUpdates(t, newRepo(t)) })
+	t.Run("drafts", func(t *testing.T) { testDrafts(t, newRepo(t)) })
+	t.Run("audit log", func(t *testing.T) { testAuditLog(t, newRepo(t)) })
+}
+
+func testInitialConfiguration(t *testing.T, repo repository.PackRepository) {
+	ctx := context.Background()
+
+	sizes, err := repo.GetPackSizes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, repository.DefaultPackSizes, sizes)
+
+	cfg, err := repo.GetPackConfiguration(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, cfg.Version)
+	assert.Equal(t, repository.DefaultPackSizes, cfg.PackSizes)
+
+	history, err := repo.GetPackConfigurationHistory(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func testVersioning(t *testing.T, repo repository.PackRepository) {
+	ctx := context.Background()
+
+	for version := 2; version <= 4; version++ {
+		cfg, err := repo.UpdatePackSizes(ctx, []int{version * 10, version * 100}, "alice", "bump")
+		require.NoError(t, err)
+		assert.Equal(t, version, cfg.Version)
+		assert.Equal(t, []int{version * 10, version * 100}, cfg.PackSizes)
+		assert.Equal(t, "alice", cfg.UpdatedBy)
+		assert.False(t, cfg.UpdatedAt.IsZero())
+	}
+
+	cfg, err := repo.GetPackConfiguration(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, cfg.Version)
+	assert.Equal(t, []int{40, 400}, cfg.PackSizes)
+
+	// updating with the same sizes still creates a version
+	cfg, err = repo.UpdatePackSizes(ctx, []int{40, 400}, "alice", "same")
+	require.NoError(t, err)
+	assert.Equal(t, 5, cfg.Version)
+}
+
+func testHistoryOrdering(t *testing.T, repo repository.PackRepository) {
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		_, err := repo.UpdatePackSizes(ctx, []int{i}, "alice", "bump")
+		require.NoError(t, err)
+	}
+
+	// history holds the superseded versions, newest first
+	history, err := repo.GetPackConfigurationHistory(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, []int{3, 2, 1}, versions(history))
+	assert.Equal(t, repository.DefaultPackSizes, history[2].PackSizes)
+	assert.Equal(t, []int{2}, history[0].PackSizes)
+
+	full, err := repo.GetFullPackConfigurationHistory(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, versions(full))
+
+	// each version became active when the one before it was archived, the oldest when the store was created
+	for i, cfg := range full {
+		require.NotNil(t, cfg.ValidFrom, "version %d", cfg.Version)
+		assert.False(t, cfg.ValidFrom.After(cfg.UpdatedAt), "version %d", cfg.Version)
+		if i > 0 {
+			assert.True(t, cfg.ValidFrom.Equal(full[i-1].UpdatedAt), "version %d", cfg.Version)
+		}
+	}
+}
+
+func testHistoryLimit(t *testing.T, repo repository.PackRepository) {
+	ctx := context.Background()
+
+	for i := 1; i <= 105; i++ {
+		_, err := repo.UpdatePackSizes(ctx, []int{i}, "alice", "bump")
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		limit    int
+		expected int
+	}{
+		{limit: 0, expected: 10},
+		{limit: -5, expected: 10},
+		{limit: 3, expected: 3},
+		{limit: 100, expected: 100},
+		{limit: 500, expected: 100},
+	}
+	for _, tt := range tests {
+		history, err := repo.GetPackConfigurationHistory(ctx, tt.limit)
+		require.NoError(t, err)
+		assert.Len(t, history, tt.expected, "limit %d", tt.limit)
+	}
+}
+
+func testLookup(t *testing.T, repo repository.PackRepository) {
+	ctx := context.Background()
+
+	_, err := repo.UpdatePackSizes(ctx, []int{23, 31, 53}, "alice", "bump")
+	require.NoError(t, err)
+
+	cfg, err := repo.GetPackConfigurationByVersion(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, repository.DefaultPackSizes, cfg.PackSizes)
+
+	cfg, err = repo.GetPackConfigurationByVersion(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{23, 31, 53}, cfg.PackSizes)
+
+	_, err = repo.GetPackConfigurationByVersion(ctx, 3)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	_, err = repo.GetPackConfigurationAsOf(ctx, time.Now().AddDate(-10, 0, 0))
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	cfg, err = repo.GetPackConfigurationAsOf(ctx, time.Now().AddDate(1, 0, 0))
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.Version)
+}
+
+func testModify(t *testing.T, repo repository.PackRepository) {
+	ctx := context.Background()
+
+	cfg, err := repo.ModifyPackSizes(ctx, "alice", "add 750", func(current []int) ([]int, error) {
+		assert.Equal(t, repository.DefaultPackSizes, current)
+		return append(current, 750), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.Version)
+	assert.Contains(t, cfg.PackSizes, 750)
+
+	// an unchanged set, in any order, does not create a version
+	cfg, err = repo.ModifyPackSizes(ctx, "alice", "noop", func(current []int) ([]int, error) {
+		slices.Reverse(current)
+		return current, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.Version)
+
+	// errors from the change function are returned as is and nothing is written
+	_, err = repo.ModifyPackSizes(ctx, "alice", "fail", func(current []int) ([]int, error) {
+		return nil, assert.AnError
+	})
+	assert.ErrorIs(t, err, assert.AnError)
+
+	cfg, err = repo.GetPackConfiguration(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.Version)
+}
+
+func testConcurrentUpdates(t *testing.T, repo repository.PackRepository) {
+	ctx := context.Background()
+	const workers = 10
+
+	var mu sync.Mutex
+	var added []int
+
+	var wg sync.WaitGroup
+	for size := 1; size <= workers; size++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.ModifyPackSizes(ctx, "worker", "add", func(current []int) ([]int, error) {
+				return append(current, size), nil
+			})
+			// implementations may refuse conflicting writes, but must not lose accepted ones
+			if err == nil {
+				mu.Lock()
+				added = append(added, size)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.NotEmpty(t, added)
+
+	cfg, err := repo.GetPackConfiguration(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1+len(added), cfg.Version)
+	for _, size := range added {
+		assert.Contains(t, cfg.PackSizes, size)
+	}
+
+	history, err := repo.GetFullPackConfigurationHistory(ctx)
+	require.NoError(t, err)
+	assert.Len(t, history, len(added))
+}
+
+func testDrafts(t *testing.T, repo repository.PackRepository) {
+	ctx := context.Background()
+
+	draft, err := repo.CreateDraft(ctx, []int{250, 750}, "alice", "peak season")
+	require.NoError(t, err)
+	assert.Equal(t, model.DraftStatusPending, draft.Status)
+	assert.Equal(t, 1, draft.BaseVersion)
+	assert.Equal(t, "alice", draft.UpdatedBy)
+	assert.Equal(t, "peak season", draft.Reason)
+
+	stale, err := repo.CreateDraft(ctx, []int{300}, "carol", "smaller boxes")
+	require.NoError(t, err)
+
+	pending, err := repo.ListDrafts(ctx, model.DraftStatusPending, 0)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, stale.ID, pending[0].ID)
+
+	cfg, err := repo.ApproveDraft(ctx, draft.ID, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.Version)
+	assert.Equal(t, []int{250, 750}, cfg.PackSizes)
+	assert.Equal(t, "alice", cfg.UpdatedBy)
+	assert.Equal(t, "bob", cfg.ApprovedBy)
+
+	approved, err := repo.GetDraft(ctx, draft.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.DraftStatusApproved, approved.Status)
+	assert.Equal(t, "bob", approved.ReviewedBy)
+	require.NotNil(t, approved.AppliedVersion)
+	assert.Equal(t, 2, *approved.AppliedVersion)
+
+	_, err = repo.ApproveDraft(ctx, draft.ID, "bob")
+	assert.ErrorIs(t, err, repository.ErrDraftNotPending)
+
+	_, err = repo.ApproveDraft(ctx, stale.ID, "bob")
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+
+	rejected, err := repo.RejectDraft(ctx, stale.ID, "bob", "outdated")
+	require.NoError(t, err)
+	assert.Equal(t, model.DraftStatusRejected, rejected.Status)
+	assert.Equal(t, "outdated", rejected.ReviewComment)
+
+	_, err = repo.RejectDraft(ctx, stale.ID, "bob", "again")
+	assert.ErrorIs(t, err, repository.ErrDraftNotPending)
+
+	_, err = repo.GetDraft(ctx, 999999)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = repo.ApproveDraft(ctx, 999999, "bob")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func testAuditLog(t *testing.T, repo repository.PackRepository) {
+	ctx := reqctx.WithMetadata(context.Background(), reqctx.Metadata{
+		RequestID: "req-contract",
+		ClientIP:  "192.0.2.1",
+		Identity:  "alice@example.com",
+	})
+
+	_, err := repo.UpdatePackSizes(ctx, []int{23, 31}, "alice", "first")
+	require.NoError(t, err)
+	_, err = repo.UpdatePackSizes(context.Background(), []int{53}, "bob", "second")
+	require.NoError(t, err)
+
+	entries, err := repo.ListAuditEntries(ctx, model.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "second", entries[0].Reason)
+	assert.Equal(t, 3, entries[0].Version)
+	assert.Equal(t, 2, entries[0].PreviousVersion)
+	assert.Equal(t, []int{23, 31}, entries[0].PreviousPackSizes)
+
+	entries, err = repo.ListAuditEntries(ctx, model.AuditFilter{RequestID: "req-contract"})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "first", entries[0].Reason)
+	assert.Equal(t, repository.DefaultPackSizes, entries[0].PreviousPackSizes)
+	assert.Equal(t, []int{23, 31}, entries[0].PackSizes)
+	assert.Equal(t, "192.0.2.1", entries[0].ClientIP)
+	assert.Equal(t, "alice@example.com", entries[0].Identity)
+
+	entries, err = repo.ListAuditEntries(ctx, model.AuditFilter{UpdatedBy: "bob", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "second", entries[0].Reason)
+}
+
+// versions returns the versions of the given configurations
+func versions(configs []*model.PackConfiguration) []int {
+	result := make([]int, 0, len(configs))
+	for _, cfg := range configs {
+		result = append(result, cfg.Version)
+	}
+	return result
+}
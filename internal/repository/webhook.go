@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// ErrDeliveryNotDead indicates a delivery can not be retried because it has not run out of attempts
+var ErrDeliveryNotDead = errors.New("webhook delivery is not dead")
+
+// WebhookAttempt is the outcome of delivering a webhook
+// Pending deliveries are attempted again after RetryAfter, delivered and dead ones are final
+type WebhookAttempt struct {
+	Status         model.WebhookDeliveryStatus
+	LastError      string
+	ResponseStatus int
+	RetryAfter     time.Duration
+}
+
+// WebhookRepository defines the data access for webhook subscriptions and their deliveries
+type WebhookRepository interface {
+	// CreateWebhook stores a new subscription
+	CreateWebhook(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error)
+
+	// GetWebhook returns a subscription including its secret
+	GetWebhook(ctx context.Context, id int) (*model.WebhookSubscription, error)
+
+	// ListWebhooks returns all subscriptions ordered by ID, including their secrets
+	ListWebhooks(ctx context.Context) ([]*model.WebhookSubscription, error)
+
+	// UpdateWebhook stores the URL, description, active flag and secret of a subscription
+	UpdateWebhook(ctx context.Context, sub *model.WebhookSubscription) (*model.WebhookSubscription, error)
+
+	// DeleteWebhook removes a subscription together with its deliveries
+	DeleteWebhook(ctx context.Context, id int) error
+
+	// EnqueueWebhookDeliveries creates a pending delivery of payload for every active subscription
+	// Enqueueing the same event again does not create duplicates
+	EnqueueWebhookDeliveries(ctx context.Context, event *model.OutboxEvent, payload []byte) (int, error)
+
+	// ClaimWebhookDeliveries leases up to limit pending deliveries that are due, oldest first, and counts the attempt
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDelivery, error)
+
+	// RecordWebhookAttempt stores the outcome of an attempt
+	RecordWebhookAttempt(ctx context.Context, id int64, attempt WebhookAttempt) error
+
+	// ListWebhookDeliveries returns deliveries ordered from newest to oldest
+	ListWebhookDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter) ([]*model.WebhookDelivery, error)
+
+	// RetryWebhookDelivery moves a dead delivery back to pending with a fresh set of attempts
+	RetryWebhookDelivery(ctx context.Context, id int64) (*model.WebhookDelivery, error)
+}
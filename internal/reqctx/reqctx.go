@@ -0,0 +1,32 @@
+package reqctx
+
+import "context"
+
+// Metadata describes the request a piece of work is done for
+// It is carried in the request context so layers without access to gin can record it
+type Metadata struct {
+	RequestID string
+	ClientIP  string
+	// Identity is the authenticated caller, empty for anonymous requests
+	Identity string
+}
+
+type metadataKey struct{}
+
+// WithMetadata returns a copy of ctx carrying the given request metadata
+func WithMetadata(ctx context.Context, md Metadata) context.Context {
+	return context.WithValue(ctx, metadataKey{}, md)
+}
+
+// FromContext returns the request metadata carried by ctx, or zero Metadata if there is none
+func FromContext(ctx context.Context) Metadata {
+	md, _ := ctx.Value(metadataKey{}).(Metadata)
+	return md
+}
+
+// WithIdentity returns a copy of ctx with the authenticated identity added to its request metadata
+func WithIdentity(ctx context.Context, identity string) context.Context {
+	md := FromContext(ctx)
+	md.Identity = identity
+	return WithMetadata(ctx, md)
+}
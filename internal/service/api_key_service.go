@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+)
+
+const (
+	// apiKeyPrefix marks generated keys so they are recognizable in configuration and logs
+	apiKeyPrefix = "pmk_"
+	// apiKeyDisplayLength is how much of a key is kept as its prefix
+	apiKeyDisplayLength = 12
+	// apiKeyTouchInterval limits how often last_used_at is written for a busy key
+	apiKeyTouchInterval = time.Minute
+)
+
+// APIKeyService defines the interface for issuing, listing, revoking and checking API keys
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, req *model.CreateAPIKeyRequest) (*model.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]*model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int) (*model.APIKey, error)
+	Authenticate(ctx context.Context, rawKey string) (*model.Principal, error)
+}
+
+// apiKeyService is the concrete implementation of APIKeyService
+type apiKeyService struct {
+	repo repository.APIKeyRepository
+	// bootstrapKey is an admin key from configuration, it allows issuing the first stored key
+	bootstrapKey string
+}
+
+// NewAPIKeyService creates a new instance of APIKeyService
+// An empty bootstrapKey disables the bootstrap key
+func NewAPIKeyService(repo repository.APIKeyRepository, bootstrapKey string) APIKeyService {
+	return &apiKeyService{repo: repo, bootstrapKey: bootstrapKey}
+}
+
+// CreateAPIKey issues a new key, the key is part of the response and is not returned again afterwards
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, req *model.CreateAPIKeyRequest) (*model.APIKey, error) {
+	rawKey, err := generateAPIKey()
+	if err != nil {
+		return nil, apperror.InternalError("Failed to generate API key", err)
+	}
+
+	key, err := s.repo.CreateAPIKey(ctx, &model.APIKey{
+		Name:      req.Name,
+		Prefix:    rawKey[:apiKeyDisplayLength],
+		Scopes:    req.Scopes,
+		CreatedBy: req.CreatedBy,
+	}, hashAPIKey(rawKey))
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNameTaken) {
+			return nil, apperror.ConflictError("An API key with this name already exists", err)
+		}
+		return nil, apperror.InternalError("Failed to create API key", err)
+	}
+
+	key.Key = rawKey
+	return key, nil
+}
+
+// ListAPIKeys retrieves all keys, including revoked keys
+func (s *apiKeyService) ListAPIKeys(ctx context.Context) ([]*model.APIKey, error) {
+	keys, err := s.repo.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, apperror.InternalError("Failed to retrieve API keys", err)
+	}
+
+	// return an empty list rather than null
+	if keys == nil {
+		keys = []*model.APIKey{}
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes a key, requests made with it are rejected from then on
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, id int) (*model.APIKey, error) {
+	key, err := s.repo.RevokeAPIKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperror.NotFoundError("API key not found", err)
+		}
+		return nil, apperror.InternalError("Failed to revoke API key", err)
+	}
+
+	return key, nil
+}
+
+// Authenticate returns the caller identified by the key a request was made with
+// Unknown and revoked keys are rejected with the same error so a caller cannot tell them apart
+func (s *apiKeyService) Authenticate(ctx context.Context, rawKey string) (*model.Principal, error) {
+	if s.bootstrapKey != "" && subtle.ConstantTimeCompare([]byte(rawKey), []byte(s.bootstrapKey)) == 1 {
+		return &model.Principal{Name: model.BootstrapPrincipal, Scopes: []model.Scope{model.ScopeAdmin}}, nil
+	}
+
+	key, err := s.repo.GetAPIKeyByHash(ctx, hashAPIKey(rawKey))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperror.UnauthorizedError("Invalid API key", err)
+		}
+		return nil, apperror.InternalError("Failed to verify API key", err)
+	}
+	if key.RevokedAt != nil {
+		return nil, apperror.UnauthorizedError("Invalid API key", nil)
+	}
+
+	// last use is informational, failing to record it does not fail the request
+	if key.LastUsedAt == nil || time.Since(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.repo.TouchAPIKey(ctx, key.ID); err != nil {
+			slog.WarnContext(ctx, "failed to record API key use",
+				slog.Int("api_key_id", key.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	return &model.Principal{Name: model.APIKeyPrincipalPrefix + key.Name, Scopes: key.Scopes}, nil
+}
+
+// generateAPIKey returns a random key with 256 bits of entropy
+func generateAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + hex.EncodeToString(b), nil
+}
+
+// hashAPIKey returns the hex SHA-256 hash a key is stored under
+// Keys carry enough entropy that a fast unsalted hash cannot be brute forced
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	t.Run("only the hash of the generated key is stored", func(t *testing.T) {
+		mockRepo := mocks.MockAPIKeyRepository{}
+		service := apiKeyService{repo: &mockRepo}
+
+		var storedHash string
+		mockRepo.On("CreateAPIKey", mock.Anything, mock.MatchedBy(func(key *model.APIKey) bool {
+			return key.Name == "ci" && key.CreatedBy == "alice" && key.Key == "" &&
+				strings.HasPrefix(key.Prefix, apiKeyPrefix) && len(key.Prefix) == apiKeyDisplayLength
+		}), mock.Anything).Run(func(args mock.Arguments) {
+			storedHash = args.String(2)
+		}).Return(&model.APIKey{ID: 1, Name: "ci", Scopes: []model.Scope{model.ScopeRead}}, nil)
+
+		res, err := service.CreateAPIKey(context.Background(), &model.CreateAPIKeyRequest{
+			Name:      "ci",
+			Scopes:    []model.Scope{model.ScopeRead},
+			CreatedBy: "alice",
+		})
+		require.NoError(t, err)
+		// the key is returned once, on creation
+		assert.True(t, strings.HasPrefix(res.Key, apiKeyPrefix))
+		assert.Len(t, res.Key, len(apiKeyPrefix)+64)
+		assert.Equal(t, hashAPIKey(res.Key), storedHash)
+		assert.NotContains(t, storedHash, res.Key)
+	})
+	t.Run("name already taken", func(t *testing.T) {
+		mockRepo := mocks.MockAPIKeyRepository{}
+		service := apiKeyService{repo: &mockRepo}
+
+		mockRepo.On("CreateAPIKey", mock.Anything, mock.Anything, mock.Anything).Return(nil, repository.ErrAPIKeyNameTaken)
+
+		res, err := service.CreateAPIKey(context.Background(), &model.CreateAPIKeyRequest{Name: "ci", Scopes: []model.Scope{model.ScopeRead}})
+		assert.Nil(t, res)
+		appErr, ok := apperror.AsAppError(err)
+		require.True(t, ok)
+		assert.Equal(t, apperror.ErrCodeConflict, appErr.Code)
+	})
+}
+
+func TestRevokeAPIKey_NotFound(t *testing.T) {
+	mockRepo := mocks.MockAPIKeyRepository{}
+	service := apiKeyService{repo: &mockRepo}
+
+	mockRepo.On("RevokeAPIKey", mock.Anything, 9).Return(nil, repository.ErrNotFound)
+
+	res, err := service.RevokeAPIKey(context.Background(), 9)
+	assert.Nil(t, res)
+	assert.EqualError(t, err, apperror.NotFoundError("API key not found", repository.ErrNotFound).Error())
+}
+
+func TestAuthenticate(t *testing.T) {
+	const rawKey = "pmk_0123456789abcdef"
+
+	t.Run("stored key records its use", func(t *testing.T) {
+		mockRepo := mocks.MockAPIKeyRepository{}
+		service := apiKeyService{repo: &mockRepo}
+
+		key := &model.APIKey{ID: 3, Name: "ci", Scopes: []model.Scope{model.ScopeCalculate}}
+		mockRepo.On("GetAPIKeyByHash", mock.Anything, hashAPIKey(rawKey)).Return(key, nil)
+		mockRepo.On("TouchAPIKey", mock.Anything, 3).Return(nil)
+
+		res, err := service.Authenticate(context.Background(), rawKey)
+		require.NoError(t, err)
+		assert.Equal(t, "key:ci", res.Name)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("recent use is not recorded again", func(t *testing.T) {
+		mockRepo := mocks.MockAPIKeyRepository{}
+		service := apiKeyService{repo: &mockRepo}
+
+		lastUsedAt := time.Now().Add(-10 * time.Second)
+		key := &model.APIKey{ID: 3, Name: "ci", LastUsedAt: &lastUsedAt}
+		mockRepo.On("GetAPIKeyByHash", mock.Anything, hashAPIKey(rawKey)).Return(key, nil)
+
+		_, err := service.Authenticate(context.Background(), rawKey)
+		require.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "TouchAPIKey", mock.Anything, mock.Anything)
+	})
+	t.Run("failing to record use does not fail the request", func(t *testing.T) {
+		mockRepo := mocks.MockAPIKeyRepository{}
+		service := apiKeyService{repo: &mockRepo}
+
+		mockRepo.On("GetAPIKeyByHash", mock.Anything, hashAPIKey(rawKey)).Return(&model.APIKey{ID: 3, Name: "ci"}, nil)
+		mockRepo.On("TouchAPIKey", mock.Anything, 3).Return(assert.AnError)
+
+		res, err := service.Authenticate(context.Background(), rawKey)
+		require.NoError(t, err)
+		assert.Equal(t, "key:ci", res.Name)
+	})
+	t.Run("unknown and revoked keys are rejected alike", func(t *testing.T) {
+		mockRepo := mocks.MockAPIKeyRepository{}
+		service := apiKeyService{repo: &mockRepo}
+
+		revokedAt := time.Now()
+		mockRepo.On("GetAPIKeyByHash", mock.Anything, hashAPIKey("pmk_unknown")).Return(nil, repository.ErrNotFound)
+		mockRepo.On("GetAPIKeyByHash", mock.Anything, hashAPIKey(rawKey)).Return(&model.APIKey{ID: 3, RevokedAt: &revokedAt}, nil)
+
+		_, unknownErr := service.Authenticate(context.Background(), "pmk_unknown")
+		_, revokedErr := service.Authenticate(context.Background(), rawKey)
+		for _, err := range []error{unknownErr, revokedErr} {
+			appErr, ok := apperror.AsAppError(err)
+			require.True(t, ok)
+			assert.Equal(t, apperror.ErrCodeUnauthorized, appErr.Code)
+			assert.Equal(t, "Invalid API key", appErr.Message)
+		}
+		mockRepo.AssertNotCalled(t, "TouchAPIKey", mock.Anything, mock.Anything)
+	})
+	t.Run("bootstrap key is an admin key that is not stored", func(t *testing.T) {
+		mockRepo := mocks.MockAPIKeyRepository{}
+		service := apiKeyService{repo: &mockRepo, bootstrapKey: "bootstrap-key-0123456789"}
+
+		res, err := service.Authenticate(context.Background(), "bootstrap-key-0123456789")
+		require.NoError(t, err)
+		assert.Equal(t, model.BootstrapPrincipal, res.Name)
+		assert.True(t, res.HasScope(model.ScopeAdmin))
+		mockRepo.AssertNotCalled(t, "GetAPIKeyByHash", mock.Anything, mock.Anything)
+	})
+}
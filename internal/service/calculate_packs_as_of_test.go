@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCalculatePacksAsOf(t *testing.T) {
+	at := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("by version", func(t *testing.T) {
+		repoMock := mocks.MockPackRepository{}
+		service := packService{packRepo: &repoMock}
+		repoMock.On("GetPackConfigurationByVersion", mock.Anything, 3).
+			Return(&model.PackConfiguration{Version: 3, PackSizes: []int{250, 500, 1000}}, nil)
+
+		res, err := service.CalculatePacksAsOf(context.Background(), 1200, 3, time.Time{})
+		assert.NoError(t, err)
+		assert.Equal(t, &model.PackCalculationResponse{
+			Quantity: 1200,
+			Packs:    map[int]int{1000: 1, 250: 1},
+			Version:  3,
+		}, res)
+		repoMock.AssertNotCalled(t, "GetPackConfigurationAsOf", mock.Anything, mock.Anything)
+	})
+	t.Run("by timestamp", func(t *testing.T) {
+		repoMock := mocks.MockPackRepository{}
+		service := packService{packRepo: &repoMock}
+		repoMock.On("GetPackConfigurationAsOf", mock.Anything, at).
+			Return(&model.PackConfiguration{Version: 2, PackSizes: []int{250, 500}}, nil)
+
+		res, err := service.CalculatePacksAsOf(context.Background(), 251, 0, at)
+		assert.NoError(t, err)
+		assert.Equal(t, &model.PackCalculationResponse{
+			Quantity: 251,
+			Packs:    map[int]int{250: 2},
+			Version:  2,
+		}, res)
+	})
+	t.Run("version not found", func(t *testing.T) {
+		repoMock := mocks.MockPackRepository{}
+		service := packService{packRepo: &repoMock}
+		repoMock.On("GetPackConfigurationByVersion", mock.Anything, 99).Return(nil, repository.ErrNotFound)
+
+		res, err := service.CalculatePacksAsOf(context.Background(), 251, 99, time.Time{})
+		assert.Nil(t, res)
+		assert.EqualError(t, err, apperror.NotFoundError("Pack configuration version not found", repository.ErrNotFound).Error())
+	})
+	t.Run("repository error", func(t *testing.T) {
+		repoMock := mocks.MockPackRepository{}
+		service := packService{packRepo: &repoMock}
+		repoMock.On("GetPackConfigurationAsOf", mock.Anything, at).Return(nil, assert.AnError)
+
+		res, err := service.CalculatePacksAsOf(context.Background(), 251, 0, at)
+		assert.Nil(t, res)
+		assert.EqualError(t, err, apperror.InternalError("Failed to retrieve pack configuration", assert.AnError).Error())
+	})
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// simulationBudget bounds the pack size steps of the calculations simulated to analyze one set,
+// so a change is analyzed quickly while the configuration row is locked
+const simulationBudget = 1_000_000
+
+// analyzePackSizes reports semantic problems with a pack size set that the request validation cannot catch
+// It returns nil when analysis is disabled by policy
+func analyzePackSizes(sizes []int, policy config.PackAnalysisConfig) []model.PackSizeWarning {
+	if policy.Mode == "" || policy.Mode == config.PackAnalysisModeOff || len(sizes) == 0 {
+		return nil
+	}
+
+	sizes = slices.Clone(sizes)
+	sort.Ints(sizes)
+
+	var warnings []model.PackSizeWarning
+
+	if policy.MaxPackSizes > 0 && len(sizes) > policy.MaxPackSizes {
+		warnings = append(warnings, model.PackSizeWarning{
+			Code:    model.WarningTooManySizes,
+			Message: fmt.Sprintf("%d pack sizes configured, more than the recommended maximum of %d", len(sizes), policy.MaxPackSizes),
+		})
+	}
+
+	divisor := sizes[0]
+	for _, size := range sizes[1:] {
+		divisor = gcd(divisor, size)
+	}
+	if policy.MaxCommonDivisor > 0 && divisor > policy.MaxCommonDivisor {
+		warnings = append(warnings, model.PackSizeWarning{
+			Code:    model.WarningCommonDivisor,
+			Message: fmt.Sprintf("all pack sizes are multiples of %d, quantities in between are always rounded up", divisor),
+		})
+	}
+
+	// multiples[i] is the smallest size that sizes[i] is a multiple of, or 0
+	multiples := make([]int, len(sizes))
+	simulations := 1
+	for i, size := range sizes {
+		if j := slices.IndexFunc(sizes[:i], func(smaller int) bool { return size%smaller == 0 }); j >= 0 {
+			multiples[i] = sizes[j]
+			simulations++
+		}
+	}
+
+	largest := sizes[len(sizes)-1]
+	step := simulationStep(largest, divisor, simulations*len(sizes))
+	shipped := simulatePacks(sizes, largest, step)
+
+	for i, size := range sizes {
+		if multiples[i] == 0 {
+			continue
+		}
+		// a multiple always saves packs, it is only redundant if it never saves items as well
+		without := simulatePacks(slices.Delete(slices.Clone(sizes), i, i+1), largest, step)
+		if !shipsFewerItems(shipped, without) {
+			warnings = append(warnings, model.PackSizeWarning{
+				Code:    model.WarningUnusedSize,
+				Message: fmt.Sprintf("pack size %d is a multiple of %d and never reduces the items shipped", size, multiples[i]),
+				Sizes:   []int{size, multiples[i]},
+			})
+		}
+	}
+
+	overshoot := 0
+	for i, items := range shipped {
+		overshoot = max(overshoot, items-(1+i*step))
+	}
+
+	if policy.MaxOvershoot > 0 && overshoot > policy.MaxOvershoot {
+		warnings = append(warnings, model.PackSizeWarning{
+			Code:    model.WarningExcessiveOvershoot,
+			Message: fmt.Sprintf("some orders are overshot by up to %d items, more than the allowed %d", overshoot, policy.MaxOvershoot),
+		})
+	}
+
+	return warnings
+}
+
+// simulationStep returns the distance of the simulated quantities up to largest, so that simulations
+// that take sizesPerQuantity steps each stay within the simulation budget
+// Every size is a multiple of divisor, so only the quantities right above each multiple of it are needed,
+// where the overshoot is the largest. Beyond the budget they are spread further apart
+func simulationStep(largest, divisor, sizesPerQuantity int) int {
+	quantities := max(1, simulationBudget/sizesPerQuantity)
+	return divisor * max(1, (largest/divisor+quantities-1)/quantities)
+}
+
+// simulatePacks runs the calculation for the quantities 1, 1+step, 1+2*step... up to maxQuantity and
+// returns the items shipped, shipped[i] for quantity 1+i*step
+// Up to the largest pack size this covers every remainder, larger quantities only add whole largest packs
+func simulatePacks(sortedSizes []int, maxQuantity, step int) []int {
+	descSizes := slices.Clone(sortedSizes)
+	slices.Reverse(descSizes)
+
+	shipped := make([]int, 0, (maxQuantity-1)/step+1)
+	packs := make(map[int]int, len(descSizes))
+	for quantity := 1; quantity <= maxQuantity; quantity += step {
+		clear(packs)
+		fillPacks(descSizes, quantity, packs)
+
+		items := 0
+		for size, count := range packs {
+			items += size * count
+		}
+		shipped = append(shipped, items)
+	}
+
+	return shipped
+}
+
+// shipsFewerItems reports whether shipped has fewer items than other for any quantity
+func shipsFewerItems(shipped, other []int) bool {
+	for i := range shipped {
+		if shipped[i] < other[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPackSizes analyzes pack sizes and fails with a validation error when the policy rejects problematic sets
+func (s *packService) checkPackSizes(sizes []int) ([]model.PackSizeWarning, error) {
+	warnings := analyzePackSizes(sizes, s.analysis)
+	if len(warnings) > 0 && s.analysis.Mode == config.PackAnalysisModeReject {
+		return nil, apperror.ValidationError("Pack sizes failed analysis", nil).WithDetails("warnings", warnings)
+	}
+	return warnings, nil
+}
+
+// gcd returns the greatest common divisor of two positive integers
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testAnalysisPolicy = config.PackAnalysisConfig{
+	Mode:             "warn",
+	MaxPackSizes:     3,
+	MaxCommonDivisor: 100,
+	MaxOvershoot:     400,
+}
+
+func warningCodes(warnings []model.PackSizeWarning) []model.PackSizeWarningCode {
+	codes := make([]model.PackSizeWarningCode, 0, len(warnings))
+	for _, w := range warnings {
+		codes = append(codes, w.Code)
+	}
+	return codes
+}
+
+func TestAnalyzePackSizes(t *testing.T) {
+	tests := []struct {
+		name     string
+		sizes    []int
+		policy   config.PackAnalysisConfig
+		expected []model.PackSizeWarningCode
+	}{
+		{
+			name:     "healthy set",
+			sizes:    []int{23, 31, 53},
+			policy:   testAnalysisPolicy,
+			expected: []model.PackSizeWarningCode{},
+		},
+		{
+			name:     "too many sizes",
+			sizes:    []int{23, 31, 53, 71},
+			policy:   testAnalysisPolicy,
+			expected: []model.PackSizeWarningCode{model.WarningTooManySizes},
+		},
+		{
+			name:     "large common divisor",
+			sizes:    []int{400, 600},
+			policy:   testAnalysisPolicy,
+			expected: []model.PackSizeWarningCode{model.WarningCommonDivisor},
+		},
+		{
+			name:     "excessive overshoot",
+			sizes:    []int{500, 1001},
+			policy:   testAnalysisPolicy,
+			expected: []model.PackSizeWarningCode{model.WarningExcessiveOvershoot},
+		},
+		{
+			name:     "multiples that only save packs",
+			sizes:    []int{5, 10, 20},
+			policy:   testAnalysisPolicy,
+			expected: []model.PackSizeWarningCode{model.WarningUnusedSize, model.WarningUnusedSize},
+		},
+		{
+			name:     "multiples that reduce the items shipped are not reported",
+			sizes:    []int{3, 5, 6},
+			policy:   testAnalysisPolicy,
+			expected: []model.PackSizeWarningCode{},
+		},
+		{
+			name:     "analysis disabled",
+			sizes:    []int{250, 500, 1001, 2000},
+			policy:   config.PackAnalysisConfig{Mode: "off", MaxPackSizes: 1, MaxCommonDivisor: 1, MaxOvershoot: 1},
+			expected: []model.PackSizeWarningCode{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := analyzePackSizes(tt.sizes, tt.policy)
+			assert.Equal(t, tt.expected, warningCodes(warnings))
+		})
+	}
+}
+
+func TestSimulatePacks(t *testing.T) {
+	shipped := simulatePacks([]int{3, 5, 6}, 12, 1)
+	// 8 items take a 6 pack and a 3 pack for the rest
+	assert.Equal(t, []int{3, 3, 3, 6, 5, 6, 9, 9, 9, 12, 11, 12}, shipped)
+	assert.False(t, shipsFewerItems(shipped, shipped))
+	// without the 6 pack 12 items take 13
+	assert.True(t, shipsFewerItems(shipped, simulatePacks([]int{3, 5}, 12, 1)))
+
+	// quantities right above each multiple of the common divisor
+	assert.Equal(t, []int{250, 500, 750, 1000}, simulatePacks([]int{250, 500, 1000}, 1000, 250))
+}
+
+func TestSimulationStep(t *testing.T) {
+	assert.Equal(t, 1, simulationStep(53, 1, 3))
+	assert.Equal(t, 250, simulationStep(5000, 250, 25))
+	// the largest allowed sizes are simulated within the budget
+	step := simulationStep(1_000_000, 1, 100*100)
+	assert.Equal(t, 10_000, step)
+	assert.LessOrEqual(t, 1_000_000/step*100*100, simulationBudget)
+}
+
+func TestAnalyzePackSizes_UnusedSize(t *testing.T) {
+	warnings := analyzePackSizes([]int{5, 10, 20}, testAnalysisPolicy)
+	require.Len(t, warnings, 2)
+	assert.Equal(t, model.WarningUnusedSize, warnings[0].Code)
+	assert.Equal(t, []int{10, 5}, warnings[0].Sizes)
+	assert.Equal(t, "pack size 20 is a multiple of 5 and never reduces the items shipped", warnings[1].Message)
+}
+
+func TestUpdatePackSizesAnalysis(t *testing.T) {
+	t.Run("warn mode returns warnings with the update", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo, analysis: testAnalysisPolicy, approval: directWrites}
+
+		sizes := []int{400, 600}
+		mockRepo.On("UpdatePackSizes", mock.Anything, sizes, "tester", "new supplier").
+			Return(&model.PackConfiguration{Version: 2, PackSizes: sizes, UpdatedBy: "tester"}, nil)
+
+		res, err := service.UpdatePackSizes(context.Background(), sizes, "tester", "new supplier")
+		assert.NoError(t, err)
+		assert.Equal(t, []model.PackSizeWarningCode{model.WarningCommonDivisor}, warningCodes(res.Warnings))
+	})
+	t.Run("reject mode fails before writing", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		policy := testAnalysisPolicy
+		policy.Mode = "reject"
+		service := packService{packRepo: &mockRepo, analysis: policy, approval: directWrites}
+
+		res, err := service.UpdatePackSizes(context.Background(), []int{400, 600}, "tester", "new supplier")
+		assert.Nil(t, res)
+		appErr, ok := apperror.AsAppError(err)
+		assert.True(t, ok)
+		assert.Equal(t, apperror.ErrCodeValidation, appErr.Code)
+		assert.Contains(t, appErr.Details, "warnings")
+		mockRepo.AssertNotCalled(t, "UpdatePackSizes", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("dry run import reports warnings in reject mode", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		policy := testAnalysisPolicy
+		policy.Mode = "reject"
+		service := packService{packRepo: &mockRepo, analysis: policy}
+
+		mockRepo.On("GetPackConfiguration", mock.Anything).
+			Return(&model.PackConfiguration{Version: 3, PackSizes: []int{23, 31}}, nil)
+
+		res, err := service.ImportPackSizes(context.Background(), []int{400, 600}, "tester", "", true)
+		assert.NoError(t, err)
+		assert.True(t, res.DryRun)
+		assert.Equal(t, []model.PackSizeWarningCode{model.WarningCommonDivisor}, warningCodes(res.Warnings))
+	})
+}
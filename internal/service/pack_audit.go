@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/model"
+)
+
+// ListAuditEntries retrieves configuration changes from the audit log, newest first
+func (s *packService) ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	entries, err := s.packRepo.ListAuditEntries(ctx, filter)
+	if err != nil {
+		return nil, apperror.InternalError("Failed to retrieve audit log", err)
+	}
+
+	// return an empty list rather than null
+	if entries == nil {
+		entries = []*model.AuditEntry{}
+	}
+
+	return entries, nil
+}
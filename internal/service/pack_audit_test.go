@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListAuditEntries(t *testing.T) {
+	t.Run("returns entries", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo}
+
+		filter := model.AuditFilter{UpdatedBy: "alice", Limit: 5}
+		expected := []*model.AuditEntry{{ID: 7, Version: 3, PreviousVersion: 2, Reason: "new supplier", UpdatedBy: "alice"}}
+		mockRepo.On("ListAuditEntries", mock.Anything, filter).Return(expected, nil)
+
+		res, err := service.ListAuditEntries(context.Background(), filter)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+	t.Run("empty log is an empty list", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo}
+
+		mockRepo.On("ListAuditEntries", mock.Anything, model.AuditFilter{}).Return(nil, nil)
+
+		res, err := service.ListAuditEntries(context.Background(), model.AuditFilter{})
+		assert.NoError(t, err)
+		assert.NotNil(t, res)
+		assert.Empty(t, res)
+	})
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo}
+
+		mockRepo.On("ListAuditEntries", mock.Anything, model.AuditFilter{}).Return(nil, assert.AnError)
+
+		res, err := service.ListAuditEntries(context.Background(), model.AuditFilter{})
+		assert.Nil(t, res)
+		assert.EqualError(t, err, apperror.InternalError("Failed to retrieve audit log", assert.AnError).Error())
+	})
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+)
+
+// CreateDraft proposes new pack sizes that must be approved by another user before they become active
+// reason is kept with the draft and recorded in the audit log once it is approved
+func (s *packService) CreateDraft(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.PackConfigurationDraft, error) {
+	sort.Ints(sizes)
+
+	warnings, err := s.checkPackSizes(sizes)
+	if err != nil {
+		return nil, err
+	}
+
+	draft, err := s.packRepo.CreateDraft(ctx, sizes, updatedBy, reason)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperror.NotFoundError("Pack configuration not found", err)
+		}
+		return nil, apperror.InternalError("Failed to create draft", err)
+	}
+
+	draft.Warnings = warnings
+	return draft, nil
+}
+
+// GetDraft retrieves a single draft
+func (s *packService) GetDraft(ctx context.Context, id int) (*model.PackConfigurationDraft, error) {
+	draft, err := s.packRepo.GetDraft(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperror.NotFoundError("Draft not found", err)
+		}
+		return nil, apperror.InternalError("Failed to retrieve draft", err)
+	}
+
+	return draft, nil
+}
+
+// ListDrafts retrieves drafts, optionally filtered by status
+func (s *packService) ListDrafts(ctx context.Context, status model.DraftStatus, limit int) ([]*model.PackConfigurationDraft, error) {
+	drafts, err := s.packRepo.ListDrafts(ctx, status, limit)
+	if err != nil {
+		return nil, apperror.InternalError("Failed to retrieve drafts", err)
+	}
+
+	// return an empty list rather than null
+	if drafts == nil {
+		drafts = []*model.PackConfigurationDraft{}
+	}
+
+	return drafts, nil
+}
+
+// ApproveDraft applies a pending draft as the active configuration
+// The reviewer must be a different user than the one who proposed the draft
+func (s *packService) ApproveDraft(ctx context.Context, id int, reviewedBy string) (*model.UpdatePackSizesResponse, error) {
+	draft, err := s.GetDraft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPending(draft); err != nil {
+		return nil, err
+	}
+
+	// two-person rule: the author cannot approve their own change
+	if draft.UpdatedBy == reviewedBy {
+		return nil, apperror.ForbiddenError("Drafts must be approved by a different user than the author", nil)
+	}
+
+	res, err := s.packRepo.ApproveDraft(ctx, id, reviewedBy)
+	if err != nil {
+		return nil, mapReviewError(err, "Failed to approve draft")
+	}
+
+	// the draft passed the policy when it was created, warnings are informational for the reviewer
+	updated := toUpdatePackSizesResponse(res)
+	updated.Warnings = analyzePackSizes(res.PackSizes, s.analysis)
+	return updated, nil
+}
+
+// RejectDraft rejects a pending draft without changing the active configuration
+// Authors may reject their own drafts to withdraw them
+func (s *packService) RejectDraft(ctx context.Context, id int, reviewedBy string, comment string) (*model.PackConfigurationDraft, error) {
+	draft, err := s.GetDraft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkPending(draft); err != nil {
+		return nil, err
+	}
+
+	res, err := s.packRepo.RejectDraft(ctx, id, reviewedBy, comment)
+	if err != nil {
+		return nil, mapReviewError(err, "Failed to reject draft")
+	}
+
+	return res, nil
+}
+
+// checkPending ensures the draft is still open for review
+func checkPending(draft *model.PackConfigurationDraft) error {
+	if draft.Status != model.DraftStatusPending {
+		return apperror.ConflictError("Draft has already been reviewed", nil).
+			WithDetails("status", draft.Status)
+	}
+	return nil
+}
+
+// mapReviewError converts repository errors raised while reviewing a draft into AppErrors
+func mapReviewError(err error, message string) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return apperror.NotFoundError("Draft not found", err)
+	case errors.Is(err, repository.ErrDraftNotPending):
+		return apperror.ConflictError("Draft has already been reviewed", err)
+	case errors.Is(err, repository.ErrVersionConflict):
+		return apperror.ConflictError("Pack configuration has changed since the draft was created", err)
+	case errors.Is(err, repository.ErrConcurrentUpdate):
+		return apperror.ConflictError("Pack configuration is being changed concurrently, please retry", err)
+	case errors.Is(err, repository.ErrUnavailable):
+		return apperror.ServiceUnavailableError("", err)
+	default:
+		return apperror.InternalError(message, err)
+	}
+}
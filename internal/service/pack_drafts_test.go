@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateDraft(t *testing.T) {
+	t.Run("sizes are sorted before storing", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo}
+
+		expected := &model.PackConfigurationDraft{
+			ID:          1,
+			PackSizes:   []int{250, 500, 1000},
+			Status:      model.DraftStatusPending,
+			BaseVersion: 3,
+			UpdatedBy:   "alice",
+		}
+		mockRepo.On("CreateDraft", mock.Anything, []int{250, 500, 1000}, "alice", "peak season").Return(expected, nil)
+
+		res, err := service.CreateDraft(context.Background(), []int{1000, 250, 500}, "alice", "peak season")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res)
+	})
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo}
+
+		mockRepo.On("CreateDraft", mock.Anything, []int{250}, "alice", "peak season").Return(nil, assert.AnError)
+
+		res, err := service.CreateDraft(context.Background(), []int{250}, "alice", "peak season")
+		assert.Nil(t, res)
+		assert.EqualError(t, err, apperror.InternalError("Failed to create draft", assert.AnError).Error())
+	})
+}
+
+func TestApproveDraft(t *testing.T) {
+	pendingDraft := func() *model.PackConfigurationDraft {
+		return &model.PackConfigurationDraft{
+			ID:          7,
+			PackSizes:   []int{250, 750},
+			Status:      model.DraftStatusPending,
+			BaseVersion: 3,
+			UpdatedBy:   "alice",
+		}
+	}
+
+	t.Run("successful approval", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo}
+
+		mockRepo.On("GetDraft", mock.Anything, 7).Return(pendingDraft(), nil)
+		mockRepo.On("ApproveDraft", mock.Anything, 7, "bob").Return(&model.PackConfiguration{
+			ID:         1,
+			Version:    4,
+			PackSizes:  []int{250, 750},
+			UpdatedBy:  "alice",
+			ApprovedBy: "bob",
+		}, nil)
+
+		res, err := service.ApproveDraft(context.Background(), 7, "bob")
+		assert.NoError(t, err)
+		assert.Equal(t, 4, res.Version)
+		assert.Equal(t, "alice", res.UpdatedBy)
+		assert.Equal(t, "bob", res.ApprovedBy)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("author cannot approve own draft", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo}
+
+		mockRepo.On("GetDraft", mock.Anything, 7).Return(pendingDraft(), nil)
+
+		res, err := service.ApproveDraft(context.Background(), 7, "alice")
+		assert.Nil(t, res)
+		appErr, ok := apperror.AsAppError(err)
+		assert.True(t, ok)
+		assert.Equal(t, apperror.ErrCodeForbidden, appErr.Code)
+		mockRepo.AssertNotCalled(t, "ApproveDraft", mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("draft already reviewed", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo}
+
+		draft := pendingDraft()
+		draft.Status = model.DraftStatusRejected
+		mockRepo.On("GetDraft", mock.Anything, 7).Return(draft, nil)
+
+		res, err := service.ApproveDraft(context.Background(), 7, "bob")
+		assert.Nil(t, res)
+		appErr, ok := apperror.AsAppError(err)
+		assert.True(t, ok)
+		assert.Equal(t, apperror.ErrCodeConflict, appErr.Code)
+	})
+	t.Run("draft not found", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo}
+
+		mockRepo.On("GetDraft", mock.Anything, 7).Return(nil, repository.ErrNotFound)
+
+		res, err := service.ApproveDraft(context.Background(), 7, "bob")
+		assert.Nil(t, res)
+		assert.EqualError(t, err, apperror.NotFoundError("Draft not found", repository.ErrNotFound).Error())
+	})
+	t.Run("configuration changed since draft was created", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo}
+
+		mockRepo.On("GetDraft", mock.Anything, 7).Return(pendingDraft(), nil)
+		mockRepo.On("ApproveDraft", mock.Anything, 7, "bob").Return(nil, repository.ErrVersionConflict)
+
+		res, err := service.ApproveDraft(context.Background(), 7, "bob")
+		assert.Nil(t, res)
+		appErr, ok := apperror.AsAppError(err)
+		assert.True(t, ok)
+		assert.Equal(t, apperror.ErrCodeConflict, appErr.Code)
+	})
+}
+
+func TestRejectDraft(t *testing.T) {
+	t.Run("successful rejection", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo}
+
+		mockRepo.On("GetDraft", mock.Anything, 7).Return(&model.PackConfigurationDraft{
+			ID:        7,
+			Status:    model.DraftStatusPending,
+			UpdatedBy: "alice",
+		}, nil)
+		rejected := &model.PackConfigurationDraft{
+			ID:            7,
+			Status:        model.DraftStatusRejected,
+			UpdatedBy:     "alice",
+			ReviewedBy:    "bob",
+			ReviewComment: "750 is not a stocked box",
+		}
+		mockRepo.On("RejectDraft", mock.Anything, 7, "bob", "750 is not a stocked box").Return(rejected, nil)
+
+		res, err := service.RejectDraft(context.Background(), 7, "bob", "750 is not a stocked box")
+		assert.NoError(t, err)
+		assert.Equal(t, rejected, res)
+	})
+	t.Run("author can withdraw own draft", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo}
+
+		mockRepo.On("GetDraft", mock.Anything, 7).Return(&model.PackConfigurationDraft{
+			ID:        7,
+			Status:    model.DraftStatusPending,
+			UpdatedBy: "alice",
+		}, nil)
+		mockRepo.On("RejectDraft", mock.Anything, 7, "alice", "").Return(&model.PackConfigurationDraft{
+			ID:         7,
+			Status:     model.DraftStatusRejected,
+			UpdatedBy:  "alice",
+			ReviewedBy: "alice",
+		}, nil)
+
+		res, err := service.RejectDraft(context.Background(), 7, "alice", "")
+		assert.NoError(t, err)
+		assert.Equal(t, model.DraftStatusRejected, res.Status)
+	})
+}
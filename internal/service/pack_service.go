@@ -3,8 +3,11 @@ package service
 import (
 	"context"
 	"errors"
+	"slices"
 	"sort"
+	"time"
 
+	"github.com/nsaltun/packman/config"
 	"github.com/nsaltun/packman/internal/apperror"
 	"github.com/nsaltun/packman/internal/model"
 	"github.com/nsaltun/packman/internal/repository"
@@ -13,18 +16,31 @@ import (
 // PackService defines the interface for pack-related operations
 type PackService interface {
 	CalculatePacks(ctx context.Context, quantity int) (*model.PackCalculationResponse, error)
+	CalculatePacksAsOf(ctx context.Context, quantity int, version int, at time.Time) (*model.PackCalculationResponse, error)
 	GetPackSizes(ctx context.Context) (*model.GetPackSizesResponse, error)
-	UpdatePackSizes(ctx context.Context, sizes []int, updatedBy string) (*model.UpdatePackSizesResponse, error)
+	UpdatePackSizes(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.UpdatePackSizesResponse, error)
+	PatchPackSizes(ctx context.Context, add, remove []int, updatedBy string, reason string) (*model.UpdatePackSizesResponse, error)
+	ExportConfiguration(ctx context.Context) (*model.PackConfigurationExport, error)
+	ImportPackSizes(ctx context.Context, sizes []int, updatedBy string, reason string, dryRun bool) (*model.ImportPackSizesResponse, error)
+	CreateDraft(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.PackConfigurationDraft, error)
+	GetDraft(ctx context.Context, id int) (*model.PackConfigurationDraft, error)
+	ListDrafts(ctx context.Context, status model.DraftStatus, limit int) ([]*model.PackConfigurationDraft, error)
+	ApproveDraft(ctx context.Context, id int, reviewedBy string) (*model.UpdatePackSizesResponse, error)
+	RejectDraft(ctx context.Context, id int, reviewedBy string, comment string) (*model.PackConfigurationDraft, error)
+	ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error)
 }
 
 // packService is the concrete implementation of PackService
 type packService struct {
 	packRepo repository.PackRepository
+	analysis config.PackAnalysisConfig
+	approval config.PackApprovalConfig
 }
 
 // NewPackService creates a new instance of PackService
-func NewPackService(packRepo repository.PackRepository) PackService {
-	return &packService{packRepo: packRepo}
+// analysis is the policy applied to new pack size sets, approval decides whether they may skip the drafts
+func NewPackService(packRepo repository.PackRepository, analysis config.PackAnalysisConfig, approval config.PackApprovalConfig) PackService {
+	return &packService{packRepo: packRepo, analysis: analysis, approval: approval}
 }
 
 // CalculatePacks calculates the optimal combination of packs for a given quantity
@@ -43,21 +59,58 @@ func (s *packService) CalculatePacks(ctx context.Context, quantity int) (*model.
 		return nil, apperror.InternalError("Pack sizes configuration is empty", nil)
 	}
 
-	// sort pack sizes in descending order
+	// return result
+	return &model.PackCalculationResponse{Packs: calculatePacks(packSizes, quantity), Quantity: quantity}, nil
+}
+
+// CalculatePacksAsOf calculates packs using a historical configuration, selected either by
+// version (when version > 0) or by the time it was active, and reports the version used
+func (s *packService) CalculatePacksAsOf(ctx context.Context, quantity int, version int, at time.Time) (*model.PackCalculationResponse, error) {
+	var cfg *model.PackConfiguration
+	var err error
+	if version > 0 {
+		cfg, err = s.packRepo.GetPackConfigurationByVersion(ctx, version)
+	} else {
+		cfg, err = s.packRepo.GetPackConfigurationAsOf(ctx, at)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperror.NotFoundError("Pack configuration version not found", err)
+		}
+		return nil, apperror.InternalError("Failed to retrieve pack configuration", err)
+	}
+
+	if len(cfg.PackSizes) == 0 {
+		return nil, apperror.InternalError("Pack sizes configuration is empty", nil)
+	}
+
+	return &model.PackCalculationResponse{
+		Packs:    calculatePacks(cfg.PackSizes, quantity),
+		Quantity: quantity,
+		Version:  cfg.Version,
+	}, nil
+}
+
+// calculatePacks returns the number of packs per size needed to fulfil quantity
+func calculatePacks(packSizes []int, quantity int) map[int]int {
+	// sort a copy of pack sizes in descending order, the given slice may be shared
+	packSizes = slices.Clone(packSizes)
 	sort.Slice(packSizes, func(i, j int) bool {
 		return packSizes[i] > packSizes[j]
 	})
 
-	//-- Callculate packs --
-
-	//Create a copy of quantity to return later
-	originalQuantity := quantity
+	packsNumberResult := make(map[int]int)
+	fillPacks(packSizes, quantity, packsNumberResult)
+	return packsNumberResult
+}
 
+// fillPacks adds the packs needed to fulfil quantity to packsNumberResult
+// packSizes must be sorted in descending order
+func fillPacks(packSizes []int, quantity int, packsNumberResult map[int]int) {
 	// get the smallest pack size
 	minPackSize := packSizes[len(packSizes)-1]
 
 	// greedy algorithm to find the combination of packs
-	packsNumberResult := make(map[int]int)
 	// iterate over pack sizes
 	for _, packSize := range packSizes {
 		// if quantity is zero, break
@@ -79,9 +132,6 @@ func (s *packService) CalculatePacks(ctx context.Context, quantity int) (*model.
 	if quantity > 0 {
 		packsNumberResult[minPackSize]++
 	}
-
-	// return result
-	return &model.PackCalculationResponse{Packs: packsNumberResult, Quantity: originalQuantity}, nil
 }
 
 // GetPackSizes retrieves the current pack sizes from the repository
@@ -95,29 +145,126 @@ func (s *packService) GetPackSizes(ctx context.Context) (*model.GetPackSizesResp
 	}
 
 	return &model.GetPackSizesResponse{
-		PackSizes: res.PackSizes,
-		UpdatedAt: res.UpdatedAt,
-		UpdatedBy: res.UpdatedBy,
-		Version:   res.Version,
+		PackSizes:  res.PackSizes,
+		UpdatedAt:  res.UpdatedAt,
+		UpdatedBy:  res.UpdatedBy,
+		ApprovedBy: res.ApprovedBy,
+		Version:    res.Version,
 	}, nil
 }
 
 // UpdatePackSizes updates the pack sizes in the repository and returns the updated configuration
-func (s *packService) UpdatePackSizes(ctx context.Context, sizes []int, updatedBy string) (*model.UpdatePackSizesResponse, error) {
+// reason is recorded in the audit log together with the request metadata from ctx
+func (s *packService) UpdatePackSizes(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.UpdatePackSizesResponse, error) {
+	if err := s.checkDirectWrites(); err != nil {
+		return nil, err
+	}
+
 	sort.Ints(sizes)
 
-	res, err := s.packRepo.UpdatePackSizes(ctx, sizes, updatedBy)
+	warnings, err := s.checkPackSizes(sizes)
 	if err != nil {
-		if errors.Is(err, repository.ErrNotFound) {
-			return nil, apperror.NotFoundError("Pack configuration not found", err)
+		return nil, err
+	}
+
+	res, err := s.packRepo.UpdatePackSizes(ctx, sizes, updatedBy, reason)
+	if err != nil {
+		return nil, mapWriteError(err, "Failed to update pack sizes")
+	}
+
+	updated := toUpdatePackSizesResponse(res)
+	updated.Warnings = warnings
+	return updated, nil
+}
+
+// PatchPackSizes adds and removes individual pack sizes atomically
+// The resulting set is checked while the configuration row is locked, it cannot be empty
+func (s *packService) PatchPackSizes(ctx context.Context, add, remove []int, updatedBy string, reason string) (*model.UpdatePackSizesResponse, error) {
+	if err := s.checkDirectWrites(); err != nil {
+		return nil, err
+	}
+
+	var warnings []model.PackSizeWarning
+	res, err := s.packRepo.ModifyPackSizes(ctx, updatedBy, reason, func(current []int) ([]int, error) {
+		sizes := applyPackSizesPatch(current, add, remove)
+		if len(sizes) == 0 {
+			return nil, apperror.ValidationError("pack_sizes cannot be empty", nil)
+		}
+
+		var err error
+		warnings, err = s.checkPackSizes(sizes)
+		if err != nil {
+			return nil, err
 		}
-		return nil, apperror.InternalError("Failed to update pack sizes", err)
+		return sizes, nil
+	})
+	if err != nil {
+		return nil, mapWriteError(err, "Failed to update pack sizes")
 	}
 
+	updated := toUpdatePackSizesResponse(res)
+	updated.Warnings = warnings
+	return updated, nil
+}
+
+// checkDirectWrites rejects changes of the active configuration that skip the draft approval,
+// unless direct writes are enabled
+func (s *packService) checkDirectWrites() error {
+	if s.approval.DirectWrites {
+		return nil
+	}
+	return apperror.ForbiddenError("Pack sizes must be changed through a draft approved by a different user", nil).
+		WithDetails("drafts", "/api/v1/pack-sizes/drafts")
+}
+
+// mapWriteError converts errors raised while changing the active configuration into AppErrors
+// AppErrors returned by change functions are passed through as is
+func mapWriteError(err error, message string) error {
+	if appErr, ok := apperror.AsAppError(err); ok {
+		return appErr
+	}
+
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return apperror.NotFoundError("Pack configuration not found", err)
+	case errors.Is(err, repository.ErrConcurrentUpdate):
+		return apperror.ConflictError("Pack configuration is being changed concurrently, please retry", err)
+	case errors.Is(err, repository.ErrUnavailable):
+		return apperror.ServiceUnavailableError("", err)
+	default:
+		return apperror.InternalError(message, err)
+	}
+}
+
+// applyPackSizesPatch returns the sorted set of current sizes plus add, minus remove
+// Adding an existing size or removing a missing one is a no-op
+func applyPackSizesPatch(current, add, remove []int) []int {
+	set := make(map[int]struct{}, len(current)+len(add))
+	for _, size := range current {
+		set[size] = struct{}{}
+	}
+	for _, size := range add {
+		set[size] = struct{}{}
+	}
+	for _, size := range remove {
+		delete(set, size)
+	}
+
+	sizes := make([]int, 0, len(set))
+	for size := range set {
+		sizes = append(sizes, size)
+	}
+	sort.Ints(sizes)
+	return sizes
+}
+
+// toUpdatePackSizesResponse maps an updated configuration to the update response
+func toUpdatePackSizesResponse(cfg *model.PackConfiguration) *model.UpdatePackSizesResponse {
 	return &model.UpdatePackSizesResponse{
-		PackSizes: res.PackSizes,
-		UpdatedAt: res.UpdatedAt,
-		UpdatedBy: res.UpdatedBy,
-		Version:   res.Version,
-	}, nil
+		PackSizes:  cfg.PackSizes,
+		UpdatedAt:  cfg.UpdatedAt,
+		UpdatedBy:  cfg.UpdatedBy,
+		ApprovedBy: cfg.ApprovedBy,
+		Version:    cfg.Version,
+	}
 }
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests run the service against the in-memory repository instead of mocks
+func TestPackServiceWithMemoryRepo(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("update, calculate and calculate against the previous version", func(t *testing.T) {
+		service := packService{packRepo: repository.NewMemoryRepo(), approval: directWrites}
+
+		updated, err := service.UpdatePackSizes(ctx, []int{53, 31, 23}, "alice", "edge case sizes")
+		require.NoError(t, err)
+		assert.Equal(t, 2, updated.Version)
+
+		res, err := service.CalculatePacks(ctx, 263)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]int{53: 4, 31: 1, 23: 1}, res.Packs)
+
+		res, err = service.CalculatePacksAsOf(ctx, 251, 1, time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, res.Version)
+		assert.Equal(t, map[int]int{250: 2}, res.Packs)
+	})
+	t.Run("draft approval follows the two-person rule", func(t *testing.T) {
+		service := packService{packRepo: repository.NewMemoryRepo()}
+
+		draft, err := service.CreateDraft(ctx, []int{750, 250}, "alice", "peak season")
+		require.NoError(t, err)
+
+		_, err = service.ApproveDraft(ctx, draft.ID, "alice")
+		appErr, ok := apperror.AsAppError(err)
+		require.True(t, ok)
+		assert.Equal(t, apperror.ErrCodeForbidden, appErr.Code)
+
+		approved, err := service.ApproveDraft(ctx, draft.ID, "bob")
+		require.NoError(t, err)
+		assert.Equal(t, []int{250, 750}, approved.PackSizes)
+
+		entries, err := service.ListAuditEntries(ctx, model.AuditFilter{})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "peak season", entries[0].Reason)
+		assert.Equal(t, "bob", entries[0].ApprovedBy)
+	})
+	t.Run("patch validation rolls back", func(t *testing.T) {
+		service := packService{packRepo: repository.NewMemoryRepo(), approval: directWrites}
+
+		_, err := service.PatchPackSizes(ctx, nil, repository.DefaultPackSizes, "alice", "remove everything")
+		appErr, ok := apperror.AsAppError(err)
+		require.True(t, ok)
+		assert.Equal(t, apperror.ErrCodeValidation, appErr.Code)
+
+		current, err := service.GetPackSizes(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, current.Version)
+		assert.Equal(t, repository.DefaultPackSizes, current.PackSizes)
+	})
+	t.Run("direct writes are rejected while approval is required", func(t *testing.T) {
+		service := packService{packRepo: repository.NewMemoryRepo()}
+
+		_, err := service.UpdatePackSizes(ctx, []int{750}, "alice", "skip review")
+		appErr, ok := apperror.AsAppError(err)
+		require.True(t, ok)
+		assert.Equal(t, apperror.ErrCodeForbidden, appErr.Code)
+		_, err = service.PatchPackSizes(ctx, []int{750}, nil, "alice", "skip review")
+		assert.Error(t, err)
+		_, err = service.ImportPackSizes(ctx, []int{750}, "alice", "skip review", false)
+		assert.Error(t, err)
+
+		current, err := service.GetPackSizes(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, current.Version)
+	})
+}
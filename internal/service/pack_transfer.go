@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+)
+
+// ExportConfiguration returns the active configuration together with its full history
+func (s *packService) ExportConfiguration(ctx context.Context) (*model.PackConfigurationExport, error) {
+	cfg, err := s.packRepo.GetPackConfiguration(ctx)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperror.NotFoundError("Pack configuration not found", err)
+		}
+		return nil, apperror.InternalError("Failed to retrieve pack configuration", err)
+	}
+
+	history, err := s.packRepo.GetFullPackConfigurationHistory(ctx)
+	if err != nil {
+		return nil, apperror.InternalError("Failed to retrieve pack configuration history", err)
+	}
+
+	export := &model.PackConfigurationExport{
+		Version:    cfg.Version,
+		PackSizes:  cfg.PackSizes,
+		UpdatedAt:  cfg.UpdatedAt,
+		UpdatedBy:  cfg.UpdatedBy,
+		ApprovedBy: cfg.ApprovedBy,
+		ExportedAt: time.Now().UTC(),
+		History:    make([]model.PackConfigurationVersion, 0, len(history)),
+	}
+	for _, h := range history {
+		export.History = append(export.History, model.PackConfigurationVersion{
+			Version:    h.Version,
+			PackSizes:  h.PackSizes,
+			ValidFrom:  h.ValidFrom,
+			ArchivedAt: h.UpdatedAt,
+			UpdatedBy:  h.UpdatedBy,
+			ApprovedBy: h.ApprovedBy,
+		})
+	}
+
+	return export, nil
+}
+
+// ImportPackSizes applies imported pack sizes as a new version, or only reports the changes on a dry run
+// Importing the active set again does not create a new version, so repeated syncs are safe
+func (s *packService) ImportPackSizes(ctx context.Context, sizes []int, updatedBy string, reason string, dryRun bool) (*model.ImportPackSizesResponse, error) {
+	sort.Ints(sizes)
+
+	if dryRun {
+		cfg, err := s.packRepo.GetPackConfiguration(ctx)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, apperror.NotFoundError("Pack configuration not found", err)
+			}
+			return nil, apperror.InternalError("Failed to retrieve pack configuration", err)
+		}
+
+		// a dry run reports the warnings without applying the reject policy
+		res := diffPackSizes(cfg.PackSizes, sizes)
+		res.DryRun = true
+		res.CurrentVersion = cfg.Version
+		res.Warnings = analyzePackSizes(sizes, s.analysis)
+		return res, nil
+	}
+
+	if err := s.checkDirectWrites(); err != nil {
+		return nil, err
+	}
+
+	warnings, err := s.checkPackSizes(sizes)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *model.ImportPackSizesResponse
+	cfg, err := s.packRepo.ModifyPackSizes(ctx, updatedBy, reason, func(current []int) ([]int, error) {
+		res = diffPackSizes(current, sizes)
+		return sizes, nil
+	})
+	if err != nil {
+		return nil, mapWriteError(err, "Failed to import pack sizes")
+	}
+
+	res.Warnings = warnings
+	res.Configuration = toUpdatePackSizesResponse(cfg)
+	return res, nil
+}
+
+// diffPackSizes reports which sizes are added and removed when replacing current with next
+func diffPackSizes(current, next []int) *model.ImportPackSizesResponse {
+	res := &model.ImportPackSizesResponse{
+		Added:   []int{},
+		Removed: []int{},
+	}
+	for _, size := range next {
+		if !slices.Contains(current, size) {
+			res.Added = append(res.Added, size)
+		}
+	}
+	for _, size := range current {
+		if !slices.Contains(next, size) {
+			res.Removed = append(res.Removed, size)
+		}
+	}
+	sort.Ints(res.Added)
+	sort.Ints(res.Removed)
+
+	res.Changed = len(res.Added) > 0 || len(res.Removed) > 0
+	return res
+}
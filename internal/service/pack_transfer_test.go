@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExportConfiguration(t *testing.T) {
+	t.Run("active configuration with history", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo}
+
+		updatedAt := time.Date(2025, 11, 20, 9, 0, 0, 0, time.UTC)
+		seededAt := updatedAt.Add(-5 * time.Hour)
+		supersededAt := updatedAt.Add(-2 * time.Hour)
+		mockRepo.On("GetPackConfiguration", mock.Anything).Return(&model.PackConfiguration{
+			Version: 3, PackSizes: []int{250, 500}, UpdatedAt: updatedAt, UpdatedBy: "alice", ApprovedBy: "bob",
+		}, nil)
+		mockRepo.On("GetFullPackConfigurationHistory", mock.Anything).Return([]*model.PackConfiguration{
+			{Version: 1, PackSizes: []int{250}, UpdatedAt: supersededAt, UpdatedBy: "system", ValidFrom: &seededAt},
+			{Version: 2, PackSizes: []int{250, 1000}, UpdatedAt: updatedAt, UpdatedBy: "alice", ValidFrom: &supersededAt},
+		}, nil)
+
+		res, err := service.ExportConfiguration(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 3, res.Version)
+		assert.Equal(t, []int{250, 500}, res.PackSizes)
+		assert.Equal(t, "bob", res.ApprovedBy)
+		assert.Len(t, res.History, 2)
+		assert.Equal(t, 1, res.History[0].Version)
+		// the times versions became active are exported as stored, including that of the oldest version
+		assert.Equal(t, &seededAt, res.History[0].ValidFrom)
+		assert.Equal(t, updatedAt, res.History[1].ArchivedAt)
+		assert.Equal(t, &supersededAt, res.History[1].ValidFrom)
+	})
+	t.Run("history error", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo}
+
+		mockRepo.On("GetPackConfiguration", mock.Anything).Return(&model.PackConfiguration{Version: 3}, nil)
+		mockRepo.On("GetFullPackConfigurationHistory", mock.Anything).Return(nil, assert.AnError)
+
+		res, err := service.ExportConfiguration(context.Background())
+		assert.Nil(t, res)
+		assert.EqualError(t, err, apperror.InternalError("Failed to retrieve pack configuration history", assert.AnError).Error())
+	})
+}
+
+func TestImportPackSizes(t *testing.T) {
+	t.Run("dry run reports changes without writing", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo}
+
+		mockRepo.On("GetPackConfiguration", mock.Anything).Return(&model.PackConfiguration{
+			Version: 3, PackSizes: []int{250, 500, 1000},
+		}, nil)
+
+		res, err := service.ImportPackSizes(context.Background(), []int{1000, 750, 250}, "ci", "", true)
+		assert.NoError(t, err)
+		assert.Equal(t, &model.ImportPackSizesResponse{
+			DryRun:         true,
+			Changed:        true,
+			Added:          []int{750},
+			Removed:        []int{500},
+			CurrentVersion: 3,
+		}, res)
+		mockRepo.AssertNotCalled(t, "ModifyPackSizes", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("applies imported sizes", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo, approval: directWrites}
+
+		var changed []int
+		var changeErr error
+		mockRepo.On("ModifyPackSizes", mock.Anything, "ci", "nightly sync", mock.Anything).
+			Run(runChange([]int{250, 500}, &changed, &changeErr)).
+			Return(&model.PackConfiguration{Version: 4, PackSizes: []int{250, 750}, UpdatedBy: "ci"}, nil)
+
+		res, err := service.ImportPackSizes(context.Background(), []int{750, 250}, "ci", "nightly sync", false)
+		assert.NoError(t, err)
+		assert.NoError(t, changeErr)
+		assert.Equal(t, []int{250, 750}, changed)
+		assert.False(t, res.DryRun)
+		assert.True(t, res.Changed)
+		assert.Equal(t, []int{750}, res.Added)
+		assert.Equal(t, []int{500}, res.Removed)
+		assert.Equal(t, 4, res.Configuration.Version)
+	})
+	t.Run("unchanged import", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo, approval: directWrites}
+
+		var changed []int
+		var changeErr error
+		mockRepo.On("ModifyPackSizes", mock.Anything, "ci", "nightly sync", mock.Anything).
+			Run(runChange([]int{250, 500}, &changed, &changeErr)).
+			Return(&model.PackConfiguration{Version: 3, PackSizes: []int{250, 500}}, nil)
+
+		res, err := service.ImportPackSizes(context.Background(), []int{500, 250}, "ci", "nightly sync", false)
+		assert.NoError(t, err)
+		assert.False(t, res.Changed)
+		assert.Empty(t, res.Added)
+		assert.Empty(t, res.Removed)
+		assert.Equal(t, 3, res.Configuration.Version)
+	})
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// runChange invokes the change function passed to ModifyPackSizes against the given current sizes
+func runChange(current []int, result *[]int, resultErr *error) func(args mock.Arguments) {
+	return func(args mock.Arguments) {
+		change := args.Get(3).(func([]int) ([]int, error))
+		*result, *resultErr = change(current)
+	}
+}
+
+func TestPatchPackSizes(t *testing.T) {
+	t.Run("adds and removes sizes from the current set", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo, approval: directWrites}
+
+		var changed []int
+		var changeErr error
+		mockRepo.On("ModifyPackSizes", mock.Anything, "tester", "tidy up sizes", mock.Anything).
+			Run(runChange([]int{250, 500, 1000}, &changed, &changeErr)).
+			Return(&model.PackConfiguration{Version: 2, PackSizes: []int{500, 750, 1000}, UpdatedBy: "tester"}, nil)
+
+		res, err := service.PatchPackSizes(context.Background(), []int{750, 500}, []int{250, 300}, "tester", "tidy up sizes")
+		assert.NoError(t, err)
+		assert.NoError(t, changeErr)
+		assert.Equal(t, []int{500, 750, 1000}, changed)
+		assert.Equal(t, 2, res.Version)
+	})
+	t.Run("resulting set cannot be empty", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo, approval: directWrites}
+
+		var changed []int
+		var changeErr error
+		mockRepo.On("ModifyPackSizes", mock.Anything, "tester", "tidy up sizes", mock.Anything).
+			Run(runChange([]int{250}, &changed, &changeErr)).
+			Return(nil, apperror.ValidationError("pack_sizes cannot be empty", nil))
+
+		res, err := service.PatchPackSizes(context.Background(), nil, []int{250}, "tester", "tidy up sizes")
+		assert.Nil(t, res)
+		assert.Error(t, changeErr)
+		appErr, ok := apperror.AsAppError(err)
+		assert.True(t, ok)
+		assert.Equal(t, apperror.ErrCodeValidation, appErr.Code)
+	})
+	t.Run("repository not found error", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo, approval: directWrites}
+
+		mockRepo.On("ModifyPackSizes", mock.Anything, "tester", "tidy up sizes", mock.Anything).Return(nil, repository.ErrNotFound)
+
+		res, err := service.PatchPackSizes(context.Background(), []int{750}, nil, "tester", "tidy up sizes")
+		assert.Nil(t, res)
+		assert.EqualError(t, err, apperror.NotFoundError("Pack configuration not found", repository.ErrNotFound).Error())
+	})
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := mocks.MockPackRepository{}
+		service := packService{packRepo: &mockRepo, approval: directWrites}
+
+		mockRepo.On("ModifyPackSizes", mock.Anything, "tester", "tidy up sizes", mock.Anything).Return(nil, assert.AnError)
+
+		res, err := service.PatchPackSizes(context.Background(), []int{750}, nil, "tester", "tidy up sizes")
+		assert.Nil(t, res)
+		assert.EqualError(t, err, apperror.InternalError("Failed to update pack sizes", assert.AnError).Error())
+	})
+}
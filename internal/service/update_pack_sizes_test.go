@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
+	"github.com/nsaltun/packman/config"
 	"github.com/nsaltun/packman/internal/apperror"
 	"github.com/nsaltun/packman/internal/mocks"
 	"github.com/nsaltun/packman/internal/model"
@@ -12,10 +14,13 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+// directWrites allows changing the active pack sizes without a draft
+var directWrites = config.PackApprovalConfig{DirectWrites: true}
+
 func TestUpdatePackSizes(t *testing.T) {
 	t.Run("successful update", func(t *testing.T) {
 		mockRepo := mocks.MockPackRepository{}
-		service := packService{packRepo: &mockRepo}
+		service := packService{packRepo: &mockRepo, approval: directWrites}
 
 		sizesToUpdate := []int{250, 500, 1000}
 		updatedBy := "tester"
@@ -26,9 +31,9 @@ func TestUpdatePackSizes(t *testing.T) {
 			UpdatedBy: updatedBy,
 		}
 
-		mockRepo.On("UpdatePackSizes", mock.Anything, sizesToUpdate, updatedBy).Return(expectedConfig, nil)
+		mockRepo.On("UpdatePackSizes", mock.Anything, sizesToUpdate, updatedBy, "new supplier").Return(expectedConfig, nil)
 
-		res, err := service.UpdatePackSizes(context.Background(), sizesToUpdate, updatedBy)
+		res, err := service.UpdatePackSizes(context.Background(), sizesToUpdate, updatedBy, "new supplier")
 		assert.NoError(t, err)
 		assert.NotNil(t, res)
 		assert.Equal(t, sizesToUpdate, res.PackSizes)
@@ -36,24 +41,53 @@ func TestUpdatePackSizes(t *testing.T) {
 	})
 	t.Run("repository error", func(t *testing.T) {
 		mockRepo := mocks.MockPackRepository{}
-		service := packService{packRepo: &mockRepo}
+		service := packService{packRepo: &mockRepo, approval: directWrites}
 		sizesToUpdate := []int{250, 500, 1000}
 		updatedBy := "tester"
 
-		mockRepo.On("UpdatePackSizes", mock.Anything, sizesToUpdate, updatedBy).Return(nil, assert.AnError)
-		res, err := service.UpdatePackSizes(context.Background(), sizesToUpdate, updatedBy)
+		mockRepo.On("UpdatePackSizes", mock.Anything, sizesToUpdate, updatedBy, "new supplier").Return(nil, assert.AnError)
+		res, err := service.UpdatePackSizes(context.Background(), sizesToUpdate, updatedBy, "new supplier")
 		assert.Nil(t, res)
 		assert.EqualError(t, err, apperror.InternalError("Failed to update pack sizes", assert.AnError).Error())
 	})
 	t.Run("repository not found error", func(t *testing.T) {
 		mockRepo := mocks.MockPackRepository{}
-		service := packService{packRepo: &mockRepo}
+		service := packService{packRepo: &mockRepo, approval: directWrites}
 		sizesToUpdate := []int{250, 500, 1000}
 		updatedBy := "tester"
 
-		mockRepo.On("UpdatePackSizes", mock.Anything, sizesToUpdate, updatedBy).Return(nil, repository.ErrNotFound)
-		res, err := service.UpdatePackSizes(context.Background(), sizesToUpdate, updatedBy)
+		mockRepo.On("UpdatePackSizes", mock.Anything, sizesToUpdate, updatedBy, "new supplier").Return(nil, repository.ErrNotFound)
+		res, err := service.UpdatePackSizes(context.Background(), sizesToUpdate, updatedBy, "new supplier")
 		assert.Nil(t, res)
 		assert.EqualError(t, err, apperror.NotFoundError("Pack configuration not found", repository.ErrNotFound).Error())
 	})
+	t.Run("exhausted transaction retries", func(t *testing.T) {
+		tests := []struct {
+			name         string
+			repoErr      error
+			expectedCode apperror.ErrorCode
+			expectedHTTP int
+		}{
+			{name: "concurrent updates", repoErr: repository.ErrConcurrentUpdate, expectedCode: apperror.ErrCodeConflict, expectedHTTP: 409},
+			{name: "database unavailable", repoErr: repository.ErrUnavailable, expectedCode: apperror.ErrCodeServiceUnavail, expectedHTTP: 503},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockRepo := mocks.MockPackRepository{}
+				service := packService{packRepo: &mockRepo, approval: directWrites}
+				sizesToUpdate := []int{250, 500, 1000}
+
+				mockRepo.On("UpdatePackSizes", mock.Anything, sizesToUpdate, "tester", "new supplier").
+					Return(nil, fmt.Errorf("%w: %w", tt.repoErr, assert.AnError))
+				res, err := service.UpdatePackSizes(context.Background(), sizesToUpdate, "tester", "new supplier")
+				assert.Nil(t, res)
+
+				appErr, ok := apperror.AsAppError(err)
+				assert.True(t, ok)
+				assert.Equal(t, tt.expectedCode, appErr.Code)
+				assert.Equal(t, tt.expectedHTTP, appErr.StatusCode)
+				assert.ErrorIs(t, err, tt.repoErr)
+			})
+		}
+	})
 }
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+)
+
+// webhookSecretPrefix marks generated secrets so they are recognizable in configuration and logs
+const webhookSecretPrefix = "whsec_"
+
+// WebhookService defines the interface for managing webhook subscriptions and inspecting their deliveries
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, req *model.CreateWebhookRequest) (*model.WebhookSubscription, error)
+	GetWebhook(ctx context.Context, id int) (*model.WebhookSubscription, error)
+	ListWebhooks(ctx context.Context) ([]*model.WebhookSubscription, error)
+	UpdateWebhook(ctx context.Context, id int, req *model.UpdateWebhookRequest) (*model.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	ListWebhookDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter) ([]*model.WebhookDelivery, error)
+	RetryWebhookDelivery(ctx context.Context, id int64) (*model.WebhookDelivery, error)
+}
+
+// webhookService is the concrete implementation of WebhookService
+type webhookService struct {
+	repo repository.WebhookRepository
+}
+
+// NewWebhookService creates a new instance of WebhookService
+func NewWebhookService(repo repository.WebhookRepository) WebhookService {
+	return &webhookService{repo: repo}
+}
+
+// CreateWebhook subscribes an endpoint, a secret is generated when the request has none
+// The secret is part of the response, it is not returned again afterwards
+func (s *webhookService) CreateWebhook(ctx context.Context, req *model.CreateWebhookRequest) (*model.WebhookSubscription, error) {
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateWebhookSecret(); err != nil {
+			return nil, apperror.InternalError("Failed to generate webhook secret", err)
+		}
+	}
+
+	sub, err := s.repo.CreateWebhook(ctx, &model.WebhookSubscription{
+		URL:         req.URL,
+		Description: req.Description,
+		Active:      true,
+		Secret:      secret,
+		CreatedBy:   req.CreatedBy,
+	})
+	if err != nil {
+		return nil, apperror.InternalError("Failed to create webhook", err)
+	}
+
+	return sub, nil
+}
+
+// GetWebhook retrieves a single subscription without its secret
+func (s *webhookService) GetWebhook(ctx context.Context, id int) (*model.WebhookSubscription, error) {
+	sub, err := s.getWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sub.Secret = ""
+	return sub, nil
+}
+
+// ListWebhooks retrieves all subscriptions without their secrets
+func (s *webhookService) ListWebhooks(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	subs, err := s.repo.ListWebhooks(ctx)
+	if err != nil {
+		return nil, apperror.InternalError("Failed to retrieve webhooks", err)
+	}
+
+	// return an empty list rather than null
+	if subs == nil {
+		subs = []*model.WebhookSubscription{}
+	}
+	for _, sub := range subs {
+		sub.Secret = ""
+	}
+
+	return subs, nil
+}
+
+// UpdateWebhook changes the fields set in req, the secret is only returned when it was rotated
+func (s *webhookService) UpdateWebhook(ctx context.Context, id int, req *model.UpdateWebhookRequest) (*model.WebhookSubscription, error) {
+	sub, err := s.getWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		sub.URL = *req.URL
+	}
+	if req.Description != nil {
+		sub.Description = *req.Description
+	}
+	if req.Active != nil {
+		sub.Active = *req.Active
+	}
+	if req.RotateSecret {
+		if sub.Secret, err = generateWebhookSecret(); err != nil {
+			return nil, apperror.InternalError("Failed to generate webhook secret", err)
+		}
+	}
+
+	updated, err := s.repo.UpdateWebhook(ctx, sub)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperror.NotFoundError("Webhook not found", err)
+		}
+		return nil, apperror.InternalError("Failed to update webhook", err)
+	}
+
+	if !req.RotateSecret {
+		updated.Secret = ""
+	}
+	return updated, nil
+}
+
+// DeleteWebhook removes a subscription and its delivery log
+func (s *webhookService) DeleteWebhook(ctx context.Context, id int) error {
+	if err := s.repo.DeleteWebhook(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return apperror.NotFoundError("Webhook not found", err)
+		}
+		return apperror.InternalError("Failed to delete webhook", err)
+	}
+
+	return nil
+}
+
+// ListWebhookDeliveries retrieves deliveries from newest to oldest
+// Filtering by a subscription that does not exist is reported as not found rather than an empty list
+func (s *webhookService) ListWebhookDeliveries(ctx context.Context, filter model.WebhookDeliveryFilter) ([]*model.WebhookDelivery, error) {
+	if filter.SubscriptionID != 0 {
+		if _, err := s.getWebhook(ctx, filter.SubscriptionID); err != nil {
+			return nil, err
+		}
+	}
+
+	deliveries, err := s.repo.ListWebhookDeliveries(ctx, filter)
+	if err != nil {
+		return nil, apperror.InternalError("Failed to retrieve webhook deliveries", err)
+	}
+
+	// return an empty list rather than null
+	if deliveries == nil {
+		deliveries = []*model.WebhookDelivery{}
+	}
+
+	return deliveries, nil
+}
+
+// RetryWebhookDelivery moves a dead-lettered delivery back to pending, it is attempted on the next dispatch
+func (s *webhookService) RetryWebhookDelivery(ctx context.Context, id int64) (*model.WebhookDelivery, error) {
+	delivery, err := s.repo.RetryWebhookDelivery(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, apperror.NotFoundError("Webhook delivery not found", err)
+		case errors.Is(err, repository.ErrDeliveryNotDead):
+			return nil, apperror.ConflictError("Only dead-lettered deliveries can be retried", err)
+		}
+		return nil, apperror.InternalError("Failed to retry webhook delivery", err)
+	}
+
+	return delivery, nil
+}
+
+// getWebhook retrieves a subscription including its secret
+func (s *webhookService) getWebhook(ctx context.Context, id int) (*model.WebhookSubscription, error) {
+	sub, err := s.repo.GetWebhook(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperror.NotFoundError("Webhook not found", err)
+		}
+		return nil, apperror.InternalError("Failed to retrieve webhook", err)
+	}
+
+	return sub, nil
+}
+
+// generateWebhookSecret returns a random secret with 256 bits of entropy
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + hex.EncodeToString(b), nil
+}
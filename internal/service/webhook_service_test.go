@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	t.Run("a secret is generated when none is given", func(t *testing.T) {
+		mockRepo := mocks.MockWebhookRepository{}
+		service := webhookService{repo: &mockRepo}
+
+		generated := mock.MatchedBy(func(sub *model.WebhookSubscription) bool {
+			return sub.Active && sub.CreatedBy == "alice" &&
+				strings.HasPrefix(sub.Secret, webhookSecretPrefix) && len(sub.Secret) == len(webhookSecretPrefix)+64
+		})
+		created := &model.WebhookSubscription{ID: 1, URL: "https://example.com/hook", Active: true, Secret: "whsec_abc"}
+		mockRepo.On("CreateWebhook", mock.Anything, generated).Return(created, nil)
+
+		res, err := service.CreateWebhook(context.Background(), &model.CreateWebhookRequest{URL: "https://example.com/hook", CreatedBy: "alice"})
+		require.NoError(t, err)
+		// the secret is returned once, on creation
+		assert.Equal(t, "whsec_abc", res.Secret)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("a given secret is kept", func(t *testing.T) {
+		mockRepo := mocks.MockWebhookRepository{}
+		service := webhookService{repo: &mockRepo}
+
+		given := mock.MatchedBy(func(sub *model.WebhookSubscription) bool {
+			return sub.Secret == "my-shared-secret-123"
+		})
+		mockRepo.On("CreateWebhook", mock.Anything, given).Return(&model.WebhookSubscription{ID: 1}, nil)
+
+		_, err := service.CreateWebhook(context.Background(), &model.CreateWebhookRequest{URL: "https://example.com/hook", Secret: "my-shared-secret-123"})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetAndListWebhooks_HideSecrets(t *testing.T) {
+	mockRepo := mocks.MockWebhookRepository{}
+	service := webhookService{repo: &mockRepo}
+
+	mockRepo.On("GetWebhook", mock.Anything, 1).Return(&model.WebhookSubscription{ID: 1, Secret: "whsec_abc"}, nil)
+	mockRepo.On("GetWebhook", mock.Anything, 2).Return(nil, repository.ErrNotFound)
+	mockRepo.On("ListWebhooks", mock.Anything).Return([]*model.WebhookSubscription{{ID: 1, Secret: "whsec_abc"}}, nil)
+
+	sub, err := service.GetWebhook(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Empty(t, sub.Secret)
+
+	subs, err := service.ListWebhooks(context.Background())
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Empty(t, subs[0].Secret)
+
+	_, err = service.GetWebhook(context.Background(), 2)
+	appErr, ok := apperror.AsAppError(err)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrCodeNotFound, appErr.Code)
+}
+
+func TestUpdateWebhook(t *testing.T) {
+	existing := func() *model.WebhookSubscription {
+		return &model.WebhookSubscription{ID: 1, URL: "https://example.com/hook", Description: "erp", Active: true, Secret: "whsec_old"}
+	}
+
+	t.Run("omitted fields are kept", func(t *testing.T) {
+		mockRepo := mocks.MockWebhookRepository{}
+		service := webhookService{repo: &mockRepo}
+
+		active := false
+		paused := mock.MatchedBy(func(sub *model.WebhookSubscription) bool {
+			return !sub.Active && sub.URL == "https://example.com/hook" && sub.Description == "erp" && sub.Secret == "whsec_old"
+		})
+		mockRepo.On("GetWebhook", mock.Anything, 1).Return(existing(), nil)
+		mockRepo.On("UpdateWebhook", mock.Anything, paused).Return(&model.WebhookSubscription{ID: 1, Secret: "whsec_old"}, nil)
+
+		res, err := service.UpdateWebhook(context.Background(), 1, &model.UpdateWebhookRequest{Active: &active})
+		require.NoError(t, err)
+		assert.Empty(t, res.Secret)
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("rotated secret is returned", func(t *testing.T) {
+		mockRepo := mocks.MockWebhookRepository{}
+		service := webhookService{repo: &mockRepo}
+
+		rotated := mock.MatchedBy(func(sub *model.WebhookSubscription) bool {
+			return sub.Secret != "whsec_old" && strings.HasPrefix(sub.Secret, webhookSecretPrefix)
+		})
+		mockRepo.On("GetWebhook", mock.Anything, 1).Return(existing(), nil)
+		mockRepo.On("UpdateWebhook", mock.Anything, rotated).Return(&model.WebhookSubscription{ID: 1, Secret: "whsec_new"}, nil)
+
+		res, err := service.UpdateWebhook(context.Background(), 1, &model.UpdateWebhookRequest{RotateSecret: true})
+		require.NoError(t, err)
+		assert.Equal(t, "whsec_new", res.Secret)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	mockRepo := mocks.MockWebhookRepository{}
+	service := webhookService{repo: &mockRepo}
+
+	mockRepo.On("DeleteWebhook", mock.Anything, 1).Return(nil)
+	mockRepo.On("DeleteWebhook", mock.Anything, 2).Return(repository.ErrNotFound)
+
+	assert.NoError(t, service.DeleteWebhook(context.Background(), 1))
+
+	appErr, ok := apperror.AsAppError(service.DeleteWebhook(context.Background(), 2))
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrCodeNotFound, appErr.Code)
+}
+
+func TestListWebhookDeliveries(t *testing.T) {
+	t.Run("unknown subscription", func(t *testing.T) {
+		mockRepo := mocks.MockWebhookRepository{}
+		service := webhookService{repo: &mockRepo}
+
+		mockRepo.On("GetWebhook", mock.Anything, 9).Return(nil, repository.ErrNotFound)
+
+		_, err := service.ListWebhookDeliveries(context.Background(), model.WebhookDeliveryFilter{SubscriptionID: 9})
+		appErr, ok := apperror.AsAppError(err)
+		require.True(t, ok)
+		assert.Equal(t, apperror.ErrCodeNotFound, appErr.Code)
+		mockRepo.AssertNotCalled(t, "ListWebhookDeliveries", mock.Anything, mock.Anything)
+	})
+	t.Run("dead letters across subscriptions return an empty list", func(t *testing.T) {
+		mockRepo := mocks.MockWebhookRepository{}
+		service := webhookService{repo: &mockRepo}
+
+		filter := model.WebhookDeliveryFilter{Status: model.WebhookDeliveryDead}
+		mockRepo.On("ListWebhookDeliveries", mock.Anything, filter).Return(nil, nil)
+
+		res, err := service.ListWebhookDeliveries(context.Background(), filter)
+		require.NoError(t, err)
+		assert.NotNil(t, res)
+		assert.Empty(t, res)
+	})
+}
+
+func TestRetryWebhookDelivery(t *testing.T) {
+	tests := []struct {
+		name         string
+		repoErr      error
+		expectedCode apperror.ErrorCode
+	}{
+		{name: "dead delivery", repoErr: nil},
+		{name: "unknown delivery", repoErr: repository.ErrNotFound, expectedCode: apperror.ErrCodeNotFound},
+		{name: "delivery is not dead", repoErr: repository.ErrDeliveryNotDead, expectedCode: apperror.ErrCodeConflict},
+		{name: "repository failure", repoErr: assert.AnError, expectedCode: apperror.ErrCodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.MockWebhookRepository{}
+			service := webhookService{repo: &mockRepo}
+
+			if tt.repoErr != nil {
+				mockRepo.On("RetryWebhookDelivery", mock.Anything, int64(5)).Return(nil, tt.repoErr)
+			} else {
+				mockRepo.On("RetryWebhookDelivery", mock.Anything, int64(5)).
+					Return(&model.WebhookDelivery{ID: 5, Status: model.WebhookDeliveryPending}, nil)
+			}
+
+			res, err := service.RetryWebhookDelivery(context.Background(), 5)
+			if tt.expectedCode == "" {
+				require.NoError(t, err)
+				assert.Equal(t, model.WebhookDeliveryPending, res.Status)
+				return
+			}
+			appErr, ok := apperror.AsAppError(err)
+			require.True(t, ok)
+			assert.Equal(t, tt.expectedCode, appErr.Code)
+		})
+	}
+}
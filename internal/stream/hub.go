@@ -0,0 +1,77 @@
+package stream
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/nsaltun/packman/internal/app"
+)
+
+// Hub fans out change signals to the open streams of this instance
+// Signals carry no data and coalesce, a subscriber that is behind sees a single pending signal
+// and reads the current state itself, so a slow client never blocks the others
+type Hub struct {
+	app.AbstractComponent
+
+	mu          sync.Mutex
+	subscribers map[chan struct{}]struct{}
+	closed      chan struct{}
+	closeOnce   sync.Once
+}
+
+// NewHub creates an empty hub
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[chan struct{}]struct{}),
+		closed:      make(chan struct{}),
+	}
+}
+
+// Subscribe returns a channel that receives a signal after every Notify and a function to unsubscribe
+func (h *Hub) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subscribers, ch)
+		h.mu.Unlock()
+	}
+}
+
+// Notify signals every subscriber that the state changed
+func (h *Hub) Notify() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Subscribers returns the number of open subscriptions
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers)
+}
+
+// Done is closed when the hub is closed, streams must end then
+func (h *Hub) Done() <-chan struct{} {
+	return h.closed
+}
+
+// Close ends all streams, it has to run before the HTTP server shuts down because
+// the server waits for open streams to finish
+func (h *Hub) Close(ctx context.Context) error {
+	slog.InfoContext(ctx, "closing change streams", slog.Int("subscribers", h.Subscribers()))
+	h.closeOnce.Do(func() { close(h.closed) })
+	return nil
+}
@@ -0,0 +1,49 @@
+package stream
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub_NotifyCoalescesSignals(t *testing.T) {
+	hub := NewHub()
+	changes, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+
+	// a subscriber that does not keep up sees one pending signal and does not block Notify
+	hub.Notify()
+	hub.Notify()
+	hub.Notify()
+
+	assert.Len(t, changes, 1)
+	<-changes
+	assert.Empty(t, changes)
+}
+
+func TestHub_Unsubscribe(t *testing.T) {
+	hub := NewHub()
+	changes, unsubscribe := hub.Subscribe()
+	assert.Equal(t, 1, hub.Subscribers())
+
+	unsubscribe()
+	hub.Notify()
+
+	assert.Zero(t, hub.Subscribers())
+	assert.Empty(t, changes)
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := NewHub()
+
+	assert.NoError(t, hub.Close(context.Background()))
+	// closing twice is harmless
+	assert.NoError(t, hub.Close(context.Background()))
+
+	select {
+	case <-hub.Done():
+	default:
+		t.Fatal("Done must be closed after Close")
+	}
+}
@@ -0,0 +1,133 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/nsaltun/packman/internal/service"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the spans of the service layer
+const tracerName = "github.com/nsaltun/packman/internal/tracing"
+
+// tracedPackService records a span for every method of a PackService
+type tracedPackService struct {
+	next   service.PackService
+	tracer trace.Tracer
+}
+
+// NewPackService wraps packService so each of its methods records a span, e.g. PackService.CalculatePacks
+// The spans are children of the request span and parents of the query spans of the repositories
+func NewPackService(packService service.PackService) service.PackService {
+	return &tracedPackService{next: packService, tracer: otel.Tracer(tracerName)}
+}
+
+// traced runs call in a span named PackService.method with attrs
+func traced[T any](ctx context.Context, s *tracedPackService, method string, call func(ctx context.Context) (T, error), attrs ...attribute.KeyValue) (T, error) {
+	ctx, span := s.tracer.Start(ctx, "PackService."+method, trace.WithAttributes(attrs...))
+	defer span.End()
+
+	res, err := call(ctx)
+	if err != nil {
+		RecordError(span, err)
+	}
+	return res, err
+}
+
+// CalculatePacks traces the calculation with the active configuration
+func (s *tracedPackService) CalculatePacks(ctx context.Context, quantity int) (*model.PackCalculationResponse, error) {
+	return traced(ctx, s, "CalculatePacks", func(ctx context.Context) (*model.PackCalculationResponse, error) {
+		return s.next.CalculatePacks(ctx, quantity)
+	}, attribute.Int("packman.quantity", quantity))
+}
+
+// CalculatePacksAsOf traces the calculation with a historical configuration
+func (s *tracedPackService) CalculatePacksAsOf(ctx context.Context, quantity int, version int, at time.Time) (*model.PackCalculationResponse, error) {
+	attrs := []attribute.KeyValue{attribute.Int("packman.quantity", quantity)}
+	if version > 0 {
+		attrs = append(attrs, attribute.Int("packman.config.version", version))
+	}
+	if !at.IsZero() {
+		attrs = append(attrs, attribute.String("packman.config.as_of", at.Format(time.RFC3339)))
+	}
+	return traced(ctx, s, "CalculatePacksAsOf", func(ctx context.Context) (*model.PackCalculationResponse, error) {
+		return s.next.CalculatePacksAsOf(ctx, quantity, version, at)
+	}, attrs...)
+}
+
+// GetPackSizes traces reading the active configuration
+func (s *tracedPackService) GetPackSizes(ctx context.Context) (*model.GetPackSizesResponse, error) {
+	return traced(ctx, s, "GetPackSizes", s.next.GetPackSizes)
+}
+
+// UpdatePackSizes traces replacing the pack sizes
+func (s *tracedPackService) UpdatePackSizes(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.UpdatePackSizesResponse, error) {
+	return traced(ctx, s, "UpdatePackSizes", func(ctx context.Context) (*model.UpdatePackSizesResponse, error) {
+		return s.next.UpdatePackSizes(ctx, sizes, updatedBy, reason)
+	}, attribute.IntSlice("packman.pack_sizes", sizes))
+}
+
+// PatchPackSizes traces adding and removing pack sizes
+func (s *tracedPackService) PatchPackSizes(ctx context.Context, add, remove []int, updatedBy string, reason string) (*model.UpdatePackSizesResponse, error) {
+	return traced(ctx, s, "PatchPackSizes", func(ctx context.Context) (*model.UpdatePackSizesResponse, error) {
+		return s.next.PatchPackSizes(ctx, add, remove, updatedBy, reason)
+	}, attribute.IntSlice("packman.pack_sizes.add", add), attribute.IntSlice("packman.pack_sizes.remove", remove))
+}
+
+// ExportConfiguration traces exporting the configuration and its history
+func (s *tracedPackService) ExportConfiguration(ctx context.Context) (*model.PackConfigurationExport, error) {
+	return traced(ctx, s, "ExportConfiguration", s.next.ExportConfiguration)
+}
+
+// ImportPackSizes traces importing pack sizes
+func (s *tracedPackService) ImportPackSizes(ctx context.Context, sizes []int, updatedBy string, reason string, dryRun bool) (*model.ImportPackSizesResponse, error) {
+	return traced(ctx, s, "ImportPackSizes", func(ctx context.Context) (*model.ImportPackSizesResponse, error) {
+		return s.next.ImportPackSizes(ctx, sizes, updatedBy, reason, dryRun)
+	}, attribute.IntSlice("packman.pack_sizes", sizes), attribute.Bool("packman.dry_run", dryRun))
+}
+
+// CreateDraft traces proposing pack sizes for review
+func (s *tracedPackService) CreateDraft(ctx context.Context, sizes []int, updatedBy string, reason string) (*model.PackConfigurationDraft, error) {
+	return traced(ctx, s, "CreateDraft", func(ctx context.Context) (*model.PackConfigurationDraft, error) {
+		return s.next.CreateDraft(ctx, sizes, updatedBy, reason)
+	}, attribute.IntSlice("packman.pack_sizes", sizes))
+}
+
+// GetDraft traces reading a draft
+func (s *tracedPackService) GetDraft(ctx context.Context, id int) (*model.PackConfigurationDraft, error) {
+	return traced(ctx, s, "GetDraft", func(ctx context.Context) (*model.PackConfigurationDraft, error) {
+		return s.next.GetDraft(ctx, id)
+	}, attribute.Int("packman.draft.id", id))
+}
+
+// ListDrafts traces listing drafts
+func (s *tracedPackService) ListDrafts(ctx context.Context, status model.DraftStatus, limit int) ([]*model.PackConfigurationDraft, error) {
+	return traced(ctx, s, "ListDrafts", func(ctx context.Context) ([]*model.PackConfigurationDraft, error) {
+		return s.next.ListDrafts(ctx, status, limit)
+	}, attribute.String("packman.draft.status", string(status)), attribute.Int("packman.limit", limit))
+}
+
+// ApproveDraft traces applying a draft
+func (s *tracedPackService) ApproveDraft(ctx context.Context, id int, reviewedBy string) (*model.UpdatePackSizesResponse, error) {
+	return traced(ctx, s, "ApproveDraft", func(ctx context.Context) (*model.UpdatePackSizesResponse, error) {
+		return s.next.ApproveDraft(ctx, id, reviewedBy)
+	}, attribute.Int("packman.draft.id", id))
+}
+
+// RejectDraft traces rejecting a draft
+func (s *tracedPackService) RejectDraft(ctx context.Context, id int, reviewedBy string, comment string) (*model.PackConfigurationDraft, error) {
+	return traced(ctx, s, "RejectDraft", func(ctx context.Context) (*model.PackConfigurationDraft, error) {
+		return s.next.RejectDraft(ctx, id, reviewedBy, comment)
+	}, attribute.Int("packman.draft.id", id))
+}
+
+// ListAuditEntries traces reading the audit log
+func (s *tracedPackService) ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	return traced(ctx, s, "ListAuditEntries", func(ctx context.Context) ([]*model.AuditEntry, error) {
+		return s.next.ListAuditEntries(ctx, filter)
+	})
+}
@@ -0,0 +1,39 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nsaltun/packman/internal/mocks"
+	"github.com/nsaltun/packman/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewPackService(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	packService := new(mocks.MockPackService)
+	packService.On("GetDraft", mock.Anything, 7).Return(&model.PackConfigurationDraft{ID: 7}, nil)
+
+	traced := &tracedPackService{
+		next:   packService,
+		tracer: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer(tracerName),
+	}
+	draft, err := traced.GetDraft(context.Background(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, 7, draft.ID)
+
+	ended := recorder.Ended()
+	require.Len(t, ended, 1)
+	assert.Equal(t, "PackService.GetDraft", ended[0].Name())
+	assert.Contains(t, ended[0].Attributes(), attribute.Int("packman.draft.id", 7))
+
+	// the service is called with the context of the span
+	ctx := packService.Calls[0].Arguments.Get(0).(context.Context)
+	assert.Equal(t, ended[0].SpanContext().SpanID(), trace.SpanContextFromContext(ctx).SpanID())
+}
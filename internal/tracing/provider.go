@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/nsaltun/packman/config"
+	"github.com/nsaltun/packman/internal/app"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+)
+
+// Provider exports the spans of the service, closing it flushes the spans that are still buffered
+type Provider struct {
+	app.AbstractComponent
+	provider *sdktrace.TracerProvider
+}
+
+// NewProvider creates the tracer provider of cfg and installs it as the global provider, together with the
+// W3C trace context and baggage propagators. The stdout exporter writes one JSON span per line to w.
+// The OTLP exporter connects lazily, an unreachable collector does not fail startup
+func NewProvider(cfg config.TracingConfig, w io.Writer) (*Provider, error) {
+	var exporterOption sdktrace.TracerProviderOption
+	switch cfg.Exporter {
+	case config.TracingExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout span exporter: %w", err)
+		}
+		// written right away, so spans show up next to the logs of their request
+		exporterOption = sdktrace.WithSyncer(exporter)
+	default:
+		options := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			options = append(options, otlptracegrpc.WithInsecure())
+		}
+		exporter, err := otlptracegrpc.New(context.Background(), options...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP span exporter: %w", err)
+		}
+		exporterOption = sdktrace.WithBatcher(exporter)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		exporterOption,
+		sdktrace.WithResource(res),
+		// callers that sampled a trace out are followed, so traces are recorded either entirely or not at all
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	slog.Info("tracing enabled",
+		slog.String("exporter", cfg.Exporter),
+		slog.Float64("sample_ratio", cfg.SampleRatio),
+	)
+	return &Provider{provider: provider}, nil
+}
+
+// Close exports the buffered spans and stops the exporter
+func (p *Provider) Close(ctx context.Context) error {
+	if err := p.provider.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to shut down tracing: %w", err)
+	}
+	slog.Info("tracing stopped")
+	return nil
+}
@@ -0,0 +1,32 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// otherErrorType is the error.type of errors that are not AppErrors
+const otherErrorType = "_OTHER"
+
+// RecordError records err on span with its error code as error.type
+// Only server errors mark the span as failed, client errors such as an invalid quantity or an unknown draft
+// are expected outcomes of a call
+func RecordError(span trace.Span, err error) {
+	appErr, ok := apperror.AsAppError(err)
+	if !ok {
+		span.SetAttributes(semconv.ErrorTypeKey.String(otherErrorType))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+
+	span.SetAttributes(semconv.ErrorTypeKey.String(string(appErr.Code)))
+	if appErr.StatusCode >= http.StatusInternalServerError {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, appErr.Message)
+	}
+}
@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nsaltun/packman/internal/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// errorType returns the error.type attribute of span
+func errorType(span sdktrace.ReadOnlySpan) string {
+	for _, attr := range span.Attributes() {
+		if attr.Key == "error.type" {
+			return attr.Value.AsString()
+		}
+	}
+	return ""
+}
+
+func TestRecordError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		errorType string
+		status    codes.Code
+	}{
+		{"client errors are expected outcomes", apperror.NotFoundError("Draft not found", nil), "NOT_FOUND", codes.Unset},
+		{"server errors fail the span", apperror.InternalError("", errors.New("disk full")), "INTERNAL_ERROR", codes.Error},
+		{"unknown errors fail the span", errors.New("connection refused"), "_OTHER", codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			_, span := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test").Start(context.Background(), "call")
+			RecordError(span, tt.err)
+			span.End()
+
+			ended := recorder.Ended()
+			require.Len(t, ended, 1)
+			assert.Equal(t, tt.errorType, errorType(ended[0]))
+			assert.Equal(t, tt.status, ended[0].Status().Code)
+		})
+	}
+}
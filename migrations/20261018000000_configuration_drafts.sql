@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Reviewer of the change that produced a configuration version
+ALTER TABLE pack_configuration ADD COLUMN approved_by VARCHAR(255);
+ALTER TABLE pack_configuration_history ADD COLUMN approved_by VARCHAR(255);
+
+-- Proposed configuration changes waiting for a second person's review
+CREATE TABLE pack_configuration_drafts (
+    id SERIAL PRIMARY KEY,
+    pack_sizes JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    base_version INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by VARCHAR(255) NOT NULL,
+    reviewed_at TIMESTAMP,
+    reviewed_by VARCHAR(255),
+    review_comment TEXT,
+    applied_version INTEGER
+);
+
+CREATE INDEX idx_pack_configuration_drafts_status ON pack_configuration_drafts (status, created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pack_configuration_drafts;
+ALTER TABLE pack_configuration_history DROP COLUMN IF EXISTS approved_by;
+ALTER TABLE pack_configuration DROP COLUMN IF EXISTS approved_by;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Time an archived configuration became active, used to resolve the configuration in effect at a point in time
+ALTER TABLE pack_configuration_history ADD COLUMN valid_from TIMESTAMP;
+
+CREATE INDEX idx_pack_configuration_history_version ON pack_configuration_history (version);
+CREATE INDEX idx_pack_configuration_history_created_at ON pack_configuration_history (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_pack_configuration_history_created_at;
+DROP INDEX IF EXISTS idx_pack_configuration_history_version;
+ALTER TABLE pack_configuration_history DROP COLUMN IF EXISTS valid_from;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Reason given by the author of a draft, recorded in the audit log when the draft is approved
+ALTER TABLE pack_configuration_drafts ADD COLUMN reason TEXT NOT NULL DEFAULT '';
+
+-- One row per configuration change with the old and new state and who made it, from where and why
+CREATE TABLE pack_configuration_audit (
+    id SERIAL PRIMARY KEY,
+    version INTEGER NOT NULL,
+    previous_version INTEGER NOT NULL,
+    pack_sizes JSONB NOT NULL,
+    previous_pack_sizes JSONB NOT NULL,
+    reason TEXT NOT NULL,
+    updated_by VARCHAR(255),
+    approved_by VARCHAR(255),
+    draft_id INTEGER REFERENCES pack_configuration_drafts (id),
+    identity VARCHAR(255),
+    request_id VARCHAR(255),
+    client_ip VARCHAR(64),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_pack_configuration_audit_created_at ON pack_configuration_audit (created_at DESC);
+CREATE INDEX idx_pack_configuration_audit_request_id ON pack_configuration_audit (request_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pack_configuration_audit;
+ALTER TABLE pack_configuration_drafts DROP COLUMN IF EXISTS reason;
+-- +goose StatementEnd
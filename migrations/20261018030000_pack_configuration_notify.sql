@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Notifies listeners with the new version whenever the active configuration changes,
+-- so every instance can drop its cached pack sizes
+CREATE OR REPLACE FUNCTION notify_pack_configuration_changed() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('pack_configuration_changed', NEW.version::TEXT);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER pack_configuration_changed
+    AFTER INSERT OR UPDATE ON pack_configuration
+    FOR EACH ROW EXECUTE FUNCTION notify_pack_configuration_changed();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS pack_configuration_changed ON pack_configuration;
+DROP FUNCTION IF EXISTS notify_pack_configuration_changed();
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Change events written in the same transaction as the change, relayed to downstream systems afterwards
+CREATE TABLE pack_configuration_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    delivered_sinks TEXT[] NOT NULL DEFAULT '{}',
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP
+);
+
+CREATE INDEX idx_pack_configuration_outbox_pending ON pack_configuration_outbox (next_attempt_at) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pack_configuration_outbox;
+-- +goose StatementEnd
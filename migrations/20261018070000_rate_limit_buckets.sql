@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Token buckets of the rate limiter shared by all instances. Losing them on a crash only resets the limits,
+-- so the table is not written to the WAL
+CREATE UNLOGGED TABLE rate_limit_buckets (
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    -- whether the last request took a token, returned to the limiter together with what is left
+    allowed BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_rate_limit_buckets_updated_at ON rate_limit_buckets (updated_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS rate_limit_buckets;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Requests made with an Idempotency-Key and their responses, replayed when a client retries the request
+CREATE TABLE idempotency_keys (
+    -- the caller and the key it sent, keys of different callers do not collide
+    key TEXT PRIMARY KEY,
+    -- SHA-256 of the method, path and body, a retry with another request is rejected
+    fingerprint TEXT NOT NULL,
+    -- the stored response, NULL while the request is in progress
+    status_code INTEGER,
+    content_type TEXT,
+    body BYTEA,
+    -- an unfinished request is presumed lost after this, e.g. when its instance crashed
+    locked_until TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS idempotency_keys;
+-- +goose StatementEnd
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -13,12 +15,23 @@ import (
 )
 
 // Client wraps pgxpool with observability and best practices
+// When a replica is configured, read-only queries can be routed to it with Read
 type Client struct {
 	app.AbstractComponent
 	Pool *pgxpool.Pool
+	// Replica serves read-only queries, nil when no replica is configured
+	Replica *pgxpool.Pool
+
+	readYourWrites       time.Duration
+	replicaCheckPeriod   time.Duration
+	replicaHealthy       atomic.Bool
+	lastWrite            atomic.Int64
+	stopReplicaCheck     chan struct{}
+	stopReplicaCheckOnce sync.Once
 }
 
 // NewClient creates a production-ready pgx connection pool with observability
+// An unreachable replica does not fail startup, reads use the primary until the replica recovers
 func NewClient(cfg config.DatabaseConfig) (*Client, error) {
 	start := time.Now()
 
@@ -26,8 +39,55 @@ func NewClient(cfg config.DatabaseConfig) (*Client, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	pool, err := newPool(ctx, cfg.URL, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify connectivity
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	slog.Info("PostgreSQL connection pool established",
+		slog.Duration("duration", time.Since(start)),
+		slog.Int("max_conns", cfg.MaxOpenConns),
+		slog.Int("min_conns", cfg.MaxIdleConns),
+		slog.Duration("max_lifetime", cfg.ConnMaxLifetime),
+	)
+
+	client := &Client{
+		Pool:               pool,
+		readYourWrites:     cfg.ReadYourWritesWindow,
+		replicaCheckPeriod: cfg.ReplicaHealthCheckPeriod,
+		stopReplicaCheck:   make(chan struct{}),
+	}
+
+	if cfg.ReplicaURL != "" {
+		replica, err := newPool(ctx, cfg.ReplicaURL, cfg)
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("replica: %w", err)
+		}
+		client.Replica = replica
+
+		if err := replica.Ping(ctx); err != nil {
+			slog.Warn("read replica is unreachable, reading from primary",
+				slog.String("error", err.Error()))
+		} else {
+			client.replicaHealthy.Store(true)
+			slog.Info("PostgreSQL read replica connection pool established")
+		}
+	}
+
+	return client, nil
+}
+
+// newPool creates a connection pool for the given URL with the pool settings of cfg
+func newPool(ctx context.Context, url string, cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
 	// Build pgx pool config
-	poolCfg, err := pgxpool.ParseConfig(cfg.URL)
+	poolCfg, err := pgxpool.ParseConfig(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse database URL: %w", err)
 	}
@@ -39,6 +99,9 @@ func NewClient(cfg config.DatabaseConfig) (*Client, error) {
 	poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
 	poolCfg.HealthCheckPeriod = cfg.HealthCheckPeriod
 
+	// Trace the queries of traced requests
+	poolCfg.ConnConfig.Tracer = newQueryTracer()
+
 	// Add connection lifecycle hooks for observability
 	poolCfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
 		slog.Debug("new database connection established",
@@ -52,25 +115,41 @@ func NewClient(cfg config.DatabaseConfig) (*Client, error) {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
 	}
 
-	// Verify connectivity
-	if err := pool.Ping(ctx); err != nil {
-		pool.Close()
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+	return pool, nil
+}
+
+// Run periodically checks the replica so reads return to it once it has recovered
+func (c *Client) Run() error {
+	if c.Replica == nil || c.replicaCheckPeriod <= 0 {
+		return nil
 	}
 
-	slog.Info("PostgreSQL connection pool established",
-		slog.Duration("duration", time.Since(start)),
-		slog.Int("max_conns", cfg.MaxOpenConns),
-		slog.Int("min_conns", cfg.MaxIdleConns),
-		slog.Duration("max_lifetime", cfg.ConnMaxLifetime),
-	)
+	ticker := time.NewTicker(c.replicaCheckPeriod)
+	defer ticker.Stop()
 
-	return &Client{Pool: pool}, nil
+	for {
+		select {
+		case <-c.stopReplicaCheck:
+			return nil
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			c.checkReplica(ctx)
+			cancel()
+		}
+	}
 }
 
 // Close gracefully shuts down the connection pool
 func (c *Client) Close(ctx context.Context) error {
 	slog.InfoContext(ctx, "closing database connection pool")
+	if c.Replica != nil {
+		c.stopReplicaCheckOnce.Do(func() {
+			if c.stopReplicaCheck != nil {
+				close(c.stopReplicaCheck)
+			}
+		})
+		c.Replica.Close()
+	}
 	c.Pool.Close()
 	return nil
 }
@@ -12,18 +12,38 @@ type HealthStatus struct {
 	ResponseTime time.Duration `json:"response_time_ms"`
 	Error        string        `json:"error,omitempty"`
 	PoolStats    *pgxpool.Stat `json:"pool_stats,omitempty"`
+	// Replica is the status of the read replica, nil when no replica is configured
+	// An unhealthy replica does not make the database unhealthy since reads fall back to the primary
+	Replica *HealthStatus `json:"replica,omitempty"`
+	// ReadsFrom is "replica" or "primary"
+	ReadsFrom string `json:"reads_from,omitempty"`
 }
 
 // CheckHealth performs a health check on the PostgreSQL database
 func (c *Client) CheckHealth(ctx context.Context) HealthStatus {
-	start := time.Now()
-
 	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
 	// ping the database to check connectivity
-	err := c.Pool.Ping(ctx)
+	status := checkPool(ctx, c.Pool, c.Pool.Ping)
+	if c.Replica == nil {
+		return status
+	}
 
+	// the replica check also updates the routing, so a recovered replica is used right away
+	replicaStatus := checkPool(ctx, c.Replica, c.checkReplica)
+	status.Replica = &replicaStatus
+	status.ReadsFrom = "primary"
+	if c.ReadsFromReplica() {
+		status.ReadsFrom = "replica"
+	}
+	return status
+}
+
+// checkPool runs ping and reports the result together with the statistics of pool
+func checkPool(ctx context.Context, pool *pgxpool.Pool, ping func(ctx context.Context) error) HealthStatus {
+	start := time.Now()
+	err := ping(ctx)
 	elapsed := time.Since(start)
 
 	if err != nil {
@@ -31,13 +51,13 @@ func (c *Client) CheckHealth(ctx context.Context) HealthStatus {
 			Status:       "unhealthy",
 			ResponseTime: elapsed,
 			Error:        err.Error(),
-			PoolStats:    c.Pool.Stat(),
+			PoolStats:    pool.Stat(),
 		}
 	}
 
 	return HealthStatus{
 		Status:       "healthy",
 		ResponseTime: elapsed,
-		PoolStats:    c.Pool.Stat(),
+		PoolStats:    pool.Stat(),
 	}
 }
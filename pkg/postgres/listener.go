@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nsaltun/packman/internal/app"
+)
+
+// listenerReconnectDelay is the pause before the listener reconnects after losing its connection
+const listenerReconnectDelay = time.Second
+
+// NotificationHandler handles the payload of a notification received on a channel
+type NotificationHandler func(ctx context.Context, payload string)
+
+// Listener holds a dedicated connection that LISTENs on channels and dispatches notifications to handlers
+// Notifications sent while the connection is down are lost, so subscribers should resynchronize in OnConnect
+type Listener struct {
+	app.AbstractComponent
+	pool *pgxpool.Pool
+
+	mu        sync.Mutex
+	handlers  map[string][]NotificationHandler
+	onConnect []func(ctx context.Context)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewListener creates a listener that takes its connection from the pool
+func NewListener(pool *pgxpool.Pool) *Listener {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Listener{
+		pool:     pool,
+		handlers: make(map[string][]NotificationHandler),
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Subscribe registers a handler for notifications on the channel, must be called before Run
+func (l *Listener) Subscribe(channel string, handler NotificationHandler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.handlers[channel] = append(l.handlers[channel], handler)
+}
+
+// OnConnect registers a callback invoked every time the listener (re)connects and LISTENs on all channels
+func (l *Listener) OnConnect(fn func(ctx context.Context)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onConnect = append(l.onConnect, fn)
+}
+
+// Run listens for notifications until Close, reconnecting when the connection drops
+func (l *Listener) Run() error {
+	defer close(l.done)
+
+	for {
+		err := l.listen(l.ctx)
+		if l.ctx.Err() != nil {
+			return nil
+		}
+		slog.Warn("notification listener disconnected, reconnecting",
+			slog.Any("error", err),
+			slog.Duration("delay", listenerReconnectDelay))
+
+		select {
+		case <-l.ctx.Done():
+			return nil
+		case <-time.After(listenerReconnectDelay):
+		}
+	}
+}
+
+// listen runs a single connection until it fails or the context is cancelled
+func (l *Listener) listen(ctx context.Context) error {
+	pooled, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	// the connection keeps its LISTEN registrations, so it must not go back to the pool
+	conn := pooled.Hijack()
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = conn.Close(closeCtx)
+	}()
+
+	l.mu.Lock()
+	channels := make([]string, 0, len(l.handlers))
+	for channel := range l.handlers {
+		channels = append(channels, channel)
+	}
+	onConnect := append([]func(context.Context){}, l.onConnect...)
+	l.mu.Unlock()
+
+	for _, channel := range channels {
+		if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", channel, err)
+		}
+	}
+	slog.InfoContext(ctx, "notification listener connected", slog.Any("channels", channels))
+
+	for _, fn := range onConnect {
+		fn(ctx)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		l.mu.Lock()
+		handlers := l.handlers[notification.Channel]
+		l.mu.Unlock()
+		for _, handler := range handlers {
+			handler(ctx, notification.Payload)
+		}
+	}
+}
+
+// Close stops listening and waits for Run to return
+func (l *Listener) Close(ctx context.Context) error {
+	slog.InfoContext(ctx, "closing notification listener")
+	l.cancel()
+
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier runs queries, implemented by *pgxpool.Pool, *pgx.Conn and pgx.Tx
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Read runs the read-only fn on the replica when it is usable, otherwise on the primary
+// A failing fn is run again on the primary, so fn must not have side effects. Only connection and availability
+// errors mark the replica unhealthy, other errors such as a statement canceled by a recovery conflict are retried
+// once without taking the replica out of rotation
+// pgx.ErrNoRows is a regular result and does not trigger the fallback
+func (c *Client) Read(ctx context.Context, fn func(q Querier) error) error {
+	if !c.readFromReplica() {
+		return fn(c.Pool)
+	}
+
+	err := fn(c.Replica)
+	if err == nil || errors.Is(err, pgx.ErrNoRows) || ctx.Err() != nil {
+		return err
+	}
+
+	unavailable := isUnavailable(err)
+	slog.WarnContext(ctx, "read replica query failed, falling back to primary",
+		slog.String("error", err.Error()),
+		slog.Bool("replica_unavailable", unavailable),
+	)
+	if unavailable {
+		c.replicaHealthy.Store(false)
+	}
+	return fn(c.Pool)
+}
+
+// isUnavailable reports whether err means the server cannot be reached or cannot serve queries right now:
+// a failed connection, a connection lost mid-query, or a SQLSTATE of class 08 (connection exception) or
+// 57P0x (the server is shutting down, starting up or unreachable)
+func isUnavailable(err error) bool {
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "57P0")
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// MarkWrite records that the primary has just been written to
+// Reads go to the primary for the read-your-writes window, until the replica has likely replayed the write
+func (c *Client) MarkWrite() {
+	c.lastWrite.Store(time.Now().UnixNano())
+}
+
+// ReadsFromReplica reports whether reads currently go to the replica
+func (c *Client) ReadsFromReplica() bool {
+	return c.readFromReplica()
+}
+
+// readFromReplica reports whether a replica is configured, healthy and outside the read-your-writes window
+func (c *Client) readFromReplica() bool {
+	if c.Replica == nil || !c.replicaHealthy.Load() {
+		return false
+	}
+	return time.Since(time.Unix(0, c.lastWrite.Load())) >= c.readYourWrites
+}
+
+// checkReplica pings the replica and records whether it can serve reads
+func (c *Client) checkReplica(ctx context.Context) error {
+	err := c.Replica.Ping(ctx)
+
+	healthy := err == nil
+	if c.replicaHealthy.Swap(healthy) != healthy {
+		if healthy {
+			slog.InfoContext(ctx, "read replica recovered, routing reads to replica")
+		} else {
+			slog.WarnContext(ctx, "read replica is unhealthy, reading from primary",
+				slog.String("error", err.Error()))
+		}
+	}
+	return err
+}
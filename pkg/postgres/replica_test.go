@@ -0,0 +1,167 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client with a healthy replica, pools connect lazily so no database is needed
+func newTestClient(t *testing.T, readYourWrites time.Duration) *Client {
+	t.Helper()
+	primary, err := pgxpool.New(context.Background(), "postgres://primary.invalid/packman")
+	require.NoError(t, err)
+	replica, err := pgxpool.New(context.Background(), "postgres://replica.invalid/packman")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		primary.Close()
+		replica.Close()
+	})
+
+	client := &Client{Pool: primary, Replica: replica, readYourWrites: readYourWrites}
+	client.replicaHealthy.Store(true)
+	return client
+}
+
+// target names the pool a Querier belongs to
+func target(c *Client, q Querier) string {
+	if q == Querier(c.Replica) {
+		return "replica"
+	}
+	return "primary"
+}
+
+func TestClient_Read(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("without replica reads from primary", func(t *testing.T) {
+		client := newTestClient(t, 0)
+		client.Replica = nil
+
+		var used []string
+		err := client.Read(ctx, func(q Querier) error {
+			used = append(used, target(client, q))
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"primary"}, used)
+		assert.False(t, client.ReadsFromReplica())
+	})
+	t.Run("healthy replica serves reads", func(t *testing.T) {
+		client := newTestClient(t, time.Minute)
+
+		var used []string
+		err := client.Read(ctx, func(q Querier) error {
+			used = append(used, target(client, q))
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"replica"}, used)
+	})
+	t.Run("reads stay on primary right after a write", func(t *testing.T) {
+		client := newTestClient(t, time.Minute)
+		client.MarkWrite()
+
+		var used []string
+		err := client.Read(ctx, func(q Querier) error {
+			used = append(used, target(client, q))
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"primary"}, used)
+
+		// once the window has passed the replica is used again
+		client.lastWrite.Store(time.Now().Add(-time.Minute).UnixNano())
+		assert.True(t, client.ReadsFromReplica())
+	})
+	t.Run("unavailable replica falls back to primary", func(t *testing.T) {
+		client := newTestClient(t, 0)
+
+		var used []string
+		err := client.Read(ctx, func(q Querier) error {
+			used = append(used, target(client, q))
+			if target(client, q) == "replica" {
+				return &pgconn.PgError{Code: "57P03", Message: "the database system is starting up"}
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"replica", "primary"}, used)
+
+		// the replica is skipped until a health check succeeds
+		assert.False(t, client.ReadsFromReplica())
+	})
+	t.Run("query errors fall back without marking the replica unhealthy", func(t *testing.T) {
+		client := newTestClient(t, 0)
+
+		var used []string
+		err := client.Read(ctx, func(q Querier) error {
+			used = append(used, target(client, q))
+			if target(client, q) == "replica" {
+				return &pgconn.PgError{Code: "40001", Message: "canceling statement due to conflict with recovery"}
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"replica", "primary"}, used)
+		assert.True(t, client.ReadsFromReplica())
+	})
+	t.Run("no rows is a result, not a failure", func(t *testing.T) {
+		client := newTestClient(t, 0)
+
+		calls := 0
+		err := client.Read(ctx, func(q Querier) error {
+			calls++
+			return pgx.ErrNoRows
+		})
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+		assert.Equal(t, 1, calls)
+		assert.True(t, client.ReadsFromReplica())
+	})
+}
+
+func TestIsUnavailable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection exception", &pgconn.PgError{Code: "08006"}, true},
+		{"admin shutdown", fmt.Errorf("query: %w", &pgconn.PgError{Code: "57P01"}), true},
+		{"connection lost", io.ErrUnexpectedEOF, true},
+		{"network error", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"recovery conflict", &pgconn.PgError{Code: "40001"}, false},
+		{"undefined table", &pgconn.PgError{Code: "42P01"}, false},
+		{"scan error", errors.New("cannot scan NULL into *int"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isUnavailable(tt.err))
+		})
+	}
+}
+
+func TestClient_CheckHealthReportsReplica(t *testing.T) {
+	client := newTestClient(t, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	status := client.CheckHealth(ctx)
+
+	assert.Equal(t, "unhealthy", status.Status)
+	require.NotNil(t, status.Replica)
+	assert.Equal(t, "unhealthy", status.Replica.Status)
+	assert.Equal(t, "primary", status.ReadsFrom)
+	assert.False(t, client.ReadsFromReplica())
+}
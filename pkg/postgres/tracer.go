@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the query spans
+const tracerName = "github.com/nsaltun/packman/pkg/postgres"
+
+type querySpanKey struct{}
+
+// queryTracer records a span for every query of a traced request, implementing pgx.QueryTracer
+// Queries without a span in their context, e.g. the polling of the outbox relay, are not traced, so
+// background work does not start a trace of its own every few seconds
+type queryTracer struct {
+	tracer trace.Tracer
+}
+
+// newQueryTracer creates a tracer recording with the global tracer provider
+func newQueryTracer() *queryTracer {
+	return &queryTracer{tracer: otel.Tracer(tracerName)}
+}
+
+// TraceQueryStart starts the span of a query, named after its operation, e.g. SELECT
+// The query text is recorded with its placeholders, the arguments are not
+func (t *queryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	operation := queryOperation(data.SQL)
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemNamePostgreSQL,
+		semconv.DBOperationName(operation),
+		semconv.DBQueryText(data.SQL),
+	}
+	if conn != nil {
+		connConfig := conn.Config()
+		attrs = append(attrs,
+			semconv.DBNamespace(connConfig.Database),
+			semconv.ServerAddress(connConfig.Host),
+			semconv.ServerPort(int(connConfig.Port)),
+		)
+	}
+
+	ctx, span := t.tracer.Start(ctx, operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return context.WithValue(ctx, querySpanKey{}, span)
+}
+
+// TraceQueryEnd ends the span of a query, failed queries mark it as failed
+// pgx.ErrNoRows is an expected outcome, e.g. of looking up an unknown draft
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	span, ok := ctx.Value(querySpanKey{}).(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+		return
+	}
+	if data.CommandTag.Select() {
+		span.SetAttributes(semconv.DBResponseReturnedRows(int(data.CommandTag.RowsAffected())))
+	}
+}
+
+// queryOperation returns the first keyword of sql in upper case, e.g. SELECT or WITH
+func queryOperation(sql string) string {
+	operation := strings.TrimSpace(sql)
+	if end := strings.IndexFunc(operation, unicode.IsSpace); end >= 0 {
+		operation = operation[:end]
+	}
+	operation = strings.ToUpper(strings.TrimRight(operation, ";"))
+	if operation == "" {
+		return "QUERY"
+	}
+	return operation
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestQueryTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	queryTracer := &queryTracer{tracer: tracer}
+
+	// runs a query of a request with a span, or of background work without one
+	query := func(traced bool, sql string, tag string, err error) {
+		ctx := context.Background()
+		if traced {
+			var span trace.Span
+			ctx, span = tracer.Start(ctx, "request")
+			defer span.End()
+		}
+		ctx = queryTracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: sql})
+		queryTracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag(tag), Err: err})
+	}
+
+	t.Run("records queries of traced requests", func(t *testing.T) {
+		recorder.Reset()
+		query(true, "\n\t\tSELECT sizes FROM pack_configurations WHERE version = $1", "SELECT 1", nil)
+
+		ended := recorder.Ended()
+		require.Len(t, ended, 2)
+		span := ended[0]
+		assert.Equal(t, "SELECT", span.Name())
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+		assert.Equal(t, ended[1].SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Contains(t, span.Attributes(), attribute.String("db.system.name", "postgresql"))
+		assert.Contains(t, span.Attributes(), attribute.Int("db.response.returned_rows", 1))
+		assert.Equal(t, codes.Unset, span.Status().Code)
+	})
+
+	t.Run("failed queries fail the span", func(t *testing.T) {
+		recorder.Reset()
+		query(true, "INSERT INTO pack_configurations (sizes) VALUES ($1)", "", errors.New("unique violation"))
+		query(true, "SELECT id FROM drafts WHERE id = $1", "", pgx.ErrNoRows)
+
+		ended := recorder.Ended()
+		require.Len(t, ended, 4)
+		assert.Equal(t, "INSERT", ended[0].Name())
+		assert.Equal(t, codes.Error, ended[0].Status().Code)
+		// no rows is an expected outcome
+		assert.Equal(t, codes.Unset, ended[2].Status().Code)
+	})
+
+	t.Run("skips queries without a span", func(t *testing.T) {
+		recorder.Reset()
+		query(false, "SELECT * FROM outbox_events", "SELECT 0", nil)
+		assert.Empty(t, recorder.Ended())
+	})
+}
+
+func TestQueryOperation(t *testing.T) {
+	assert.Equal(t, "WITH", queryOperation("with latest AS (SELECT 1) SELECT * FROM latest"))
+	assert.Equal(t, "BEGIN", queryOperation("begin;"))
+	assert.Equal(t, "DELETE", queryOperation("\n  DELETE\nFROM api_keys"))
+	assert.Equal(t, "QUERY", queryOperation("  "))
+}
@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	// ErrTxConflict indicates a transaction kept failing because of concurrent transactions
+	// (serialization failures or deadlocks) and its retries were exhausted
+	ErrTxConflict = errors.New("transaction conflicts with concurrent transactions")
+	// ErrTxUnavailable indicates the database kept refusing the transaction and its retries were exhausted
+	ErrTxUnavailable = errors.New("database is temporarily unavailable")
+)
+
+// SQLSTATEs worth retrying, the transaction was rolled back by the server and can be run again as is
+var (
+	conflictSQLStates = map[string]bool{
+		"40001": true, // serialization_failure
+		"40P01": true, // deadlock_detected
+	}
+	unavailableSQLStates = map[string]bool{
+		"53300": true, // too_many_connections
+		"57P03": true, // cannot_connect_now
+	}
+)
+
+// TxRetryPolicy bounds the retries of a transaction that failed with a retryable SQLSTATE
+// Retries wait a random duration of up to BaseDelay doubled per attempt and capped at MaxDelay
+type TxRetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultTxRetryPolicy is suitable for short transactions on a few hot rows
+var DefaultTxRetryPolicy = TxRetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   10 * time.Millisecond,
+	MaxDelay:    250 * time.Millisecond,
+}
+
+// TxBeginner starts transactions, implemented by *pgxpool.Pool and pgx.Conn
+type TxBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// RunInTx runs fn in a transaction and commits it, rolling back when fn or the commit fails
+// When the transaction fails with a retryable SQLSTATE it is run again from the start, so fn must
+// not have side effects outside the transaction. Once the retries are exhausted the returned error
+// wraps ErrTxConflict or ErrTxUnavailable together with the last database error
+// Any other error, including the ones returned by fn, is returned as is
+func RunInTx(ctx context.Context, db TxBeginner, opts pgx.TxOptions, policy TxRetryPolicy, fn func(tx pgx.Tx) error) error {
+	maxAttempts := max(policy.MaxAttempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		err := runTxOnce(ctx, db, opts, fn)
+		if err == nil {
+			return nil
+		}
+
+		class := retryClass(err)
+		if class == nil {
+			return err
+		}
+		if attempt >= maxAttempts {
+			return fmt.Errorf("%w after %d attempts: %w", class, attempt, err)
+		}
+
+		delay := retryDelay(policy, attempt)
+		slog.WarnContext(ctx, "retrying transaction",
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay),
+			slog.String("error", err.Error()),
+		)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-time.After(delay):
+		}
+	}
+}
+
+// runTxOnce runs a single attempt of the transaction
+func runTxOnce(ctx context.Context, db TxBeginner, opts pgx.TxOptions, fn func(tx pgx.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	// Ensure transaction is rolled back only on error
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				slog.ErrorContext(ctx, "failed to rollback transaction",
+					slog.String("error", rbErr.Error()),
+				)
+			}
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// retryClass returns the error reported when retries of err are exhausted, nil when err is not retryable
+func retryClass(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+
+	switch {
+	case conflictSQLStates[pgErr.Code]:
+		return ErrTxConflict
+	case unavailableSQLStates[pgErr.Code]:
+		return ErrTxUnavailable
+	default:
+		return nil
+	}
+}
+
+// retryDelay returns a jittered delay before the given retry so that conflicting transactions spread out
+func retryDelay(policy TxRetryPolicy, attempt int) time.Duration {
+	if policy.BaseDelay <= 0 {
+		return 0
+	}
+
+	ceiling := policy.BaseDelay << min(attempt-1, 16)
+	if policy.MaxDelay > 0 && ceiling > policy.MaxDelay {
+		ceiling = policy.MaxDelay
+	}
+	return rand.N(ceiling) + 1
+}
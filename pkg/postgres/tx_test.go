@@ -0,0 +1,166 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTx records how a transaction ended, commitErr is returned by Commit
+type fakeTx struct {
+	pgx.Tx
+	commitErr  error
+	committed  bool
+	rolledBack bool
+}
+
+func (tx *fakeTx) Commit(ctx context.Context) error {
+	if tx.commitErr != nil {
+		return tx.commitErr
+	}
+	tx.committed = true
+	return nil
+}
+
+func (tx *fakeTx) Rollback(ctx context.Context) error {
+	tx.rolledBack = true
+	return nil
+}
+
+// fakeBeginner hands out a new fakeTx per attempt
+type fakeBeginner struct {
+	commitErrs []error
+	txs        []*fakeTx
+}
+
+func (b *fakeBeginner) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	tx := &fakeTx{}
+	if len(b.txs) < len(b.commitErrs) {
+		tx.commitErr = b.commitErrs[len(b.txs)]
+	}
+	b.txs = append(b.txs, tx)
+	return tx, nil
+}
+
+var testPolicy = TxRetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+
+func TestRunInTx(t *testing.T) {
+	serializationFailure := &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+	deadlock := &pgconn.PgError{Code: "40P01", Message: "deadlock detected"}
+	tooManyConnections := &pgconn.PgError{Code: "53300", Message: "too many connections"}
+	uniqueViolation := &pgconn.PgError{Code: "23505", Message: "duplicate key"}
+
+	tests := []struct {
+		name          string
+		fnErrs        []error
+		commitErrs    []error
+		expectedErr   error
+		expectedCalls int
+	}{
+		{
+			name:          "commits on success",
+			expectedCalls: 1,
+		},
+		{
+			name:          "retries serialization failures until success",
+			fnErrs:        []error{serializationFailure, deadlock},
+			expectedCalls: 3,
+		},
+		{
+			name:          "retries serialization failures on commit",
+			commitErrs:    []error{serializationFailure},
+			expectedCalls: 2,
+		},
+		{
+			name:          "exhausted conflicts",
+			fnErrs:        []error{serializationFailure, serializationFailure, deadlock},
+			expectedErr:   ErrTxConflict,
+			expectedCalls: 3,
+		},
+		{
+			name:          "exhausted unavailability",
+			fnErrs:        []error{tooManyConnections, tooManyConnections, tooManyConnections},
+			expectedErr:   ErrTxUnavailable,
+			expectedCalls: 3,
+		},
+		{
+			name:          "other database errors are not retried",
+			fnErrs:        []error{uniqueViolation},
+			expectedErr:   uniqueViolation,
+			expectedCalls: 1,
+		},
+		{
+			name:          "errors of fn are returned as is",
+			fnErrs:        []error{pgx.ErrNoRows},
+			expectedErr:   pgx.ErrNoRows,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &fakeBeginner{commitErrs: tt.commitErrs}
+			calls := 0
+			err := RunInTx(context.Background(), db, pgx.TxOptions{IsoLevel: pgx.Serializable}, testPolicy, func(tx pgx.Tx) error {
+				calls++
+				if calls <= len(tt.fnErrs) {
+					return tt.fnErrs[calls-1]
+				}
+				return nil
+			})
+
+			assert.Equal(t, tt.expectedCalls, calls)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.False(t, db.txs[len(db.txs)-1].committed)
+			} else {
+				assert.NoError(t, err)
+				assert.True(t, db.txs[len(db.txs)-1].committed)
+			}
+			// every failed attempt is rolled back
+			for _, tx := range db.txs[:len(db.txs)-1] {
+				assert.True(t, tx.rolledBack)
+			}
+		})
+	}
+}
+
+func TestRunInTx_ExhaustedErrorKeepsCause(t *testing.T) {
+	serializationFailure := &pgconn.PgError{Code: "40001"}
+	err := RunInTx(context.Background(), &fakeBeginner{}, pgx.TxOptions{}, TxRetryPolicy{MaxAttempts: 1}, func(tx pgx.Tx) error {
+		return serializationFailure
+	})
+
+	assert.ErrorIs(t, err, ErrTxConflict)
+	var pgErr *pgconn.PgError
+	assert.True(t, errors.As(err, &pgErr))
+	assert.Equal(t, "40001", pgErr.Code)
+}
+
+func TestRunInTx_StopsWhenContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := RunInTx(ctx, &fakeBeginner{}, pgx.TxOptions{}, TxRetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour}, func(tx pgx.Tx) error {
+		calls++
+		cancel()
+		return &pgconn.PgError{Code: "40001"}
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := TxRetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	for attempt := 1; attempt <= 10; attempt++ {
+		delay := retryDelay(policy, attempt)
+		assert.Positive(t, delay)
+		assert.LessOrEqual(t, delay, 50*time.Millisecond)
+	}
+	assert.Zero(t, retryDelay(TxRetryPolicy{}, 1))
+}
@@ -0,0 +1,833 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: packman/v1/pack_service.proto
+
+package packmanv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CalculatePacksRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Quantity int64                  `protobuf:"varint,1,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	// as_of_version or as_of select a historical configuration instead of the active one
+	AsOfVersion   int64                  `protobuf:"varint,2,opt,name=as_of_version,json=asOfVersion,proto3" json:"as_of_version,omitempty"`
+	AsOf          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalculatePacksRequest) Reset() {
+	*x = CalculatePacksRequest{}
+	mi := &file_packman_v1_pack_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalculatePacksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalculatePacksRequest) ProtoMessage() {}
+
+func (x *CalculatePacksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_packman_v1_pack_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalculatePacksRequest.ProtoReflect.Descriptor instead.
+func (*CalculatePacksRequest) Descriptor() ([]byte, []int) {
+	return file_packman_v1_pack_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CalculatePacksRequest) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *CalculatePacksRequest) GetAsOfVersion() int64 {
+	if x != nil {
+		return x.AsOfVersion
+	}
+	return 0
+}
+
+func (x *CalculatePacksRequest) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
+type CalculatePacksResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Quantity int64                  `protobuf:"varint,1,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	// packs are ordered from the largest size to the smallest
+	Packs []*Pack `protobuf:"bytes,2,rep,name=packs,proto3" json:"packs,omitempty"`
+	// version is set when the calculation used a historical configuration
+	Version       int64 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalculatePacksResponse) Reset() {
+	*x = CalculatePacksResponse{}
+	mi := &file_packman_v1_pack_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalculatePacksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalculatePacksResponse) ProtoMessage() {}
+
+func (x *CalculatePacksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_packman_v1_pack_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalculatePacksResponse.ProtoReflect.Descriptor instead.
+func (*CalculatePacksResponse) Descriptor() ([]byte, []int) {
+	return file_packman_v1_pack_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CalculatePacksResponse) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *CalculatePacksResponse) GetPacks() []*Pack {
+	if x != nil {
+		return x.Packs
+	}
+	return nil
+}
+
+func (x *CalculatePacksResponse) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type Pack struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Size          int64                  `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pack) Reset() {
+	*x = Pack{}
+	mi := &file_packman_v1_pack_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pack) ProtoMessage() {}
+
+func (x *Pack) ProtoReflect() protoreflect.Message {
+	mi := &file_packman_v1_pack_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pack.ProtoReflect.Descriptor instead.
+func (*Pack) Descriptor() ([]byte, []int) {
+	return file_packman_v1_pack_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Pack) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Pack) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type GetPackSizesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPackSizesRequest) Reset() {
+	*x = GetPackSizesRequest{}
+	mi := &file_packman_v1_pack_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPackSizesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPackSizesRequest) ProtoMessage() {}
+
+func (x *GetPackSizesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_packman_v1_pack_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPackSizesRequest.ProtoReflect.Descriptor instead.
+func (*GetPackSizesRequest) Descriptor() ([]byte, []int) {
+	return file_packman_v1_pack_service_proto_rawDescGZIP(), []int{3}
+}
+
+type GetPackSizesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Configuration *PackConfiguration     `protobuf:"bytes,1,opt,name=configuration,proto3" json:"configuration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPackSizesResponse) Reset() {
+	*x = GetPackSizesResponse{}
+	mi := &file_packman_v1_pack_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPackSizesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPackSizesResponse) ProtoMessage() {}
+
+func (x *GetPackSizesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_packman_v1_pack_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPackSizesResponse.ProtoReflect.Descriptor instead.
+func (*GetPackSizesResponse) Descriptor() ([]byte, []int) {
+	return file_packman_v1_pack_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetPackSizesResponse) GetConfiguration() *PackConfiguration {
+	if x != nil {
+		return x.Configuration
+	}
+	return nil
+}
+
+type PackConfiguration struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PackSizes     []int64                `protobuf:"varint,1,rep,packed,name=pack_sizes,json=packSizes,proto3" json:"pack_sizes,omitempty"`
+	Version       int64                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	UpdatedBy     string                 `protobuf:"bytes,4,opt,name=updated_by,json=updatedBy,proto3" json:"updated_by,omitempty"`
+	ApprovedBy    string                 `protobuf:"bytes,5,opt,name=approved_by,json=approvedBy,proto3" json:"approved_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PackConfiguration) Reset() {
+	*x = PackConfiguration{}
+	mi := &file_packman_v1_pack_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PackConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PackConfiguration) ProtoMessage() {}
+
+func (x *PackConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_packman_v1_pack_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PackConfiguration.ProtoReflect.Descriptor instead.
+func (*PackConfiguration) Descriptor() ([]byte, []int) {
+	return file_packman_v1_pack_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PackConfiguration) GetPackSizes() []int64 {
+	if x != nil {
+		return x.PackSizes
+	}
+	return nil
+}
+
+func (x *PackConfiguration) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *PackConfiguration) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *PackConfiguration) GetUpdatedBy() string {
+	if x != nil {
+		return x.UpdatedBy
+	}
+	return ""
+}
+
+func (x *PackConfiguration) GetApprovedBy() string {
+	if x != nil {
+		return x.ApprovedBy
+	}
+	return ""
+}
+
+type UpdatePackSizesRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	PackSizes []int64                `protobuf:"varint,1,rep,packed,name=pack_sizes,json=packSizes,proto3" json:"pack_sizes,omitempty"`
+	// updated_by is ignored when the call is authenticated, the caller is recorded instead
+	UpdatedBy     string `protobuf:"bytes,2,opt,name=updated_by,json=updatedBy,proto3" json:"updated_by,omitempty"`
+	Reason        string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePackSizesRequest) Reset() {
+	*x = UpdatePackSizesRequest{}
+	mi := &file_packman_v1_pack_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePackSizesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePackSizesRequest) ProtoMessage() {}
+
+func (x *UpdatePackSizesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_packman_v1_pack_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePackSizesRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePackSizesRequest) Descriptor() ([]byte, []int) {
+	return file_packman_v1_pack_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdatePackSizesRequest) GetPackSizes() []int64 {
+	if x != nil {
+		return x.PackSizes
+	}
+	return nil
+}
+
+func (x *UpdatePackSizesRequest) GetUpdatedBy() string {
+	if x != nil {
+		return x.UpdatedBy
+	}
+	return ""
+}
+
+func (x *UpdatePackSizesRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type UpdatePackSizesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Configuration *PackConfiguration     `protobuf:"bytes,1,opt,name=configuration,proto3" json:"configuration,omitempty"`
+	Warnings      []*PackSizeWarning     `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePackSizesResponse) Reset() {
+	*x = UpdatePackSizesResponse{}
+	mi := &file_packman_v1_pack_service_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePackSizesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePackSizesResponse) ProtoMessage() {}
+
+func (x *UpdatePackSizesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_packman_v1_pack_service_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePackSizesResponse.ProtoReflect.Descriptor instead.
+func (*UpdatePackSizesResponse) Descriptor() ([]byte, []int) {
+	return file_packman_v1_pack_service_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdatePackSizesResponse) GetConfiguration() *PackConfiguration {
+	if x != nil {
+		return x.Configuration
+	}
+	return nil
+}
+
+func (x *UpdatePackSizesResponse) GetWarnings() []*PackSizeWarning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+type PackSizeWarning struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// code is one of COMMON_DIVISOR, UNUSED_SIZE, EXCESSIVE_OVERSHOOT or TOO_MANY_SIZES
+	Code          string  `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string  `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Sizes         []int64 `protobuf:"varint,3,rep,packed,name=sizes,proto3" json:"sizes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PackSizeWarning) Reset() {
+	*x = PackSizeWarning{}
+	mi := &file_packman_v1_pack_service_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PackSizeWarning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PackSizeWarning) ProtoMessage() {}
+
+func (x *PackSizeWarning) ProtoReflect() protoreflect.Message {
+	mi := &file_packman_v1_pack_service_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PackSizeWarning.ProtoReflect.Descriptor instead.
+func (*PackSizeWarning) Descriptor() ([]byte, []int) {
+	return file_packman_v1_pack_service_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PackSizeWarning) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *PackSizeWarning) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *PackSizeWarning) GetSizes() []int64 {
+	if x != nil {
+		return x.Sizes
+	}
+	return nil
+}
+
+type GetPackSizeHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPackSizeHistoryRequest) Reset() {
+	*x = GetPackSizeHistoryRequest{}
+	mi := &file_packman_v1_pack_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPackSizeHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPackSizeHistoryRequest) ProtoMessage() {}
+
+func (x *GetPackSizeHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_packman_v1_pack_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPackSizeHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetPackSizeHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_packman_v1_pack_service_proto_rawDescGZIP(), []int{9}
+}
+
+type GetPackSizeHistoryResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Current *PackConfiguration     `protobuf:"bytes,1,opt,name=current,proto3" json:"current,omitempty"`
+	// versions are the archived configurations, ordered from the oldest to the newest
+	Versions      []*PackConfigurationVersion `protobuf:"bytes,2,rep,name=versions,proto3" json:"versions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPackSizeHistoryResponse) Reset() {
+	*x = GetPackSizeHistoryResponse{}
+	mi := &file_packman_v1_pack_service_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPackSizeHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPackSizeHistoryResponse) ProtoMessage() {}
+
+func (x *GetPackSizeHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_packman_v1_pack_service_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPackSizeHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetPackSizeHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_packman_v1_pack_service_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetPackSizeHistoryResponse) GetCurrent() *PackConfiguration {
+	if x != nil {
+		return x.Current
+	}
+	return nil
+}
+
+func (x *GetPackSizeHistoryResponse) GetVersions() []*PackConfigurationVersion {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+type PackConfigurationVersion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	PackSizes     []int64                `protobuf:"varint,2,rep,packed,name=pack_sizes,json=packSizes,proto3" json:"pack_sizes,omitempty"`
+	ArchivedAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=archived_at,json=archivedAt,proto3" json:"archived_at,omitempty"`
+	UpdatedBy     string                 `protobuf:"bytes,4,opt,name=updated_by,json=updatedBy,proto3" json:"updated_by,omitempty"`
+	ApprovedBy    string                 `protobuf:"bytes,5,opt,name=approved_by,json=approvedBy,proto3" json:"approved_by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PackConfigurationVersion) Reset() {
+	*x = PackConfigurationVersion{}
+	mi := &file_packman_v1_pack_service_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PackConfigurationVersion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PackConfigurationVersion) ProtoMessage() {}
+
+func (x *PackConfigurationVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_packman_v1_pack_service_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PackConfigurationVersion.ProtoReflect.Descriptor instead.
+func (*PackConfigurationVersion) Descriptor() ([]byte, []int) {
+	return file_packman_v1_pack_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PackConfigurationVersion) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *PackConfigurationVersion) GetPackSizes() []int64 {
+	if x != nil {
+		return x.PackSizes
+	}
+	return nil
+}
+
+func (x *PackConfigurationVersion) GetArchivedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ArchivedAt
+	}
+	return nil
+}
+
+func (x *PackConfigurationVersion) GetUpdatedBy() string {
+	if x != nil {
+		return x.UpdatedBy
+	}
+	return ""
+}
+
+func (x *PackConfigurationVersion) GetApprovedBy() string {
+	if x != nil {
+		return x.ApprovedBy
+	}
+	return ""
+}
+
+var File_packman_v1_pack_service_proto protoreflect.FileDescriptor
+
+const file_packman_v1_pack_service_proto_rawDesc = "" +
+	"\n" +
+	"\x1dpackman/v1/pack_service.proto\x12\n" +
+	"packman.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x88\x01\n" +
+	"\x15CalculatePacksRequest\x12\x1a\n" +
+	"\bquantity\x18\x01 \x01(\x03R\bquantity\x12\"\n" +
+	"\ras_of_version\x18\x02 \x01(\x03R\vasOfVersion\x12/\n" +
+	"\x05as_of\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\"v\n" +
+	"\x16CalculatePacksResponse\x12\x1a\n" +
+	"\bquantity\x18\x01 \x01(\x03R\bquantity\x12&\n" +
+	"\x05packs\x18\x02 \x03(\v2\x10.packman.v1.PackR\x05packs\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x03R\aversion\"0\n" +
+	"\x04Pack\x12\x12\n" +
+	"\x04size\x18\x01 \x01(\x03R\x04size\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\"\x15\n" +
+	"\x13GetPackSizesRequest\"[\n" +
+	"\x14GetPackSizesResponse\x12C\n" +
+	"\rconfiguration\x18\x01 \x01(\v2\x1d.packman.v1.PackConfigurationR\rconfiguration\"\xc7\x01\n" +
+	"\x11PackConfiguration\x12\x1d\n" +
+	"\n" +
+	"pack_sizes\x18\x01 \x03(\x03R\tpackSizes\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x03R\aversion\x129\n" +
+	"\n" +
+	"updated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_by\x18\x04 \x01(\tR\tupdatedBy\x12\x1f\n" +
+	"\vapproved_by\x18\x05 \x01(\tR\n" +
+	"approvedBy\"n\n" +
+	"\x16UpdatePackSizesRequest\x12\x1d\n" +
+	"\n" +
+	"pack_sizes\x18\x01 \x03(\x03R\tpackSizes\x12\x1d\n" +
+	"\n" +
+	"updated_by\x18\x02 \x01(\tR\tupdatedBy\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\x97\x01\n" +
+	"\x17UpdatePackSizesResponse\x12C\n" +
+	"\rconfiguration\x18\x01 \x01(\v2\x1d.packman.v1.PackConfigurationR\rconfiguration\x127\n" +
+	"\bwarnings\x18\x02 \x03(\v2\x1b.packman.v1.PackSizeWarningR\bwarnings\"U\n" +
+	"\x0fPackSizeWarning\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x14\n" +
+	"\x05sizes\x18\x03 \x03(\x03R\x05sizes\"\x1b\n" +
+	"\x19GetPackSizeHistoryRequest\"\x97\x01\n" +
+	"\x1aGetPackSizeHistoryResponse\x127\n" +
+	"\acurrent\x18\x01 \x01(\v2\x1d.packman.v1.PackConfigurationR\acurrent\x12@\n" +
+	"\bversions\x18\x02 \x03(\v2$.packman.v1.PackConfigurationVersionR\bversions\"\xd0\x01\n" +
+	"\x18PackConfigurationVersion\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x03R\aversion\x12\x1d\n" +
+	"\n" +
+	"pack_sizes\x18\x02 \x03(\x03R\tpackSizes\x12;\n" +
+	"\varchived_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"archivedAt\x12\x1d\n" +
+	"\n" +
+	"updated_by\x18\x04 \x01(\tR\tupdatedBy\x12\x1f\n" +
+	"\vapproved_by\x18\x05 \x01(\tR\n" +
+	"approvedBy2\xfa\x02\n" +
+	"\vPackService\x12W\n" +
+	"\x0eCalculatePacks\x12!.packman.v1.CalculatePacksRequest\x1a\".packman.v1.CalculatePacksResponse\x12Q\n" +
+	"\fGetPackSizes\x12\x1f.packman.v1.GetPackSizesRequest\x1a .packman.v1.GetPackSizesResponse\x12Z\n" +
+	"\x0fUpdatePackSizes\x12\".packman.v1.UpdatePackSizesRequest\x1a#.packman.v1.UpdatePackSizesResponse\x12c\n" +
+	"\x12GetPackSizeHistory\x12%.packman.v1.GetPackSizeHistoryRequest\x1a&.packman.v1.GetPackSizeHistoryResponseB7Z5github.com/nsaltun/packman/proto/packman/v1;packmanv1b\x06proto3"
+
+var (
+	file_packman_v1_pack_service_proto_rawDescOnce sync.Once
+	file_packman_v1_pack_service_proto_rawDescData []byte
+)
+
+func file_packman_v1_pack_service_proto_rawDescGZIP() []byte {
+	file_packman_v1_pack_service_proto_rawDescOnce.Do(func() {
+		file_packman_v1_pack_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_packman_v1_pack_service_proto_rawDesc), len(file_packman_v1_pack_service_proto_rawDesc)))
+	})
+	return file_packman_v1_pack_service_proto_rawDescData
+}
+
+var file_packman_v1_pack_service_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_packman_v1_pack_service_proto_goTypes = []any{
+	(*CalculatePacksRequest)(nil),      // 0: packman.v1.CalculatePacksRequest
+	(*CalculatePacksResponse)(nil),     // 1: packman.v1.CalculatePacksResponse
+	(*Pack)(nil),                       // 2: packman.v1.Pack
+	(*GetPackSizesRequest)(nil),        // 3: packman.v1.GetPackSizesRequest
+	(*GetPackSizesResponse)(nil),       // 4: packman.v1.GetPackSizesResponse
+	(*PackConfiguration)(nil),          // 5: packman.v1.PackConfiguration
+	(*UpdatePackSizesRequest)(nil),     // 6: packman.v1.UpdatePackSizesRequest
+	(*UpdatePackSizesResponse)(nil),    // 7: packman.v1.UpdatePackSizesResponse
+	(*PackSizeWarning)(nil),            // 8: packman.v1.PackSizeWarning
+	(*GetPackSizeHistoryRequest)(nil),  // 9: packman.v1.GetPackSizeHistoryRequest
+	(*GetPackSizeHistoryResponse)(nil), // 10: packman.v1.GetPackSizeHistoryResponse
+	(*PackConfigurationVersion)(nil),   // 11: packman.v1.PackConfigurationVersion
+	(*timestamppb.Timestamp)(nil),      // 12: google.protobuf.Timestamp
+}
+var file_packman_v1_pack_service_proto_depIdxs = []int32{
+	12, // 0: packman.v1.CalculatePacksRequest.as_of:type_name -> google.protobuf.Timestamp
+	2,  // 1: packman.v1.CalculatePacksResponse.packs:type_name -> packman.v1.Pack
+	5,  // 2: packman.v1.GetPackSizesResponse.configuration:type_name -> packman.v1.PackConfiguration
+	12, // 3: packman.v1.PackConfiguration.updated_at:type_name -> google.protobuf.Timestamp
+	5,  // 4: packman.v1.UpdatePackSizesResponse.configuration:type_name -> packman.v1.PackConfiguration
+	8,  // 5: packman.v1.UpdatePackSizesResponse.warnings:type_name -> packman.v1.PackSizeWarning
+	5,  // 6: packman.v1.GetPackSizeHistoryResponse.current:type_name -> packman.v1.PackConfiguration
+	11, // 7: packman.v1.GetPackSizeHistoryResponse.versions:type_name -> packman.v1.PackConfigurationVersion
+	12, // 8: packman.v1.PackConfigurationVersion.archived_at:type_name -> google.protobuf.Timestamp
+	0,  // 9: packman.v1.PackService.CalculatePacks:input_type -> packman.v1.CalculatePacksRequest
+	3,  // 10: packman.v1.PackService.GetPackSizes:input_type -> packman.v1.GetPackSizesRequest
+	6,  // 11: packman.v1.PackService.UpdatePackSizes:input_type -> packman.v1.UpdatePackSizesRequest
+	9,  // 12: packman.v1.PackService.GetPackSizeHistory:input_type -> packman.v1.GetPackSizeHistoryRequest
+	1,  // 13: packman.v1.PackService.CalculatePacks:output_type -> packman.v1.CalculatePacksResponse
+	4,  // 14: packman.v1.PackService.GetPackSizes:output_type -> packman.v1.GetPackSizesResponse
+	7,  // 15: packman.v1.PackService.UpdatePackSizes:output_type -> packman.v1.UpdatePackSizesResponse
+	10, // 16: packman.v1.PackService.GetPackSizeHistory:output_type -> packman.v1.GetPackSizeHistoryResponse
+	13, // [13:17] is the sub-list for method output_type
+	9,  // [9:13] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_packman_v1_pack_service_proto_init() }
+func file_packman_v1_pack_service_proto_init() {
+	if File_packman_v1_pack_service_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_packman_v1_pack_service_proto_rawDesc), len(file_packman_v1_pack_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_packman_v1_pack_service_proto_goTypes,
+		DependencyIndexes: file_packman_v1_pack_service_proto_depIdxs,
+		MessageInfos:      file_packman_v1_pack_service_proto_msgTypes,
+	}.Build()
+	File_packman_v1_pack_service_proto = out.File
+	file_packman_v1_pack_service_proto_goTypes = nil
+	file_packman_v1_pack_service_proto_depIdxs = nil
+}
@@ -0,0 +1,253 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: packman/v1/pack_service.proto
+
+package packmanv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PackService_CalculatePacks_FullMethodName     = "/packman.v1.PackService/CalculatePacks"
+	PackService_GetPackSizes_FullMethodName       = "/packman.v1.PackService/GetPackSizes"
+	PackService_UpdatePackSizes_FullMethodName    = "/packman.v1.PackService/UpdatePackSizes"
+	PackService_GetPackSizeHistory_FullMethodName = "/packman.v1.PackService/GetPackSizeHistory"
+)
+
+// PackServiceClient is the client API for PackService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PackService calculates packs and manages the pack size configuration, like the /api/v1 HTTP routes.
+// Calls are authenticated with the "authorization: Bearer <credential>" or "x-api-key" metadata
+// and need the same scopes as the HTTP routes. The "x-request-id" metadata is returned in the
+// response headers and generated when it is missing.
+type PackServiceClient interface {
+	// CalculatePacks calculates the optimal pack combination for a quantity, requires the calculate scope
+	CalculatePacks(ctx context.Context, in *CalculatePacksRequest, opts ...grpc.CallOption) (*CalculatePacksResponse, error)
+	// GetPackSizes returns the active configuration, requires the read scope
+	GetPackSizes(ctx context.Context, in *GetPackSizesRequest, opts ...grpc.CallOption) (*GetPackSizesResponse, error)
+	// UpdatePackSizes replaces the pack sizes with a new version, requires the admin scope
+	UpdatePackSizes(ctx context.Context, in *UpdatePackSizesRequest, opts ...grpc.CallOption) (*UpdatePackSizesResponse, error)
+	// GetPackSizeHistory returns the active configuration and every earlier version, requires the read scope
+	GetPackSizeHistory(ctx context.Context, in *GetPackSizeHistoryRequest, opts ...grpc.CallOption) (*GetPackSizeHistoryResponse, error)
+}
+
+type packServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPackServiceClient(cc grpc.ClientConnInterface) PackServiceClient {
+	return &packServiceClient{cc}
+}
+
+func (c *packServiceClient) CalculatePacks(ctx context.Context, in *CalculatePacksRequest, opts ...grpc.CallOption) (*CalculatePacksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CalculatePacksResponse)
+	err := c.cc.Invoke(ctx, PackService_CalculatePacks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packServiceClient) GetPackSizes(ctx context.Context, in *GetPackSizesRequest, opts ...grpc.CallOption) (*GetPackSizesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPackSizesResponse)
+	err := c.cc.Invoke(ctx, PackService_GetPackSizes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packServiceClient) UpdatePackSizes(ctx context.Context, in *UpdatePackSizesRequest, opts ...grpc.CallOption) (*UpdatePackSizesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdatePackSizesResponse)
+	err := c.cc.Invoke(ctx, PackService_UpdatePackSizes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *packServiceClient) GetPackSizeHistory(ctx context.Context, in *GetPackSizeHistoryRequest, opts ...grpc.CallOption) (*GetPackSizeHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetPackSizeHistoryResponse)
+	err := c.cc.Invoke(ctx, PackService_GetPackSizeHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PackServiceServer is the server API for PackService service.
+// All implementations must embed UnimplementedPackServiceServer
+// for forward compatibility.
+//
+// PackService calculates packs and manages the pack size configuration, like the /api/v1 HTTP routes.
+// Calls are authenticated with the "authorization: Bearer <credential>" or "x-api-key" metadata
+// and need the same scopes as the HTTP routes. The "x-request-id" metadata is returned in the
+// response headers and generated when it is missing.
+type PackServiceServer interface {
+	// CalculatePacks calculates the optimal pack combination for a quantity, requires the calculate scope
+	CalculatePacks(context.Context, *CalculatePacksRequest) (*CalculatePacksResponse, error)
+	// GetPackSizes returns the active configuration, requires the read scope
+	GetPackSizes(context.Context, *GetPackSizesRequest) (*GetPackSizesResponse, error)
+	// UpdatePackSizes replaces the pack sizes with a new version, requires the admin scope
+	UpdatePackSizes(context.Context, *UpdatePackSizesRequest) (*UpdatePackSizesResponse, error)
+	// GetPackSizeHistory returns the active configuration and every earlier version, requires the read scope
+	GetPackSizeHistory(context.Context, *GetPackSizeHistoryRequest) (*GetPackSizeHistoryResponse, error)
+	mustEmbedUnimplementedPackServiceServer()
+}
+
+// UnimplementedPackServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPackServiceServer struct{}
+
+func (UnimplementedPackServiceServer) CalculatePacks(context.Context, *CalculatePacksRequest) (*CalculatePacksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CalculatePacks not implemented")
+}
+func (UnimplementedPackServiceServer) GetPackSizes(context.Context, *GetPackSizesRequest) (*GetPackSizesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetPackSizes not implemented")
+}
+func (UnimplementedPackServiceServer) UpdatePackSizes(context.Context, *UpdatePackSizesRequest) (*UpdatePackSizesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdatePackSizes not implemented")
+}
+func (UnimplementedPackServiceServer) GetPackSizeHistory(context.Context, *GetPackSizeHistoryRequest) (*GetPackSizeHistoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetPackSizeHistory not implemented")
+}
+func (UnimplementedPackServiceServer) mustEmbedUnimplementedPackServiceServer() {}
+func (UnimplementedPackServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafePackServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PackServiceServer will
+// result in compilation errors.
+type UnsafePackServiceServer interface {
+	mustEmbedUnimplementedPackServiceServer()
+}
+
+func RegisterPackServiceServer(s grpc.ServiceRegistrar, srv PackServiceServer) {
+	// If the following call panics, it indicates UnimplementedPackServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PackService_ServiceDesc, srv)
+}
+
+func _PackService_CalculatePacks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CalculatePacksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackServiceServer).CalculatePacks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackService_CalculatePacks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackServiceServer).CalculatePacks(ctx, req.(*CalculatePacksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackService_GetPackSizes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPackSizesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackServiceServer).GetPackSizes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackService_GetPackSizes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackServiceServer).GetPackSizes(ctx, req.(*GetPackSizesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackService_UpdatePackSizes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePackSizesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackServiceServer).UpdatePackSizes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackService_UpdatePackSizes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackServiceServer).UpdatePackSizes(ctx, req.(*UpdatePackSizesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PackService_GetPackSizeHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPackSizeHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PackServiceServer).GetPackSizeHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PackService_GetPackSizeHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PackServiceServer).GetPackSizeHistory(ctx, req.(*GetPackSizeHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PackService_ServiceDesc is the grpc.ServiceDesc for PackService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PackService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "packman.v1.PackService",
+	HandlerType: (*PackServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CalculatePacks",
+			Handler:    _PackService_CalculatePacks_Handler,
+		},
+		{
+			MethodName: "GetPackSizes",
+			Handler:    _PackService_GetPackSizes_Handler,
+		},
+		{
+			MethodName: "UpdatePackSizes",
+			Handler:    _PackService_UpdatePackSizes_Handler,
+		},
+		{
+			MethodName: "GetPackSizeHistory",
+			Handler:    _PackService_GetPackSizeHistory_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "packman/v1/pack_service.proto",
+}